@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ubuntu/zsys"
+	"github.com/ubuntu/zsys/internal/i18n"
+)
+
+// readAptHookPackages parses the package list sent by APT to a DPkg::Pre-Install-Pkgs hook using the version 2 or 3
+// protocol: a version line, the configuration, an empty line and then one line per package operation.
+// The whole input is consumed so that APT is never blocked writing to the hook.
+func readAptHookPackages(r io.Reader) (*zsys.PackageChanges, error) {
+	br := bufio.NewReader(r)
+
+	version, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	version = strings.TrimSpace(version)
+	if version != "VERSION 2" && version != "VERSION 3" {
+		io.Copy(ioutil.Discard, br)
+		return nil, fmt.Errorf(i18n.G("unsupported APT hook protocol %q"), version)
+	}
+
+	changes := &zsys.PackageChanges{}
+	seen := make(map[string]bool)
+	inConfig := true
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if inConfig {
+			if line == "" {
+				inConfig = false
+			}
+		} else if line != "" {
+			addAptHookPackage(changes, seen, version, line)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// addAptHookPackage records a single package operation line in changes, if it wasn't already seen.
+// Version 2 lines are "name old_version direction new_version action" and version 3 ones insert the architecture
+// and multi-arch attribute after each version. Versions are "-" when the package isn't installed before or after.
+func addAptHookPackage(changes *zsys.PackageChanges, seen map[string]bool, version, line string) {
+	fields := strings.Fields(line)
+	var name, oldVersion, action string
+	switch {
+	case version == "VERSION 2" && len(fields) >= 5:
+		name, oldVersion, action = fields[0], fields[1], fields[4]
+	case version == "VERSION 3" && len(fields) >= 9:
+		name, oldVersion, action = fields[0], fields[1], fields[8]
+	default:
+		return
+	}
+
+	// Configure steps follow an unpack or only finish a previous operation.
+	if action == "**CONFIGURE**" || seen[name] {
+		return
+	}
+	seen[name] = true
+
+	switch {
+	case action == "**REMOVE**":
+		changes.Removed = append(changes.Removed, name)
+	case oldVersion == "-":
+		changes.Installed = append(changes.Installed, name)
+	default:
+		changes.Upgraded = append(changes.Upgraded, name)
+	}
+}
@@ -13,8 +13,9 @@ import (
 )
 
 var (
-	printModifiedBoot bool
-	updateMenuAuto    bool
+	printModifiedBoot   bool
+	updateMenuAuto      bool
+	bootNextRevertUsers bool
 
 	bootCmd = &cobra.Command{
 		Use:    "boot COMMAND",
@@ -41,6 +42,14 @@ var (
 		Args:  cobra.NoArgs,
 		Run:   func(cmd *cobra.Command, args []string) { cmdErr = updateBootMenu(updateMenuAuto) },
 	}
+	bootNextCmd = &cobra.Command{
+		Use:   "next STATE_ID",
+		Short: i18n.G("Boot once on a system state of the current machine on next boot, without changing the boot menu"),
+		Long: i18n.G(`Boot once on a system state of the current machine on next boot, without changing the boot menu.
+Following boots are on the state committed by this one. Using the current state cancels the request.`),
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { cmdErr = bootNext(args[0], bootNextRevertUsers) },
+	}
 	updateLastUsedCmd = &cobra.Command{
 		Use:   "update-lastused",
 		Short: i18n.G("Update last used timestamp"),
@@ -57,6 +66,8 @@ func init() {
 	bootCmd.AddCommand(updateMenuCmd)
 	updateMenuCmd.Flags().BoolVarP(&updateMenuAuto, "auto", "", false, i18n.G("Signal this is an automated request triggered by script"))
 	bootCmd.AddCommand(updateLastUsedCmd)
+	bootCmd.AddCommand(bootNextCmd)
+	bootNextCmd.Flags().BoolVarP(&bootNextRevertUsers, "revert-userdata", "", false, i18n.G("Revert user data to the state too"))
 }
 
 func bootPrepare(printModifiedBoot bool) (err error) {
@@ -204,3 +215,38 @@ func updateLastUsed() error {
 
 	return nil
 }
+
+func bootNext(stateName string, revertUserData bool) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.BootNext(ctx, &zsys.BootNextRequest{
+		StateName:      stateName,
+		RevertUserData: revertUserData,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
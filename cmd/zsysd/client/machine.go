@@ -37,6 +37,7 @@ var (
 
 var (
 	fullInfo bool
+	showTags []string
 )
 
 func init() {
@@ -45,6 +46,7 @@ func init() {
 	machineCmd.AddCommand(listCmd)
 
 	showCmd.Flags().BoolVarP(&fullInfo, "full", "", false, i18n.G("Give more detail informations on each machine."))
+	showCmd.Flags().StringArrayVarP(&showTags, "tag", "t", nil, i18n.G("Only show states having this tag, as key or key=value. Can be repeated."))
 
 	cmdhandler.RegisterAlias(listCmd, rootCmd)
 	cmdhandler.RegisterAlias(showCmd, rootCmd)
@@ -64,7 +66,7 @@ func show(args []string) error {
 	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
 	defer cancel()
 
-	stream, err := client.MachineShow(ctx, &zsys.MachineShowRequest{MachineId: machineID, Full: fullInfo})
+	stream, err := client.MachineShow(ctx, &zsys.MachineShowRequest{MachineId: machineID, Full: fullInfo, Tags: parseTags(showTags)})
 
 	if err = checkConn(err, reset); err != nil {
 		return err
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/ubuntu/zsys"
@@ -70,7 +73,27 @@ var (
 		Use:   "gc",
 		Short: i18n.G("Run daemon state saves garbage collection."),
 		Args:  cobra.NoArgs,
-		Run:   func(cmd *cobra.Command, args []string) { cmdErr = gc(gcAll) },
+		Run: func(cmd *cobra.Command, args []string) {
+			if gcDryRun {
+				cmdErr = gcPlan(gcAll, gcSpace, gcJSON)
+				return
+			}
+			cmdErr = gc(gcAll, gcSpace)
+		},
+	}
+	gcSimulateCmd = &cobra.Command{
+		Use:   "simulate",
+		Short: i18n.G("Simulates garbage collection over the next days, on a copy of the current states, to validate a retention policy."),
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdErr = gcSimulate(gcSimulateConfig, gcSimulateDays, gcSimulateEvery, gcAll, gcJSON)
+		},
+	}
+	replicateCmd = &cobra.Command{
+		Use:   "replicate",
+		Short: i18n.G("Replicate saved states to the configured backup pool."),
+		Args:  cobra.NoArgs,
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = replicate() },
 	}
 )
 
@@ -79,6 +102,13 @@ var (
 	traceType     string
 	traceDuration int
 	gcAll         bool
+	gcSpace       bool
+	gcDryRun      bool
+	gcJSON        bool
+
+	gcSimulateConfig string
+	gcSimulateDays   int
+	gcSimulateEvery  time.Duration
 )
 
 func init() {
@@ -90,6 +120,8 @@ func init() {
 	serviceCmd.AddCommand(traceCmd)
 	serviceCmd.AddCommand(reloadCmd)
 	serviceCmd.AddCommand(gcCmd)
+	gcCmd.AddCommand(gcSimulateCmd)
+	serviceCmd.AddCommand(replicateCmd)
 
 	traceCmd.Flags().StringVarP(&traceOutput, "output", "o", "", i18n.G("Dump the trace to a file. Default is ./zsys.<trace-type>.pprof"))
 	traceCmd.Flags().StringVarP(&traceType, "type", "t", "cpu", i18n.G("Type of profiling cpu or mem. Default is cpu."))
@@ -98,6 +130,15 @@ func init() {
 	serviceCmd.AddCommand(statusCmd)
 
 	gcCmd.Flags().BoolVarP(&gcAll, "all", "a", false, i18n.G("Collects all the datasets including manual snapshots and clones."))
+	gcCmd.Flags().BoolVarP(&gcSpace, "space", "", false, i18n.G("Removes the least valuable states until each pool reaches the configured free space target, instead of following the history rules."))
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "", false, i18n.G("Prints which states would be kept or removed, and why, without removing anything."))
+	gcCmd.Flags().BoolVarP(&gcJSON, "json", "", false, i18n.G("Prints the dry run result as JSON."))
+
+	gcSimulateCmd.Flags().StringVarP(&gcSimulateConfig, "config", "c", "", i18n.G("Configuration file with the history rules to simulate. Default is the daemon configuration."))
+	gcSimulateCmd.Flags().IntVarP(&gcSimulateDays, "days", "", 90, i18n.G("Number of days to simulate."))
+	gcSimulateCmd.Flags().DurationVarP(&gcSimulateEvery, "snapshot-every", "", time.Hour, i18n.G("Interval between two simulated system state saves."))
+	gcSimulateCmd.Flags().BoolVarP(&gcAll, "all", "a", false, i18n.G("Collects all the datasets including manual snapshots and clones."))
+	gcSimulateCmd.Flags().BoolVarP(&gcJSON, "json", "", false, i18n.G("Prints the simulation result as JSON."))
 }
 
 func daemonStop() error {
@@ -375,7 +416,131 @@ func reloadConfig() error {
 	return nil
 }
 
-func gc(gcAll bool) error {
+func gc(gcAll, gcSpace bool) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.GC(ctx, &zsys.GCRequest{All: gcAll, Space: gcSpace})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gcDecision is the JSON representation of a garbage collection decision on a state.
+type gcDecision struct {
+	State    string `json:"state"`
+	Machine  string `json:"machine"`
+	User     string `json:"user,omitempty"`
+	LastUsed string `json:"last_used"`
+	Bucket   string `json:"bucket,omitempty"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason"`
+}
+
+func gcPlan(gcAll, gcSpace, asJSON bool) error {
+	if gcSpace {
+		return errors.New(i18n.G("--dry-run can't be used with --space"))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.GCPlan(ctx, &zsys.GCRequest{All: gcAll})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	var plan *zsys.GCPlan
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		plan = r.GetPlan()
+	}
+
+	var decisions []gcDecision
+	for _, d := range plan.GetDecisions() {
+		action := i18n.G("keep")
+		if d.GetRemove() {
+			action = i18n.G("remove")
+		}
+		decisions = append(decisions, gcDecision{
+			State:    d.GetId(),
+			Machine:  d.GetMachine(),
+			User:     d.GetUser(),
+			LastUsed: time.Unix(d.GetLastUsed(), 0).Format("2006-01-02 15:04:05"),
+			Bucket:   d.GetBucket(),
+			Action:   action,
+			Reason:   d.GetReason(),
+		})
+	}
+
+	if asJSON {
+		if decisions == nil {
+			decisions = []gcDecision{}
+		}
+		b, err := json.MarshalIndent(decisions, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, i18n.G("STATE\tUSER\tLAST USED\tBUCKET\tACTION\tREASON\n"))
+	var machine string
+	for _, d := range decisions {
+		if d.Machine != machine {
+			machine = d.Machine
+			fmt.Fprintf(w, i18n.G("Machine %s:\n"), machine)
+		}
+		user := d.User
+		if user == "" {
+			user = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", d.State, user, d.LastUsed, d.Bucket, d.Action, d.Reason)
+	}
+	return w.Flush()
+}
+
+func replicate() error {
 	client, err := newClient()
 	if err != nil {
 		return err
@@ -385,7 +550,7 @@ func gc(gcAll bool) error {
 	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
 	defer cancel()
 
-	stream, err := client.GC(ctx, &zsys.GCRequest{All: gcAll})
+	stream, err := client.Replicate(ctx, &zsys.Empty{})
 	if err = checkConn(err, reset); err != nil {
 		return err
 	}
@@ -406,3 +571,93 @@ func gc(gcAll bool) error {
 
 	return nil
 }
+
+// gcSimulationDay is the JSON representation of a simulated day of garbage collection.
+type gcSimulationDay struct {
+	Date       string   `json:"date"`
+	Saved      int32    `json:"saved"`
+	Removed    int32    `json:"removed"`
+	States     []string `json:"states"`
+	UserStates int32    `json:"user_states"`
+}
+
+func gcSimulate(configPath string, days int, every time.Duration, gcAll, asJSON bool) error {
+	var conf []byte
+	if configPath != "" {
+		var err error
+		if conf, err = os.ReadFile(configPath); err != nil {
+			return fmt.Errorf(i18n.G("couldn't read configuration to simulate: ")+config.ErrorFormat, err)
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.GCSimulate(ctx, &zsys.GCSimulateRequest{
+		Config:        conf,
+		Days:          int32(days),
+		SnapshotEvery: int64(every / time.Second),
+		All:           gcAll,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	var simulation *zsys.GCSimulation
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		simulation = r.GetSimulation()
+	}
+
+	var simDays []gcSimulationDay
+	for _, d := range simulation.GetDays() {
+		states := d.GetStates()
+		if states == nil {
+			states = []string{}
+		}
+		simDays = append(simDays, gcSimulationDay{
+			Date:       time.Unix(d.GetDate(), 0).Format("2006-01-02 15:04"),
+			Saved:      d.GetCreated(),
+			Removed:    d.GetRemoved(),
+			States:     states,
+			UserStates: d.GetUserStates(),
+		})
+	}
+
+	if asJSON {
+		if simDays == nil {
+			simDays = []gcSimulationDay{}
+		}
+		b, err := json.MarshalIndent(simDays, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	for i, d := range simDays {
+		fmt.Printf(i18n.G("Day %d (%s): %d saved, %d removed, %d system states and %d user states kept\n"),
+			i+1, d.Date, d.Saved, d.Removed, len(d.States), d.UserStates)
+		for _, s := range d.States {
+			fmt.Printf("  - %s\n", s)
+		}
+	}
+	return nil
+}
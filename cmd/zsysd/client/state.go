@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -31,7 +32,7 @@ var (
 		Short: i18n.G("Saves the current state of the machine. By default it saves only the user state. state_id is generated if not provided."),
 		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			cmdErr = saveState(args, system, userName, noUpdateBootMenu, saveAuto)
+			cmdErr = saveState(args, system, userName, noUpdateBootMenu, saveAuto, fromAptHook, description, tags)
 		},
 	}
 	stateremoveCmd = &cobra.Command{
@@ -40,26 +41,123 @@ var (
 		Args:  cobra.MaximumNArgs(1),
 		Run:   func(cmd *cobra.Command, args []string) { cmdErr = removeState(args) },
 	}
+	stateexportCmd = &cobra.Command{
+		Use:   "export state_id",
+		Short: i18n.G("Exports a saved system state, with its linked user states, to a single archive file."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = exportState(args[0], exportFrom, exportOutput) },
+	}
+	stateimportCmd = &cobra.Command{
+		Use:   "import file",
+		Short: i18n.G("Imports a state archive as a new history state of the machine it was exported from, or as a new machine."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = importState(args[0], importAsNewMachine) },
+	}
+	staterestoreCmd = &cobra.Command{
+		Use:   "restore state_id",
+		Short: i18n.G("Restores the user datasets of a saved user state as the active ones, without rebooting. The user must be logged out."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = restoreState(args[0], userName) },
+	}
+	staterevertCmd = &cobra.Command{
+		Use:   "revert state_id",
+		Short: i18n.G("Reverts datasets of the current system, like /var/lib/docker, to a saved state without rebooting. Services using them are restarted."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = revertDatasets(args[0], revertRoutes) },
+	}
+	statefilesCmd = &cobra.Command{
+		Use:   "files state_id [path]",
+		Short: i18n.G("Lists the content of a directory, or the root of a saved state if no path is provided."),
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = listStateFiles(args, userName) },
+	}
+	staterestorefileCmd = &cobra.Command{
+		Use:   "restore-file state_id path [dest]",
+		Short: i18n.G("Copies a file or directory from a saved state to dest. Default is to restore it at its original path."),
+		Args:  cobra.RangeArgs(2, 3),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = restoreStateFile(args, userName) },
+	}
+	statetagCmd = &cobra.Command{
+		Use:   "tag state_id key[=value]...",
+		Short: i18n.G("Adds or replaces tags on a saved state."),
+		Args:  cobra.MinimumNArgs(2),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = tagState(args[0], userName, args[1:]) },
+	}
+	stateuntagCmd = &cobra.Command{
+		Use:   "untag state_id key...",
+		Short: i18n.G("Removes tags from a saved state."),
+		Args:  cobra.MinimumNArgs(2),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = untagState(args[0], userName, args[1:]) },
+	}
+	statepinCmd = &cobra.Command{
+		Use:   "pin state_id",
+		Short: i18n.G("Pins a saved state so that it is never garbage collected."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = pinState(args[0], userName, true) },
+	}
+	stateunpinCmd = &cobra.Command{
+		Use:   "unpin state_id",
+		Short: i18n.G("Unpins a saved state so that it can be garbage collected again."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = pinState(args[0], userName, false) },
+	}
+	stateusageCmd = &cobra.Command{
+		Use:   "usage state_id",
+		Short: i18n.G("Shows the disk space only used by a state, shared with other states and freed by removing it."),
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = stateUsage(args[0], userName) },
+	}
+	statediffCmd = &cobra.Command{
+		Use:   "diff state_id [state_id|current]",
+		Short: i18n.G("Shows files added, removed, modified or renamed between a saved state and a more recent one. Default is to compare to the current state."),
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = diffStates(args, userName) },
+	}
 )
 
 var (
-	system           bool
-	noUpdateBootMenu bool
-	saveAuto         bool
-	userName         string
-	force            bool
-	dryrun           bool
+	system             bool
+	noUpdateBootMenu   bool
+	saveAuto           bool
+	fromAptHook        bool
+	description        string
+	tags               []string
+	userName           string
+	force              bool
+	dryrun             bool
+	exportOutput       string
+	exportFrom         string
+	importAsNewMachine bool
+	revertRoutes       []string
 )
 
+// importChunkSize is the size of the archive chunks sent to the daemon on import.
+const importChunkSize = 64 * 1024
+
 func init() {
 	rootCmd.AddCommand(stateCmd)
 	stateCmd.AddCommand(statesaveCmd)
 	stateCmd.AddCommand(stateremoveCmd)
+	stateCmd.AddCommand(stateexportCmd)
+	stateCmd.AddCommand(stateimportCmd)
+	stateCmd.AddCommand(staterestoreCmd)
+	stateCmd.AddCommand(staterevertCmd)
+	stateCmd.AddCommand(statefilesCmd)
+	stateCmd.AddCommand(staterestorefileCmd)
+	stateCmd.AddCommand(statediffCmd)
+	stateCmd.AddCommand(statetagCmd)
+	stateCmd.AddCommand(stateuntagCmd)
+	stateCmd.AddCommand(statepinCmd)
+	stateCmd.AddCommand(stateunpinCmd)
+	stateCmd.AddCommand(stateusageCmd)
 
 	statesaveCmd.Flags().BoolVarP(&system, "system", "s", false, i18n.G("Save complete system state (users and system)"))
 	statesaveCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Save the state for a given user or current user if empty"))
 	statesaveCmd.Flags().BoolVarP(&noUpdateBootMenu, "no-update-bootmenu", "", false, i18n.G("Do not update bootmenu on system state save"))
 	statesaveCmd.Flags().BoolVarP(&saveAuto, "auto", "", false, i18n.G("Signal this is an automated request triggered by script"))
+	statesaveCmd.Flags().StringVarP(&description, "description", "d", "", i18n.G("Describe the saved state"))
+	statesaveCmd.Flags().StringArrayVarP(&tags, "tag", "t", nil, i18n.G("Tag the saved state, as key or key=value. Can be repeated."))
+	statesaveCmd.Flags().BoolVarP(&fromAptHook, "from-apt-hook", "", false, i18n.G("Record packages about to be changed, as sent on stdin to an APT Pre-Install-Pkgs hook"))
 
 	// user name and system or exclusive: TODO
 	stateremoveCmd.Flags().BoolVarP(&system, "system", "s", false, i18n.G("Remove system state (system and users linked to it)"))
@@ -67,10 +165,25 @@ func init() {
 	stateremoveCmd.Flags().BoolVarP(&force, "force", "f", false, i18n.G("Force removing, even if dependencies are found"))
 	stateremoveCmd.Flags().BoolVarP(&dryrun, "dry-run", "", false, i18n.G("Dry run, will not remove anything"))
 
+	stateexportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", i18n.G("Write the archive to a file. Default is ./zsys.<state_id>.tar"))
+	stateexportCmd.Flags().StringVarP(&exportFrom, "from", "", "", i18n.G("Export incrementally from an older saved state, so that the archive can be imported as history of this machine"))
+
+	staterestoreCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Restore the state for a given user"))
+	staterevertCmd.Flags().StringSliceVarP(&revertRoutes, "datasets", "", nil, i18n.G("Mountpoints or names of the datasets to revert, with their children. Can be repeated."))
+	statefilesCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("List files of a given user state instead of a system state"))
+	staterestorefileCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Restore files from a given user state instead of a system state"))
+	statetagCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Tag a given user state instead of a system state"))
+	stateuntagCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Untag a given user state instead of a system state"))
+	statepinCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Pin a given user state instead of a system state"))
+	stateunpinCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Unpin a given user state instead of a system state"))
+	stateusageCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Show disk usage of a given user state instead of a system state"))
+	statediffCmd.Flags().StringVarP(&userName, "user", "u", "", i18n.G("Compare states of a given user instead of system states"))
+	stateimportCmd.Flags().BoolVarP(&importAsNewMachine, "new-machine", "", false, i18n.G("Import a full archive as a new machine instead of a history state"))
+
 	cmdhandler.RegisterAlias(statesaveCmd, rootCmd)
 }
 
-func saveState(args []string, system bool, userName string, noUpdateBootMenu, saveAuto bool) (err error) {
+func saveState(args []string, system bool, userName string, noUpdateBootMenu, saveAuto, fromAptHook bool, description string, tags []string) (err error) {
 
 	if system && userName != "" {
 		return errors.New(i18n.G("you can't provide system and user flags at the same time"))
@@ -78,6 +191,17 @@ func saveState(args []string, system bool, userName string, noUpdateBootMenu, sa
 	if !system && noUpdateBootMenu {
 		return errors.New(i18n.G("you can't provide no-update-bootmenu option on user state save"))
 	}
+	if !system && fromAptHook {
+		return errors.New(i18n.G("you can't provide from-apt-hook option on user state save"))
+	}
+
+	// Package changes are only informative: save the state even if they can't be read.
+	var changes *zsys.PackageChanges
+	if fromAptHook {
+		if changes, err = readAptHookPackages(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Couldn't read packages to change, saving state without them: %v\n"), err)
+		}
+	}
 
 	var stateName string
 	if len(args) > 0 {
@@ -98,6 +222,9 @@ func saveState(args []string, system bool, userName string, noUpdateBootMenu, sa
 			StateName:      stateName,
 			UpdateBootMenu: !noUpdateBootMenu,
 			Autosave:       saveAuto,
+			PackageChanges: changes,
+			Description:    description,
+			Tags:           parseTags(tags),
 		})
 
 		if err = checkConn(err, reset); err != nil {
@@ -128,7 +255,12 @@ func saveState(args []string, system bool, userName string, noUpdateBootMenu, sa
 			userName = user.Username
 		}
 
-		stream, err := client.SaveUserState(ctx, &zsys.SaveUserStateRequest{UserName: userName, StateName: stateName})
+		stream, err := client.SaveUserState(ctx, &zsys.SaveUserStateRequest{
+			UserName:    userName,
+			StateName:   stateName,
+			Description: description,
+			Tags:        parseTags(tags),
+		})
 
 		if err = checkConn(err, reset); err != nil {
 			return err
@@ -286,3 +418,592 @@ func removeStateGRPC(client *zsys.ZsysLogClient, force, dryrun, system bool, use
 
 	return err
 }
+
+func exportState(stateName, from, output string) (err error) {
+	if output == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		name := strings.NewReplacer("/", "_", "@", "_").Replace(stateName)
+		output = filepath.Join(dir, fmt.Sprintf("zsys.%s.tar", name))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Couldn't open archive file %s: %v"), output, err)
+	}
+	defer func() {
+		f.Close()
+		// Don’t leave a partial archive behind
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.ExportState(ctx, &zsys.ExportStateRequest{StateName: stateName, From: from})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Each chunk of the archive shows the daemon is still progressing
+		reset <- struct{}{}
+		if _, err := f.Write(r.GetArchive()); err != nil {
+			return fmt.Errorf(i18n.G("Couldn't write to file: %v"), err)
+		}
+	}
+
+	fmt.Printf(i18n.G("State %q exported to %s\n"), stateName, output)
+
+	return nil
+}
+
+func importState(path string, asNewMachine bool) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Couldn't open archive file %s: %v"), path, err)
+	}
+	defer f.Close()
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.ImportState(ctx)
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	// Receive logs and result while sending the archive, so that the daemon is never blocked on us.
+	var stateName string
+	errRecv := make(chan error, 1)
+	go func() {
+		for {
+			r, err := stream.Recv()
+			if err == streamlogger.ErrLogMsg {
+				reset <- struct{}{}
+				continue
+			}
+			if err == io.EOF {
+				errRecv <- nil
+				return
+			}
+			if err != nil {
+				errRecv <- err
+				return
+			}
+
+			stateName = r.GetStateName()
+		}
+	}()
+
+	// The first message carries the import options.
+	err = stream.Send(&zsys.ImportStateRequest{AsNewMachine: asNewMachine})
+	buf := make([]byte, importChunkSize)
+	for err == nil {
+		var n int
+		n, err = f.Read(buf)
+		if n > 0 {
+			if errSend := stream.Send(&zsys.ImportStateRequest{Archive: buf[:n]}); errSend != nil {
+				err = errSend
+				break
+			}
+			// Each chunk of the archive shows the daemon is still progressing
+			reset <- struct{}{}
+		}
+	}
+	// io.EOF on send means that the daemon ended the request: its status is returned on receive.
+	if err != nil && err != io.EOF {
+		return fmt.Errorf(i18n.G("Couldn't send archive: %v"), err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	if err := <-errRecv; err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Successfully imported as %q\n"), stateName)
+
+	return nil
+}
+
+func restoreState(stateName, userName string) error {
+	if userName == "" {
+		return errors.New(i18n.G("a user name is required: only user states can be restored without rebooting"))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.RestoreUserState(ctx, &zsys.RestoreUserStateRequest{
+		UserName:  userName,
+		StateName: stateName,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	var id string
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		id = r.GetStateName()
+	}
+
+	fmt.Printf(i18n.G("Successfully restored %s for %s as %q\n"), stateName, userName, id)
+	return nil
+}
+
+func revertDatasets(stateName string, routes []string) error {
+	if len(routes) == 0 {
+		return errors.New(i18n.G("at least one dataset to revert is required, with --datasets"))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.RevertDatasets(ctx, &zsys.RevertDatasetsRequest{
+		StateName: stateName,
+		Datasets:  routes,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(i18n.G("Successfully reverted %s to %s\n"), strings.Join(routes, ", "), stateName)
+	return nil
+}
+
+func listStateFiles(args []string, userName string) error {
+	var path string
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	p, err := statePath(args[0], userName, path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(p)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't read %s: %v"), path, err)
+	}
+	if !info.IsDir() {
+		fmt.Println(info.Name())
+		return nil
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't list %s: %v"), path, err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func restoreStateFile(args []string, userName string) error {
+	path := args[1]
+	var dest string
+	if len(args) > 2 {
+		dest = args[2]
+	}
+
+	p, err := statePath(args[0], userName, path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(p); err != nil {
+		return fmt.Errorf(i18n.G("couldn't read %s: %v"), path, err)
+	}
+
+	// Restore at original location by default, or inside dest if this is an existing directory.
+	if dest == "" {
+		dest = path
+	} else if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, filepath.Base(path))
+	}
+
+	if err := copyFromState(p, dest); err != nil {
+		return fmt.Errorf(i18n.G("couldn't restore %s to %s: %v"), path, dest, err)
+	}
+
+	fmt.Printf(i18n.G("Successfully restored %s to %s\n"), path, dest)
+	return nil
+}
+
+// statePath returns where path of the state stateName can be read on the system.
+func statePath(stateName, userName, path string) (string, error) {
+	// The daemon maps it to a path in the saved state: give it an absolute path.
+	if path != "" {
+		var err error
+		if path, err = filepath.Abs(path); err != nil {
+			return "", err
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.StatePath(ctx, &zsys.StatePathRequest{
+		UserName:  userName,
+		StateName: stateName,
+		Path:      path,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return "", err
+	}
+
+	var p string
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		p = r.GetPath()
+	}
+
+	return p, nil
+}
+
+// copyFromState copies src, a file or a directory, to dst. Permissions and modification times of files are kept.
+// Files are copied with the rights of the current user.
+func copyFromState(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			fmt.Fprintf(os.Stderr, i18n.G("Skipping %s: not a regular file\n"), p)
+			return nil
+		}
+
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+func diffStates(args []string, userName string) (err error) {
+	stateA := args[0]
+	var stateB string
+	if len(args) > 1 {
+		stateB = args[1]
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.StateDiff(ctx, &zsys.StateDiffRequest{
+		UserName: userName,
+		StateA:   stateA,
+		StateB:   stateB,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(r.GetDiff())
+	}
+
+	return nil
+}
+
+func tagState(stateName, userName string, tags []string) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.TagState(ctx, &zsys.TagStateRequest{
+		UserName:  userName,
+		StateName: stateName,
+		Tags:      parseTags(tags),
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func untagState(stateName, userName string, keys []string) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.UntagState(ctx, &zsys.UntagStateRequest{
+		UserName:  userName,
+		StateName: stateName,
+		Tags:      keys,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func pinState(stateName, userName string, pin bool) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.PinState(ctx, &zsys.PinStateRequest{
+		UserName:  userName,
+		StateName: stateName,
+		Pin:       pin,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stateUsage(stateName, userName string) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.StateUsage(ctx, &zsys.StateUsageRequest{
+		UserName:  userName,
+		StateName: stateName,
+	})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	var u *zsys.StateUsage
+	for {
+		r, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		u = r.GetUsage()
+	}
+
+	fmt.Printf(i18n.G("Unique: %d bytes\n"), u.GetUnique())
+	fmt.Printf(i18n.G("Shared: %d bytes\n"), u.GetShared())
+	fmt.Printf(i18n.G("Freed on removal: %d bytes\n"), u.GetFreed())
+
+	return nil
+}
+
+// parseTags converts a list of "key" or "key=value" elements to tags. Keys are validated by the daemon.
+func parseTags(elems []string) map[string]string {
+	if len(elems) == 0 {
+		return nil
+	}
+	tags := make(map[string]string)
+	for _, e := range elems {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 1 {
+			tags[kv[0]] = ""
+			continue
+		}
+		tags[kv[0]] = kv[1]
+	}
+	return tags
+}
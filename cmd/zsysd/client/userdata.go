@@ -1,7 +1,15 @@
 package client
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/ubuntu/zsys"
@@ -9,6 +17,7 @@ import (
 	"github.com/ubuntu/zsys/internal/config"
 	"github.com/ubuntu/zsys/internal/i18n"
 	"github.com/ubuntu/zsys/internal/streamlogger"
+	"golang.org/x/sys/unix"
 )
 
 var (
@@ -23,7 +32,15 @@ var (
 		Use:   "create USER HOME_DIRECTORY",
 		Short: i18n.G("Create a new home user dataset via an user dataset (if doesn't exist) creation"),
 		Args:  cobra.ExactArgs(2),
-		Run:   func(cmd *cobra.Command, args []string) { cmdErr = createUserData(args[0], args[1]) },
+		Run:   func(cmd *cobra.Command, args []string) { cmdErr = createUserData(args[0], args[1], encrypt) },
+	}
+	userdataSessionCmd = &cobra.Command{
+		Use:   "session",
+		Short: i18n.G("Unlock and mount user data on login and save user state on logout, when called by pam_exec"),
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdErr = userSession(os.Getenv("PAM_TYPE"), os.Getenv("PAM_USER"))
+		},
 	}
 	userdataRenameCmd = &cobra.Command{
 		Use:   "set-home OLD_HOME NEW_HOME",
@@ -41,19 +58,36 @@ var (
 
 var (
 	removeHome bool
+	encrypt    bool
 )
 
+// minUserUID is the first uid of regular users. System users don't have user data.
+const minUserUID = 1000
+
+// maxPassphraseRead is the maximum number of bytes of a piped passphrase.
+const maxPassphraseRead = 1024
+
 func init() {
 	rootCmd.AddCommand(userdataCmd)
 	userdataCmd.AddCommand(userdataCreateCmd)
+	userdataCmd.AddCommand(userdataSessionCmd)
 	userdataCmd.AddCommand(userdataRenameCmd)
 	userdataCmd.AddCommand(userdataDissociateCmd)
+	userdataCreateCmd.Flags().BoolVarP(&encrypt, "encrypt", "e", false, i18n.G("Encrypt the new user dataset with a passphrase read from standard input, which should be the user password to unlock it on login"))
 	userdataDissociateCmd.Flags().BoolVarP(&removeHome, "remove", "r", false, i18n.G("Empty home directory content if not associated to any machine state"))
 }
 
 // createUserData creates a new userdata for user and set it to homepath on current zsys system.
 // if the user already exists for a dataset attached to the current system, set its mountpoint to homepath.
-func createUserData(user, homepath string) (err error) {
+// If encrypt is set, the new userdata is encrypted with a passphrase read from stdin.
+func createUserData(user, homepath string, encrypt bool) (err error) {
+	var passphrase []byte
+	if encrypt {
+		if passphrase, err = readPassphrase(os.Stdin); err != nil {
+			return err
+		}
+	}
+
 	client, err := newClient()
 	if err != nil {
 		return err
@@ -63,7 +97,105 @@ func createUserData(user, homepath string) (err error) {
 	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
 	defer cancel()
 
-	stream, err := client.CreateUserData(ctx, &zsys.CreateUserDataRequest{User: user, Homepath: homepath})
+	stream, err := client.CreateUserData(ctx, &zsys.CreateUserDataRequest{User: user, Homepath: homepath, Encrypt: encrypt, Passphrase: passphrase})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userSession loads the encryption keys of userName from its password on authentication, mounts its user data when
+// its session is opened and saves its user state when it's closed.
+// pamType and userName are set by pam_exec, which writes the password on stdin on authentication.
+// Sessions of system users are ignored.
+func userSession(pamType, userName string) (err error) {
+	if userName == "" {
+		return errors.New(i18n.G("no user name was set by PAM"))
+	}
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't find user %q: %v"), userName, err)
+	}
+	if uid, err := strconv.Atoi(u.Uid); err != nil || uid < minUserUID {
+		return nil
+	}
+
+	switch pamType {
+	case "auth":
+		passphrase, err := readPassphrase(os.Stdin)
+		if err != nil {
+			return err
+		}
+		return loadUserKeys(userName, passphrase)
+	case "open_session":
+		return mountUserData(userName, u.HomeDir)
+	case "close_session":
+		return saveState(nil, false, userName, false, false, false, "", nil)
+	}
+	return fmt.Errorf(i18n.G("unsupported PAM session type: %q"), pamType)
+}
+
+// mountUserData mounts the user datasets of user attached to the current system, creating a new one for homepath if
+// there is none.
+func mountUserData(user, homepath string) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.MountUserData(ctx, &zsys.MountUserDataRequest{User: user, Homepath: homepath})
+	if err = checkConn(err, reset); err != nil {
+		return err
+	}
+
+	for {
+		_, err := stream.Recv()
+		if err == streamlogger.ErrLogMsg {
+			reset <- struct{}{}
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadUserKeys loads the encryption keys of the user datasets of user with passphrase.
+func loadUserKeys(user string, passphrase []byte) (err error) {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel, reset := contextWithResettableTimeout(client.Ctx, config.DefaultClientTimeout)
+	defer cancel()
+
+	stream, err := client.LoadUserKeys(ctx, &zsys.LoadUserKeysRequest{User: user, Passphrase: passphrase})
 	if err = checkConn(err, reset); err != nil {
 		return err
 	}
@@ -150,3 +282,46 @@ func dissociateUser(user string, removeHome bool) (err error) {
 
 	return nil
 }
+
+// readPassphrase reads a passphrase from in, up to its first new line or NUL byte, which pam_exec terminates it with.
+// On a terminal, the passphrase is asked twice without echoing it.
+func readPassphrase(in *os.File) ([]byte, error) {
+	fd := int(in.Fd())
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		// Not a terminal: the passphrase is piped.
+		p, err := ioutil.ReadAll(io.LimitReader(in, maxPassphraseRead))
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("couldn't read passphrase: %v"), err)
+		}
+		if i := bytes.IndexAny(p, "\x00\n"); i >= 0 {
+			p = p[:i]
+		}
+		return p, nil
+	}
+
+	noEcho := *termios
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ISIG
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &noEcho); err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't disable terminal echo: %v"), err)
+	}
+	defer unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+
+	br := bufio.NewReader(in)
+	var passphrases [2][]byte
+	for i, prompt := range []string{i18n.G("Passphrase: "), i18n.G("Confirm passphrase: ")} {
+		fmt.Fprint(os.Stderr, prompt)
+		p, err := br.ReadBytes('\n')
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("couldn't read passphrase: %v"), err)
+		}
+		passphrases[i] = bytes.TrimSuffix(p, []byte("\n"))
+	}
+	if !bytes.Equal(passphrases[0], passphrases[1]) {
+		return nil, errors.New(i18n.G("passphrases don't match"))
+	}
+
+	return passphrases[0], nil
+}
@@ -0,0 +1,223 @@
+package bootmenu
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+const (
+	defaultBLSEntriesDir = "/boot/loader/entries"
+	defaultBLSOptions    = "ro quiet splash"
+	// blsEntryPrefix is the file name prefix of entries handled by zsys. Others are never touched.
+	blsEntryPrefix = "zsys-"
+	blsEntrySuffix = ".conf"
+	blsTimeFormat  = "2006-01-02 15:04:05"
+	// bootctlCmd selects the entry to boot once, for boot loaders implementing the Boot Loader Interface.
+	bootctlCmd = "bootctl"
+	// blsKernelsDir is the directory, relative to the partition of the entries, where the kernels of saved states are
+	// copied. The boot loader can't read them from ZFS.
+	blsKernelsDir = "zsys"
+)
+
+// bls writes one Boot Loader Specification entry per saved state, with a copy of its kernel and initrd next to the
+// entries. Entries for the current states are left to kernel-install.
+// The fallback of a failed trial boot is booted once, through the LoaderEntryOneShot EFI variable set by bootctl.
+type bls struct {
+	dir     string
+	options string
+	run     commandRunner
+}
+
+func newBLS(conf config.BLSRules) bls {
+	b := bls{
+		dir:     conf.EntriesDir,
+		options: conf.Options,
+		run:     runCommand,
+	}
+	if b.dir == "" {
+		b.dir = defaultBLSEntriesDir
+	}
+	if b.options == "" {
+		b.options = defaultBLSOptions
+	}
+	return b
+}
+
+// ReadsKernels marks that the kernels of the saved states are copied from Entry.KernelDir.
+func (bls) ReadsKernels() {}
+
+// Update writes an entry for each saved state and removes the entries and kernels of states which don't exist
+// anymore.
+func (b bls) Update(ctx context.Context, entries []Entry) error {
+	log.RemotePrintln(ctx, i18n.G("ZSys is updating Boot Loader Specification entries"))
+
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return fmt.Errorf(i18n.G("couldn't create boot entries directory: ")+config.ErrorFormat, err)
+	}
+
+	written := make(map[string]bool)
+	for _, e := range entries {
+		if !e.History {
+			continue
+		}
+		if e.Kernel == "" {
+			log.Infof(ctx, i18n.G("No kernel recorded for %s, skipping its boot entry"), e.ID)
+			continue
+		}
+		if e.KernelDir == "" {
+			log.Warningf(ctx, i18n.G("Kernel of %s can't be read, skipping its boot entry"), e.ID)
+			continue
+		}
+
+		name := blsEntryName(e.ID)
+		if err := b.copyKernels(e); err != nil {
+			log.Warningf(ctx, i18n.G("Couldn't copy kernel of %s, skipping its boot entry: %v"), e.ID, err)
+			continue
+		}
+		if err := writeFileAtomically(filepath.Join(b.dir, name), b.entry(e)); err != nil {
+			return fmt.Errorf(i18n.G("couldn't write boot entry for %s: ")+config.ErrorFormat, e.ID, err)
+		}
+		written[name] = true
+	}
+
+	files, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't list boot entries: ")+config.ErrorFormat, err)
+	}
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasPrefix(name, blsEntryPrefix) || !strings.HasSuffix(name, blsEntrySuffix) || written[name] {
+			continue
+		}
+		log.Debugf(ctx, "Removing boot entry %s of a removed state", name)
+		if err := os.Remove(filepath.Join(b.dir, name)); err != nil {
+			return fmt.Errorf(i18n.G("couldn't remove boot entry %s: ")+config.ErrorFormat, name, err)
+		}
+	}
+
+	kernelsDirs, err := os.ReadDir(b.kernelsRoot())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(i18n.G("couldn't list copied kernels: ")+config.ErrorFormat, err)
+	}
+	for _, f := range kernelsDirs {
+		if written[blsEntryPrefix+f.Name()+blsEntrySuffix] {
+			continue
+		}
+		log.Debugf(ctx, "Removing kernels %s of a removed state", f.Name())
+		if err := os.RemoveAll(filepath.Join(b.kernelsRoot(), f.Name())); err != nil {
+			return fmt.Errorf(i18n.G("couldn't remove kernels %s: ")+config.ErrorFormat, f.Name(), err)
+		}
+	}
+
+	e, ok := defaultEntry(entries)
+	if !ok {
+		return nil
+	}
+	name := blsEntryName(e.ID)
+	if !written[name] {
+		return fmt.Errorf(i18n.G("no boot entry to fall back to %s"), e.ID)
+	}
+	log.Infof(ctx, i18n.G("Next boot falls back to %s"), e.ID)
+	return b.run(ctx, bootctlCmd, "set-oneshot", name)
+}
+
+// entry returns the content of the entry file for e.
+func (b bls) entry(e Entry) []byte {
+	version := kernelVersion(e.Kernel)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "title      %s (%s)\n", e.ID, e.LastUsed.Format(blsTimeFormat))
+	fmt.Fprintf(&buf, "sort-key   zsys\n")
+	fmt.Fprintf(&buf, "version    %s\n", version)
+	fmt.Fprintf(&buf, "linux      /%s/%s/vmlinuz-%s\n", blsKernelsDir, blsStateDir(e.ID), version)
+	fmt.Fprintf(&buf, "initrd     /%s/%s/initrd.img-%s\n", blsKernelsDir, blsStateDir(e.ID), version)
+	fmt.Fprintf(&buf, "options    root=ZFS=%s %s\n", e.ID, b.options)
+	return buf.Bytes()
+}
+
+// copyKernels copies the kernel and initrd of e from its kernel directory to the partition of the entries.
+// They are never modified in a saved state, so existing copies are kept.
+func (b bls) copyKernels(e Entry) error {
+	version := kernelVersion(e.Kernel)
+	dir := filepath.Join(b.kernelsRoot(), blsStateDir(e.ID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, n := range []string{"vmlinuz-" + version, "initrd.img-" + version} {
+		dest := filepath.Join(dir, n)
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+		if err := copyFileAtomically(filepath.Join(e.KernelDir, n), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kernelsRoot returns the directory where the kernels of saved states are copied.
+// Entries are in the loader/entries directory of the partition.
+func (b bls) kernelsRoot() string {
+	return filepath.Join(filepath.Dir(filepath.Dir(b.dir)), blsKernelsDir)
+}
+
+// blsEntryName returns the file name of the entry for the state id.
+func blsEntryName(id string) string {
+	return blsEntryPrefix + blsStateDir(id) + blsEntrySuffix
+}
+
+// blsStateDir returns the name of the directory holding the kernels of the state id.
+// Slashes can't be part of a file name and are replaced. A hash of the full id keeps ids only differing by them apart,
+// without characters that the FAT partition of the entries would refuse.
+func blsStateDir(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return fmt.Sprintf("%s-%x", strings.ReplaceAll(id, "/", "-"), sum[:4])
+}
+
+// copyFileAtomically copies src to dest, so that the boot loader never reads a partial file.
+func copyFileAtomically(src, dest string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// writeFileAtomically replaces path content, so that the boot loader never reads a partial entry.
+func writeFileAtomically(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
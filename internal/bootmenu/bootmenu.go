@@ -0,0 +1,89 @@
+package bootmenu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+)
+
+const (
+	// GrubBackend regenerates the menu with update-grub.
+	GrubBackend = "grub"
+	// BLSBackend writes Boot Loader Specification entries, as read by systemd-boot.
+	BLSBackend = "bls"
+	// NativeGrubBackend writes the GRUB history menu itself, sourced by a grub.d drop-in.
+	NativeGrubBackend = "grub-native"
+)
+
+// Entry is a state which can be booted from the boot menu.
+type Entry struct {
+	// ID is the root system dataset of the state, passed as root=ZFS=<ID> on the kernel command line.
+	ID string
+	// Machine is the ID of the machine the state belongs to.
+	Machine string
+	// Kernel is the kernel the state was last booted with, like vmlinuz-5.4.0-42-generic.
+	Kernel string
+	// LastUsed is the last time the state was used, or its creation time for snapshots.
+	LastUsed time.Time
+	// History is set for saved states, as opposed to the current state of the machine.
+	History bool
+	// Boot is the dataset, or snapshot, holding the kernels of the state. It's the root one when there is no
+	// separate dataset mounted on /boot.
+	Boot string
+	// BootOnRoot is set when the kernels are in the /boot directory of the root dataset.
+	BootOnRoot bool
+	// Description is the description of the state, if any.
+	Description string
+	// Users are the names of the users having a user state attached to the state.
+	Users []string
+	// Default is set on the entry which should be booted by default, like the fallback of a failed trial boot.
+	Default bool
+	// KernelDir is the directory on the running system where the kernels of the state can be read. It's only set for
+	// backends implementing KernelsReader.
+	KernelDir string
+}
+
+// Backend regenerates the boot menu from all bootable states.
+type Backend interface {
+	Update(ctx context.Context, entries []Entry) error
+}
+
+// KernelsReader is implemented by backends which need to read the kernels of the states, as their boot loader can't
+// read them from ZFS.
+type KernelsReader interface {
+	Backend
+	ReadsKernels()
+}
+
+// New returns the boot menu backend selected by the configuration.
+func New(conf config.BootRules) (Backend, error) {
+	switch strings.ToLower(conf.Menu) {
+	case "", GrubBackend:
+		return newGrub(), nil
+	case BLSBackend:
+		return newBLS(conf.BLS), nil
+	case NativeGrubBackend:
+		return newNativeGrub(conf.GrubNative), nil
+	default:
+		return nil, fmt.Errorf(i18n.G("unknown boot menu backend %q"), conf.Menu)
+	}
+}
+
+// defaultEntry returns the entry to boot by default, if any.
+func defaultEntry(entries []Entry) (Entry, bool) {
+	for _, e := range entries {
+		if e.Default {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// kernelVersion returns the version of a kernel image name, like 5.4.0-42-generic for vmlinuz-5.4.0-42-generic.
+func kernelVersion(kernel string) string {
+	return strings.TrimPrefix(kernel, "vmlinuz-")
+}
@@ -0,0 +1,327 @@
+package bootmenu_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubuntu/zsys/internal/bootmenu"
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/testutils"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		menu string
+
+		wantErr bool
+	}{
+		"Default to grub": {},
+		"Grub":            {menu: "grub"},
+		"BLS":             {menu: "bls"},
+		"BLS, mixed case": {menu: "BLS"},
+		"Native GRUB":     {menu: "grub-native"},
+
+		"Error on unknown backend": {menu: "lilo", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := bootmenu.New(config.BootRules{Menu: tc.menu})
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+			assert.NotNil(t, b, "New should return a backend")
+		})
+	}
+}
+
+func TestBLSUpdate(t *testing.T) {
+	t.Parallel()
+
+	lastUsed := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	kernels := filepath.Join("testdata", "kernels")
+	current := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-42-generic", LastUsed: lastUsed}
+	snapshot := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@autozsys_abcd", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-time.Hour), History: true,
+		KernelDir: kernels}
+	clone := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_5678", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-26-generic", LastUsed: lastUsed.Add(-24 * time.Hour), History: true,
+		KernelDir: kernels}
+	noKernel := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@manual", Machine: "rpool/ROOT/ubuntu_1234", LastUsed: lastUsed.Add(-2 * time.Hour), History: true,
+		KernelDir: kernels}
+	unreadableKernel := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@unmountable", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-3 * time.Hour), History: true}
+	missingKernel := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@missing", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-10-generic", LastUsed: lastUsed.Add(-4 * time.Hour), History: true,
+		KernelDir: kernels}
+	slashes := bootmenu.Entry{ID: "rpool/ROOT/ubuntu-1234@manual", Machine: "rpool/ROOT/ubuntu-1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-5 * time.Hour), History: true,
+		KernelDir: kernels}
+	dashes := bootmenu.Entry{ID: "rpool/ROOT-ubuntu/1234@manual", Machine: "rpool/ROOT-ubuntu/1234", Kernel: "vmlinuz-5.4.0-26-generic", LastUsed: lastUsed.Add(-6 * time.Hour), History: true,
+		KernelDir: kernels}
+	fallback := snapshot
+	fallback.Default = true
+	fallbackNoKernel := noKernel
+	fallbackNoKernel.Default = true
+
+	tests := map[string]struct {
+		entries       []bootmenu.Entry
+		options       string
+		existingFiles map[string]string
+		bootctlErr    bool
+
+		wantCommands []string
+		wantErr      bool
+	}{
+		"One entry per saved state":                          {entries: []bootmenu.Entry{current, snapshot, clone}},
+		"No entry without saved states":                      {entries: []bootmenu.Entry{current}},
+		"Skip states without a recorded kernel":              {entries: []bootmenu.Entry{current, snapshot, noKernel}},
+		"Skip states which kernel can't be read":             {entries: []bootmenu.Entry{current, snapshot, unreadableKernel, missingKernel}},
+		"Distinct entries for ids only differing by slashes": {entries: []bootmenu.Entry{current, slashes, dashes}},
+		"Keep existing copied kernels": {entries: []bootmenu.Entry{current, snapshot},
+			existingFiles: map[string]string{"zsys/rpool-ROOT-ubuntu_1234@autozsys_abcd-d846f615/vmlinuz-5.4.0-40-generic": "already copied"}},
+		"Remove kernels of removed states": {entries: []bootmenu.Entry{current, snapshot},
+			existingFiles: map[string]string{"zsys/rpool-ROOT-ubuntu_1234@autozsys_old-35136498/vmlinuz-5.4.0-40-generic": "removed state"}},
+		"Custom kernel options": {entries: []bootmenu.Entry{current, snapshot}, options: "ro console=ttyS0"},
+		"Replace existing entries": {entries: []bootmenu.Entry{current, snapshot},
+			existingFiles: map[string]string{"loader/entries/zsys-rpool-ROOT-ubuntu_1234@autozsys_abcd-d846f615.conf": "outdated"}},
+		"Remove entries of removed states": {entries: []bootmenu.Entry{current, snapshot},
+			existingFiles: map[string]string{"loader/entries/zsys-rpool-ROOT-ubuntu_1234@autozsys_old-35136498.conf": "removed state"}},
+		"Keep entries not handled by zsys": {entries: []bootmenu.Entry{current, snapshot},
+			existingFiles: map[string]string{"loader/entries/ubuntu-5.4.0-42-generic.conf": "kernel-install entry", "loader/entries/zsys-notes.txt": "not an entry",
+				"vmlinuz-5.4.0-42-generic": "kernel-install kernel"}},
+		"Fallback of a failed trial is booted once": {entries: []bootmenu.Entry{current, fallback, clone},
+			wantCommands: []string{"bootctl set-oneshot zsys-rpool-ROOT-ubuntu_1234@autozsys_abcd-d846f615.conf"}},
+
+		"Error on fallback without boot entry": {entries: []bootmenu.Entry{current, snapshot, fallbackNoKernel}, wantErr: true},
+		"Error on setting entry to boot once fails": {entries: []bootmenu.Entry{current, fallback}, bootctlErr: true,
+			wantCommands: []string{"bootctl set-oneshot zsys-rpool-ROOT-ubuntu_1234@autozsys_abcd-d846f615.conf"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			entriesDir := filepath.Join(dir, "loader", "entries")
+
+			for n, content := range tc.existingFiles {
+				p := filepath.Join(dir, n)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal("couldn't create existing file directory:", err)
+				}
+				if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+					t.Fatal("couldn't write existing file:", err)
+				}
+			}
+
+			var commands []string
+			run := func(ctx context.Context, name string, args ...string) error {
+				commands = append(commands, strings.Join(append([]string{name}, args...), " "))
+				if tc.bootctlErr {
+					return errors.New("bootctl failed")
+				}
+				return nil
+			}
+			b := bootmenu.NewBLS(config.BLSRules{EntriesDir: entriesDir, Options: tc.options}, run)
+
+			err := b.Update(context.Background(), tc.entries)
+			assert.Equal(t, tc.wantCommands, commands, "unexpected commands run")
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			// Entries and kernels are relative to the boot partition.
+			got := make(map[string]string)
+			err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				content, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dir, p)
+				if err != nil {
+					return err
+				}
+				got[rel] = string(content)
+				return nil
+			})
+			if err != nil {
+				t.Fatal("couldn't read boot partition:", err)
+			}
+
+			want := make(map[string]string)
+			testutils.LoadFromGoldenFile(t, got, &want)
+			assert.Equal(t, want, got, "Boot entries and kernels don't match")
+		})
+	}
+}
+
+func TestGrubUpdate(t *testing.T) {
+	t.Parallel()
+
+	lastUsed := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-42-generic", LastUsed: lastUsed}
+	snapshot := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@autozsys_abcd", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-time.Hour), History: true}
+	clone := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_5678", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-26-generic", LastUsed: lastUsed.Add(-24 * time.Hour), History: true}
+	fallback := snapshot
+	fallback.Default = true
+	fallbackWithoutID := clone
+	fallbackWithoutID.Default = true
+	fallbackNotInMenu := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@manual", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-2 * time.Hour), History: true, Default: true}
+
+	tests := map[string]struct {
+		entries       []bootmenu.Entry
+		grubEnv       string
+		updateGrubErr bool
+
+		wantErr bool
+	}{
+		"Regenerate menu": {entries: []bootmenu.Entry{current, snapshot, clone}},
+		"Fallback of a failed trial is booted once":    {entries: []bootmenu.Entry{current, fallback, clone}},
+		"Fallback to an entry without id uses titles":  {entries: []bootmenu.Entry{current, snapshot, fallbackWithoutID}},
+		"Keep other GRUB environment variables":        {entries: []bootmenu.Entry{current, fallback}, grubEnv: "# GRUB Environment Block\nsaved_entry=0\nnext_entry=old\n"},
+		"Error on update-grub fails":                   {entries: []bootmenu.Entry{current, fallback}, updateGrubErr: true, wantErr: true},
+		"Error on fallback isn't in the GRUB menu":     {entries: []bootmenu.Entry{current, snapshot, fallbackNotInMenu}, wantErr: true},
+		"Error on GRUB environment block is too large": {entries: []bootmenu.Entry{current, fallback}, grubEnv: strings.Repeat("a", 1024) + "=b\n", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			grubEnv := filepath.Join(dir, "grubenv")
+
+			if tc.grubEnv != "" {
+				env := tc.grubEnv + strings.Repeat("#", 1024-len(tc.grubEnv)%1024)
+				if err := os.WriteFile(grubEnv, []byte(env), 0644); err != nil {
+					t.Fatal("couldn't write existing GRUB environment block:", err)
+				}
+			}
+
+			var commands []string
+			run := func(ctx context.Context, name string, args ...string) error {
+				commands = append(commands, strings.Join(append([]string{name}, args...), " "))
+				if tc.updateGrubErr {
+					return errors.New("update-grub failed")
+				}
+				return nil
+			}
+			b := bootmenu.NewGrub(filepath.Join("testdata", "grub.cfg"), grubEnv, run)
+
+			err := b.Update(context.Background(), tc.entries)
+			assert.Equal(t, []string{"update-grub"}, commands, "update-grub should regenerate the menu")
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			var got []string
+			if content, err := os.ReadFile(grubEnv); err == nil {
+				assert.Len(t, content, 1024, "GRUB environment block has a fixed size")
+				got = strings.Split(strings.TrimRight(string(content), "#"), "\n")
+			}
+			var want []string
+			testutils.LoadFromGoldenFile(t, got, &want)
+			assert.Equal(t, want, got, "GRUB environment block doesn't match")
+		})
+	}
+}
+
+func TestNativeGrubUpdate(t *testing.T) {
+	t.Parallel()
+
+	lastUsed := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-42-generic", LastUsed: lastUsed,
+		Boot: "bpool/BOOT/ubuntu_1234"}
+	snapshot := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@autozsys_abcd", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-time.Hour), History: true,
+		Boot: "bpool/BOOT/ubuntu_1234@autozsys_abcd", Users: []string{"root", "user1"}}
+	clone := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_5678", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-26-generic", LastUsed: lastUsed.Add(-24 * time.Hour), History: true,
+		Boot: "bpool/BOOT/ubuntu_5678"}
+	noKernel := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@manual", Machine: "rpool/ROOT/ubuntu_1234", LastUsed: lastUsed.Add(-2 * time.Hour), History: true,
+		Boot: "bpool/BOOT/ubuntu_1234@manual"}
+	kernelsOnRoot := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@autozsys_efgh", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-3 * time.Hour), History: true,
+		Boot: "rpool/ROOT/ubuntu_1234@autozsys_efgh", BootOnRoot: true}
+	withDescription := bootmenu.Entry{ID: "rpool/ROOT/ubuntu_1234@manual", Machine: "rpool/ROOT/ubuntu_1234", Kernel: "vmlinuz-5.4.0-40-generic", LastUsed: lastUsed.Add(-4 * time.Hour), History: true,
+		Boot: "bpool/BOOT/ubuntu_1234@manual", Description: "Before 'release' upgrade"}
+	fallback := snapshot
+	fallback.Default = true
+	otherMachine := bootmenu.Entry{ID: "rpool/ROOT/debian_abcd@autozsys_ijkl", Machine: "rpool/ROOT/debian_abcd", Kernel: "vmlinuz-4.19.0-10-amd64", LastUsed: lastUsed.Add(-5 * time.Hour), History: true,
+		Boot: "bpool/BOOT/debian_abcd@autozsys_ijkl"}
+
+	tests := map[string]struct {
+		entries []bootmenu.Entry
+		options string
+	}{
+		"One submenu per saved state":                {entries: []bootmenu.Entry{current, snapshot, clone}},
+		"No menu without saved states":               {entries: []bootmenu.Entry{current}},
+		"No menu without machines":                   {},
+		"Skip states without a recorded kernel":      {entries: []bootmenu.Entry{current, snapshot, noKernel}},
+		"Kernels in the root dataset":                {entries: []bootmenu.Entry{current, kernelsOnRoot}},
+		"Description is quoted in the title":         {entries: []bootmenu.Entry{current, withDescription}},
+		"Fallback of a failed trial is the default":  {entries: []bootmenu.Entry{current, fallback, clone}},
+		"One history submenu per machine":            {entries: []bootmenu.Entry{current, snapshot, otherMachine}},
+		"Custom kernel options":                      {entries: []bootmenu.Entry{current, snapshot}, options: "ro console=ttyS0"},
+		"Revert user data only when there are users": {entries: []bootmenu.Entry{current, clone}},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			fragment := filepath.Join(dir, "grub", "zsys.cfg")
+
+			b, err := bootmenu.New(config.BootRules{Menu: bootmenu.NativeGrubBackend, GrubNative: config.GrubNativeRules{Fragment: fragment, Options: tc.options}})
+			if err != nil {
+				t.Fatal("couldn't create native GRUB backend:", err)
+			}
+
+			if err := b.Update(context.Background(), tc.entries); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			content, err := os.ReadFile(fragment)
+			if err != nil {
+				t.Fatal("couldn't read GRUB menu:", err)
+			}
+			if _, err := os.Stat(fragment + ".tmp"); err == nil {
+				t.Error("temporary GRUB menu file should have been renamed")
+			}
+
+			// One golden element per line keeps the fragment readable.
+			got := strings.Split(string(content), "\n")
+			var want []string
+			testutils.LoadFromGoldenFile(t, got, &want)
+			assert.Equal(t, want, got, "GRUB menu doesn't match")
+		})
+	}
+}
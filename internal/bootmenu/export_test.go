@@ -0,0 +1,23 @@
+package bootmenu
+
+import (
+	"context"
+
+	"github.com/ubuntu/zsys/internal/config"
+)
+
+// CommandRunner runs external commands of the backends
+type CommandRunner = func(ctx context.Context, name string, args ...string) error
+
+// NewGrub returns the update-grub backend using the GRUB configuration and environment files at cfg and env, and
+// running external commands with run
+func NewGrub(cfg, env string, run CommandRunner) Backend {
+	return grub{config: cfg, env: env, run: run}
+}
+
+// NewBLS returns the Boot Loader Specification backend running external commands with run
+func NewBLS(conf config.BLSRules, run CommandRunner) Backend {
+	b := newBLS(conf)
+	b.run = run
+	return b
+}
@@ -0,0 +1,169 @@
+package bootmenu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+const (
+	updateGrubCmd     = "update-grub"
+	defaultGrubConfig = "/boot/grub/grub.cfg"
+	defaultGrubEnv    = "/boot/grub/grubenv"
+
+	// grubEnvHeader starts the GRUB environment block, which is padded with '#' to grubEnvSize bytes.
+	grubEnvHeader = "# GRUB Environment Block\n"
+	grubEnvSize   = 1024
+	// grubNextEntry is the GRUB environment variable holding the entry to boot once, as set by grub-reboot.
+	grubNextEntry = "next_entry"
+)
+
+// grubMenuEntryRe matches the id, or the title if it has none, of a GRUB menu entry or submenu.
+var grubMenuEntryRe = regexp.MustCompile(`^(?:menuentry|submenu)\s+'((?:[^']|'\\'')*)'.*?(?:(?:--id|\$\{?menuentry_id_option\}?)\s+'([^']*)')?\s*{$`)
+
+// commandRunner runs the command name with args, logging its output.
+type commandRunner func(ctx context.Context, name string, args ...string) error
+
+// grub lets update-grub scan the states by itself.
+// The fallback of a failed trial boot is booted once through the GRUB environment, as grub-reboot does.
+type grub struct {
+	config string
+	env    string
+	run    commandRunner
+}
+
+func newGrub() grub {
+	return grub{
+		config: defaultGrubConfig,
+		env:    defaultGrubEnv,
+		run:    runCommand,
+	}
+}
+
+// Update runs update-grub and selects the default entry, if any, for next boot.
+func (g grub) Update(ctx context.Context, entries []Entry) error {
+	log.RemotePrintln(ctx, i18n.G("ZSys is adding automatic system snapshot to GRUB menu"))
+	if err := g.run(ctx, updateGrubCmd); err != nil {
+		return err
+	}
+
+	e, ok := defaultEntry(entries)
+	if !ok {
+		return nil
+	}
+	path, err := grubEntryPath(g.config, e.ID)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't find boot entry of %s: ")+config.ErrorFormat, e.ID, err)
+	}
+	log.Infof(ctx, i18n.G("Next boot falls back to %s"), e.ID)
+	if err := setGrubEnv(g.env, grubNextEntry, path); err != nil {
+		return fmt.Errorf(i18n.G("couldn't select %s for next boot: ")+config.ErrorFormat, e.ID, err)
+	}
+	return nil
+}
+
+// grubEntryPath returns the GRUB path, like submenu>entry, of the first menu entry of cfg booting the state id
+// without reverting user data.
+func grubEntryPath(cfg, id string) (string, error) {
+	f, err := os.Open(cfg)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	root := "root=ZFS=" + id
+	// blocks are the opened menus and entries. Other blocks, like functions, are empty.
+	var blocks []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		switch {
+		case l == "}":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		case strings.HasSuffix(l, "{"):
+			var name string
+			if m := grubMenuEntryRe.FindStringSubmatch(l); m != nil {
+				name = m[2]
+				if name == "" {
+					name = strings.ReplaceAll(m[1], `'\''`, "'")
+				}
+			}
+			blocks = append(blocks, name)
+		case strings.HasPrefix(l, "linux"):
+			args := strings.Fields(l)
+			if !containsString(args, root) || containsString(args, grubRevertUserdata) {
+				continue
+			}
+			return strings.Join(blocks, ">"), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf(i18n.G("no entry booting %s in %s"), id, cfg)
+}
+
+// setGrubEnv sets the variable key to value in the GRUB environment block path, keeping the other variables.
+func setGrubEnv(path, key, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(grubEnvHeader)
+	for _, l := range strings.Split(string(content), "\n") {
+		if l == "" || strings.HasPrefix(l, "#") || strings.HasPrefix(l, key+"=") {
+			continue
+		}
+		fmt.Fprintln(&buf, l)
+	}
+	fmt.Fprintf(&buf, "%s=%s\n", key, value)
+	if buf.Len() > grubEnvSize {
+		return fmt.Errorf(i18n.G("GRUB environment block is larger than %d bytes"), grubEnvSize)
+	}
+	buf.WriteString(strings.Repeat("#", grubEnvSize-buf.Len()))
+
+	return writeFileAtomically(path, buf.Bytes())
+}
+
+// runCommand runs the command name with args, logging its output in debug mode.
+func runCommand(ctx context.Context, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	logger := &logWriter{ctx: ctx}
+	cmd.Stdout = logger
+	cmd.Stderr = logger
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(i18n.G("%q returned an error: ")+config.ErrorFormat, name, err)
+	}
+	return nil
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+type logWriter struct {
+	ctx context.Context
+}
+
+func (lw logWriter) Write(p []byte) (n int, err error) {
+	log.Debug(lw.ctx, string(p))
+	return len(p), nil
+}
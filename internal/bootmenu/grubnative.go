@@ -0,0 +1,168 @@
+package bootmenu
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+const (
+	defaultGrubFragment = "/boot/grub/zsys.cfg"
+	defaultGrubOptions  = "ro quiet splash"
+	grubTimeFormat      = "2006-01-02 15:04:05"
+	// grubRevertUserdata is the kernel command line option asking the initramfs to revert user states too.
+	grubRevertUserdata = "zsys-revert=userdata"
+)
+
+// nativeGrub writes the history submenus in a fragment sourced by the 42_zsys_history grub.d drop-in.
+// update-grub only needs to run when the drop-in is installed, not on each state change.
+type nativeGrub struct {
+	fragment string
+	options  string
+}
+
+func newNativeGrub(conf config.GrubNativeRules) nativeGrub {
+	g := nativeGrub{
+		fragment: conf.Fragment,
+		options:  conf.Options,
+	}
+	if g.fragment == "" {
+		g.fragment = defaultGrubFragment
+	}
+	if g.options == "" {
+		g.options = defaultGrubOptions
+	}
+	return g
+}
+
+// Update replaces the fragment with the history submenus of entries.
+func (g nativeGrub) Update(ctx context.Context, entries []Entry) error {
+	log.RemotePrintln(ctx, i18n.G("ZSys is updating GRUB history menu"))
+
+	if err := os.MkdirAll(filepath.Dir(g.fragment), 0755); err != nil {
+		return fmt.Errorf(i18n.G("couldn't create GRUB menu directory: ")+config.ErrorFormat, err)
+	}
+	if err := writeFileAtomically(g.fragment, g.menu(ctx, entries)); err != nil {
+		return fmt.Errorf(i18n.G("couldn't write GRUB menu %s: ")+config.ErrorFormat, g.fragment, err)
+	}
+	return nil
+}
+
+// menu returns the fragment content: one submenu per machine, listing its saved states from the most recent one.
+// Entries are expected grouped by machine, as returned by Machines.
+func (g nativeGrub) menu(ctx context.Context, entries []Entry) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "# Generated by ZSys on each state change, do not edit.")
+
+	var machine, defaultEntry string
+	for _, e := range entries {
+		if !e.History {
+			continue
+		}
+		if e.Kernel == "" {
+			log.Infof(ctx, i18n.G("No kernel recorded for %s, skipping its boot entry"), e.ID)
+			continue
+		}
+
+		if e.Machine != machine {
+			if machine != "" {
+				fmt.Fprintln(&buf, "}")
+			}
+			machine = e.Machine
+			fmt.Fprintf(&buf, "submenu %s --id %s {\n", grubQuote(fmt.Sprintf(i18n.G("History for %s"), machine)), grubQuote(grubMachineID(machine)))
+		}
+
+		stateID := grubStateID(e.ID)
+		title := fmt.Sprintf(i18n.G("Revert to %s"), e.LastUsed.Format(grubTimeFormat))
+		if e.Description != "" {
+			title = fmt.Sprintf("%s (%s)", title, e.Description)
+		}
+		fmt.Fprintf(&buf, "\tsubmenu %s --id %s {\n", grubQuote(title), grubQuote(stateID))
+		g.writeEntry(&buf, e, i18n.G("Revert system only"), stateID+"-system", "")
+		if len(e.Users) > 0 {
+			g.writeEntry(&buf, e, i18n.G("Revert system and user data"), stateID+"-userdata", grubRevertUserdata)
+		}
+		fmt.Fprintln(&buf, "\t}")
+
+		if e.Default {
+			defaultEntry = grubMachineID(machine) + ">" + stateID + ">" + stateID + "-system"
+		}
+	}
+	if machine != "" {
+		fmt.Fprintln(&buf, "}")
+	}
+
+	if defaultEntry != "" {
+		fmt.Fprintf(&buf, "set default=%s\n", grubQuote(defaultEntry))
+	}
+
+	return buf.Bytes()
+}
+
+// writeEntry writes the menuentry booting e with the additional kernel command line option extra, if any.
+func (g nativeGrub) writeEntry(buf *bytes.Buffer, e Entry, title, id, extra string) {
+	version := kernelVersion(e.Kernel)
+	pool, dir := grubBootPath(e)
+
+	options := g.options
+	if extra != "" {
+		options = extra + " " + options
+	}
+
+	fmt.Fprintf(buf, "\t\tmenuentry %s --id %s {\n", grubQuote(title), grubQuote(id))
+	fmt.Fprintln(buf, "\t\t\tinsmod zfs")
+	fmt.Fprintf(buf, "\t\t\tsearch --no-floppy --label %s --set=root\n", grubQuote(pool))
+	fmt.Fprintf(buf, "\t\t\tlinux %s %s\n", grubQuote(dir+"vmlinuz-"+version), "root=ZFS="+e.ID+" "+options)
+	fmt.Fprintf(buf, "\t\t\tinitrd %s\n", grubQuote(dir+"initrd.img-"+version))
+	fmt.Fprintln(buf, "\t\t}")
+}
+
+// grubBootPath returns the pool holding the kernels of e and the GRUB path to their directory, like
+// /BOOT/ubuntu_1234@autozsys_abcd/ for a snapshot or /ROOT/ubuntu_1234@/boot/ for a filesystem dataset.
+func grubBootPath(e Entry) (pool, dir string) {
+	boot := e.Boot
+	if boot == "" {
+		boot = e.ID
+	}
+	if !strings.Contains(boot, "@") {
+		boot += "@"
+	}
+
+	pool = boot
+	var path string
+	if i := strings.Index(boot, "/"); i >= 0 {
+		pool, path = boot[:i], boot[i:]
+	}
+	if i := strings.Index(pool, "@"); i >= 0 {
+		// Snapshot of the pool root dataset.
+		pool, path = pool[:i], "/"+pool[i:]
+	}
+
+	dir = path + "/"
+	if e.BootOnRoot {
+		dir += "boot/"
+	}
+	return pool, dir
+}
+
+// grubMachineID returns the GRUB menu id of the history submenu of machine.
+func grubMachineID(machine string) string {
+	return "zsys-history-" + machine
+}
+
+// grubStateID returns the GRUB menu id of the submenu of the state id.
+func grubStateID(id string) string {
+	return "zsys-" + id
+}
+
+// grubQuote returns s quoted for GRUB scripts.
+func grubQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -23,10 +23,14 @@ var ErrorFormat = "%v"
 
 // ZConfig stores the configuration of zsys
 type ZConfig struct {
-	History HistoryRules
-	General struct {
-		Timeout          int
-		MinFreePoolSpace int
+	History     HistoryRules
+	UserHistory *UserHistoryRules
+	Replication ReplicationRules
+	Boot        BootRules
+	General     struct {
+		Timeout             int
+		MinFreePoolSpace    int
+		TargetFreePoolSpace int
 	}
 	Path string
 }
@@ -35,6 +39,7 @@ type ZConfig struct {
 type HistoryRules struct {
 	GCStartAfter int64
 	KeepLast     int
+	KeepTags     []string
 	GCRules      []struct {
 		Name             string
 		Buckets          int
@@ -43,6 +48,59 @@ type HistoryRules struct {
 	}
 }
 
+// UserHistoryRules store the rules for each GC element of user states which aren't linked to a system state.
+// Users overrides those rules for the given user names.
+type UserHistoryRules struct {
+	HistoryRules `yaml:",inline"`
+	Users        map[string]HistoryRules
+}
+
+// UserRules returns the history rules applying to the states of user.
+// Without any user history configuration, system history rules apply.
+func (c ZConfig) UserRules(user string) HistoryRules {
+	if c.UserHistory == nil {
+		return c.History
+	}
+	if r, ok := c.UserHistory.Users[user]; ok {
+		return r
+	}
+	return c.UserHistory.HistoryRules
+}
+
+// ReplicationRules store where and how many states are replicated to a backup pool
+type ReplicationRules struct {
+	Target   string
+	KeepLast int
+}
+
+// BootRules store how new system states are tried before being committed and how the boot menu is generated
+type BootRules struct {
+	TrialBoots   int
+	HealthChecks HealthChecks
+	Menu         string
+	BLS          BLSRules
+	GrubNative   GrubNativeRules
+}
+
+// HealthChecks store what needs to succeed for a trial boot to be committed
+type HealthChecks struct {
+	Units   []string
+	Scripts []string
+	Timeout int
+}
+
+// BLSRules store where and how Boot Loader Specification entries are written
+type BLSRules struct {
+	EntriesDir string
+	Options    string
+}
+
+// GrubNativeRules store where and how the GRUB history menu generated by zsys is written
+type GrubNativeRules struct {
+	Fragment string
+	Options  string
+}
+
 // SetVerboseMode change ErrorFormat and logs between very, middly and non verbose
 func SetVerboseMode(level int) {
 	if level > 2 {
@@ -73,9 +131,9 @@ func Load(ctx context.Context, path string) (ZConfig, error) {
 		b = internalconf
 	}
 
-	err = yaml.Unmarshal(b, &c)
+	c, err = Parse(b)
 	if err != nil {
-		return c, fmt.Errorf(i18n.G("failed to unmarshal yaml: %v"), err)
+		return c, err
 	}
 
 	c.Path = path
@@ -83,6 +141,15 @@ func Load(ctx context.Context, path string) (ZConfig, error) {
 	return c, nil
 }
 
+// Parse reads a zsys configuration from its yaml content
+func Parse(b []byte) (ZConfig, error) {
+	var c ZConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf(i18n.G("failed to unmarshal yaml: %v"), err)
+	}
+	return c, nil
+}
+
 // SocketPath returns the unix path which can be overridden by environment variable
 func SocketPath() string {
 	s := defaultSocket
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/ubuntu/zsys"
@@ -8,6 +9,7 @@ import (
 	"github.com/ubuntu/zsys/internal/config"
 	"github.com/ubuntu/zsys/internal/i18n"
 	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/machines"
 )
 
 // PrepareBoot consolidates canmount states for early boot.
@@ -35,6 +37,7 @@ func (s *Server) PrepareBoot(req *zsys.Empty, stream zsys.Zsys_PrepareBootServer
 
 // CommitBoot commits current state to be the active one by promoting its datasets if needed, set last used,
 // associate user datasets to it and rebuilding grub menu.
+// Trial boots are only committed once they pass health checks.
 // After this operation, every New() call will get the current and correct system state.
 // Return if any dataset / machine changed has been done during boot commit and an error if any encountered.
 func (s *Server) CommitBoot(req *zsys.Empty, stream zsys.Zsys_CommitBootServer) (err error) {
@@ -49,6 +52,13 @@ func (s *Server) CommitBoot(req *zsys.Empty, stream zsys.Zsys_CommitBootServer)
 
 	changed, err := s.Machines.Commit(stream.Context())
 	if err != nil {
+		// The boot menu needs to fall back once the trial failed.
+		var e *machines.ErrTrialFailed
+		if errors.As(err, &e) && e.Exhausted {
+			if errMenu := s.Machines.UpdateBootMenu(stream.Context()); errMenu != nil {
+				log.Warningf(stream.Context(), i18n.G("couldn't update boot menu: ")+config.ErrorFormat, errMenu)
+			}
+		}
 		return fmt.Errorf(i18n.G("couldn't commit: ")+config.ErrorFormat, err)
 	}
 	stream.Send(&zsys.CommitBootResponse{
@@ -59,7 +69,7 @@ func (s *Server) CommitBoot(req *zsys.Empty, stream zsys.Zsys_CommitBootServer)
 		return nil
 	}
 
-	return updateBootMenu(stream.Context())
+	return s.Machines.UpdateBootMenu(stream.Context())
 }
 
 // UpdateBootMenu updates machine bootmenu.
@@ -78,7 +88,7 @@ func (s *Server) UpdateBootMenu(req *zsys.UpdateBootMenuRequest, stream zsys.Zsy
 
 	log.Infof(stream.Context(), i18n.G("Updating system boot menu"))
 
-	return updateBootMenu(stream.Context())
+	return s.Machines.UpdateBootMenu(stream.Context())
 }
 
 // UpdateLastUsed updates all active (system and user) datasets with current time
@@ -94,3 +104,21 @@ func (s *Server) UpdateLastUsed(req *zsys.Empty, stream zsys.Zsys_UpdateLastUsed
 
 	return s.Machines.UpdateLastUsed(stream.Context())
 }
+
+// BootNext records a system state of the current machine to boot on once, on next boot.
+func (s *Server) BootNext(req *zsys.BootNextRequest, stream zsys.Zsys_BootNextServer) (err error) {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+	log.Infof(stream.Context(), i18n.G("Requesting next boot on %q"), stateName)
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	if err := s.Machines.BootNext(stream.Context(), stateName, req.GetRevertUserData()); err != nil {
+		return fmt.Errorf(i18n.G("couldn't set next boot on %s: ")+config.ErrorFormat, stateName, err)
+	}
+	return nil
+}
@@ -28,11 +28,16 @@ type Server struct {
 
 	// Requests mutex
 	RWRequest sync.RWMutex
+	// replication prevents concurrent replications, which run without holding RWRequest
+	replication sync.Mutex
 
 	socket     string
 	lis        net.Listener
 	grpcserver *grpc.Server
 
+	// stopEvents stops following ZFS events
+	stopEvents context.CancelFunc
+
 	// Those elements could be mocked in tests
 	authorizer        *authorizer.Authorizer
 	systemdSdNotifier func(unsetEnvironment bool, state string) (bool, error)
@@ -138,6 +143,15 @@ func New(socket string, opts ...option) (*Server, error) {
 	grpcserver := zsys.RegisterServer(s)
 	s.grpcserver = grpcserver
 
+	// Follow changes done outside of zsys
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopEvents = cancel
+	if events, err := s.Machines.Events(ctx); err != nil {
+		log.Warningf(ctx, i18n.G("couldn't follow ZFS events, changes done outside of zsys won't be noticed: %v"), err)
+	} else {
+		go s.followEvents(ctx, events)
+	}
+
 	// Handle idle timeout
 	go s.idlerTimeout.start(s)
 
@@ -164,6 +178,7 @@ func (s *Server) Listen() error {
 func (s *Server) Stop() {
 	log.Debug(context.Background(), i18n.G("Stopping daemon requested. Wait for active requests to close"))
 	s.grpcserver.GracefulStop()
+	s.stopEvents()
 	log.Debug(context.Background(), i18n.G("All connections closed"))
 }
 
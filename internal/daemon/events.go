@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// eventsBatchDelay is how long events are collected after the first one before being applied together, as a single
+// zfs command sends one event per changed dataset.
+const eventsBatchDelay = 500 * time.Millisecond
+
+// followEvents applies the ZFS events to the machines until the event stream is closed, so that changes done outside
+// of zsys are known without any full rescan.
+func (s *Server) followEvents(ctx context.Context, events <-chan libzfs.Event) {
+	for e := range events {
+		batch := []libzfs.Event{e}
+		timeout := time.After(eventsBatchDelay)
+	collect:
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, e)
+			case <-timeout:
+				break collect
+			}
+		}
+
+		s.RWRequest.Lock()
+		if err := s.Machines.ApplyEvents(ctx, batch); err != nil {
+			log.Warningf(ctx, "%v", err)
+		}
+		s.RWRequest.Unlock()
+	}
+}
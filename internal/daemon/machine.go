@@ -24,7 +24,7 @@ func (s *Server) MachineShow(req *zsys.MachineShowRequest, stream zsys.Zsys_Mach
 
 	log.Infof(stream.Context(), i18n.G("Retrieving information for machine %s"), m.ID)
 
-	machineInfo, err := m.Info(fullInfo)
+	machineInfo, err := m.Info(fullInfo, req.GetTags())
 	if err != nil {
 		return fmt.Errorf(i18n.G("couldn't fetch matching information: %v"), err)
 	}
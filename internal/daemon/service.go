@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -180,5 +181,142 @@ func (s *Server) GC(req *zsys.GCRequest, stream zsys.Zsys_GCServer) error {
 	s.RWRequest.Lock()
 	defer s.RWRequest.Unlock()
 
+	if req.GetSpace() {
+		return s.Machines.GCSpace(stream.Context(), req.GetAll())
+	}
 	return s.Machines.GC(stream.Context(), req.GetAll())
 }
+
+// GCPlan returns what garbage collection would remove or keep, and why, without removing anything.
+func (s *Server) GCPlan(req *zsys.GCRequest, stream zsys.Zsys_GCPlanServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionAlwaysAllowed); err != nil {
+		return err
+	}
+	log.Info(stream.Context(), i18n.G("Requesting zsys daemon garbage collection plan"))
+
+	if req.GetSpace() {
+		return errors.New(i18n.G("dry run isn't supported for free space garbage collection"))
+	}
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	decisions, err := s.Machines.GCPlan(stream.Context(), req.GetAll())
+	if err != nil {
+		return err
+	}
+
+	plan := &zsys.GCPlan{}
+	for _, d := range decisions {
+		plan.Decisions = append(plan.Decisions, &zsys.GCDecision{
+			Id:       d.ID,
+			Machine:  d.Machine,
+			User:     d.User,
+			LastUsed: d.LastUsed.Unix(),
+			Bucket:   d.Bucket,
+			Remove:   d.Remove,
+			Reason:   d.Reason,
+		})
+	}
+	stream.Send(&zsys.GCPlanResponse{
+		Reply: &zsys.GCPlanResponse_Plan{Plan: plan},
+	})
+
+	return nil
+}
+
+// GCSimulate replays garbage collection over future days on a copy of the states, saving new ones at regular interval
+func (s *Server) GCSimulate(req *zsys.GCSimulateRequest, stream zsys.Zsys_GCSimulateServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionAlwaysAllowed); err != nil {
+		return err
+	}
+	log.Info(stream.Context(), i18n.G("Requesting zsys daemon garbage collection simulation"))
+
+	var conf *config.ZConfig
+	if len(req.GetConfig()) > 0 {
+		c, err := config.Parse(req.GetConfig())
+		if err != nil {
+			return fmt.Errorf(i18n.G("invalid configuration to simulate: ")+config.ErrorFormat, err)
+		}
+		conf = &c
+	}
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	days, err := s.Machines.SimulateGC(stream.Context(), conf, int(req.GetDays()), time.Duration(req.GetSnapshotEvery())*time.Second, req.GetAll())
+	if err != nil {
+		return err
+	}
+
+	simulation := &zsys.GCSimulation{}
+	for _, d := range days {
+		simulation.Days = append(simulation.Days, &zsys.GCSimulationDay{
+			Date:       d.Date.Unix(),
+			Created:    int32(d.Created),
+			Removed:    int32(d.Removed),
+			States:     d.States,
+			UserStates: int32(d.UserStates),
+		})
+	}
+	stream.Send(&zsys.GCSimulateResponse{
+		Reply: &zsys.GCSimulateResponse_Simulation{Simulation: simulation},
+	})
+
+	return nil
+}
+
+// Replicate mirrors saved states to the backup pool
+func (s *Server) Replicate(req *zsys.Empty, stream zsys.Zsys_ReplicateServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+	log.Info(stream.Context(), i18n.G("Requesting zsys daemon to replicate states"))
+
+	return s.replicate(stream.Context())
+}
+
+// replicate mirrors saved states to the replication target. The states are listed under the request lock, but streamed
+// without holding it, so that other requests aren't blocked by the transfer.
+// Only one replication runs at a time.
+func (s *Server) replicate(ctx context.Context) error {
+	s.replication.Lock()
+	defer s.replication.Unlock()
+
+	s.RWRequest.RLock()
+	r := s.Machines.PrepareReplication(ctx)
+	s.RWRequest.RUnlock()
+
+	errRun := r.Run(ctx)
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+	if err := s.Machines.RefreshReplicated(ctx, r); err != nil {
+		if errRun != nil {
+			return fmt.Errorf("%v\n%v", errRun, err)
+		}
+		return err
+	}
+	return errRun
+}
+
+// replicateInBackground mirrors new saved states to the replication target, if any is configured.
+// It can be called while holding the request lock: the replication waits for it to be released.
+func (s *Server) replicateInBackground() {
+	done := s.TrackRequest()
+	go func() {
+		defer done()
+
+		ctx := context.Background()
+		s.RWRequest.RLock()
+		configured := s.Machines.PrepareReplication(ctx).Configured()
+		s.RWRequest.RUnlock()
+		if !configured {
+			return
+		}
+
+		if err := s.replicate(ctx); err != nil {
+			log.Warningf(ctx, i18n.G("couldn't replicate new state: %v"), err)
+		}
+	}()
+}
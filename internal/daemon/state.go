@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ubuntu/zsys"
 	"github.com/ubuntu/zsys/internal/authorizer"
@@ -46,12 +47,23 @@ func (s *Server) SaveSystemState(req *zsys.SaveSystemStateRequest, stream zsys.Z
 		}
 	}
 
-	if stateName, err = s.Machines.CreateSystemSnapshot(stream.Context(), stateName); err != nil {
+	meta := machines.StateMetadata{
+		Description: req.GetDescription(),
+		Tags:        req.GetTags(),
+		PackageChanges: machines.PackageChanges{
+			Upgraded:  req.GetPackageChanges().GetUpgraded(),
+			Installed: req.GetPackageChanges().GetInstalled(),
+			Removed:   req.GetPackageChanges().GetRemoved(),
+		},
+	}
+	if stateName, err = s.Machines.CreateSystemSnapshot(stream.Context(), stateName, meta); err != nil {
 		return fmt.Errorf(i18n.G("couldn't save system state: ")+config.ErrorFormat, err)
 	}
+	// Mirror the new state once this request has released the lock.
+	defer s.replicateInBackground()
 
 	if req.GetUpdateBootMenu() {
-		if err := updateBootMenu(stream.Context()); err != nil {
+		if err := s.Machines.UpdateBootMenu(stream.Context()); err != nil {
 			return err
 		}
 	}
@@ -86,9 +98,14 @@ func (s *Server) SaveUserState(req *zsys.SaveUserStateRequest, stream zsys.Zsys_
 		log.Infof(stream.Context(), i18n.G("Requesting to save state for user %q"), userName)
 	}
 
-	if stateName, err = s.Machines.CreateUserSnapshot(stream.Context(), userName, stateName); err != nil {
+	meta := machines.StateMetadata{
+		Description: req.GetDescription(),
+		Tags:        req.GetTags(),
+	}
+	if stateName, err = s.Machines.CreateUserSnapshot(stream.Context(), userName, stateName, meta); err != nil {
 		return fmt.Errorf(i18n.G("couldn't save state for user %q: ")+config.ErrorFormat, userName, err)
 	}
+	defer s.replicateInBackground()
 
 	stream.Send(&zsys.CreateSaveStateResponse{
 		Reply: &zsys.CreateSaveStateResponse_StateName{StateName: stateName},
@@ -138,7 +155,7 @@ func (s *Server) RemoveSystemState(req *zsys.RemoveSystemStateRequest, stream zs
 	if req.GetDryrun() {
 		return nil
 	}
-	return updateBootMenu(stream.Context())
+	return s.Machines.UpdateBootMenu(stream.Context())
 }
 
 // RemoveUserState removes a user state
@@ -184,3 +201,340 @@ func (s *Server) RemoveUserState(req *zsys.RemoveUserStateRequest, stream zsys.Z
 
 	return nil
 }
+
+type exportStateForwarder struct {
+	zsys.Zsys_ExportStateServer
+}
+
+func (e exportStateForwarder) Write(p []byte) (int, error) {
+	err := e.Send(&zsys.ExportStateResponse{
+		Reply: &zsys.ExportStateResponse_Archive{
+			Archive: p,
+		},
+	})
+
+	return len(p), err
+}
+
+// ExportState streams back an archive of a system state, with all its datasets and linked user datasets.
+func (s *Server) ExportState(req *zsys.ExportStateRequest, stream zsys.Zsys_ExportStateServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+	from := req.GetFrom()
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("System state name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to export system state %q"), stateName)
+
+	if err := s.Machines.ExportState(stream.Context(), stateName, from, exportStateForwarder{Zsys_ExportStateServer: stream}); err != nil {
+		return fmt.Errorf(i18n.G("couldn't export system state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	return nil
+}
+
+// importStateReader reads the archive streamed by the client.
+type importStateReader struct {
+	zsys.Zsys_ImportStateServer
+	buf []byte
+}
+
+func (r *importStateReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.GetArchive()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// ImportState imports an archive generated by ExportState, streamed by the client, as a new history state or as a new
+// machine.
+func (s *Server) ImportState(stream zsys.Zsys_ImportStateServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	// The first message carries the import options.
+	req, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't receive import request: %v"), err)
+	}
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	log.Infof(stream.Context(), i18n.G("Requesting to import system state"))
+
+	r := &importStateReader{Zsys_ImportStateServer: stream, buf: req.GetArchive()}
+	stateName, err := s.Machines.ImportState(stream.Context(), r, req.GetAsNewMachine())
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't import system state: ")+config.ErrorFormat, err)
+	}
+
+	if err := s.Machines.UpdateBootMenu(stream.Context()); err != nil {
+		return err
+	}
+
+	stream.Send(&zsys.CreateSaveStateResponse{
+		Reply: &zsys.CreateSaveStateResponse_StateName{StateName: stateName},
+	})
+
+	return nil
+}
+
+// RevertDatasets reverts in place the datasets of the current system state matching the requested routes to the saved
+// state stateName, without rebooting.
+func (s *Server) RevertDatasets(req *zsys.RevertDatasetsRequest, stream zsys.Zsys_RevertDatasetsServer) error {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+	routes := req.GetDatasets()
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to revert %s to state %q"), strings.Join(routes, ", "), stateName)
+
+	if err := s.Machines.RevertDatasets(stream.Context(), stateName, routes); err != nil {
+		return fmt.Errorf(i18n.G("couldn't revert datasets to state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	return nil
+}
+
+// StatePath returns where a path of a saved state can be read on the system.
+// If userName is not empty, only the states of this user are searched.
+func (s *Server) StatePath(req *zsys.StatePathRequest, stream zsys.Zsys_StatePathServer) error {
+	userName := req.GetUserName()
+
+	action := authorizer.ActionSystemList
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting path of %q in state %q"), req.GetPath(), stateName)
+
+	p, err := s.Machines.StatePath(stream.Context(), stateName, userName, req.GetPath())
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't find %q in state %s: ")+config.ErrorFormat, req.GetPath(), stateName, err)
+	}
+
+	stream.Send(&zsys.StatePathResponse{
+		Reply: &zsys.StatePathResponse_Path{Path: p},
+	})
+
+	return nil
+}
+
+// StateDiff returns all file changes between two states of the system.
+// If userName is not empty, only the states of this user are compared.
+// If the second state is empty or "current", the first state is compared to the current one.
+func (s *Server) StateDiff(req *zsys.StateDiffRequest, stream zsys.Zsys_StateDiffServer) error {
+	userName := req.GetUserName()
+
+	// Changed file names of every user are listed when comparing system states.
+	action := authorizer.ActionSystemWrite
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateA, stateB := req.GetStateA(), req.GetStateB()
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	if stateA == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting changes between %q and %q"), stateA, stateB)
+
+	diff, err := s.Machines.StateDiff(stream.Context(), stateA, stateB, userName)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't compare %s: ")+config.ErrorFormat, stateA, err)
+	}
+
+	stream.Send(&zsys.StateDiffResponse{
+		Reply: &zsys.StateDiffResponse_Diff{Diff: diff},
+	})
+
+	return nil
+}
+
+// TagState adds or replaces tags on a system or user state.
+func (s *Server) TagState(req *zsys.TagStateRequest, stream zsys.Zsys_TagStateServer) error {
+	userName := req.GetUserName()
+
+	action := authorizer.ActionSystemWrite
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to tag state %q"), stateName)
+
+	if err := s.Machines.TagState(stream.Context(), stateName, userName, req.GetTags()); err != nil {
+		return fmt.Errorf(i18n.G("couldn't tag state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	return nil
+}
+
+// UntagState removes tags from a system or user state.
+func (s *Server) UntagState(req *zsys.UntagStateRequest, stream zsys.Zsys_UntagStateServer) error {
+	userName := req.GetUserName()
+
+	action := authorizer.ActionSystemWrite
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to remove tags from state %q"), stateName)
+
+	if err := s.Machines.UntagState(stream.Context(), stateName, userName, req.GetTags()); err != nil {
+		return fmt.Errorf(i18n.G("couldn't untag state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	return nil
+}
+
+// PinState pins or unpins a system or user state so that garbage collection never removes it.
+func (s *Server) PinState(req *zsys.PinStateRequest, stream zsys.Zsys_PinStateServer) error {
+	userName := req.GetUserName()
+
+	action := authorizer.ActionSystemWrite
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+	pin := req.GetPin()
+
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	if !pin {
+		log.Infof(stream.Context(), i18n.G("Requesting to unpin state %q"), stateName)
+		if err := s.Machines.PinState(stream.Context(), stateName, userName, false); err != nil {
+			return fmt.Errorf(i18n.G("couldn't unpin state %s: ")+config.ErrorFormat, stateName, err)
+		}
+		return nil
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to pin state %q"), stateName)
+	if err := s.Machines.PinState(stream.Context(), stateName, userName, true); err != nil {
+		return fmt.Errorf(i18n.G("couldn't pin state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	return nil
+}
+
+// StateUsage returns the disk space held by a system or user state.
+func (s *Server) StateUsage(req *zsys.StateUsageRequest, stream zsys.Zsys_StateUsageServer) error {
+	userName := req.GetUserName()
+
+	action := authorizer.ActionSystemList
+	if userName != "" {
+		action = authorizer.ActionUserWrite
+	}
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		action); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+
+	s.RWRequest.RLock()
+	defer s.RWRequest.RUnlock()
+
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting disk usage of state %q"), stateName)
+
+	u, err := s.Machines.StateUsage(stream.Context(), stateName, userName)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't get disk usage of state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	stream.Send(&zsys.StateUsageResponse{
+		Reply: &zsys.StateUsageResponse_Usage{Usage: &zsys.StateUsage{
+			Unique: u.Unique,
+			Shared: u.Shared,
+			Freed:  u.Freed,
+		}},
+	})
+
+	return nil
+}
@@ -1,8 +1,11 @@
 package daemon
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/godbus/dbus/v5"
 	"github.com/ubuntu/zsys"
 	"github.com/ubuntu/zsys/internal/authorizer"
 	"github.com/ubuntu/zsys/internal/config"
@@ -12,6 +15,7 @@ import (
 
 // CreateUserData creates a new userdata for user and set it to homepath on current zsys system.
 // if the user already exists for a dataset attached to the current system, set its mountpoint to homepath.
+// A new userdata is encrypted if requested, with the passphrase sent by the client.
 // This is called by zsys grpc request, once the server is registered
 func (s *Server) CreateUserData(req *zsys.CreateUserDataRequest, stream zsys.Zsys_CreateUserDataServer) (err error) {
 	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
@@ -20,17 +24,63 @@ func (s *Server) CreateUserData(req *zsys.CreateUserDataRequest, stream zsys.Zsy
 
 	user := req.GetUser()
 	homepath := req.GetHomepath()
+	var passphrase []byte
+	if req.GetEncrypt() {
+		if passphrase = req.GetPassphrase(); len(passphrase) == 0 {
+			return errors.New(i18n.G("an encrypted user dataset requires a passphrase"))
+		}
+	}
 	s.RWRequest.Lock()
 	defer s.RWRequest.Unlock()
 
 	log.Infof(stream.Context(), i18n.G("Create user dataset for %q on %q"), user, homepath)
 
-	if err := s.Machines.CreateUserData(stream.Context(), user, homepath); err != nil {
+	if err := s.Machines.CreateUserData(stream.Context(), user, homepath, passphrase); err != nil {
 		return fmt.Errorf(i18n.G("couldn't create userdataset for %q: ")+config.ErrorFormat, homepath, err)
 	}
 	return nil
 }
 
+// MountUserData mounts the user datasets of user attached to the current system, creating one for homepath if needed.
+// This is called by zsys grpc request, once the server is registered
+func (s *Server) MountUserData(req *zsys.MountUserDataRequest, stream zsys.Zsys_MountUserDataServer) (err error) {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	user := req.GetUser()
+	homepath := req.GetHomepath()
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	log.Infof(stream.Context(), i18n.G("Mount user data for %q"), user)
+
+	if err := s.Machines.MountUserData(stream.Context(), user, homepath); err != nil {
+		return fmt.Errorf(i18n.G("couldn't mount user data for %q: ")+config.ErrorFormat, user, err)
+	}
+	return nil
+}
+
+// LoadUserKeys loads the encryption keys of the user datasets of user, with the passphrase sent by the client.
+// This is called by zsys grpc request, once the server is registered
+func (s *Server) LoadUserKeys(req *zsys.LoadUserKeysRequest, stream zsys.Zsys_LoadUserKeysServer) (err error) {
+	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
+		return err
+	}
+
+	user := req.GetUser()
+	passphrase := req.GetPassphrase()
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	log.Infof(stream.Context(), i18n.G("Load encryption keys of user data for %q"), user)
+
+	if err := s.Machines.LoadUserKeys(stream.Context(), user, passphrase); err != nil {
+		return fmt.Errorf(i18n.G("couldn't load encryption keys for %q: ")+config.ErrorFormat, user, err)
+	}
+	return nil
+}
+
 // ChangeHomeOnUserData tries to find an existing dataset matching home as a valid mountpoint and rename it to newhome
 func (s *Server) ChangeHomeOnUserData(req *zsys.ChangeHomeOnUserDataRequest, stream zsys.Zsys_ChangeHomeOnUserDataServer) (err error) {
 	if err := s.authorizer.IsAllowedFromContext(stream.Context(), authorizer.ActionSystemWrite); err != nil {
@@ -69,3 +119,71 @@ func (s *Server) DissociateUser(req *zsys.DissociateUserRequest, stream zsys.Zsy
 	}
 	return nil
 }
+
+// RestoreUserState replaces the active user datasets of the current system by the user state stateName.
+// It is refused while the user has any session opened.
+func (s *Server) RestoreUserState(req *zsys.RestoreUserStateRequest, stream zsys.Zsys_RestoreUserStateServer) error {
+	userName := req.GetUserName()
+
+	if err := s.authorizer.IsAllowedFromContext(context.WithValue(stream.Context(), authorizer.OnUserKey, userName),
+		authorizer.ActionUserWrite); err != nil {
+		return err
+	}
+
+	stateName := req.GetStateName()
+	if stateName == "" {
+		return fmt.Errorf(i18n.G("State name is required"))
+	}
+
+	// Logins mount user datasets through us: holding the lock prevents any new session until the state is restored.
+	s.RWRequest.Lock()
+	defer s.RWRequest.Unlock()
+
+	hasSession, err := userHasSession(userName)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't check sessions of %s: ")+config.ErrorFormat, userName, err)
+	}
+	if hasSession {
+		return fmt.Errorf(i18n.G("%s has an active session. Please log out this user before restoring its state"), userName)
+	}
+
+	log.Infof(stream.Context(), i18n.G("Requesting to restore user state %q for user %s"), stateName, userName)
+
+	id, err := s.Machines.RestoreUserState(stream.Context(), userName, stateName)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't restore user state %s: ")+config.ErrorFormat, stateName, err)
+	}
+
+	stream.Send(&zsys.CreateSaveStateResponse{
+		Reply: &zsys.CreateSaveStateResponse_StateName{StateName: id},
+	})
+
+	return nil
+}
+
+// userHasSession returns if logind has any session opened for user.
+func userHasSession(user string) (bool, error) {
+	bus, err := dbus.SystemBus()
+	if err != nil {
+		return false, err
+	}
+
+	var sessions []struct {
+		ID   string
+		UID  uint32
+		User string
+		Seat string
+		Path dbus.ObjectPath
+	}
+	if err := bus.Object("org.freedesktop.login1", "/org/freedesktop/login1").Call(
+		"org.freedesktop.login1.Manager.ListSessions", 0).Store(&sessions); err != nil {
+		return false, err
+	}
+
+	for _, session := range sessions {
+		if session.User == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,457 @@
+package machines
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+const (
+	// archiveVersion is the version of the archive format, bumped on any incompatible manifest change.
+	archiveVersion = 1
+	// archiveManifestName is the first entry of any archive, describing its content.
+	archiveManifestName = "manifest.json"
+	// archiveStreamsDir is the directory in the archive containing one send stream per dataset.
+	archiveStreamsDir = "streams"
+)
+
+// archiveManifest describes the layout of an exported state.
+type archiveManifest struct {
+	Version int
+	// ID is the id of the exported system state.
+	ID       string
+	LastUsed time.Time
+	// Datasets are all system datasets (ROOT and BOOT) of this state. Parents are always listed before their children.
+	Datasets []archiveDataset
+	// Users are all user datasets linked to this state, per user name.
+	Users map[string][]archiveDataset `json:",omitempty"`
+}
+
+// archiveDataset is a dataset snapshot stored in an archive, with its zsys properties.
+type archiveDataset struct {
+	// Name is the snapshot name on the exported system.
+	Name string
+	// Route is the root dataset of the route this dataset belongs to.
+	Route string
+	// Stream is the archive entry containing the send stream for this dataset.
+	Stream string
+	// From, if not empty, is the snapshot on the exported system the stream is incremental from.
+	From string `json:",omitempty"`
+
+	Mountpoint       string `json:",omitempty"`
+	CanMount         string `json:",omitempty"`
+	BootFS           bool   `json:",omitempty"`
+	LastUsed         int    `json:",omitempty"`
+	LastBootedKernel string `json:",omitempty"`
+	BootfsDatasets   string `json:",omitempty"`
+}
+
+// ExportState writes to w an archive of the system state name, with all its datasets and linked user datasets.
+// Only saved states (snapshots) can be exported.
+// If from is not empty, datasets are exported incrementally from this older saved state, so that the archive can be
+// imported as part of the history of the machine on a system having from.
+func (ms *Machines) ExportState(ctx context.Context, name, from string, w io.Writer) (err error) {
+	s, err := ms.IDToState(ctx, name, "")
+	if err != nil {
+		return err
+	}
+	if !s.isSnapshot() {
+		return fmt.Errorf(i18n.G("%s isn't a saved state. Please save it first and export the resulting state"), s.ID)
+	}
+
+	var bases map[string]string
+	if from != "" {
+		fromState, err := ms.IDToState(ctx, from, "")
+		if err != nil {
+			return err
+		}
+		if !fromState.isSnapshot() {
+			return fmt.Errorf(i18n.G("%s isn't a saved state and can't be used as an incremental base"), fromState.ID)
+		}
+		if fromState.ID == s.ID || !fromState.LastUsed.Before(s.LastUsed) {
+			return fmt.Errorf(i18n.G("%s isn't older than %s and can't be used as an incremental base"), fromState.ID, s.ID)
+		}
+		bases = fromState.snapshotsPerDataset()
+		log.Infof(ctx, i18n.G("Exporting state %s incrementally from %s"), s.ID, fromState.ID)
+	} else {
+		log.Infof(ctx, i18n.G("Exporting state %s"), s.ID)
+	}
+
+	manifest := archiveManifest{
+		Version:  archiveVersion,
+		ID:       s.ID,
+		LastUsed: s.LastUsed,
+		Datasets: newArchiveDatasets(s.Datasets, bases),
+	}
+	if len(s.Users) > 0 {
+		manifest.Users = make(map[string][]archiveDataset)
+	}
+	for u, us := range s.Users {
+		manifest.Users[u] = newArchiveDatasets(us.Datasets, bases)
+	}
+
+	// Streams are buffered on disk, as the tar header needs the size of each entry upfront.
+	dir, err := ioutil.TempDir("", "zsys-export-")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't create temporary directory: %v"), err)
+	}
+	defer os.RemoveAll(dir)
+
+	tw := tar.NewWriter(w)
+	defer func() {
+		if errClose := tw.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf(i18n.G("couldn't finalize archive: %v"), errClose)
+		}
+	}()
+
+	b, err := json.MarshalIndent(manifest, "", "   ")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't generate archive manifest: %v"), err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    archiveManifestName,
+		Mode:    0644,
+		Size:    int64(len(b)),
+		ModTime: s.LastUsed,
+	}); err != nil {
+		return fmt.Errorf(i18n.G("couldn't write archive manifest: %v"), err)
+	}
+	if _, err := tw.Write(b); err != nil {
+		return fmt.Errorf(i18n.G("couldn't write archive manifest: %v"), err)
+	}
+
+	for _, d := range manifest.allDatasets() {
+		if err := ms.writeStreamToArchive(ctx, tw, dir, d, s.LastUsed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeStreamToArchive sends the dataset snapshot d into the archive, using dir to buffer the stream.
+func (ms *Machines) writeStreamToArchive(ctx context.Context, tw *tar.Writer, dir string, d archiveDataset, modTime time.Time) error {
+	f, err := ioutil.TempFile(dir, "stream-")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't create temporary file: %v"), err)
+	}
+	defer f.Close()
+
+	if err := ms.z.Send(ctx, f, d.Name, d.From); err != nil {
+		return fmt.Errorf(i18n.G("couldn't export %q: ")+config.ErrorFormat, d.Name, err)
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't get stream size for %q: %v"), d.Name, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf(i18n.G("couldn't read stream for %q: %v"), d.Name, err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    d.Stream,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	}); err != nil {
+		return fmt.Errorf(i18n.G("couldn't add %q to archive: %v"), d.Name, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf(i18n.G("couldn't add %q to archive: %v"), d.Name, err)
+	}
+
+	return nil
+}
+
+// newArchiveDatasets returns the archive description of all datasets per route, sorted by depth so that
+// parents are always before their children.
+// bases are the snapshots, per dataset, streams are incremental from.
+func newArchiveDatasets(datasets map[string][]*zfs.Dataset, bases map[string]string) (r []archiveDataset) {
+	for _, route := range sortedDatasetNames(datasets) {
+		ds := make(sortedDataset, len(datasets[route]))
+		copy(ds, datasets[route])
+		sort.Sort(ds)
+
+		for _, d := range ds {
+			base, _ := splitSnapshotName(d.Name)
+			r = append(r, archiveDataset{
+				Name:             d.Name,
+				Route:            route,
+				Stream:           filepath.Join(archiveStreamsDir, d.Name),
+				From:             bases[base],
+				Mountpoint:       d.Mountpoint,
+				CanMount:         d.CanMount,
+				BootFS:           d.BootFS,
+				LastUsed:         d.LastUsed,
+				LastBootedKernel: d.LastBootedKernel,
+				BootfsDatasets:   d.BootfsDatasets,
+			})
+		}
+	}
+	return r
+}
+
+// sortedArchiveUsers returns the user names of an archive, sorted.
+func sortedArchiveUsers(users map[string][]archiveDataset) (names []string) {
+	for n := range users {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ImportState receives from r an archive generated by ExportState.
+// By default, the state is imported as a new history state, cloned from the saved state the archive was incrementally
+// exported from. If asNewMachine is true, a full archive is imported as a new machine instead.
+// User datasets are linked to the imported state. The id of the imported state is returned.
+func (ms *Machines) ImportState(ctx context.Context, r io.Reader, asNewMachine bool) (string, error) {
+	tr := tar.NewReader(r)
+	manifest, err := readArchiveManifest(tr)
+	if err != nil {
+		return "", err
+	}
+
+	// Compute the new state ID, named after the machine it's part of the history of.
+	base, _ := splitSnapshotName(manifest.ID)
+	if asNewMachine {
+		for _, d := range manifest.allDatasets() {
+			if d.From != "" {
+				return "", errors.New(i18n.G("incremental archives can only be imported as part of the history of the machine they were exported from"))
+			}
+		}
+	} else {
+		from := manifest.root().From
+		if from == "" {
+			return "", errors.New(i18n.G("archive isn't incremental and can only be imported as a new machine. Please export it from a saved state of this system to import it as history"))
+		}
+		s, err := ms.IDToState(ctx, from, "")
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("archive is incremental from %s, which isn't a saved state of this system: ")+config.ErrorFormat, from, err)
+		}
+		base, _ = splitSnapshotName(s.ID)
+	}
+	stateID := renameWithID(base, ms.z.GenerateID(6))
+
+	log.Infof(ctx, i18n.G("Importing state %s as %s"), manifest.ID, stateID)
+
+	// Map each stream to its new dataset name.
+	targets := make(map[string]string)
+	routeTargets := make(map[string]string)
+	for _, d := range manifest.Datasets {
+		routeBase, _ := splitSnapshotName(d.Route)
+		routeTargets[d.Route] = filepath.Join(filepath.Dir(routeBase), filepath.Base(stateID))
+		if d.Route == manifest.ID {
+			routeTargets[d.Route] = stateID
+		}
+	}
+	userSuffix := ms.z.GenerateID(6)
+	for _, u := range sortedArchiveUsers(manifest.Users) {
+		for _, d := range manifest.Users[u] {
+			routeBase, _ := splitSnapshotName(d.Route)
+			routeTargets[d.Route] = renameWithID(routeBase, userSuffix)
+		}
+	}
+	for _, d := range manifest.allDatasets() {
+		n, _ := splitSnapshotName(d.Name)
+		routeBase, _ := splitSnapshotName(d.Route)
+		targets[d.Stream] = routeTargets[d.Route] + strings.TrimPrefix(n, routeBase)
+	}
+
+	if err := ms.receiveArchive(ctx, tr, manifest, targets, routeTargets, stateID); err != nil {
+		return "", err
+	}
+
+	// Received snapshots would be attached to any state with the same snapshot name: remove them, the imported
+	// state is made of the received filesystem datasets.
+	nt := ms.z.NewNoTransaction(ctx)
+	for _, route := range sortedArchiveRoutes(routeTargets) {
+		_, snapshot := splitSnapshotName(route)
+		if err := nt.Destroy(routeTargets[route] + "@" + snapshot); err != nil {
+			log.Warningf(ctx, i18n.G("couldn't destroy received snapshot: %v"), err)
+		}
+	}
+
+	if err := ms.applyChanges(ctx); err != nil {
+		return "", err
+	}
+	return stateID, nil
+}
+
+// receiveArchive receives all streams from tr into their targets and sets zsys properties on them, in a single
+// transaction.
+// Incremental streams are received as clones of the local snapshot they were sent from.
+func (ms *Machines) receiveArchive(ctx context.Context, tr *tar.Reader, manifest archiveManifest, targets, routeTargets map[string]string, stateID string) error {
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+
+	origins := make(map[string]string)
+	for _, d := range manifest.allDatasets() {
+		origins[d.Stream] = d.From
+	}
+
+	received := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cancel()
+			return fmt.Errorf(i18n.G("couldn't read archive: %v"), err)
+		}
+
+		target, ok := targets[hdr.Name]
+		if !ok {
+			log.Warningf(ctx, i18n.G("ignoring unknown archive entry %q"), hdr.Name)
+			continue
+		}
+		log.RemotePrintf(ctx, i18n.G("Receiving dataset %s\n"), target)
+		if err := t.Receive(target, origins[hdr.Name], tr); err != nil {
+			cancel()
+			return err
+		}
+		received[hdr.Name] = true
+	}
+
+	for _, d := range manifest.allDatasets() {
+		if !received[d.Stream] {
+			cancel()
+			return fmt.Errorf(i18n.G("archive doesn't contain any stream for %s"), d.Name)
+		}
+	}
+
+	// Recreate zsys properties on the new datasets: none of them are active until booted.
+	for _, d := range manifest.Datasets {
+		if err := setArchiveDatasetProperties(t, d, targets[d.Stream], routeTargets[d.Route], ""); err != nil {
+			cancel()
+			return err
+		}
+	}
+	for _, u := range sortedArchiveUsers(manifest.Users) {
+		for _, d := range manifest.Users[u] {
+			if err := setArchiveDatasetProperties(t, d, targets[d.Stream], routeTargets[d.Route], stateID); err != nil {
+				cancel()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setArchiveDatasetProperties sets zsys properties from the archive on name. Only the route root dataset holds
+// zsys user properties, children inherit them.
+// bootfsDatasets, if not empty, is the system state user datasets are linked to.
+func setArchiveDatasetProperties(t *zfs.Transaction, d archiveDataset, name, routeName, bootfsDatasets string) error {
+	if err := t.SetProperty(libzfs.CanmountProp, "noauto", name, true); err != nil {
+		return err
+	}
+	if name != routeName {
+		return nil
+	}
+
+	props := map[string]string{libzfs.LastBootedKernelProp: d.LastBootedKernel, libzfs.BootfsDatasetsProp: bootfsDatasets}
+	if d.BootFS {
+		props[libzfs.BootfsProp] = "yes"
+	}
+	if d.LastUsed != 0 {
+		props[libzfs.LastUsedProp] = strconv.Itoa(d.LastUsed)
+	}
+	for _, p := range []string{libzfs.BootfsProp, libzfs.LastUsedProp, libzfs.LastBootedKernelProp, libzfs.BootfsDatasetsProp} {
+		if props[p] == "" {
+			continue
+		}
+		if err := t.SetProperty(p, props[p], name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readArchiveManifest reads and checks the manifest heading tr.
+func readArchiveManifest(tr *tar.Reader) (manifest archiveManifest, err error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return manifest, fmt.Errorf(i18n.G("couldn't read archive: %v"), err)
+	}
+	if hdr.Name != archiveManifestName {
+		return manifest, fmt.Errorf(i18n.G("invalid archive: expected %s as first entry, got %s"), archiveManifestName, hdr.Name)
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf(i18n.G("invalid archive manifest: %v"), err)
+	}
+	if manifest.Version != archiveVersion {
+		return manifest, fmt.Errorf(i18n.G("unsupported archive version %d"), manifest.Version)
+	}
+	if len(manifest.Datasets) == 0 {
+		return manifest, errors.New(i18n.G("invalid archive manifest: no system dataset"))
+	}
+	if manifest.root().Name == "" {
+		return manifest, fmt.Errorf(i18n.G("invalid archive manifest: no dataset for %s"), manifest.ID)
+	}
+	return manifest, nil
+}
+
+// sortedArchiveRoutes returns the routes of an archive, sorted.
+func sortedArchiveRoutes(routes map[string]string) (names []string) {
+	for n := range routes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// allDatasets returns all system and user datasets of the manifest, in archive order.
+func (m archiveManifest) allDatasets() []archiveDataset {
+	all := append([]archiveDataset(nil), m.Datasets...)
+	for _, u := range sortedArchiveUsers(m.Users) {
+		all = append(all, m.Users[u]...)
+	}
+	return all
+}
+
+// snapshotsPerDataset returns the snapshots of the saved state s, with its linked user datasets, per dataset name.
+func (s State) snapshotsPerDataset() map[string]string {
+	r := make(map[string]string)
+	for _, d := range append(s.getDatasets(), s.getUsersDatasets()...) {
+		base, _ := splitSnapshotName(d.Name)
+		r[base] = d.Name
+	}
+	return r
+}
+
+// root returns the root dataset of the exported system state.
+func (m archiveManifest) root() archiveDataset {
+	for _, d := range m.Datasets {
+		if d.Name == m.ID {
+			return d
+		}
+	}
+	return archiveDataset{}
+}
+
+// renameWithID returns name with the suffix after its last "_" replaced by id.
+func renameWithID(name, id string) string {
+	dir, base := filepath.Dir(name), filepath.Base(name)
+	if i := strings.LastIndex(base, "_"); i >= 0 {
+		base = base[:i]
+	}
+	return filepath.Join(dir, base+"_"+id)
+}
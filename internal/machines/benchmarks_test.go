@@ -0,0 +1,179 @@
+package machines_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/machines"
+	"github.com/ubuntu/zsys/internal/testutils"
+	"github.com/ubuntu/zsys/internal/zfs"
+)
+
+// largeLayoutDatasets are the filesystem datasets of the machine generated by writeLargeLayout, and their properties.
+var largeLayoutDatasets = []struct{ name, props string }{
+	{"rpool/ROOT", "canmount: off"},
+	{"rpool/ROOT/ubuntu_1234", "zsys_bootfs: yes\n      last_used: 2019-04-18T02:45:55+00:00\n      mountpoint: /"},
+	{"rpool/ROOT/ubuntu_1234/var", ""},
+	{"rpool/ROOT/ubuntu_1234/var/lib", ""},
+	{"rpool/ROOT/ubuntu_1234/var/log", ""},
+	{"rpool/ROOT/ubuntu_1234/srv", ""},
+	{"rpool/USERDATA", "canmount: off"},
+	{"rpool/USERDATA/user1_abcd", "mountpoint: /home/user1\n      last_used: 2018-12-10T12:20:44+00:00\n      bootfs_datasets: rpool/ROOT/ubuntu_1234"},
+	{"rpool/USERDATA/user1_abcd/tools", ""},
+	{"rpool/USERDATA/root_bcde", "mountpoint: /root\n      last_used: 2018-08-03T21:55:33+00:00\n      bootfs_datasets: rpool/ROOT/ubuntu_1234"},
+}
+
+// writeLargeLayout writes in dir a pool definition of one machine with users, where each dataset has states
+// snapshots, and returns its path.
+func writeLargeLayout(b *testing.B, dir string, states int) string {
+	b.Helper()
+
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var def strings.Builder
+	def.WriteString("pools:\n  - name: rpool\n    datasets:\n")
+	for _, d := range largeLayoutDatasets {
+		fmt.Fprintf(&def, "    - name: %s\n", strings.TrimPrefix(d.name, "rpool/"))
+		if d.props != "" {
+			fmt.Fprintf(&def, "      %s\n", d.props)
+		}
+		if !strings.Contains(d.name, "_") {
+			continue
+		}
+		def.WriteString("      snapshots:\n")
+		for i := 0; i < states; i++ {
+			fmt.Fprintf(&def, "      - name: autozsys_%05d\n", i)
+			if d.name == "rpool/ROOT/ubuntu_1234" {
+				def.WriteString("        mountpoint: /:local\n        zsys_bootfs: yes:local\n        canmount: on:local\n")
+			}
+			fmt.Fprintf(&def, "        creation_time: %s\n", start.Add(time.Duration(i)*time.Hour).Format(time.RFC3339))
+		}
+	}
+
+	p := filepath.Join(dir, "large_layout.yaml")
+	if err := ioutil.WriteFile(p, []byte(def.String()), 0600); err != nil {
+		b.Fatalf("couldn't write pool definition: %v", err)
+	}
+	return p
+}
+
+// newLargeMachines returns machines of a generated layout with around datasets datasets.
+func newLargeMachines(b *testing.B, datasets int) (machines.Machines, func()) {
+	b.Helper()
+
+	dir, cleanup := testutils.TempDir(b)
+	states := datasets / len(largeLayoutDatasets)
+	libzfs := testutils.GetMockZFS(b)
+	fPools := testutils.NewFakePools(b, writeLargeLayout(b, dir, states), testutils.WithLibZFS(libzfs))
+	cleanupPools := fPools.Create(dir)
+
+	ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs), machines.WithConfig(config.DefaultPath))
+	if err != nil {
+		b.Fatalf("expected success but got an error scanning for machines: %v", err)
+	}
+	return ms, func() {
+		cleanupPools()
+		cleanup()
+	}
+}
+
+func BenchmarkRefresh(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d datasets", n), func(b *testing.B) {
+			ms, cleanup := newLargeMachines(b, n)
+			defer cleanup()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := ms.Refresh(context.Background()); err != nil {
+					b.Fatalf("expected no error but got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCreateSystemSnapshot(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d datasets", n), func(b *testing.B) {
+			ms, cleanup := newLargeMachines(b, n)
+			defer cleanup()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ms.CreateSystemSnapshot(context.Background(), fmt.Sprintf("bench_%d", i), machines.StateMetadata{}); err != nil {
+					b.Fatalf("expected no error but got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRemoveState(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d datasets", n), func(b *testing.B) {
+			ms, cleanup := newLargeMachines(b, n)
+			defer cleanup()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				id, err := ms.CreateSystemSnapshot(context.Background(), fmt.Sprintf("bench_%d", i), machines.StateMetadata{})
+				if err != nil {
+					b.Fatalf("expected no error but got: %v", err)
+				}
+				b.StartTimer()
+
+				if err := ms.RemoveState(context.Background(), id, "", true, false); err != nil {
+					b.Fatalf("expected no error but got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkApplyChanges(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		for _, bench := range []struct {
+			name  string
+			apply func(*machines.Machines, context.Context) error
+		}{
+			{"full refresh", (*machines.Machines).Refresh},
+			{"incremental", (*machines.Machines).ApplyChanges},
+		} {
+			apply := bench.apply
+			b.Run(fmt.Sprintf("%s, %d datasets", bench.name, n), func(b *testing.B) {
+				ms, cleanup := newLargeMachines(b, n)
+				defer cleanup()
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					// Same change set for both: a recursive snapshot of the system datasets.
+					snapshotRecursive(b, ms.Z(), fmt.Sprintf("bench_%d", i), "rpool/ROOT/ubuntu_1234")
+					b.StartTimer()
+
+					if err := apply(&ms, context.Background()); err != nil {
+						b.Fatalf("expected no error but got: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// snapshotRecursive creates the recursive snapshot name of dataset directly on z.
+func snapshotRecursive(b *testing.B, z *zfs.Zfs, name, dataset string) {
+	b.Helper()
+
+	t, _ := z.NewTransaction(context.Background())
+	defer t.Done()
+	if err := t.Snapshot(name, dataset, true); err != nil {
+		b.Fatalf("couldn't snapshot %q: %v", dataset, err)
+	}
+}
@@ -2,6 +2,7 @@ package machines
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -20,25 +21,33 @@ import (
 // were changed).
 // We ensure that we don't modify any existing tags (those will be done in commit()) so that failing boots didn't modify
 // the system, apart for canmount auto/on which are consolidated unconditionally on each boot anyway.
+// Boots on a trial state are counted, and the trial fails once all trial boots were used.
+// A boot target recorded with BootNext and honoured by the initramfs replaces the kernel command line root dataset.
 // Note that a rescan if performed if any modifications change the dataset layout. However, until ".Commit()" is called,
 // machine.current will return the correct machine, but the main dataset switch won't be done. This allows us here and
 // in .Commit()
 // Return if any dataset / machine changed has been done during boot and an error if any encountered.
 // TODO: propagate error to user graphically
-func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
+func (ms *Machines) EnsureBoot(ctx context.Context) (hasChanges bool, err error) {
 	if !ms.current.isZsys() {
 		log.Info(ctx, i18n.G("Current machine isn't Zsys, nothing to do on boot"))
 		return false, nil
 	}
 
 	t, cancel := ms.z.NewTransaction(ctx)
-	defer t.Done()
+	defer func() {
+		t.Done()
+		// Users of the booted state may have been changed before failing: reload machines from reverted datasets.
+		if err != nil {
+			ms.refresh(ctx)
+		}
+	}()
 
-	root, revertUserData := bootParametersFromCmdline(ms.cmdline)
+	root, revertUserData := ms.bootParameters()
 	m, bootedState := ms.findFromRoot(root)
 	log.Infof(ctx, i18n.G("Ensure boot on %q"), root)
 
-	bootedOnSnapshot := hasBootedOnSnapshot(ms.cmdline)
+	bootedOnSnapshot := hasBootedOnSnapshot(root)
 	// We are creating new clones (bootfs and optionnally, userdata) if wasn't promoted already
 	if bootedOnSnapshot && ms.current.ID != bootedState.ID {
 		log.Infof(ctx, i18n.G("Booting on snapshot: %q cloned to %q\n"), root, bootedState.ID)
@@ -52,7 +61,8 @@ func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
 			return false, err
 		}
 
-		if err := ms.Refresh(ctx); err != nil {
+		if err := ms.applyChanges(ctx); err != nil {
+			cancel()
 			return false, err
 		}
 		m, bootedState = ms.findFromRoot(root)
@@ -62,8 +72,16 @@ func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
 	// It's a no-op if the active state was the main one already.
 	// In case of system revert (either from cloning or rebooting this older dataset without user data revert), the newly
 	// active state won't be the main one, and so, we only take its main state userdata.
+	// Encrypted user data which couldn't be reverted while booting on a snapshot are kept too.
 	if !revertUserData {
 		bootedState.Users = m.Users
+	} else if bootedOnSnapshot {
+		bootedState.keepEncryptedUsers(ctx, m.History[root], m.Users)
+	}
+
+	if err := ms.countTrialBoot(ctx, t, bootedState); err != nil {
+		cancel()
+		return false, err
 	}
 
 	// Start switching every non desired system and user datasets to noauto
@@ -75,7 +93,7 @@ func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
 	userDatasets := bootedState.getUsersDatasets()
 
 	noAutoDatasets = append(noAutoDatasets, diffDatasets(ms.allUsersDatasets, userDatasets)...)
-	hasChanges, err := switchDatasetsCanMount(t, noAutoDatasets, "noauto")
+	hasChanges, err = switchDatasetsCanMount(t, noAutoDatasets, "noauto")
 	if err != nil {
 		cancel()
 		return false, err
@@ -91,7 +109,7 @@ func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
 
 	if ok || hasChanges {
 		hasChanges = true
-		if err := ms.Refresh(ctx); err != nil {
+		if err := ms.applyChanges(ctx); err != nil {
 			return false, err
 		}
 	}
@@ -102,20 +120,36 @@ func (ms *Machines) EnsureBoot(ctx context.Context) (bool, error) {
 // Commit current state to be the active one by promoting its datasets if needed, set last used,
 // associate user datasets to it and rebuilding grub menu.
 // After this operation, every New() call will get the current and correct system state.
+// A trial boot is only committed once it passes health checks, otherwise an ErrTrialFailed is returned.
 // Return if any dataset / machine changed has been done during boot commit and an error if any encountered.
-func (ms *Machines) Commit(ctx context.Context) (bool, error) {
+func (ms *Machines) Commit(ctx context.Context) (changed bool, err error) {
 	if !ms.current.isZsys() {
 		log.Info(ctx, i18n.G("Current machine isn't Zsys, nothing to commit on boot"))
 		return false, nil
 	}
 
 	t, cancel := ms.z.NewTransaction(ctx)
-	defer t.Done()
+	defer func() {
+		t.Done()
+		// Users of the booted state may have been changed before failing: reload machines from reverted datasets.
+		if err != nil {
+			ms.refresh(ctx)
+		}
+	}()
 
-	root, revertUserData := bootParametersFromCmdline(ms.cmdline)
+	root, revertUserData := ms.bootParameters()
 	m, bootedState := ms.findFromRoot(root)
 	log.Infof(ctx, i18n.G("Committing boot for %q"), root)
 
+	// A failed trial is recorded, but the boot isn't committed.
+	if err := ms.checkTrial(ctx, t, bootedState); err != nil {
+		var e *ErrTrialFailed
+		if !errors.As(err, &e) {
+			cancel()
+		}
+		return false, err
+	}
+
 	// Get user datasets. As we didn't tag the user datasets and promote the system one, the machines doesn't correspond
 	// to the reality.
 
@@ -123,8 +157,11 @@ func (ms *Machines) Commit(ctx context.Context) (bool, error) {
 	// It's a no-op if the active state was the main one already.
 	// In case of system revert (either from cloning or rebooting this older dataset without user data revert), the newly
 	// active state won't be the main one, and so, we only take its main state userdata.
+	// Encrypted user data which couldn't be reverted while booting on a snapshot are kept too.
 	if !revertUserData {
 		bootedState.Users = m.Users
+	} else if hasBootedOnSnapshot(root) {
+		bootedState.keepEncryptedUsers(ctx, m.History[root], m.Users)
 	}
 
 	// Retag new userdatasets if needed
@@ -150,8 +187,6 @@ func (ms *Machines) Commit(ctx context.Context) (bool, error) {
 		}
 	}
 
-	var changed bool
-
 	kernel := kernelFromCmdline(ms.cmdline)
 	log.Infof(ctx, i18n.G("Set latest booted kernel to %q\n"), kernel)
 	if systemDatasets[0].LastBootedKernel != kernel {
@@ -180,7 +215,7 @@ func (ms *Machines) Commit(ctx context.Context) (bool, error) {
 	}
 	changed = changed || chg
 
-	if err := ms.Refresh(ctx); err != nil {
+	if err := ms.applyChanges(ctx); err != nil {
 		return false, err
 	}
 
@@ -269,6 +304,11 @@ func (snapshot State) createClones(t *zfs.Transaction, bootedStateID string, nee
 	// Only root datasets are cloned
 	userDataSuffix := t.Zfs.GenerateID(6)
 	for _, us := range snapshot.Users {
+		// Keys of encrypted user datasets are only loaded on login: current user data are kept.
+		if us.isLocked() {
+			log.Warningf(t.Context(), i18n.G("Couldn't revert %q: its encryption key isn't loaded. Restore it once logged in"), us.ID)
+			continue
+		}
 		// Recursively clones childrens, which shouldn't have bootfs elements.
 		if err := t.Clone(us.ID, userDataSuffix, false, true); err != nil {
 			return fmt.Errorf(i18n.G("couldn't create new user datasets from %q: %v"), snapshot.ID, err)
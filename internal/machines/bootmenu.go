@@ -0,0 +1,157 @@
+package machines
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ubuntu/zsys/internal/bootmenu"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+)
+
+// UpdateBootMenu regenerates the boot menu with the backend selected by the configuration.
+func (ms *Machines) UpdateBootMenu(ctx context.Context) error {
+	b, err := bootmenu.New(ms.conf.Boot)
+	if err != nil {
+		return err
+	}
+
+	entries := ms.bootEntries()
+	if _, ok := b.(bootmenu.KernelsReader); ok {
+		unmount := ms.mountKernels(ctx, entries)
+		defer unmount()
+	}
+	return b.Update(ctx, entries)
+}
+
+// mountKernels sets the directory where the kernels of each saved state can be read. The kernels of mounted datasets
+// are read in place, through the .zfs directory for snapshots. Other datasets are mounted in a temporary directory
+// until the returned function is called.
+// The kernel directory isn't set on states which datasets can't be mounted.
+func (ms *Machines) mountKernels(ctx context.Context, entries []bootmenu.Entry) (unmount func()) {
+	datasets := make(map[string]*zfs.Dataset)
+	for _, d := range ms.allSystemDatasets {
+		datasets[d.Name] = d
+	}
+
+	var tmpDirs []string
+	unmount = func() {
+		for _, dir := range tmpDirs {
+			if err := ms.mount.Unmount(dir); err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't unmount %s: %v"), dir, err)
+				continue
+			}
+			if err := os.Remove(dir); err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't remove %s: %v"), dir, err)
+			}
+		}
+	}
+
+	for i, e := range entries {
+		if !e.History || e.Kernel == "" {
+			continue
+		}
+
+		base, snapshot := splitSnapshotName(e.Boot)
+		var dir string
+		if d, ok := datasets[base]; ok && d.Mounted && d.Mountpoint != "" {
+			dir = d.Mountpoint
+			if snapshot != "" {
+				dir = filepath.Join(dir, ".zfs", "snapshot", snapshot)
+			}
+		} else {
+			tmp, err := os.MkdirTemp("", "zsys-kernels-")
+			if err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't create mountpoint for kernels of %s: %v"), e.ID, err)
+				continue
+			}
+			// Snapshots are mounted read only.
+			if err := ms.mount.Mount(e.Boot, tmp); err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't mount %s to read its kernels: %v"), e.Boot, err)
+				os.Remove(tmp)
+				continue
+			}
+			tmpDirs = append(tmpDirs, tmp)
+			dir = tmp
+		}
+
+		if e.BootOnRoot {
+			dir = filepath.Join(dir, "boot")
+		}
+		entries[i].KernelDir = dir
+	}
+
+	return unmount
+}
+
+// bootEntries returns the current and saved states of every zsys machine, sorted by machine and from the most
+// recent state.
+func (ms *Machines) bootEntries() (entries []bootmenu.Entry) {
+	var ids []string
+	for id, m := range ms.all {
+		if !m.isZsys() {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		m := ms.all[id]
+		entries = append(entries, m.State.bootEntry(m.ID, false))
+
+		// The fallback of a failed trial boot is booted by default until a state is committed.
+		var fallback string
+		if tr, ok := m.State.trial(); ok && tr.failed {
+			fallback = tr.fallback
+		}
+
+		var history sortedReverseByTimeStates
+		for _, s := range m.History {
+			history = append(history, s)
+		}
+		sort.Sort(history)
+		for _, s := range history {
+			e := s.bootEntry(m.ID, true)
+			e.Default = s.ID == fallback
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// bootEntry returns the boot menu entry of the state s of machine.
+func (s State) bootEntry(machine string, history bool) bootmenu.Entry {
+	e := bootmenu.Entry{
+		ID:          s.ID,
+		Machine:     machine,
+		LastUsed:    s.LastUsed,
+		History:     history,
+		Boot:        s.ID,
+		BootOnRoot:  true,
+		Description: s.description(),
+	}
+	if ds, ok := s.Datasets[s.ID]; ok && len(ds) > 0 {
+		e.Kernel = ds[0].LastBootedKernel
+	}
+
+	for _, ds := range s.Datasets {
+		for _, d := range ds {
+			if d.Mountpoint == "/boot" {
+				e.Boot = d.Name
+				e.BootOnRoot = false
+			}
+		}
+	}
+
+	for user := range s.Users {
+		e.Users = append(e.Users, user)
+	}
+	sort.Strings(e.Users)
+
+	return e
+}
@@ -0,0 +1,147 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+const (
+	// bootNextSeparator separates the boot mode from the target state in the boot next user property.
+	bootNextSeparator = ":"
+	// bootNextSystem only boots on the target system state, keeping current user data.
+	bootNextSystem = "system"
+	// bootNextUserdata boots on the target system state and reverts user data to it.
+	bootNextUserdata = "userdata"
+	// bootNextBooting prefixes the boot mode once the initramfs redirected the boot to the target state.
+	// The initramfs clears it on the following boot, so that the target is only booted once.
+	bootNextBooting = "booting-"
+)
+
+// bootNext is the state to boot on once, stored on the root dataset of the state the bootloader boots by default.
+type bootNext struct {
+	// state is the target system state.
+	state string
+	// revertUserData is set when user data should be reverted to the target state too.
+	revertUserData bool
+	// booting is set by the initramfs on the boot it redirected to the target state.
+	booting bool
+}
+
+// parseBootNext decodes the boot next user property. It returns false if no boot target is recorded.
+func parseBootNext(v string) (bootNext, bool) {
+	elems := strings.SplitN(v, bootNextSeparator, 2)
+	if len(elems) != 2 || elems[1] == "" {
+		return bootNext{}, false
+	}
+
+	bn := bootNext{state: elems[1]}
+	mode := strings.TrimPrefix(elems[0], bootNextBooting)
+	bn.booting = mode != elems[0]
+	switch mode {
+	case bootNextSystem:
+	case bootNextUserdata:
+		bn.revertUserData = true
+	default:
+		return bootNext{}, false
+	}
+	return bn, true
+}
+
+// String encodes the boot target for its user property.
+func (bn bootNext) String() string {
+	mode := bootNextSystem
+	if bn.revertUserData {
+		mode = bootNextUserdata
+	}
+	if bn.booting {
+		mode = bootNextBooting + mode
+	}
+	return mode + bootNextSeparator + bn.state
+}
+
+// bootNext returns the boot target recorded on the root dataset of a filesystem state, if any.
+func (s State) bootNext() (bootNext, bool) {
+	if s.isSnapshot() {
+		return bootNext{}, false
+	}
+	ds, ok := s.Datasets[s.ID]
+	if !ok || len(ds) == 0 {
+		return bootNext{}, false
+	}
+	return parseBootNext(ds[0].BootNext)
+}
+
+// BootNext records name as the system state the current machine boots on, once, on its next boot.
+// User data are reverted to the target state if revertUserData is set. The bootloader menu isn't modified: the
+// initramfs redirects the boot of the current state to the target one.
+// Setting the current state as target cancels any recorded boot target.
+func (ms *Machines) BootNext(ctx context.Context, name string, revertUserData bool) error {
+	if !ms.current.isZsys() {
+		return errors.New(i18n.G("Current machine isn't Zsys, can't select the next boot"))
+	}
+
+	s, err := ms.IDToState(ctx, name, "")
+	if err != nil {
+		return err
+	}
+
+	var v string
+	switch {
+	case s.ID == ms.current.ID:
+		if _, ok := ms.current.State.bootNext(); !ok {
+			log.Infof(ctx, i18n.G("%s is already booted by default"), s.ID)
+			return nil
+		}
+		log.Infof(ctx, i18n.G("Cancelling next boot target, %s is booted by default"), s.ID)
+	case ms.current.History[s.ID] != nil:
+		bn := bootNext{state: s.ID, revertUserData: revertUserData}
+		if revertUserData && len(s.Users) == 0 {
+			log.Warningf(ctx, i18n.G("%s has no user state, only the system will be reverted"), s.ID)
+		}
+		if revertUserData {
+			log.Infof(ctx, i18n.G("Next boot will be on %s, reverting user data"), s.ID)
+		} else {
+			log.Infof(ctx, i18n.G("Next boot will be on %s"), s.ID)
+		}
+		v = bn.String()
+	default:
+		return fmt.Errorf(i18n.G("%s isn't a system state of the current machine"), s.ID)
+	}
+
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+	if err := t.SetProperty(libzfs.BootNextProp, v, ms.current.ID, true); err != nil {
+		cancel()
+		return fmt.Errorf(i18n.G("couldn't set boot next property on %q: ")+config.ErrorFormat, ms.current.ID, err)
+	}
+
+	return ms.applyChanges(ctx)
+}
+
+// bootParameters returns the root dataset and if user data are reverted for this boot.
+// When the initramfs redirected the boot to a recorded target, it replaces the kernel command line ones.
+func (ms *Machines) bootParameters() (root string, revertUserData bool) {
+	root, revertUserData = bootParametersFromCmdline(ms.cmdline)
+
+	for _, m := range ms.all {
+		s := &m.State
+		if m.ID != root {
+			if s = m.History[root]; s == nil {
+				continue
+			}
+		}
+		if bn, ok := s.bootNext(); ok && bn.booting {
+			return bn.state, bn.revertUserData
+		}
+		break
+	}
+
+	return root, revertUserData
+}
@@ -0,0 +1,540 @@
+package machines
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// inPlaceProperties are the properties which don’t change the machine or state any dataset belongs to.
+var inPlaceProperties = map[string]bool{
+	libzfs.LastUsedProp:         true,
+	libzfs.LastBootedKernelProp: true,
+	libzfs.PackageChangesProp:   true,
+	libzfs.DescriptionProp:      true,
+	libzfs.TagsProp:             true,
+	libzfs.PinnedProp:           true,
+	libzfs.TrialProp:            true,
+	libzfs.BootNextProp:         true,
+	zfs.KeyStatusProp:           true,
+	zfs.KeyLocationProp:         true,
+	zfs.UsedProp:                true,
+	zfs.WrittenProp:             true,
+	zfs.ReferencedProp:          true,
+	zfs.LogicalUsedProp:         true,
+}
+
+// applyChanges updates the machines with the datasets changed by our transactions since last update.
+// New or removed snapshots states, new user datasets and non structural properties changes are applied to the
+// existing machines and states. Any other change rescans the datasets and reloads the list of machines.
+func (ms *Machines) applyChanges(ctx context.Context) error {
+	c := ms.z.TakeChanges()
+	if c.IsEmpty() {
+		return nil
+	}
+
+	var apply func()
+	switch {
+	case c.Everything || !onlyInPlaceProperties(c.Modified) || (len(c.Created) > 0 && len(c.Destroyed) > 0):
+	case len(c.Created) > 0:
+		apply = ms.planAddDatasets(ctx, c.Created)
+	case len(c.Destroyed) > 0:
+		apply = ms.planRemoveDatasets(c.Destroyed)
+	default:
+		apply = func() {}
+	}
+
+	if apply == nil {
+		log.Debug(ctx, i18n.G("Changes can't be applied to current machines, reloading them"))
+		return ms.Refresh(ctx)
+	}
+
+	apply()
+	ms.updateLastUsed(c.Modified)
+	log.Debugf(ctx, i18n.G("Changes applied to current machines: %d created, %d destroyed and %d modified datasets"),
+		len(c.Created), len(c.Destroyed), len(c.Modified))
+	return nil
+}
+
+// onlyInPlaceProperties returns if all modified properties can be updated on existing states.
+func onlyInPlaceProperties(modified map[string]map[string]bool) bool {
+	for _, props := range modified {
+		for p := range props {
+			if !inPlaceProperties[p] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// updateLastUsed refreshes the last used time of every state which root dataset had its last used property modified.
+func (ms *Machines) updateLastUsed(modified map[string]map[string]bool) {
+	update := func(s *State) {
+		if !modified[s.ID][libzfs.LastUsedProp] || len(s.Datasets[s.ID]) == 0 {
+			return
+		}
+		s.LastUsed = time.Time{}
+		// We don't want lastused to be 1970 in our golden files
+		if d := s.Datasets[s.ID][0]; d.LastUsed != 0 {
+			s.LastUsed = time.Unix(int64(d.LastUsed), 0)
+		}
+	}
+
+	for _, m := range ms.all {
+		update(&m.State)
+		for _, h := range m.History {
+			update(h)
+		}
+		for _, us := range m.AllUsersStates {
+			for _, s := range us {
+				update(s)
+			}
+		}
+	}
+}
+
+// datasetsByName returns all non replicated datasets, indexed by their names.
+func (ms *Machines) datasetsByName() map[string]*zfs.Dataset {
+	r := make(map[string]*zfs.Dataset)
+	for _, d := range ms.z.Datasets() {
+		if ms.isReplicatedDataset(d.Name) {
+			continue
+		}
+		r[d.Name] = d
+	}
+	return r
+}
+
+// userAttachment is a user dataset with its children to attach to a machine, and to the system state s if any.
+type userAttachment struct {
+	m        *Machine
+	s        *State
+	r        *zfs.Dataset
+	children []*zfs.Dataset
+}
+
+// planAddDatasets returns the function attaching the new datasets names to machines, or nil if it can’t be done
+// without reloading them.
+func (ms *Machines) planAddDatasets(ctx context.Context, names map[string]bool) func() {
+	all := ms.datasetsByName()
+	var created []*zfs.Dataset
+	var withSnapshots, withFilesystems bool
+	for n := range names {
+		d, ok := all[n]
+		if !ok {
+			if ms.isReplicatedDataset(n) {
+				continue
+			}
+			return nil
+		}
+		created = append(created, d)
+		if d.IsSnapshot {
+			withSnapshots = true
+		} else {
+			withFilesystems = true
+		}
+	}
+	sort.Sort(sortedDataset(created))
+
+	switch {
+	case len(created) == 0:
+		return func() {}
+	case withSnapshots && withFilesystems:
+		return nil
+	case withSnapshots:
+		return ms.planAddSnapshots(ctx, all, created)
+	default:
+		return ms.planAddUserDatasets(ctx, created)
+	}
+}
+
+// planAddSnapshots returns the function attaching new snapshots sharing the same snapshot name as new states, or nil
+// if it can’t be done without reloading the machines.
+func (ms *Machines) planAddSnapshots(ctx context.Context, all map[string]*zfs.Dataset, created []*zfs.Dataset) func() {
+	_, snapshot := splitSnapshotName(created[0].Name)
+	isNew := make(map[string]bool)
+	for _, d := range created {
+		if _, s := splitSnapshotName(d.Name); s != snapshot {
+			return nil
+		}
+		isNew[d.Name] = true
+	}
+	// States are associated by snapshot name: no existing state should be impacted.
+	for n := range all {
+		if !isNew[n] && strings.HasSuffix(n, "@"+snapshot) {
+			return nil
+		}
+	}
+
+	// Sort new datasets the same way than populate does.
+	histories := make(map[*State]*Machine)
+	var boots, userdatas, unmanaged []*zfs.Dataset
+nextDataset:
+	for _, d := range created {
+		base, _ := splitSnapshotName(d.Name)
+		if m, ok := ms.all[base]; ok && d.Mountpoint == "/" && d.CanMount != "off" {
+			histories[newHistoryState(d)] = m
+			continue
+		}
+		for h := range histories {
+			if ok, _ := isChild(h.ID, *d); ok {
+				h.Datasets[h.ID] = append(h.Datasets[h.ID], d)
+				continue nextDataset
+			}
+		}
+
+		switch {
+		case isBootDataset(d):
+			boots = append(boots, d)
+		case isUserDataset(d.Name):
+			userdatas = append(userdatas, d)
+		default:
+			unmanaged = append(unmanaged, d)
+		}
+	}
+	for h := range histories {
+		h.attachRemainingDatasetsForHistory(boots)
+	}
+
+	// Associate user snapshots to new system states or to existing user states.
+	var attachments []userAttachment
+	var userDatasets []*zfs.Dataset
+	usersOnState := make(map[*State]map[string]bool)
+	roots := getRootDatasets(ctx, userdatas)
+	for _, r := range sortedRootDatasets(roots) {
+		children := roots[r]
+		base, _ := splitSnapshotName(r.Name)
+		user := userFromDatasetName(r.Name)
+
+		var associated bool
+		for h, m := range histories {
+			// The user state origin has to be already attached to this machine.
+			if o, ok := all[base]; !ok || o.Origin != "" || !m.hasUserState(user, base) {
+				return nil
+			}
+			// Multiple user datasets for the same user would compete on this state.
+			if usersOnState[h] == nil {
+				usersOnState[h] = make(map[string]bool)
+			}
+			if usersOnState[h][user] {
+				return nil
+			}
+			usersOnState[h][user] = true
+			attachments = append(attachments, userAttachment{m: m, s: h, r: r, children: children})
+			associated = true
+		}
+		// This is a user only snapshot.
+		if len(histories) == 0 {
+			for _, m := range ms.all {
+				if m.hasUserState(user, base) {
+					attachments = append(attachments, userAttachment{m: m, r: r, children: children})
+					associated = true
+				}
+			}
+		}
+
+		if !associated {
+			log.Infof(ctx, i18n.G("Couldn't find any association for user dataset %s"), r.Name)
+			unmanaged = append(unmanaged, r)
+			unmanaged = append(unmanaged, children...)
+			continue
+		}
+		userDatasets = append(userDatasets, r)
+		userDatasets = append(userDatasets, children...)
+	}
+
+	return func() {
+		for _, h := range sortedStates(histories) {
+			histories[h].History[h.ID] = h
+			for _, id := range sortedDatasetNames(h.Datasets) {
+				ms.allSystemDatasets = append(ms.allSystemDatasets, h.Datasets[id]...)
+			}
+		}
+		// Append unlinked boot datasets to ensure we will switch to noauto everything
+		ms.allSystemDatasets = appendDatasetIfNotPresent(ms.allSystemDatasets, boots, true)
+
+		for _, a := range attachments {
+			user, us := a.m.addUserState(ctx, idOrEmpty(a.s), a.r, a.children)
+			if a.s != nil {
+				a.s.Users[user] = us
+			}
+		}
+		ms.allUsersDatasets = append(ms.allUsersDatasets, userDatasets...)
+		ms.unmanagedDatasets = append(ms.unmanagedDatasets, unmanaged...)
+	}
+}
+
+// planAddUserDatasets returns the function attaching new user filesystem datasets to the system states listed in
+// their bootfs datasets, or nil if it can’t be done without reloading the machines.
+func (ms *Machines) planAddUserDatasets(ctx context.Context, created []*zfs.Dataset) func() {
+	isNew := make(map[string]bool)
+	for _, d := range created {
+		isNew[d.Name] = true
+	}
+
+	for _, d := range created {
+		// Only handle new main user datasets, which are not children of existing ones.
+		if !isUserDataset(d.Name) || isBootDataset(d) || d.Origin != "" || d.Mountpoint == "/" {
+			return nil
+		}
+		if parent := filepath.Dir(d.Name); isUserDataset(parent) && !isNew[parent] {
+			return nil
+		}
+		for s := range ms.getAllStatesOnMachines() {
+			if ok, _ := isChild(s.ID, *d); ok {
+				return nil
+			}
+		}
+	}
+
+	var attachments []userAttachment
+	var userDatasets, unmanaged []*zfs.Dataset
+	usersOnState := make(map[*State]map[string]bool)
+	roots := getRootDatasets(ctx, created)
+	for _, r := range sortedRootDatasets(roots) {
+		children := roots[r]
+		user := userFromDatasetName(r.Name)
+
+		var associated bool
+		for s, m := range ms.getAllStatesOnMachines() {
+			if !nameInBootfsDatasets(s.ID, *r) {
+				continue
+			}
+			// Multiple user datasets for the same user would compete on this state.
+			if _, exists := s.Users[user]; exists || usersOnState[s][user] {
+				return nil
+			}
+			if usersOnState[s] == nil {
+				usersOnState[s] = make(map[string]bool)
+			}
+			usersOnState[s][user] = true
+
+			var associatedChildren []*zfs.Dataset
+			for _, d := range children {
+				if !nameInBootfsDatasets(s.ID, *d) {
+					continue
+				}
+				associatedChildren = append(associatedChildren, d)
+			}
+			attachments = append(attachments, userAttachment{m: m, s: s, r: r, children: associatedChildren})
+			associated = true
+		}
+
+		if !associated {
+			log.Infof(ctx, i18n.G("Couldn't find any association for user dataset %s"), r.Name)
+			unmanaged = append(unmanaged, r)
+			unmanaged = append(unmanaged, children...)
+			continue
+		}
+		userDatasets = append(userDatasets, r)
+		userDatasets = append(userDatasets, children...)
+	}
+
+	return func() {
+		for _, a := range attachments {
+			user, us := a.m.addUserState(ctx, a.s.ID, a.r, a.children)
+			a.s.Users[user] = us
+		}
+		ms.allUsersDatasets = append(ms.allUsersDatasets, userDatasets...)
+		ms.unmanagedDatasets = append(ms.unmanagedDatasets, unmanaged...)
+	}
+}
+
+// planRemoveDatasets returns the function detaching removed snapshots states from machines, or nil if it can’t be
+// done without reloading them.
+func (ms *Machines) planRemoveDatasets(names map[string]bool) func() {
+	snapshots := make(map[string]bool)
+	for n := range names {
+		_, snapshot := splitSnapshotName(n)
+		if snapshot == "" {
+			return nil
+		}
+		snapshots[snapshot] = true
+	}
+	// States are associated by snapshot name: only user snapshots of a removed system state can remain, as user
+	// only snapshots.
+	all := ms.datasetsByName()
+	remainingUserSnapshots := make(map[string]bool)
+	for n := range all {
+		if _, snapshot := splitSnapshotName(n); snapshot == "" || !snapshots[snapshot] {
+			continue
+		}
+		if !isUserDataset(n) {
+			return nil
+		}
+		remainingUserSnapshots[n] = true
+	}
+
+	isRemoved := func(s *State) bool { return names[s.ID] }
+	hasRemovedDatasets := func(s *State) bool {
+		for _, ds := range s.Datasets {
+			for _, d := range ds {
+				if names[d.Name] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	remainingRoots := make(map[string]bool)
+	for _, m := range ms.all {
+		remaining := []*State{&m.State}
+		for _, h := range m.History {
+			if !isRemoved(h) {
+				remaining = append(remaining, h)
+			}
+		}
+		for _, s := range remaining {
+			if hasRemovedDatasets(s) {
+				return nil
+			}
+		}
+		// The origin of a user snapshot has to be attached on its own to this machine, without the removed states.
+		hasLinkedOrigin := func(s *State) bool {
+			base, _ := splitSnapshotName(s.ID)
+			o, ok := all[base]
+			if !ok || o.Origin != "" {
+				return false
+			}
+			for _, rs := range remaining {
+				if nameInBootfsDatasets(rs.ID, *o) {
+					return true
+				}
+			}
+			return false
+		}
+
+		for _, us := range m.AllUsersStates {
+			for _, s := range us {
+				switch {
+				case isRemoved(s):
+					if !hasLinkedOrigin(s) {
+						return nil
+					}
+				case hasRemovedDatasets(s):
+					return nil
+				case remainingUserSnapshots[s.ID]:
+					if !hasLinkedOrigin(s) {
+						return nil
+					}
+					remainingRoots[s.ID] = true
+					for _, d := range s.getDatasets() {
+						delete(remainingUserSnapshots, d.Name)
+					}
+				}
+			}
+		}
+	}
+	if len(remainingUserSnapshots) > 0 {
+		return nil
+	}
+	// Remaining user snapshots are associated to every machine with their origin.
+	for _, m := range ms.all {
+		for r := range remainingRoots {
+			base, _ := splitSnapshotName(r)
+			user := userFromDatasetName(r)
+			if !m.hasUserState(user, r) && m.hasUserState(user, base) {
+				return nil
+			}
+		}
+	}
+
+	return func() {
+		for _, m := range ms.all {
+			for id, h := range m.History {
+				if isRemoved(h) {
+					delete(m.History, id)
+				}
+			}
+			for _, s := range append([]*State{&m.State}, stateValues(m.History)...) {
+				for user, us := range s.Users {
+					if isRemoved(us) {
+						delete(s.Users, user)
+					}
+				}
+			}
+			for user, us := range m.AllUsersStates {
+				for id, s := range us {
+					if isRemoved(s) {
+						delete(us, id)
+					}
+				}
+				if len(us) == 0 {
+					delete(m.AllUsersStates, user)
+				}
+			}
+		}
+
+		ms.allSystemDatasets = withoutDatasets(ms.allSystemDatasets, names)
+		ms.allUsersDatasets = withoutDatasets(ms.allUsersDatasets, names)
+		ms.unmanagedDatasets = withoutDatasets(ms.unmanagedDatasets, names)
+	}
+}
+
+// hasUserState returns if the machine has a state for user with the given ID.
+func (m *Machine) hasUserState(user, ID string) bool {
+	for _, s := range m.AllUsersStates[user] {
+		if s.ID == ID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedRootDatasets returns the root datasets sorted, for reproducibility.
+func sortedRootDatasets(rds map[*zfs.Dataset][]*zfs.Dataset) []*zfs.Dataset {
+	r := make(sortedDataset, 0, len(rds))
+	for d := range rds {
+		r = append(r, d)
+	}
+	sort.Sort(r)
+	return r
+}
+
+// sortedStates returns the states sorted by ID, for reproducibility.
+func sortedStates(states map[*State]*Machine) []*State {
+	r := make([]*State, 0, len(states))
+	for s := range states {
+		r = append(r, s)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].ID < r[j].ID })
+	return r
+}
+
+// stateValues returns the states of a state map.
+func stateValues(states map[string]*State) []*State {
+	r := make([]*State, 0, len(states))
+	for _, s := range states {
+		r = append(r, s)
+	}
+	return r
+}
+
+// idOrEmpty returns the ID of s, or an empty string if there is no state.
+func idOrEmpty(s *State) string {
+	if s == nil {
+		return ""
+	}
+	return s.ID
+}
+
+// withoutDatasets returns datasets without the ones which names are in names.
+func withoutDatasets(datasets []*zfs.Dataset, names map[string]bool) []*zfs.Dataset {
+	r := make([]*zfs.Dataset, 0, len(datasets))
+	for _, d := range datasets {
+		if names[d.Name] {
+			continue
+		}
+		r = append(r, d)
+	}
+	return r
+}
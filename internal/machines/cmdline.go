@@ -85,7 +85,6 @@ func (machines *Machines) findFromRoot(rootName string) (*Machine, *State) {
 	return nil, nil
 }
 
-func hasBootedOnSnapshot(cmdline string) bool {
-	root, _ := bootParametersFromCmdline(cmdline)
+func hasBootedOnSnapshot(root string) bool {
 	return strings.Contains(root, "@")
 }
@@ -0,0 +1,115 @@
+package machines
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// currentStateName is the state name used to designate the current machine state.
+const currentStateName = "current"
+
+// StateDiff returns all file changes between the saved system state from and to, a more recent state, grouped by
+// mountpoint. System and user datasets are compared per mountpoint.
+// If to is empty or "current", the current state of the machine is used.
+// If user is not empty, only the states of this user are compared.
+func (ms *Machines) StateDiff(ctx context.Context, from, to, user string) (string, error) {
+	sFrom, err := ms.IDToState(ctx, from, user)
+	if err != nil {
+		return "", err
+	}
+	if !sFrom.isSnapshot() {
+		return "", fmt.Errorf(i18n.G("%s isn't a saved state: only saved states can be compared to a more recent state"), sFrom.ID)
+	}
+
+	var sTo *State
+	if to == "" || to == currentStateName {
+		if !ms.current.isZsys() {
+			return "", errors.New(i18n.G("Current machine isn't Zsys, can't compare to current state"))
+		}
+		sTo = &ms.current.State
+		if user != "" {
+			if sTo = ms.current.Users[user]; sTo == nil {
+				return "", fmt.Errorf(i18n.G("no user state for %s attached to current state"), user)
+			}
+		}
+	} else if sTo, err = ms.IDToState(ctx, to, user); err != nil {
+		return "", err
+	}
+	if sFrom.ID == sTo.ID {
+		return "", fmt.Errorf(i18n.G("can't compare %s to itself"), sFrom.ID)
+	}
+
+	log.Infof(ctx, i18n.G("Comparing %s to %s"), sFrom.ID, sTo.ID)
+
+	fromDatasets := datasetsByMountpoint(sFrom)
+	toDatasets := datasetsByMountpoint(sTo)
+	var mountpoints []string
+	for mp := range fromDatasets {
+		mountpoints = append(mountpoints, mp)
+	}
+	for mp := range toDatasets {
+		if _, ok := fromDatasets[mp]; !ok {
+			mountpoints = append(mountpoints, mp)
+		}
+	}
+	sort.Strings(mountpoints)
+
+	var out bytes.Buffer
+	for _, mp := range mountpoints {
+		fromD, toD := fromDatasets[mp], toDatasets[mp]
+		switch {
+		case toD == nil:
+			fmt.Fprintf(&out, i18n.G("%s: removed (%s isn't in %s)\n"), mp, fromD.Name, sTo.ID)
+			continue
+		case fromD == nil:
+			fmt.Fprintf(&out, i18n.G("%s: added (%s isn't in %s)\n"), mp, toD.Name, sFrom.ID)
+			continue
+		}
+
+		entries, err := ms.z.Diff(ctx, fromD.Name, toD.Name)
+		if err != nil {
+			return "", err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+		fmt.Fprintf(&out, "%s:\n", mp)
+		for _, e := range entries {
+			if e.Change == libzfs.DiffRenamed {
+				fmt.Fprintf(&out, "  %s\t%s -> %s\n", e.Change, e.Path, e.NewPath)
+				continue
+			}
+			fmt.Fprintf(&out, "  %s\t%s\n", e.Change, e.Path)
+		}
+	}
+
+	if out.Len() == 0 {
+		return fmt.Sprintf(i18n.G("No changes between %s and %s\n"), sFrom.ID, sTo.ID), nil
+	}
+	return out.String(), nil
+}
+
+// datasetsByMountpoint returns all mountable system and user datasets of a state, per mountpoint.
+func datasetsByMountpoint(s *State) map[string]*zfs.Dataset {
+	r := make(map[string]*zfs.Dataset)
+	for _, d := range append(s.getDatasets(), s.getUsersDatasets()...) {
+		if d.CanMount == "off" || d.Mountpoint == "" || d.Mountpoint == "none" {
+			continue
+		}
+		if _, ok := r[d.Mountpoint]; ok {
+			continue
+		}
+		r[d.Mountpoint] = d
+	}
+	return r
+}
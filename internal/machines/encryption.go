@@ -0,0 +1,137 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+// keyUnavailable is the key status of encrypted datasets which key isn't loaded.
+const keyUnavailable = "unavailable"
+
+// isEncrypted returns if any dataset of this state is encrypted.
+func (s State) isEncrypted() bool {
+	for _, d := range s.getDatasets() {
+		if d.EncryptionRoot != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocked returns if the key of any encrypted dataset of this state isn't loaded.
+// User keys are loaded on login from the user password, after boot: those datasets can't be cloned on boot.
+func (s State) isLocked() bool {
+	for _, d := range s.getDatasets() {
+		if d.KeyStatus == keyUnavailable {
+			return true
+		}
+	}
+	return false
+}
+
+// keepEncryptedUsers attaches to s the current user states of users, for encrypted user states of reverted which
+// weren't cloned to s, as their keys weren't loaded on boot.
+// Those users can revert their user data once logged in.
+func (s *State) keepEncryptedUsers(ctx context.Context, reverted *State, users map[string]*State) {
+	if reverted == nil {
+		return
+	}
+
+	for user, us := range reverted.Users {
+		if _, ok := s.Users[user]; ok || !us.isEncrypted() {
+			continue
+		}
+		current, ok := users[user]
+		if !ok {
+			continue
+		}
+		log.Infof(ctx, i18n.G("Keeping current user data of encrypted user %q"), user)
+		if s.Users == nil {
+			s.Users = make(map[string]*State)
+		}
+		s.Users[user] = current
+	}
+}
+
+// LoadUserKeys loads the keys of the encrypted datasets of user on the current machine from passphrase, the login
+// password of user as given by PAM.
+// It's a no-op for users without any encrypted dataset. Failing to load the key of the user state attached to the
+// current system is an error, while keys of older user states, which may have had another passphrase, are only
+// warned about.
+func (ms *Machines) LoadUserKeys(ctx context.Context, user string, passphrase []byte) (err error) {
+	if !ms.current.isZsys() {
+		return errors.New(i18n.G("Current machine isn't Zsys, nothing to unlock"))
+	}
+	if user == "" {
+		return errors.New(i18n.G("Needs a valid user name, got nothing"))
+	}
+
+	// Roots of the current user state first, so that we know which failures are errors.
+	current := make(map[string]bool)
+	if us, ok := ms.current.Users[user]; ok {
+		for root := range us.lockedEncryptionRoots() {
+			current[root] = true
+		}
+	}
+	others := make(map[string]bool)
+	for _, us := range ms.current.AllUsersStates[user] {
+		for root := range us.lockedEncryptionRoots() {
+			if !current[root] {
+				others[root] = true
+			}
+		}
+	}
+	if len(current) == 0 && len(others) == 0 {
+		log.Debugf(ctx, i18n.G("No encryption key to load for %q"), user)
+		return nil
+	}
+
+	nt := ms.z.NewNoTransaction(ctx)
+	// Keys loaded before any failure are applied too.
+	defer func() {
+		if errApply := ms.applyChanges(ctx); errApply != nil && err == nil {
+			err = errApply
+		}
+	}()
+	for _, root := range sortedKeys(current) {
+		log.Infof(ctx, i18n.G("Loading encryption key of %q"), root)
+		if err := nt.LoadKey(root, passphrase); err != nil {
+			return fmt.Errorf(i18n.G("couldn't unlock user data of %q: ")+config.ErrorFormat, user, err)
+		}
+	}
+	for _, root := range sortedKeys(others) {
+		log.Infof(ctx, i18n.G("Loading encryption key of %q"), root)
+		if err := nt.LoadKey(root, passphrase); err != nil {
+			log.Warningf(ctx, i18n.G("Couldn't unlock user state %q: %v"), root, err)
+		}
+	}
+	return nil
+}
+
+// lockedEncryptionRoots returns the encryption roots of the datasets of this state which key isn't loaded.
+func (s State) lockedEncryptionRoots() map[string]bool {
+	roots := make(map[string]bool)
+	for _, d := range s.getDatasets() {
+		if d.KeyStatus != keyUnavailable || d.EncryptionRoot == "" {
+			continue
+		}
+		roots[d.EncryptionRoot] = true
+	}
+	return roots
+}
+
+// sortedKeys returns the keys of m, sorted for reproducibility.
+func sortedKeys(m map[string]bool) []string {
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
@@ -0,0 +1,30 @@
+package machines
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// Events follows the ZFS events of the system until ctx is cancelled.
+func (ms *Machines) Events(ctx context.Context) (<-chan libzfs.Event, error) {
+	return ms.z.Events(ctx)
+}
+
+// ApplyEvents updates the zfs datasets touched by a batch of ZFS events, without rescanning the others, and applies
+// their changes to the affected machines only.
+// Events caused by our own changes are already known and don't change anything.
+func (ms *Machines) ApplyEvents(ctx context.Context, events []libzfs.Event) error {
+	// Datasets refreshed before any failure are applied too.
+	errEvents := ms.z.ApplyEvents(ctx, events)
+	if err := ms.applyChanges(ctx); err != nil {
+		return err
+	}
+	if errEvents != nil {
+		return fmt.Errorf(i18n.G("couldn't apply ZFS events: ")+config.ErrorFormat, errEvents)
+	}
+	return nil
+}
@@ -1,12 +1,15 @@
 package machines
 
 import (
+	"context"
 	"encoding/json"
 	"sort"
 	"testing"
 
+	"github.com/ubuntu/zsys/internal/bootmenu"
 	"github.com/ubuntu/zsys/internal/config"
 	"github.com/ubuntu/zsys/internal/testutils"
+	"github.com/ubuntu/zsys/internal/zfs"
 )
 
 const (
@@ -22,6 +25,30 @@ func WithTime(time Nower) func(o *options) error {
 	}
 }
 
+// WithHealthChecker allows overriding health checks run on trial boots with a mock
+func WithHealthChecker(c HealthChecker) func(o *options) error {
+	return func(o *options) error {
+		o.healthCheck = c
+		return nil
+	}
+}
+
+// WithServicesStopper allows overriding how services are stopped on in place reverts with a mock
+func WithServicesStopper(s ServicesStopper) func(o *options) error {
+	return func(o *options) error {
+		o.stopServices = s
+		return nil
+	}
+}
+
+// WithMounter allows overriding how datasets are mounted and unmounted on the running system with a mock
+func WithMounter(m Mounter) func(o *options) error {
+	return func(o *options) error {
+		o.mount = m
+		return nil
+	}
+}
+
 // Import from json to export the private fields
 func (ms *Machines) UnmarshalJSON(b []byte) error {
 	mt := Machinesdump{}
@@ -72,15 +99,32 @@ func (ms *Machines) MakeComparable() {
 
 	ms.z = nil
 	ms.time = nil
+	ms.healthCheck = nil
+	ms.stopServices = nil
+	ms.mount = nil
 	ms.conf = config.ZConfig{}
 }
 
 // SplitSnapshotName calls internal splitSnapshotName to split a snapshot name in base and id of a snapshot
 func SplitSnapshotName(s string) (string, string) { return splitSnapshotName(s) }
 
+// BootEntries exports the boot menu entries for tests
+func (ms *Machines) BootEntries() []bootmenu.Entry { return ms.bootEntries() }
+
+// BootEntriesWithKernels exports the boot menu entries with the directory of their kernels for tests
+func (ms *Machines) BootEntriesWithKernels(ctx context.Context) ([]bootmenu.Entry, func()) {
+	entries := ms.bootEntries()
+	return entries, ms.mountKernels(ctx, entries)
+}
+
 // AllMachines exports machines lists for tests
 func (ms *Machines) AllMachines() map[string]*Machine { return ms.all }
 
+func (ms *Machines) Z() *zfs.Zfs { return ms.z }
+
+// ApplyChanges exports applyChanges for benchmarks
+func (ms *Machines) ApplyChanges(ctx context.Context) error { return ms.applyChanges(ctx) }
+
 func (ms Machines) CopyForTests(t *testing.T) (copy Machines) {
 	t.Helper()
 
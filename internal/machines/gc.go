@@ -37,20 +37,128 @@ const (
 	keepUnknown keepStatus = iota
 	keepYes     keepStatus = iota
 	keepNo      keepStatus = iota
+	keepPinned  keepStatus = iota
 )
 
+// gcPolicy is the buckets and keep rules applying to a set of states.
+type gcPolicy struct {
+	buckets  []bucket
+	keepLast int
+	keepTags map[string]string
+}
+
+// newGCPolicy computes the policy of rules at now.
+func newGCPolicy(ctx context.Context, now time.Time, rules config.HistoryRules) (gcPolicy, error) {
+	keepTags, err := parseTags(rules.KeepTags)
+	if err != nil {
+		return gcPolicy{}, fmt.Errorf(i18n.G("invalid tags to keep in configuration: ")+config.ErrorFormat, err)
+	}
+	return gcPolicy{
+		buckets:  computeBuckets(ctx, now, rules),
+		keepLast: rules.KeepLast,
+		keepTags: keepTags,
+	}, nil
+}
+
+// userGCPolicies are the policies applying to user states, per user.
+type userGCPolicies struct {
+	users         map[string]gcPolicy
+	defaultPolicy gcPolicy
+}
+
+// newUserGCPolicies computes the policy of user states at now, with the overrides for each user of the configuration.
+func newUserGCPolicies(ctx context.Context, now time.Time, conf config.ZConfig) (userGCPolicies, error) {
+	defaultPolicy, err := newGCPolicy(ctx, now, conf.UserRules(""))
+	if err != nil {
+		return userGCPolicies{}, err
+	}
+	policies := userGCPolicies{
+		users:         make(map[string]gcPolicy),
+		defaultPolicy: defaultPolicy,
+	}
+	if conf.UserHistory == nil {
+		return policies, nil
+	}
+	for user, rules := range conf.UserHistory.Users {
+		p, err := newGCPolicy(ctx, now, rules)
+		if err != nil {
+			return userGCPolicies{}, fmt.Errorf(i18n.G("invalid history rules for user %s: ")+config.ErrorFormat, user, err)
+		}
+		policies.users[user] = p
+	}
+	return policies, nil
+}
+
+// forUser returns the policy applying to the states of user.
+func (p userGCPolicies) forUser(user string) gcPolicy {
+	if up, ok := p.users[user]; ok {
+		return up
+	}
+	return p.defaultPolicy
+}
+
 type stateWithKeep struct {
 	*State
-	keep keepStatus
+	keep   keepStatus
+	reason string // why the state is kept, if known before filling the bucket
+}
+
+// Reasons of garbage collection decisions.
+const (
+	gcReasonPinned       = "pinned"
+	gcReasonKeepLast     = "keep-last"
+	gcReasonRecent       = "recent"
+	gcReasonManual       = "manual"
+	gcReasonTag          = "tag"
+	gcReasonDependency   = "dependency"
+	gcReasonLinked       = "linked"
+	gcReasonBucketSample = "bucket-sample"
+	gcReasonExceeding    = "exceeding-bucket"
+	gcReasonUnlinked     = "unlinked"
+	gcReasonFallback     = "trial-fallback"
+)
+
+// GCDecision is what garbage collection does with a state, and why.
+type GCDecision struct {
+	ID       string
+	Machine  string
+	User     string `json:",omitempty"`
+	LastUsed time.Time
+	// Bucket is the time range of the history rules the state falls in.
+	Bucket string `json:",omitempty"`
+	Remove bool
+	Reason string
 }
 
 // GC starts garbage collection for system and users
 // If all is set manual snapshots are considered too
 func (ms *Machines) GC(ctx context.Context, all bool) error {
+	_, err := ms.gc(ctx, all, false)
+	return err
+}
+
+// GCPlan runs garbage collection without removing anything and returns the decision taken for every system and user
+// state, sorted by machine, system states first, and from the newest to the oldest state.
+// Removed states are excluded from the next passes, as if they were destroyed. User filesystem states only linked
+// to removed system states are reported as removed, as they will be collected once unlinked.
+// If all is set manual snapshots are considered too
+func (ms *Machines) GCPlan(ctx context.Context, all bool) ([]GCDecision, error) {
+	return ms.gc(ctx, all, true)
+}
+
+// gc runs garbage collection. On dryrun, nothing is removed and the decisions for each state are returned.
+func (ms *Machines) gc(ctx context.Context, all, dryrun bool) ([]GCDecision, error) {
 	now := ms.time.Now()
 
-	buckets := computeBuckets(ctx, now, ms.conf.History)
-	keepLast := ms.conf.History.KeepLast
+	systemPolicy, err := newGCPolicy(ctx, now, ms.conf.History)
+	if err != nil {
+		return nil, err
+	}
+	// User states which aren't linked to a system state follow their own rules.
+	userPolicies, err := newUserGCPolicies(ctx, now, ms.conf)
+	if err != nil {
+		return nil, err
+	}
 
 	allDatasets := make([]*zfs.Dataset, 0, len(ms.allSystemDatasets)+len(ms.allPersistentDatasets)+len(ms.allUsersDatasets)+len(ms.unmanagedDatasets))
 
@@ -74,6 +182,46 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 
 	var statesToRemove []*State
 	keepDueToErrorOnDelete := make(map[string]bool)
+	trialFallbacks := ms.trialFallbacks()
+	// pinned states are reported separately from the states kept by the history policy.
+	pinnedSystemStates, pinnedUserStates := make(map[string]bool), make(map[string]bool)
+	var nSystemStatesRemoved, nUserStatesRemoved int
+
+	// On dry run, decisions are recorded for each state instead of removing them.
+	// User states linked to multiple system states are listed once.
+	decisions := make(map[string]GCDecision)
+	removedOnDryRun := make(map[*State]bool)
+	removedSystemStateIDs := make(map[string]bool)
+	record := func(m *Machine, user string, s stateWithKeep, b bucket, remove bool) {
+		if !dryrun {
+			return
+		}
+		reason := s.reason
+		if reason == "" {
+			reason = gcReasonBucketSample
+			if remove {
+				reason = gcReasonExceeding
+			}
+		}
+		decisions[m.ID+"/"+user+"/"+s.ID] = GCDecision{
+			ID:       s.ID,
+			Machine:  m.ID,
+			User:     user,
+			LastUsed: s.LastUsed,
+			Bucket:   b.label(),
+			Remove:   remove,
+			Reason:   reason,
+		}
+	}
+	recordBucket := func(m *Machine, user string, states []stateWithKeep, statesToRemove []*State, b bucket) {
+		remove := make(map[*State]bool)
+		for _, s := range statesToRemove {
+			remove[s] = true
+		}
+		for _, s := range states {
+			record(m, user, s, b, remove[s.State])
+		}
+	}
 
 	// 1. System GC
 	var gcPassNum int
@@ -90,11 +238,14 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 			var newestStateIndex int
 			var sortedStates sortedReverseByTimeStates
 			for _, s := range m.History {
+				if removedOnDryRun[s] {
+					continue
+				}
 				sortedStates = append(sortedStates, s)
 			}
 			sort.Sort(sortedStates)
 
-			for _, bucket := range buckets {
+			for _, bucket := range systemPolicy.buckets {
 				log.Debugf(ctx, i18n.G("bucket %+v"), bucket)
 
 				// End of the array, nothing else to do.
@@ -124,12 +275,16 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 				// Don't touch anything for this bucket, skip all states in here and advance to next one.
 				if bucket.samples == -1 {
 					log.Debug(ctx, i18n.G("Keeping all snapshots for this bucket"))
+					for i := newestStateIndex; i <= oldestStateIndex; i++ {
+						record(m, "", stateWithKeep{State: sortedStates[i], reason: gcReasonRecent}, bucket, false)
+					}
 					newestStateIndex = oldestStateIndex + 1
 					continue
 				}
 
 				// Collect all states for current bucket and mark those having constraints
 				states := make([]stateWithKeep, 0, oldestStateIndex-newestStateIndex+1)
+				var nPinned int
 				log.Debug(ctx, i18n.G("Collecting all states for current bucket"))
 				for i := newestStateIndex; i <= oldestStateIndex; i++ {
 					log.Debugf(ctx, i18n.G("Analyzing state %v: %v"), sortedStates[i].ID, sortedStates[i].LastUsed.Format(timeFormat))
@@ -137,14 +292,30 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 					s := sortedStates[i]
 
 					keep := keepUnknown
+					var reason string
+					// Pinned by the user
+					if s.isPinned() {
+						log.Debugf(ctx, i18n.G("Keeping %v as it's pinned"), s.ID)
+						pinnedSystemStates[s.ID] = true
+						keep = keepPinned
+						reason = gcReasonPinned
+						nPinned++
+					}
 					// Previous deletion failed
-					if keepDueToErrorOnDelete[s.ID] {
+					if keep == keepUnknown && keepDueToErrorOnDelete[s.ID] {
 						keep = keepYes
 					}
+					// Fallback of a trial boot
+					if keep == keepUnknown && trialFallbacks[s.ID] {
+						log.Debugf(ctx, i18n.G("Keeping %v as a trial boot falls back to it"), s.ID)
+						keep = keepYes
+						reason = gcReasonFallback
+					}
 					// In keep last list
-					if keep == keepUnknown && i < keepLast {
-						log.Debugf(ctx, i18n.G("Keeping snapshot %v as it's in the last %d snapshots"), s.ID, keepLast)
+					if keep == keepUnknown && i < systemPolicy.keepLast {
+						log.Debugf(ctx, i18n.G("Keeping snapshot %v as it's in the last %d snapshots"), s.ID, systemPolicy.keepLast)
 						keep = keepYes
+						reason = gcReasonKeepLast
 					}
 					// Has snapshots as children
 					if keep == keepUnknown && !s.isSnapshot() {
@@ -152,6 +323,7 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 							if ds[0].HasSnapshotInHierarchy() {
 								log.Debugf(ctx, i18n.G("Keeping %v as it has a snapshot in its child hierarchy"), s.ID)
 								keep = keepYes
+								reason = gcReasonDependency
 							}
 						}
 					}
@@ -159,6 +331,13 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 					if keep == keepUnknown && s.isSnapshot() && !all && !strings.Contains(s.ID, "@"+automatedSnapshotPrefix) {
 						log.Debugf(ctx, i18n.G("Keeping snapshot %v as it's not a zsys one"), s.ID)
 						keep = keepYes
+						reason = gcReasonManual
+					}
+					// Tagged to be kept
+					if keep == keepUnknown && s.matchesAnyTag(systemPolicy.keepTags) {
+						log.Debugf(ctx, i18n.G("Keeping %v as it has a tag to keep"), s.ID)
+						keep = keepYes
+						reason = gcReasonTag
 					}
 					// Has clones
 					if keep == keepUnknown && s.isSnapshot() {
@@ -170,6 +349,7 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 								if byOrigin[d.Name] != nil || snapshotsByDS[d.Name] != nil {
 									log.Debugf(ctx, i18n.G("Keeping snapshot %v as at least %s dataset has dependencies"), s.ID, d.Name)
 									keep = keepYes
+									reason = gcReasonDependency
 									break analyzeSystemDataset
 								}
 							}
@@ -177,22 +357,25 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 					}
 
 					states = append(states, stateWithKeep{
-						State: s,
-						keep:  keep,
+						State:  s,
+						keep:   keep,
+						reason: reason,
 					})
 				}
 				// next bucket start point
 				newestStateIndex = oldestStateIndex + 1
 
-				// Ensure we have the minimum amount of states on this bucket.
-				nStatesToRemove := len(states) - bucket.samples
+				// Ensure we have the minimum amount of states on this bucket. Pinned states don’t take any slot.
+				nStatesToRemove := len(states) - nPinned - bucket.samples
 				if nStatesToRemove <= 0 {
 					log.Debugf(ctx, i18n.G("No exceeding states for this bucket (delta: %d). Moving on."), nStatesToRemove)
+					recordBucket(m, "", states, nil, bucket)
 					continue
 				}
 				log.Debugf(ctx, i18n.G("There are %d exceeding states to potentially remove"), nStatesToRemove)
 
 				statesToRemoveForBucket := selectStatesToRemove(ctx, bucket.samples, states)
+				recordBucket(m, "", states, statesToRemoveForBucket, bucket)
 
 				for _, s := range statesToRemoveForBucket {
 					statesChanges = true
@@ -223,15 +406,26 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 
 		// Remove the given states.
 		for _, s := range statesToRemove {
+			if dryrun {
+				log.Infof(ctx, i18n.G("Would remove state: %s"), s.ID)
+				removedOnDryRun[s] = true
+				removedSystemStateIDs[s.ID] = true
+				nSystemStatesRemoved++
+				continue
+			}
 			log.Infof(ctx, i18n.G("Selecting state to remove: %s"), s.ID)
 			if err := s.remove(ctx, ms, ""); err != nil {
 				log.Errorf(ctx, i18n.G("Couldn't fully destroy state %s: %v\nPutting it in keep list."), s.ID, err)
 				keepDueToErrorOnDelete[s.ID] = true
+				continue
 			}
+			nSystemStatesRemoved++
 		}
 		statesToRemove = nil
-		if err := ms.Refresh(ctx); err != nil {
-			return fmt.Errorf("Couldn't refresh machine list: %v", err)
+		if !dryrun {
+			if err := ms.applyChanges(ctx); err != nil {
+				return nil, fmt.Errorf("Couldn't refresh machine list: %v", err)
+			}
 		}
 		log.Debug(ctx, i18n.G("System have changes, rerun system GC"))
 	}
@@ -252,7 +446,9 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 
 		for _, m := range ms.all {
 			// FIXME: we count same user state multiple times if linked to multiple bootfs systems
-			for _, us := range m.AllUsersStates {
+			for user, us := range m.AllUsersStates {
+				policy := userPolicies.forUser(user)
+
 				var newestStateIndex int
 				var sortedStates sortedReverseByTimeStates
 
@@ -264,12 +460,24 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 							continue nextUserState
 						}
 					}
+					if removedOnDryRun[s] {
+						continue
+					}
+					// Unlinked from all system states, it will be collected with unmanaged datasets.
+					if dryrun && s.linkedOnlyToStates(removedSystemStateIDs) {
+						st := stateWithKeep{State: s, reason: gcReasonUnlinked}
+						if s.isPinned() {
+							st.reason = gcReasonPinned
+						}
+						record(m, user, st, bucket{}, !s.isPinned())
+						continue
+					}
 
 					sortedStates = append(sortedStates, s)
 				}
 				sort.Sort(sortedStates)
 
-				for _, bucket := range buckets {
+				for _, bucket := range policy.buckets {
 					log.Debugf(ctx, i18n.G("bucket %+v"), bucket)
 
 					// End of the array, nothing else to do.
@@ -297,26 +505,40 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 					// Don't touch anything for this bucket, skip all states in here and advance to next one.
 					if bucket.samples == -1 {
 						log.Debug(ctx, i18n.G("Keeping all snapshots for this bucket"))
+						for i := newestStateIndex; i <= oldestStateIndex; i++ {
+							record(m, user, stateWithKeep{State: sortedStates[i], reason: gcReasonRecent}, bucket, false)
+						}
 						newestStateIndex = oldestStateIndex + 1
 						continue
 					}
 
 					// Collect all states for current bucket and mark those having constraints
 					states := make([]stateWithKeep, 0, oldestStateIndex-newestStateIndex+1)
+					var nPinned int
 					log.Debug(ctx, i18n.G("Collecting all states for current bucket"))
 					for i := newestStateIndex; i <= oldestStateIndex; i++ {
 						s := sortedStates[i]
 						log.Debugf(ctx, i18n.G("Analyzing state %v: %v"), s.ID, s.LastUsed.Format(timeFormat))
 
 						keep := keepUnknown
+						var reason string
+						// Pinned by the user
+						if s.isPinned() {
+							log.Debugf(ctx, i18n.G("Keeping %v as it's pinned"), s.ID)
+							pinnedUserStates[s.ID] = true
+							keep = keepPinned
+							reason = gcReasonPinned
+							nPinned++
+						}
 						// Previous deletion failed
-						if keepDueToErrorOnDelete[s.ID] {
+						if keep == keepUnknown && keepDueToErrorOnDelete[s.ID] {
 							keep = keepYes
 						}
 						// In keep last list
-						if keep == keepUnknown && i < keepLast {
-							log.Debugf(ctx, i18n.G("Keeping %v as it's in the last %d snapshots"), s.ID, keepLast)
+						if keep == keepUnknown && i < policy.keepLast {
+							log.Debugf(ctx, i18n.G("Keeping %v as it's in the last %d snapshots"), s.ID, policy.keepLast)
 							keep = keepYes
+							reason = gcReasonKeepLast
 						}
 						// Has snapshots as children
 						if keep == keepUnknown && !s.isSnapshot() {
@@ -324,6 +546,7 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 								if ds[0].HasSnapshotInHierarchy() {
 									log.Debugf(ctx, i18n.G("Keeping %v as it has a snapshot in its child hierarchy"), s.ID)
 									keep = keepYes
+									reason = gcReasonDependency
 								}
 							}
 						}
@@ -331,21 +554,33 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 						if keep == keepUnknown && s.isSnapshot() && !all && !strings.Contains(s.ID, "@"+automatedSnapshotPrefix) {
 							log.Debugf(ctx, i18n.G("Keeping snapshot %v as it's not a zsys one"), s.ID)
 							keep = keepYes
+							reason = gcReasonManual
+						}
+						// Tagged to be kept
+						if keep == keepUnknown && s.matchesAnyTag(policy.keepTags) {
+							log.Debugf(ctx, i18n.G("Keeping %v as it has a tag to keep"), s.ID)
+							keep = keepYes
+							reason = gcReasonTag
 						}
 						// Filesystem linked to system state
 						if keep == keepUnknown && !s.isSnapshot() && s.linkedToSystemState() {
 							log.Debugf(ctx, i18n.G("Keeping %v as it's not a snapshot and associated to a system state"), s.ID)
 							keep = keepYes
+							reason = gcReasonLinked
 						}
 						// Snapshot linked to system state
 						if keep == keepUnknown && s.isSnapshot() {
 							_, snapshotName := splitSnapshotName(s.ID)
 							// Do we have a state associated with us?
 							for k := range m.History {
+								if removedSystemStateIDs[k] {
+									continue
+								}
 								_, n := splitSnapshotName(k)
 								if n == snapshotName {
 									log.Debugf(ctx, i18n.G("Keeping as snapshot %v is associated to a system snapshot"), s.ID)
 									keep = keepYes
+									reason = gcReasonLinked
 									break
 								}
 							}
@@ -362,6 +597,7 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 									if byOrigin[d.Name] != nil {
 										log.Debugf(ctx, i18n.G("Keeping snapshot %v as at least %s dataset has dependencies"), s.ID, d.Name)
 										keep = keepYes
+										reason = gcReasonDependency
 										break analyzeUserDataset
 									}
 								}
@@ -369,8 +605,9 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 						}
 
 						states = append(states, stateWithKeep{
-							State: s,
-							keep:  keep,
+							State:  s,
+							keep:   keep,
+							reason: reason,
 						})
 						for route := range s.Datasets {
 							userDatasetsToKeep[route] = true
@@ -379,15 +616,17 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 					// next bucket start point
 					newestStateIndex = oldestStateIndex + 1
 
-					// Ensure we have the minimum amount of states on this bucket.
-					nStatesToRemove := len(states) - bucket.samples
+					// Ensure we have the minimum amount of states on this bucket. Pinned states don’t take any slot.
+					nStatesToRemove := len(states) - nPinned - bucket.samples
 					if nStatesToRemove <= 0 {
 						log.Debugf(ctx, i18n.G("No exceeding states for this bucket (delta: %d). Moving on."), nStatesToRemove)
+						recordBucket(m, user, states, nil, bucket)
 						continue
 					}
 					log.Debugf(ctx, i18n.G("There are %d exceeding states to potentially remove"), nStatesToRemove)
 
 					statesToRemoveForBucket := selectStatesToRemove(ctx, bucket.samples, states)
+					recordBucket(m, user, states, statesToRemoveForBucket, bucket)
 
 					for _, s := range statesToRemoveForBucket {
 						statesChanges = true
@@ -416,24 +655,38 @@ func (ms *Machines) GC(ctx context.Context, all bool) error {
 
 		// Remove the given states.
 		for _, s := range statesToRemove {
+			if dryrun {
+				log.Infof(ctx, i18n.G("Would remove state: %s"), s.ID)
+				removedOnDryRun[s] = true
+				nUserStatesRemoved++
+				continue
+			}
 			log.Infof(ctx, i18n.G("Selecting state to remove: %s"), s.ID)
 			if err := s.remove(ctx, ms, ""); err != nil {
 				log.Errorf(ctx, i18n.G("Couldn't fully destroy user state %s: %v.\nPutting it in keep list."), s.ID, err)
 				keepDueToErrorOnDelete[s.ID] = true
 				continue
 			}
+			nUserStatesRemoved++
 			for route := range s.Datasets {
 				delete(userDatasetsToKeep, route)
 			}
 		}
 
 		statesToRemove = nil
-		if err := ms.Refresh(ctx); err != nil {
-			return fmt.Errorf("Couldn't refresh machine list: %v", err)
+		if !dryrun {
+			if err := ms.applyChanges(ctx); err != nil {
+				return nil, fmt.Errorf("Couldn't refresh machine list: %v", err)
+			}
 		}
 		log.Debug(ctx, i18n.G("Users states have changes, rerun user GC"))
 	}
 
+	if dryrun {
+		log.Infof(ctx, i18n.G("Garbage collection would remove %d system states and %d user states"), nSystemStatesRemoved, nUserStatesRemoved)
+		return sortedDecisions(decisions), nil
+	}
+
 	// 3. Clean up unmanaged datasets which were user datasets with empty tags.
 	log.Debug(ctx, i18n.G("Unmanaged past user datasets GC"))
 	nt := ms.z.NewNoTransaction(ctx)
@@ -458,6 +711,10 @@ nextUnmanagedUserPass:
 			if d.IsSnapshot {
 				continue
 			}
+			// Pinned datasets are never collected, even once unlinked from any system state
+			if d.Pinned == pinnedValue {
+				continue
+			}
 			r, err := d.IsUserDataset()
 			if err != nil {
 				log.Warningf(ctx, i18n.G("Couldn't determine if %s was a user dataset %v"), d.Name, err)
@@ -506,13 +763,40 @@ nextUnmanagedUserPass:
 			}
 		}
 
-		if err := ms.Refresh(ctx); err != nil {
-			return fmt.Errorf("Couldn't refresh machine list: %v", err)
+		if err := ms.applyChanges(ctx); err != nil {
+			return nil, fmt.Errorf("Couldn't refresh machine list: %v", err)
 		}
 		gcPassNum++
 	}
 
-	return nil
+	log.Infof(ctx, i18n.G("Garbage collection removed %d system states and %d user states"), nSystemStatesRemoved, nUserStatesRemoved)
+	if len(pinnedSystemStates) > 0 || len(pinnedUserStates) > 0 {
+		log.Infof(ctx, i18n.G("Kept %d pinned system states and %d pinned user states"), len(pinnedSystemStates), len(pinnedUserStates))
+	}
+
+	return nil, nil
+}
+
+// sortedDecisions returns decisions sorted by machine, system states first, then by user, and from the newest to
+// the oldest state.
+func sortedDecisions(decisions map[string]GCDecision) []GCDecision {
+	r := make([]GCDecision, 0, len(decisions))
+	for _, d := range decisions {
+		r = append(r, d)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Machine != r[j].Machine {
+			return r[i].Machine < r[j].Machine
+		}
+		if r[i].User != r[j].User {
+			return r[i].User < r[j].User
+		}
+		if !r[i].LastUsed.Equal(r[j].LastUsed) {
+			return r[i].LastUsed.After(r[j].LastUsed)
+		}
+		return r[i].ID < r[j].ID
+	})
+	return r
 }
 
 func removeFromSlice(s []string, name string) (r []string) {
@@ -585,11 +869,32 @@ func (b bucket) String() string {
 	return fmt.Sprintf("start: %s end:%s samples: %d", b.start.Format(timeFormat), b.end.Format(timeFormat), b.samples)
 }
 
+// label returns the time range of the bucket, for users. The oldest bucket has no start.
+func (b bucket) label() string {
+	if b.end.IsZero() {
+		return ""
+	}
+	if b.start.IsZero() {
+		return fmt.Sprintf(i18n.G("before %s"), b.end.Format(timeFormat))
+	}
+	return fmt.Sprintf(i18n.G("%s to %s"), b.start.Format(timeFormat), b.end.Format(timeFormat))
+}
+
 // selectStatesToRemove selects the maximum number of states to keep to fill a bucket up to samples and spread them evenly over the width of the bucket.
 // When 2 solutions are equal the first match is kept
+// Pinned states are never removed and don’t take any slot in the bucket.
 func selectStatesToRemove(ctx context.Context, samples int, states []stateWithKeep) (statesToRemove []*State) {
 	log.Debug(ctx, "selecting list of states to remove")
 
+	var unpinned []stateWithKeep
+	for _, s := range states {
+		if s.keep == keepPinned {
+			continue
+		}
+		unpinned = append(unpinned, s)
+	}
+	states = unpinned
+
 	if len(states) <= samples {
 		log.Debug(ctx, "bucket has enough capacity to keep all the states")
 		return statesToRemove
@@ -680,6 +985,26 @@ func selectStatesToRemove(ctx context.Context, samples int, states []stateWithKe
 	return statesToRemove
 }
 
+// linkedOnlyToStates returns if a filesystem user state is only linked to system states in ids.
+func (s *State) linkedOnlyToStates(ids map[string]bool) bool {
+	if s.isSnapshot() || len(ids) == 0 {
+		return false
+	}
+	var linked bool
+	for _, ds := range s.Datasets {
+		for _, b := range strings.Split(ds[0].BootfsDatasets, bootfsdatasetsSeparator) {
+			if b = strings.TrimSpace(b); b == "" {
+				continue
+			}
+			if !ids[b] {
+				return false
+			}
+			linked = true
+		}
+	}
+	return linked
+}
+
 // linkedToSystemState returns if a datasets is potentially linked to a system state.
 // Note that it doesn’t check if the system state is currently accessible.
 func (s *State) linkedToSystemState() bool {
@@ -0,0 +1,215 @@
+package machines
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+)
+
+// stateWithSpace is a state which can be removed to free up space, with the space it holds.
+type stateWithSpace struct {
+	*State
+	used    uint64 // space only referenced by the state, freed when removing it
+	written uint64 // space written in the state since the previous one
+}
+
+// GCSpace removes the least valuable states until each pool reaches the free space target of the configuration.
+// If all is set manual snapshots are considered too.
+func (ms *Machines) GCSpace(ctx context.Context, all bool) error {
+	if ms.conf.General.TargetFreePoolSpace <= 0 {
+		log.Info(ctx, i18n.G("No free space target configured, nothing to do"))
+		return nil
+	}
+
+	pools := make(map[string]bool)
+	for _, d := range append(append([]*zfs.Dataset(nil), ms.allSystemDatasets...), ms.allUsersDatasets...) {
+		pools[poolName(d.Name)] = true
+	}
+
+	return ms.freePoolsSpace(ctx, pools, all)
+}
+
+// freePoolsSpace removes the least valuable states having datasets on pools, one at a time, until every pool has
+// the free space target of the configuration.
+// States are ranked by the space only they reference, which is freed immediately, then by the space written in
+// them and finally by age. Recent, pinned, tagged to be kept and manual states, unless all is set, are never
+// removed, as well as states with dependencies and the fallbacks of trial boots.
+func (ms *Machines) freePoolsSpace(ctx context.Context, pools map[string]bool, all bool) error {
+	target := ms.conf.General.TargetFreePoolSpace
+	now := ms.time.Now()
+	systemPolicy, err := newGCPolicy(ctx, now, ms.conf.History)
+	if err != nil {
+		return err
+	}
+	userPolicies, err := newUserGCPolicies(ctx, now, ms.conf)
+	if err != nil {
+		return err
+	}
+
+	keepDueToErrorOnDelete := make(map[string]bool)
+	var nStatesRemoved int
+	for {
+		fullPools := make(map[string]bool)
+		for p := range pools {
+			free, err := ms.z.GetPoolFreeSpace(p)
+			if err != nil {
+				return err
+			}
+			if free < target {
+				log.Debugf(ctx, "Free space on pool %q is %d%%, below target of %d%%", p, free, target)
+				fullPools[p] = true
+			}
+		}
+		if len(fullPools) == 0 {
+			break
+		}
+
+		candidates := ms.statesToFreeSpace(ctx, fullPools, systemPolicy, userPolicies, keepDueToErrorOnDelete, all)
+		if len(candidates) == 0 {
+			var names []string
+			for p := range fullPools {
+				names = append(names, p)
+			}
+			sort.Strings(names)
+			log.Warningf(ctx, i18n.G("No more states can be removed to reach %d%% of free space on %s"), target, strings.Join(names, ", "))
+			break
+		}
+
+		s := candidates[0]
+		log.Infof(ctx, i18n.G("Removing state %s to free up space (%d bytes only used by it)"), s.ID, s.used)
+		if err := s.remove(ctx, ms, ""); err != nil {
+			log.Errorf(ctx, i18n.G("Couldn't fully destroy state %s: %v\nPutting it in keep list."), s.ID, err)
+			keepDueToErrorOnDelete[s.ID] = true
+		} else {
+			nStatesRemoved++
+		}
+
+		// Space used by remaining snapshots changes once one is destroyed.
+		if err := ms.Refresh(ctx); err != nil {
+			return fmt.Errorf("Couldn't refresh machine list: %v", err)
+		}
+	}
+
+	if nStatesRemoved > 0 {
+		log.Infof(ctx, i18n.G("Removed %d states to free up space"), nStatesRemoved)
+	}
+	return nil
+}
+
+// statesToFreeSpace returns the system and user saved states with datasets on pools which can be removed to free
+// up space, from the least to the most valuable.
+// The KeepLast most recent states of each system and user are kept, following their own history rules.
+func (ms *Machines) statesToFreeSpace(ctx context.Context, pools map[string]bool, systemPolicy gcPolicy, userPolicies userGCPolicies, keep map[string]bool, all bool) (candidates []stateWithSpace) {
+	// Clones prevent their origin snapshot from being destroyed.
+	origins := make(map[string]bool)
+	for _, d := range append(append(append([]*zfs.Dataset(nil), ms.allSystemDatasets...), ms.allUsersDatasets...), ms.unmanagedDatasets...) {
+		if d.Origin != "" {
+			origins[d.Origin] = true
+		}
+	}
+
+	fallbacks := ms.trialFallbacks()
+
+	seen := make(map[string]bool)
+	addCandidates := func(states sortedReverseByTimeStates, policy gcPolicy, isLinkedToSystemState func(*State) bool) {
+		sort.Sort(states)
+		for i, s := range states {
+			if seen[s.ID] {
+				continue
+			}
+			seen[s.ID] = true
+
+			if !s.isSnapshot() || keep[s.ID] || fallbacks[s.ID] ||
+				i < policy.keepLast || s.isPinned() || s.matchesAnyTag(policy.keepTags) ||
+				(!all && !strings.Contains(s.ID, "@"+automatedSnapshotPrefix)) {
+				continue
+			}
+			if isLinkedToSystemState != nil && isLinkedToSystemState(s) {
+				continue
+			}
+
+			c := stateWithSpace{State: s}
+			var hasClones, onPools bool
+			for _, d := range s.getDatasets() {
+				if origins[d.Name] {
+					hasClones = true
+					break
+				}
+				if pools[poolName(d.Name)] {
+					onPools = true
+				}
+				c.used += d.Used
+				c.written += d.Written
+			}
+			if hasClones {
+				log.Debugf(ctx, "Keeping %s as at least one of its datasets has clones", s.ID)
+				continue
+			}
+			if !onPools {
+				continue
+			}
+			candidates = append(candidates, c)
+		}
+	}
+
+	for _, m := range ms.all {
+		if !m.isZsys() {
+			continue
+		}
+
+		var systemStates sortedReverseByTimeStates
+		for _, s := range m.History {
+			systemStates = append(systemStates, s)
+		}
+		addCandidates(systemStates, systemPolicy, nil)
+
+		for user, us := range m.AllUsersStates {
+			var userStates sortedReverseByTimeStates
+		nextUserState:
+			for _, s := range us {
+				// exclude "current" user state fom history
+				for _, cur := range m.State.Users {
+					if cur == s {
+						continue nextUserState
+					}
+				}
+				userStates = append(userStates, s)
+			}
+			// User states saved with a system state are only removed once the system state is.
+			addCandidates(userStates, userPolicies.forUser(user), func(s *State) bool {
+				_, snapshotName := splitSnapshotName(s.ID)
+				for k := range m.History {
+					if _, n := splitSnapshotName(k); n == snapshotName {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].used != candidates[j].used {
+			return candidates[i].used > candidates[j].used
+		}
+		if candidates[i].written != candidates[j].written {
+			return candidates[i].written > candidates[j].written
+		}
+		if !candidates[i].LastUsed.Equal(candidates[j].LastUsed) {
+			return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	return candidates
+}
+
+// poolName returns the pool name of a dataset or snapshot.
+func poolName(name string) string {
+	return strings.SplitN(strings.SplitN(name, "@", 2)[0], "/", 2)[0]
+}
@@ -0,0 +1,99 @@
+package machines
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+const (
+	systemctlCmd = "systemctl"
+	// defaultHealthChecksTimeout is used when the configuration doesn't set any.
+	defaultHealthChecksTimeout = 120 * time.Second
+	// unitPollInterval is the time between two checks of units not active yet.
+	unitPollInterval = time.Second
+	// progressInterval is the time between two logs while waiting for health checks.
+	progressInterval = 10 * time.Second
+)
+
+// HealthChecker runs the health checks deciding if a trial boot succeeded.
+type HealthChecker func(ctx context.Context, checks config.HealthChecks) error
+
+// runHealthChecks waits for all units to be active, then runs all scripts, which need to exit with 0.
+// Everything needs to succeed before checks timeout.
+func runHealthChecks(ctx context.Context, checks config.HealthChecks) error {
+	timeout := defaultHealthChecksTimeout
+	if checks.Timeout > 0 {
+		timeout = time.Duration(checks.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, unit := range checks.Units {
+		log.Debugf(ctx, "Waiting for unit %s to be active", unit)
+		lastProgress := time.Now()
+		for {
+			out, _ := exec.CommandContext(ctx, systemctlCmd, "is-active", unit).Output()
+			state := strings.TrimSpace(string(out))
+			if state == "active" {
+				break
+			}
+			if state == "failed" {
+				return fmt.Errorf(i18n.G("unit %s failed"), unit)
+			}
+
+			// Any log keeps the client waiting for us alive.
+			if time.Since(lastProgress) >= progressInterval {
+				log.Debugf(ctx, "Unit %s is %s", unit, state)
+				lastProgress = time.Now()
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf(i18n.G("unit %s isn't active after %s: %s"), unit, timeout, state)
+			case <-time.After(unitPollInterval):
+			}
+		}
+	}
+
+	for _, script := range checks.Scripts {
+		log.Debugf(ctx, "Running health check %s", script)
+		cmd := exec.CommandContext(ctx, script)
+		// Output is logged once the script exited, as logs can't be sent concurrently.
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf(i18n.G("couldn't run health check %q: ")+config.ErrorFormat, script, err)
+		}
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		ticker := time.NewTicker(progressInterval)
+		var err error
+	wait:
+		for {
+			select {
+			case err = <-done:
+				break wait
+			case <-ticker.C:
+				log.Debugf(ctx, "Health check %s is still running", script)
+			}
+		}
+		ticker.Stop()
+		if out.Len() > 0 {
+			log.Debug(ctx, out.String())
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.G("health check %q failed: ")+config.ErrorFormat, script, err)
+		}
+	}
+
+	return nil
+}
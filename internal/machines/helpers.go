@@ -211,3 +211,8 @@ func userFromDatasetName(n string) string {
 func isUserDataset(path string) bool {
 	return strings.Contains(strings.ToLower(path), userdatasetsContainerName)
 }
+
+// isBootDataset returns if d is a boot dataset, to attach to the system states.
+func isBootDataset(d *zfs.Dataset) bool {
+	return strings.Contains(strings.ToLower(d.Name), bootdatasetsContainerName) && strings.HasPrefix(d.Mountpoint, "/boot")
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/ubuntu/zsys/internal/config"
 	"github.com/ubuntu/zsys/internal/testutils"
 	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
 	"github.com/ubuntu/zsys/internal/zfs/libzfs/mock"
 )
 
@@ -512,6 +513,7 @@ func TestSelectStatesToRemove(t *testing.T) {
 		samples       int     // Number of slots in the bucket
 		statesToKeep  []int64 // List of seconds from startOfTime
 		statesToPlace []int64 // List of seconds from startOfTime
+		statesPinned  []int64 // List of seconds from startOfTime
 		timeOffset    int64   // Seconds since epoch
 
 		wantStates []string
@@ -534,6 +536,11 @@ func TestSelectStatesToRemove(t *testing.T) {
 		"same timestamp - keep one":  {samples: 4, statesToKeep: []int64{1, 3, 5}, statesToPlace: []int64{2, 2, 4}, wantStates: []string{"p1", "p2"}},
 		"same timestamp - keep none": {samples: 3, statesToKeep: []int64{1, 3, 5}, statesToPlace: []int64{2, 2, 4}, wantStates: []string{"p0", "p1", "p2"}},
 		"same timestamp - keep all":  {samples: 6, statesToKeep: []int64{1, 3, 5}, statesToPlace: []int64{2, 2, 4}, wantStates: nil},
+
+		// pinned states
+		"pinned states don't take any slot":         {samples: 2, statesPinned: []int64{1, 2, 3}, statesToPlace: []int64{1, 2, 4}, wantStates: []string{"p1"}},
+		"pinned states are kept in a full bucket":   {samples: 3, statesToKeep: []int64{1, 3, 5}, statesPinned: []int64{2}, statesToPlace: []int64{4}, wantStates: []string{"p0"}},
+		"pinned states are kept in an empty bucket": {samples: 0, statesPinned: []int64{2, 4}, wantStates: nil},
 	}
 	for name, tc := range tests {
 		tc := tc
@@ -557,6 +564,14 @@ func TestSelectStatesToRemove(t *testing.T) {
 				}
 				states = append(states, stateWithKeep{State: &s, keep: keepUnknown})
 			}
+			for i, s := range tc.statesPinned {
+				lu := time.Unix(tc.timeOffset+s, 0)
+				s := State{
+					ID:       "x" + strconv.Itoa(i), // Unique index
+					LastUsed: lu,
+				}
+				states = append(states, stateWithKeep{State: &s, keep: keepPinned})
+			}
 
 			got := selectStatesToRemove(context.Background(), tc.samples, states)
 			assertStatesToKeepMatch(t, tc.wantStates, got)
@@ -564,6 +579,141 @@ func TestSelectStatesToRemove(t *testing.T) {
 	}
 }
 
+func TestPackageChangesSummary(t *testing.T) {
+	t.Parallel()
+	var many []string
+	for i := 0; i < 1000; i++ {
+		many = append(many, "package-"+strconv.Itoa(i))
+	}
+
+	tests := map[string]struct {
+		changes PackageChanges
+
+		want string
+	}{
+		"Upgrade one package":           {changes: PackageChanges{Upgraded: []string{"firefox"}}, want: "before upgrading firefox"},
+		"Upgrade a few packages":        {changes: PackageChanges{Upgraded: []string{"linux-image", "firefox", "a", "b"}}, want: "before upgrading linux-image, firefox (+2)"},
+		"Install, upgrade and remove":   {changes: PackageChanges{Installed: []string{"a", "b", "c"}, Upgraded: []string{"d"}, Removed: []string{"e"}}, want: "before upgrading d, installing a, b, removing e (+1)"},
+		"Only remove packages":          {changes: PackageChanges{Removed: []string{"gedit"}}, want: "before removing gedit"},
+		"Truncated list keeps count":    {changes: PackageChanges{Upgraded: many}, want: "before upgrading package-0, package-1 (+998)"},
+		"Truncated list with next list": {changes: PackageChanges{Upgraded: many, Removed: []string{"gedit"}}, want: "before upgrading package-0, package-1, removing gedit (+998)"},
+		"No changes":                    {},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded := tc.changes.encode()
+			if len(encoded) > maxPackageChangesLen {
+				t.Errorf("encoded package changes is too long: %d > %d", len(encoded), maxPackageChangesLen)
+			}
+
+			got := decodePackageChanges(encoded).summary()
+			assert.Equal(t, tc.want, got, "summary doesn't match")
+		})
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		snapshot   string
+		dissociate string
+		cancel     bool
+		scanErr    bool
+
+		wantIncremental bool
+		wantErr         bool
+	}{
+		"No change":                                    {wantIncremental: true},
+		"Snapshot is applied incrementally":            {snapshot: "rpool/ROOT/ubuntu_1234@snapnew", wantIncremental: true},
+		"Structural change reloads machines":           {dissociate: "rpool/USERDATA/root_bcde"},
+		"Cancelled snapshot is removed incrementally":  {snapshot: "rpool/ROOT/ubuntu_1234@snapnew", cancel: true, wantIncremental: true},
+		"Cancelled structural change reloads machines": {dissociate: "rpool/USERDATA/root_bcde", cancel: true},
+
+		// Only reloads rescan the datasets
+		"Scan errors are ignored on incremental changes": {snapshot: "rpool/ROOT/ubuntu_1234@snapnew", scanErr: true, wantIncremental: true},
+		"Scan fails on reload":                           {dissociate: "rpool/USERDATA/root_bcde", scanErr: true, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			adapter := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "m_with_userdata.yaml"), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+
+			lzfs := adapter.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+
+			cmdline := "root=ZFS=rpool/ROOT/ubuntu_1234"
+			ms, err := New(context.Background(), cmdline, WithLibZFS(adapter))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+			m := ms.all["rpool/ROOT/ubuntu_1234"]
+
+			trans, cancel := ms.z.NewTransaction(context.Background())
+			if tc.snapshot != "" {
+				base, snapshot := splitSnapshotName(tc.snapshot)
+				if err := trans.Snapshot(snapshot, base, true); err != nil {
+					t.Fatalf("couldn't setup testbed when snapshotting: %v", err)
+				}
+			}
+			if tc.dissociate != "" {
+				if err := trans.SetProperty(libzfs.BootfsDatasetsProp, "", tc.dissociate, false); err != nil {
+					t.Fatalf("couldn't setup testbed when dissociating %q: %v", tc.dissociate, err)
+				}
+			}
+			if tc.cancel {
+				// Changes applied before the transaction is cancelled are reverted on the machines too
+				if err := ms.applyChanges(context.Background()); err != nil {
+					t.Fatalf("couldn't setup testbed when applying changes: %v", err)
+				}
+				m = ms.all["rpool/ROOT/ubuntu_1234"]
+				cancel()
+			}
+			trans.Done()
+
+			lzfs.ErrOnScan(tc.scanErr)
+
+			err = ms.applyChanges(context.Background())
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.wantIncremental {
+				assert.Same(t, m, ms.all["rpool/ROOT/ubuntu_1234"], "machine should be updated in place")
+			} else {
+				assert.NotSame(t, m, ms.all["rpool/ROOT/ubuntu_1234"], "machines should be reloaded")
+			}
+			if tc.cancel {
+				assertMachinesEquals(t, initMachines, ms)
+			}
+
+			lzfs.ErrOnScan(false)
+			machinesAfterRescan, err := New(context.Background(), cmdline, WithLibZFS(adapter))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
 func assertStatesToKeepMatch(t *testing.T, want []string, got []*State) {
 	var gotIDs []string
 
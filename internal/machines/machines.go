@@ -33,9 +33,12 @@ type Machines struct {
 	// cantmount noauto or off datasets, which are not system, users or persistent
 	unmanagedDatasets []*zfs.Dataset
 
-	z    *zfs.Zfs
-	conf config.ZConfig
-	time Nower
+	z            *zfs.Zfs
+	conf         config.ZConfig
+	time         Nower
+	healthCheck  HealthChecker
+	stopServices ServicesStopper
+	mount        Mounter
 }
 
 // Machine is a group of Main and its History children states
@@ -93,9 +96,12 @@ func WithConfig(path string) func(o *options) error {
 }
 
 type options struct {
-	configPath string
-	libzfs     libzfs.Interface
-	time       Nower
+	configPath   string
+	libzfs       libzfs.Interface
+	time         Nower
+	healthCheck  HealthChecker
+	stopServices ServicesStopper
+	mount        Mounter
 }
 
 type option func(*options) error
@@ -115,9 +121,12 @@ func (timeAdapter) Now() time.Time {
 func New(ctx context.Context, cmdline string, opts ...option) (Machines, error) {
 	log.Info(ctx, i18n.G("Building new machines list"))
 	args := options{
-		configPath: config.DefaultPath,
-		libzfs:     &libzfs.Adapter{},
-		time:       timeAdapter{},
+		configPath:   config.DefaultPath,
+		libzfs:       &libzfs.Adapter{},
+		time:         timeAdapter{},
+		healthCheck:  runHealthChecks,
+		stopServices: stopServicesUsing,
+		mount:        syscallMounter{},
 	}
 	for _, o := range opts {
 		if err := o(&args); err != nil {
@@ -136,11 +145,14 @@ func New(ctx context.Context, cmdline string, opts ...option) (Machines, error)
 	}
 
 	machines := Machines{
-		all:     make(map[string]*Machine),
-		cmdline: cmdline,
-		z:       z,
-		conf:    conf,
-		time:    args.time,
+		all:          make(map[string]*Machine),
+		cmdline:      cmdline,
+		z:            z,
+		conf:         conf,
+		time:         args.time,
+		healthCheck:  args.healthCheck,
+		stopServices: args.stopServices,
+		mount:        args.mount,
 	}
 	machines.refresh(ctx)
 	return machines, nil
@@ -158,16 +170,29 @@ func (ms *Machines) Refresh(ctx context.Context) error {
 
 // refresh reloads the list of machines, based on already loaded zfs datasets state
 func (ms *Machines) refresh(ctx context.Context) {
+	// All changes are taken into account by rebuilding the machines.
+	ms.z.TakeChanges()
+
 	machines := Machines{
-		all:     make(map[string]*Machine),
-		cmdline: ms.cmdline,
-		z:       ms.z,
-		conf:    ms.conf,
-		time:    ms.time,
+		all:          make(map[string]*Machine),
+		cmdline:      ms.cmdline,
+		z:            ms.z,
+		conf:         ms.conf,
+		time:         ms.time,
+		healthCheck:  ms.healthCheck,
+		stopServices: ms.stopServices,
+		mount:        ms.mount,
+	}
+
+	// Replicated datasets are backups of other datasets and don’t belong to any machine.
+	var datasets []*zfs.Dataset
+	for _, d := range machines.z.Datasets() {
+		if machines.isReplicatedDataset(d.Name) {
+			continue
+		}
+		datasets = append(datasets, d)
 	}
 
-	datasets := machines.z.Datasets()
-
 	// Sort datasets so that children datasets are after their parents.
 	sortedDataset := sortedDataset(datasets)
 	sort.Sort(sortedDataset)
@@ -395,7 +420,7 @@ func (ms *Machines) refresh(ctx context.Context) {
 	machines.allPersistentDatasets = persistents
 	machines.unmanagedDatasets = unmanagedDatasets
 
-	root, _ := bootParametersFromCmdline(machines.cmdline)
+	root, _ := machines.bootParameters()
 	m, _ := machines.findFromRoot(root)
 	machines.current = m
 
@@ -434,7 +459,7 @@ func (ms *Machines) populate(ctx context.Context, allDatasets []*zfs.Dataset, or
 
 		// Extract boot datasets if any. We can't attach them directly with machines as if they are on another pool:
 		// the machine will not necessiraly loaded yet.
-		if strings.Contains(strings.ToLower(d.Name), bootdatasetsContainerName) && strings.HasPrefix(d.Mountpoint, "/boot") {
+		if isBootDataset(d) {
 			boots = append(boots, d)
 			continue
 		}
@@ -500,17 +525,7 @@ func (ms *Machines) populateSystemAndHistory(ctx context.Context, d *zfs.Dataset
 
 		// Clones or snapshot root dataset (origins points to origin dataset)
 		if d.Mountpoint == "/" && d.CanMount != "off" && origin != nil && *origin == m.ID {
-			s := &State{
-				ID:       d.Name,
-				Datasets: make(map[string][]*zfs.Dataset),
-				Users:    make(map[string]*State),
-			}
-			s.Datasets[d.Name] = []*zfs.Dataset{d}
-			m.History[d.Name] = s
-			// We don't want lastused to be 1970 in our golden files
-			if d.LastUsed != 0 {
-				m.History[d.Name].LastUsed = time.Unix(int64(d.LastUsed), 0)
-			}
+			m.History[d.Name] = newHistoryState(d)
 			return true
 		}
 
@@ -528,6 +543,21 @@ func (ms *Machines) populateSystemAndHistory(ctx context.Context, d *zfs.Dataset
 	return false
 }
 
+// newHistoryState returns a new state for d, root dataset of a clone or a snapshot of a machine.
+func newHistoryState(d *zfs.Dataset) *State {
+	s := &State{
+		ID:       d.Name,
+		Datasets: make(map[string][]*zfs.Dataset),
+		Users:    make(map[string]*State),
+	}
+	s.Datasets[d.Name] = []*zfs.Dataset{d}
+	// We don't want lastused to be 1970 in our golden files
+	if d.LastUsed != 0 {
+		s.LastUsed = time.Unix(int64(d.LastUsed), 0)
+	}
+	return s
+}
+
 // addUserState creates and attach a new user state to the machine users map.
 // It returns the username and the created state
 func (m *Machine) addUserState(ctx context.Context, systemStateID string, r *zfs.Dataset, children []*zfs.Dataset) (string, *State) {
@@ -690,14 +720,15 @@ func (ms Machines) GetMachine(ID string) (*Machine, error) {
 }
 
 // Info returns detailed machine informations.
-func (m Machine) Info(full bool) (string, error) {
+// If tagFilters isn't empty, only history and user states having all those tags are listed.
+func (m Machine) Info(full bool, tagFilters map[string]string) (string, error) {
 	var out bytes.Buffer
 	w := tabwriter.NewWriter(&out, 0, 0, 1, ' ', 0)
 	fmt.Fprintf(w, i18n.G("Name:\t%s\n"), m.ID)
 	fmt.Fprintf(w, i18n.G("ZSys:\t%t\n"), m.isZsys())
 
 	// Main machine state
-	m.toWriter(w, false, full)
+	m.toWriter(w, false, full, m.stateUsage(&m.State))
 
 	if full {
 		if len(m.PersistentDatasets) == 0 {
@@ -716,6 +747,9 @@ func (m Machine) Info(full bool) (string, error) {
 	}
 	timeToState := make(map[string]*State)
 	for id, s := range m.History {
+		if !s.matchesAllTags(tagFilters) {
+			continue
+		}
 		k := fmt.Sprintf("%010d_%s", s.LastUsed.Unix(), id)
 		timeToState[k] = s
 	}
@@ -726,7 +760,7 @@ func (m Machine) Info(full bool) (string, error) {
 	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
 
 	for _, k := range keys {
-		timeToState[k].toWriter(w, true, full)
+		timeToState[k].toWriter(w, true, full, m.stateUsage(timeToState[k]))
 	}
 
 	// Users
@@ -754,6 +788,9 @@ func (m Machine) Info(full bool) (string, error) {
 					continue nextUserState
 				}
 			}
+			if !s.matchesAllTags(tagFilters) {
+				continue
+			}
 
 			k := fmt.Sprintf("%010d_%s", s.LastUsed.Unix(), id)
 			timeToState[k] = s
@@ -770,6 +807,16 @@ func (m Machine) Info(full bool) (string, error) {
 			// We can’t use s.ID here because some user states can be duplicated (user state attached to 2 system states)
 			// and we want to display the unique generated id to the user as it’s what should be used in RemoveState()
 			uid := strings.SplitN(k, "_", 2)[1]
+			if tags := s.tags(); len(tags) > 0 {
+				uid = fmt.Sprintf("%s [%s]", uid, formatTags(tags))
+			}
+			if s.isPinned() {
+				uid = fmt.Sprintf(i18n.G("%s (pinned)"), uid)
+			}
+			lu := s.LastUsed.Format("2006-01-02 15:04:05")
+			if u := m.stateUsage(s); !u.isEmpty() {
+				lu = fmt.Sprintf(i18n.G("%s, %s"), lu, u)
+			}
 			if full {
 				var ud []string
 				for _, ds := range s.Datasets {
@@ -777,10 +824,10 @@ func (m Machine) Info(full bool) (string, error) {
 						ud = append(ud, d.Name)
 					}
 				}
-				fmt.Fprintf(w, i18n.G("     - %s (%s): %s\n"), uid, s.LastUsed.Format("2006-01-02 15:04:05"), strings.Join(ud, ", "))
+				fmt.Fprintf(w, i18n.G("     - %s (%s): %s\n"), uid, lu, strings.Join(ud, ", "))
 				continue
 			}
-			fmt.Fprintf(w, i18n.G("     - %s (%s)\n"), uid, s.LastUsed.Format("2006-01-02 15:04:05"))
+			fmt.Fprintf(w, i18n.G("     - %s (%s)\n"), uid, lu)
 		}
 	}
 	if err := w.Flush(); err != nil {
@@ -805,8 +852,8 @@ func sortedDatasetNames(datasets map[string][]*zfs.Dataset) (dNames []string) {
 	return dNames
 }
 
-// toWriter forwards dataset state to a writer
-func (s State) toWriter(w io.Writer, isHistory, full bool) {
+// toWriter forwards dataset state and its disk usage to a writer
+func (s State) toWriter(w io.Writer, isHistory, full bool, u Usage) {
 	var prefix string
 	if isHistory {
 		fmt.Fprintf(w, i18n.G("  - Name:\t%s\n"), s.ID)
@@ -823,6 +870,35 @@ func (s State) toWriter(w io.Writer, isHistory, full bool) {
 		fmt.Fprintf(w, i18n.G("%sLast Used:\t%s\n"), prefix, lu)
 	} else {
 		fmt.Fprintf(w, i18n.G("%sCreated on:\t%s\n"), prefix, lu)
+		if changes := s.Datasets[s.ID][0].PackageChanges; changes != "" {
+			fmt.Fprintf(w, i18n.G("%sPackage changes:\t%s\n"), prefix, decodePackageChanges(changes).summary())
+		}
+	}
+	if s.isPinned() {
+		fmt.Fprintf(w, i18n.G("%sPinned:\tyes\n"), prefix)
+	}
+	if tr, ok := s.trial(); ok {
+		if tr.failed {
+			fmt.Fprintf(w, i18n.G("%sTrial:\tfailed, falls back to %s\n"), prefix, tr.fallback)
+		} else {
+			fmt.Fprintf(w, i18n.G("%sTrial:\t%d boots, falls back to %s\n"), prefix, tr.boots, tr.fallback)
+		}
+	}
+	if bn, ok := s.bootNext(); ok && !bn.booting {
+		if bn.revertUserData {
+			fmt.Fprintf(w, i18n.G("%sNext boot:\t%s, reverting user data\n"), prefix, bn.state)
+		} else {
+			fmt.Fprintf(w, i18n.G("%sNext boot:\t%s\n"), prefix, bn.state)
+		}
+	}
+	if d := s.description(); d != "" {
+		fmt.Fprintf(w, i18n.G("%sDescription:\t%s\n"), prefix, d)
+	}
+	if tags := s.tags(); len(tags) > 0 {
+		fmt.Fprintf(w, i18n.G("%sTags:\t%s\n"), prefix, formatTags(tags))
+	}
+	if !u.isEmpty() {
+		fmt.Fprintf(w, i18n.G("%sDisk Usage:\t%s\n"), prefix, u)
 	}
 
 	if full {
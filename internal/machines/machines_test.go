@@ -1,16 +1,25 @@
 package machines_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/k0kubun/pp"
 	"github.com/stretchr/testify/assert"
+	"github.com/ubuntu/zsys/internal/bootmenu"
 	"github.com/ubuntu/zsys/internal/config"
 	"github.com/ubuntu/zsys/internal/machines"
 	"github.com/ubuntu/zsys/internal/testutils"
@@ -225,6 +234,9 @@ func TestBoot(t *testing.T) {
 		"Reverting userdata, with children": {def: "m_clone_with_userdata_with_children_reverting.yaml",
 			cmdline:        generateCmdLineWithRevert("rpool/ROOT/ubuntu_1234@snap1"),
 			mountedDataset: "rpool/ROOT/ubuntu_4242"},
+		"Reverting userdata, keep locked encrypted user data": {def: "m_clone_with_encrypted_userdata_reverting.yaml",
+			cmdline:        generateCmdLineWithRevert("rpool/ROOT/ubuntu_1234@snap1"),
+			mountedDataset: "rpool/ROOT/ubuntu_4242"},
 
 		// Booting on snapshot on real machines
 		"Desktop revert on snapshot": {def: "m_layout1_machines_with_snapshots_clones_reverting.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_5678@snap3"), mountedDataset: "rpool/ROOT/ubuntu_4242"},
@@ -236,6 +248,15 @@ func TestBoot(t *testing.T) {
 			cmdline:        generateCmdLineWithRevert("rpool/ROOT/ubuntu_5678@snap3"),
 			mountedDataset: "rpool/ROOT/ubuntu_4242"},
 
+		// Booting once on a state redirected by the initramfs
+		"Boot next on snapshot": {def: "m_layout1_machines_with_snapshots_clones_reverting_boot_next.yaml",
+			cmdline:        generateCmdLine("rpool/ROOT/ubuntu_1234"),
+			mountedDataset: "rpool/ROOT/ubuntu_4242"},
+		"Boot next on snapshot with userdata revert": {def: "m_layout1_machines_with_snapshots_clones_reverting_boot_next_userdata.yaml",
+			cmdline:        generateCmdLine("rpool/ROOT/ubuntu_1234"),
+			mountedDataset: "rpool/ROOT/ubuntu_4242"},
+		"Boot next not redirected by the initramfs is ignored": {def: "m_snapshot_with_userdata_boot_next.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_1234"), isNoOp: true},
+
 		// Error cases
 		"No booted state found does nothing":       {def: "m_layout1_machines_with_snapshots_clones_reverting.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_5678@snap3"), isNoOp: true},
 		"SetProperty fails":                        {def: "m_layout1_machines_with_snapshots_clones_reverting.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_5678@snap3"), mountedDataset: "rpool/ROOT/ubuntu_4242", setPropertyErr: true, wantErr: true},
@@ -410,8 +431,9 @@ func TestIdempotentBootSnapshotBeforeCommit(t *testing.T) {
 func TestCommit(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def     string
-		cmdline string
+		def            string
+		cmdline        string
+		mountedDataset string
 
 		scanErr        bool
 		setPropertyErr bool
@@ -451,12 +473,24 @@ func TestCommit(t *testing.T) {
 		"Server without user revert":  {def: "m_layout2_machines_with_snapshots_clones_no_user_revert.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_9876")},
 		"Server with user revert":     {def: "m_layout2_machines_with_snapshots_clones_user_revert.yaml", cmdline: generateCmdLineWithRevert("rpool/ROOT/ubuntu_9876")},
 
+		// Encrypted user data
+		"Encrypted user data, user revert on snapshot": {def: "m_clone_with_encrypted_userdata_to_promote_user_revert.yaml",
+			cmdline:        generateCmdLineWithRevert("rpool/ROOT/ubuntu_1234@snap1"),
+			mountedDataset: "rpool/ROOT/ubuntu_4242"},
+
+		// Booting once on a state redirected by the initramfs
+		"Boot next without user revert": {def: "m_layout1_machines_with_snapshots_clones_no_user_revert_boot_next.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_1234")},
+		"Boot next with user revert":    {def: "m_layout1_machines_with_snapshots_clones_user_revert_boot_next.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_1234")},
+
 		// Error cases
 		"SetProperty fails (first)":  {def: "m_clone_with_userdata_with_children_to_promote_no_user_revert.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_5678"), setPropertyErr: true, wantErr: true},
 		"SetProperty fails (second)": {def: "m_clone_with_userdata_to_promote_no_user_revert.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_5678"), setPropertyErr: true, wantErr: true},
 		"Promote fails":              {def: "d_one_machine_with_clone_dataset.yaml", cmdline: generateCmdLine("rpool/clone"), promoteErr: true, wantErr: true},
 		"Promote userdata fails":     {def: "m_clone_with_userdata_to_promote_user_revert.yaml", cmdline: generateCmdLineWithRevert("rpool/ROOT/ubuntu_5678"), promoteErr: true, wantErr: true},
-		"Scan fails":                 {def: "d_one_machine_with_clone_dataset.yaml", cmdline: generateCmdLine("rpool/main"), scanErr: true, wantErr: true},
+		"Scan fails":                 {def: "d_one_machine_with_clone_dataset.yaml", cmdline: generateCmdLine("rpool/clone"), scanErr: true, wantErr: true},
+
+		// Last used and kernel changes are applied without a final rescan
+		"Final scan isn't needed without promotion": {def: "d_one_machine_with_clone_dataset.yaml", cmdline: generateCmdLine("rpool/main"), scanErr: true},
 	}
 	for name, tc := range tests {
 		tc := tc
@@ -468,11 +502,15 @@ func TestCommit(t *testing.T) {
 			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
+			lzfs := libzfs.(*mock.LibZFS)
+			if tc.mountedDataset != "" {
+				lzfs.SetDatasetAsMounted(tc.mountedDataset, true)
+			}
+
 			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
-			lzfs := libzfs.(*mock.LibZFS)
 
 			lzfs.ErrOnScan(tc.scanErr)
 			lzfs.ErrOnSetProperty(tc.setPropertyErr)
@@ -499,6 +537,7 @@ func TestCommit(t *testing.T) {
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
 
+			lzfs.ErrOnScan(false)
 			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
@@ -508,6 +547,125 @@ func TestCommit(t *testing.T) {
 	}
 }
 
+func TestBootEntries(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def     string
+		cmdline string
+	}{
+		"One machine with snapshots":                {def: "m_snapshot_with_userdata.yaml"},
+		"Machines with snapshots and clones":        {def: "m_layout1_machines_with_snapshots_clones.yaml"},
+		"Non zsys machines are excluded":            {def: "d_two_machines_one_zsys_one_non_zsys.yaml"},
+		"Fallback of a failed trial is the default": {def: "m_snapshot_with_userdata_trial_failed.yaml"},
+		"No machine": {def: "d_no_machine.yaml"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			if tc.cmdline == "" {
+				tc.cmdline = generateCmdLine("rpool/ROOT/ubuntu_1234")
+			}
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			got := ms.BootEntries()
+			var want []bootmenu.Entry
+			testutils.LoadFromGoldenFile(t, got, &want)
+			if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Boot entries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMountKernels(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		mounted     []string
+		failMountOn string
+	}{
+		"Read kernels of mounted datasets in place": {mounted: []string{"bpool/BOOT/ubuntu_1234"}},
+		"Mount kernels of unmounted datasets":       {},
+		"Skip states failing to mount":              {failMountOn: "bpool/BOOT/ubuntu_5678"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "m_layout1_machines_with_snapshots_clones.yaml"), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			lzfs := libzfs.(*mock.LibZFS)
+			for _, n := range tc.mounted {
+				lzfs.SetDatasetAsMounted(n, true)
+			}
+
+			mounter := &mounterMock{failOn: tc.failMountOn}
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs),
+				machines.WithMounter(mounter))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			entries, unmount := ms.BootEntriesWithKernels(context.Background())
+
+			// Temporary mountpoints are random.
+			tmpDirs := make(map[string]bool)
+			tmpPrefix := filepath.Join(os.TempDir(), "zsys-kernels-")
+			normalize := func(s string) string {
+				fields := strings.Fields(s)
+				for i, f := range fields {
+					if strings.HasPrefix(f, tmpPrefix) {
+						tmpDirs[f] = true
+						fields[i] = "<tmp>"
+					}
+				}
+				return strings.Join(fields, " ")
+			}
+			got := make(map[string]string)
+			for _, e := range entries {
+				if !e.History {
+					assert.Empty(t, e.KernelDir, "kernels of current states aren't read")
+					continue
+				}
+				got[e.ID] = normalize(e.KernelDir)
+			}
+			unmount()
+			var gotCalls []string
+			for _, c := range mounter.calls {
+				gotCalls = append(gotCalls, normalize(c))
+			}
+			for d := range tmpDirs {
+				_, err := os.Stat(d)
+				assert.True(t, os.IsNotExist(err), "temporary mountpoint %s should be removed", d)
+			}
+
+			want := struct {
+				KernelDirs map[string]string
+				Mounts     []string
+			}{}
+			testutils.LoadFromGoldenFile(t, struct {
+				KernelDirs map[string]string
+				Mounts     []string
+			}{got, gotCalls}, &want)
+			assert.Equal(t, want.KernelDirs, got, "unexpected kernel directories")
+			assert.Equal(t, want.Mounts, gotCalls, "unexpected mounts and unmounts")
+		})
+	}
+}
+
 func TestIdempotentCommit(t *testing.T) {
 	t.Parallel()
 	dir, cleanup := testutils.TempDir(t)
@@ -543,6 +701,164 @@ func TestIdempotentCommit(t *testing.T) {
 	assertMachinesEquals(t, msAfterCommit, ms)
 }
 
+func TestTrialBoot(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def            string
+		configPath     string
+		healthCheckErr bool
+
+		wantHealthChecks bool
+		wantTrial        string
+		wantErr          bool
+		wantExhausted    bool
+	}{
+		"Not on trial":                               {def: "m_snapshot_with_userdata.yaml", healthCheckErr: true},
+		"Trial boot passing health checks":           {def: "m_snapshot_with_userdata_trial_not_booted.yaml", wantHealthChecks: true},
+		"Trial boot failing health checks":           {def: "m_snapshot_with_userdata_trial_not_booted.yaml", healthCheckErr: true, wantHealthChecks: true, wantTrial: "1:rpool/ROOT/ubuntu_1234@snap1", wantErr: true},
+		"Last trial boot passing health checks":      {def: "m_snapshot_with_userdata_trial_booted_once.yaml", wantHealthChecks: true},
+		"Last trial boot failing health checks":      {def: "m_snapshot_with_userdata_trial_booted_once.yaml", healthCheckErr: true, wantHealthChecks: true, wantTrial: "failed:rpool/ROOT/ubuntu_1234@snap1", wantErr: true, wantExhausted: true},
+		"Boot after all trial boots failed":          {def: "m_snapshot_with_userdata_trial_exhausted.yaml", healthCheckErr: true, wantHealthChecks: true, wantTrial: "failed:rpool/ROOT/ubuntu_1234@snap1", wantErr: true, wantExhausted: true},
+		"Boot after all trial boots, recovered":      {def: "m_snapshot_with_userdata_trial_exhausted.yaml", wantHealthChecks: true},
+		"Failed trial failing health checks":         {def: "m_snapshot_with_userdata_trial_failed.yaml", healthCheckErr: true, wantHealthChecks: true, wantTrial: "failed:rpool/ROOT/ubuntu_1234@snap1", wantErr: true, wantExhausted: true},
+		"Failed trial passing health checks":         {def: "m_snapshot_with_userdata_trial_failed.yaml", wantHealthChecks: true},
+		"Trial boots disabled ends trial, no checks": {def: "m_snapshot_with_userdata_trial_booted_once.yaml", configPath: "default.conf", healthCheckErr: true},
+		"Trial boots disabled ends failed trial":     {def: "m_snapshot_with_userdata_trial_failed.yaml", configPath: "default.conf", healthCheckErr: true},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			if tc.configPath == "" {
+				tc.configPath = "trial_boots.conf"
+			}
+
+			var healthChecksRan bool
+			healthChecker := func(ctx context.Context, checks config.HealthChecks) error {
+				healthChecksRan = true
+				if tc.healthCheckErr {
+					return errors.New("unit ssh.service failed")
+				}
+				return nil
+			}
+
+			cmdline := generateCmdLine("rpool/ROOT/ubuntu_1234")
+			ms, err := machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs),
+				machines.WithConfig(filepath.Join("testdata", "confs", tc.configPath)), machines.WithHealthChecker(healthChecker))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+
+			if _, err := ms.EnsureBoot(context.Background()); err != nil {
+				t.Fatalf("expected no error on boot but got: %v", err)
+			}
+			_, err = ms.Commit(context.Background())
+
+			assert.Equal(t, tc.wantHealthChecks, healthChecksRan, "health checks ran")
+			if tc.wantErr {
+				var e *machines.ErrTrialFailed
+				assert.True(t, errors.As(err, &e), "expected ErrTrialFailed error type, got %v", err)
+				assert.Equal(t, tc.wantExhausted, e.Exhausted, "trial boots exhausted")
+			} else if err != nil {
+				t.Fatalf("expected no error on commit but got: %v", err)
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			m := machinesAfterRescan.AllMachines()["rpool/ROOT/ubuntu_1234"]
+			assert.Equal(t, tc.wantTrial, m.Datasets[m.ID][0].Trial, "trial property after boot")
+			assertMachinesToGolden(t, machinesAfterRescan)
+		})
+	}
+}
+
+func TestBootNext(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def            string
+		cmdline        string
+		name           string
+		revertUserData bool
+
+		setPropertyErr bool
+
+		isNoOp  bool
+		wantErr bool
+	}{
+		"Boot next on a system state":                    {name: "rpool/ROOT/ubuntu_1234@snap1"},
+		"Boot next on a system state reverting userdata": {name: "rpool/ROOT/ubuntu_1234@snap1", revertUserData: true},
+		"Boot next on a clone":                           {def: "m_clone_simple.yaml", name: "rpool/ROOT/ubuntu_5678"},
+		"Replace previous boot next target":              {def: "m_snapshot_with_userdata_boot_next.yaml", name: "rpool/ROOT/ubuntu_1234@snap1"},
+		"Cancel boot next with current state":            {def: "m_snapshot_with_userdata_boot_next.yaml", name: "rpool/ROOT/ubuntu_1234"},
+		"Current state without boot next target":         {name: "rpool/ROOT/ubuntu_1234", isNoOp: true},
+
+		"Error on non existing state":                {name: "doesntexist", wantErr: true},
+		"Error on state of another machine":          {def: "m_two_machines_simple.yaml", name: "rpool/ROOT/ubuntu_5678", wantErr: true},
+		"Error on non zsys machine":                  {def: "d_one_machine_one_dataset_non_zsys.yaml", cmdline: generateCmdLine("rpool"), name: "rpool", wantErr: true},
+		"Error on setting boot next target property": {name: "rpool/ROOT/ubuntu_1234@snap1", setPropertyErr: true, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "m_snapshot_with_userdata.yaml")
+			tc.cmdline = getDefaultValue(tc.cmdline, generateCmdLine("rpool/ROOT/ubuntu_1234"))
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ErrOnSetProperty(tc.setPropertyErr)
+
+			err = ms.BootNext(context.Background(), tc.name, tc.revertUserData)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+
+			assertMachinesToGolden(t, ms)
+			assertMachinesNotEquals(t, initMachines, ms)
+
+			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
 func TestUpdateLastUsed(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
@@ -617,10 +933,11 @@ func TestUpdateLastUsed(t *testing.T) {
 func TestCreateUserData(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def      string
-		user     string
-		homePath string
-		cmdline  string
+		def        string
+		user       string
+		homePath   string
+		passphrase string
+		cmdline    string
 
 		setPropertyErr bool
 		createErr      bool
@@ -634,6 +951,12 @@ func TestCreateUserData(t *testing.T) {
 		"One machine with no user, only userdata":       {def: "m_with_userdata_only.yaml"},
 		"No attached userdata":                          {def: "m_no_attached_userdata_first_pool.yaml"},
 
+		// Encryption cases
+		"Encrypted user dataset":                             {def: "m_with_userdata.yaml", passphrase: "my secret passphrase"},
+		"Encrypted user dataset without userdata":            {def: "m_without_userdata.yaml", passphrase: "my secret passphrase"},
+		"Encrypted user already exists on this machine":      {def: "m_with_userdata.yaml", user: "user1", passphrase: "my secret passphrase"},
+		"Encrypted user dataset with a too short passphrase": {def: "m_with_userdata.yaml", passphrase: "secret", wantErr: true, isNoOp: true},
+
 		// Second pool cases
 		"User dataset on other pool":                       {def: "m_with_userdata_on_other_pool.yaml"},
 		"User dataset with no user on other pool":          {def: "m_with_userdata_only_on_other_pool.yaml"},
@@ -656,7 +979,9 @@ func TestCreateUserData(t *testing.T) {
 		"Create user dataset container fails":   {def: "m_without_userdata.yaml", createErr: true, wantErr: true, isNoOp: true},
 		"System bootfs property fails":          {def: "m_with_userdata.yaml", setPropertyErr: true, wantErr: true, isNoOp: true},
 		"Scan for user dataset container fails": {def: "m_without_userdata.yaml", scanErr: true, wantErr: true, isNoOp: true},
-		"Final scan triggers error":             {def: "m_with_userdata.yaml", scanErr: true, wantErr: true, isNoOp: true},
+
+		// New user datasets are attached without a final rescan
+		"Final scan isn't needed": {def: "m_with_userdata.yaml", scanErr: true},
 	}
 
 	for name, tc := range tests {
@@ -685,7 +1010,7 @@ func TestCreateUserData(t *testing.T) {
 			lzfs.ErrOnScan(tc.scanErr)
 			lzfs.ErrOnSetProperty(tc.setPropertyErr)
 
-			err = ms.CreateUserData(context.Background(), getDefaultValue(tc.user, "userfoo"), getDefaultValue(tc.homePath, "/home/foo"))
+			err = ms.CreateUserData(context.Background(), getDefaultValue(tc.user, "userfoo"), getDefaultValue(tc.homePath, "/home/foo"), []byte(tc.passphrase))
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
@@ -703,6 +1028,7 @@ func TestCreateUserData(t *testing.T) {
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
 
+			lzfs.ErrOnScan(false)
 			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
@@ -712,53 +1038,46 @@ func TestCreateUserData(t *testing.T) {
 	}
 }
 
-func TestDissociateUser(t *testing.T) {
+func TestMountUserData(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def        string
-		user       string
-		cmdline    string
-		removehome bool
+		def            string
+		user           string
+		homePath       string
+		mountedDataset string
+		key            string
+		failMountOn    string
 
-		setPropertyErr bool
-		scanErr        bool
+		scanErr bool
 
-		wantErr bool
-		isNoOp  bool
+		wantMounts []string
+		wantErr    bool
+		isNoOp     bool
 	}{
-		"Dissociate user": {def: "m_with_userdata.yaml"},
-
-		"Dissociate user but keep snapshot attached":         {def: "m_with_userdata_dataset_and_snapshot_attached.yaml"},
-		"Dissociate user with no associated snapshot":        {def: "m_with_userdata_user_snapshot.yaml"},
-		"Dissociate user with children datasets (inherited)": {def: "m_with_userdata_children_on_user.yaml"},
-		"Dissociate user with children datasets (local)":     {def: "m_with_userdata_clone_attached.yaml"},
-		"Dissociate user having shared states":               {def: "m_two_machines_with_same_userdata.yaml"},
-
-		// TODO: really test directory cleanup? Same for set-user home
-		// check that unmounts are done in correct order (children first)
-		// Remove home use cases
-		// unmounted datasets
-		// mounted datasets without remove
-		// mounted datasets with remove
-		"Dissociate user removing home": {def: "m_with_userdata.yaml", removehome: true},
+		"Mount user dataset":                         {def: "m_with_userdata.yaml", wantMounts: []string{"mount rpool/USERDATA/user1_abcd on /home/user1"}, isNoOp: true},
+		"User dataset already mounted":               {def: "m_with_userdata.yaml", mountedDataset: "rpool/USERDATA/user1_abcd", isNoOp: true},
+		"Create user dataset when there is none":     {def: "m_with_userdata.yaml", user: "userfoo"},
+		"Create user dataset without userdata":       {def: "m_without_userdata.yaml", user: "userfoo"},
+		"Encrypted user dataset with its key loaded": {def: "m_with_encrypted_userdata.yaml", wantMounts: []string{"mount rpool/USERDATA/user1_abcd on /home/user1"}, isNoOp: true},
+		"Encrypted user dataset once its key is loaded on login": {def: "m_with_encrypted_userdata.yaml", user: "user2", key: "my secret passphrase",
+			wantMounts: []string{"mount rpool/USERDATA/user2_efgh on /home/user2"}, isNoOp: true},
 
-		"User has no state associated with current machine": {def: "m_with_userdata.yaml", user: "doesntexist", wantErr: true},
-		"Empty user name":            {def: "m_with_userdata.yaml", user: "-", wantErr: true},
-		"SetProperty fails":          {def: "m_with_userdata.yaml", setPropertyErr: true, wantErr: true},
-		"Scanning fails":             {def: "m_with_userdata.yaml", scanErr: true, wantErr: true},
-		"Current machine isn’t zsys": {def: "m_with_userdata.yaml", cmdline: "foo", wantErr: true},
+		// Error cases
+		"Encrypted user dataset with its key not loaded": {def: "m_with_encrypted_userdata.yaml", user: "user2", wantErr: true, isNoOp: true},
+		"Error on mount": {def: "m_with_userdata.yaml", failMountOn: "rpool/USERDATA/user1_abcd",
+			wantMounts: []string{"mount rpool/USERDATA/user1_abcd on /home/user1"}, wantErr: true, isNoOp: true},
+		"No user set":                        {def: "m_with_userdata.yaml", user: "[empty]", wantErr: true, isNoOp: true},
+		"No home path set to create dataset": {def: "m_with_userdata.yaml", user: "userfoo", homePath: "[empty]", wantErr: true, isNoOp: true},
+		"System not zsys":                    {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
+
+		// New user datasets are attached without a final rescan
+		"Final scan isn't needed when creating user dataset": {def: "m_with_userdata.yaml", user: "userfoo", scanErr: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			tc.cmdline = getDefaultValue(tc.cmdline, generateCmdLine("rpool/ROOT/ubuntu_1234"))
-			if tc.user == "-" {
-				tc.user = ""
-			} else {
-				tc.user = getDefaultValue(tc.user, "user1")
-			}
 
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
@@ -768,22 +1087,33 @@ func TestDissociateUser(t *testing.T) {
 
 			lzfs := libzfs.(*mock.LibZFS)
 			lzfs.ForceLastUsedTime(true)
+			if tc.mountedDataset != "" {
+				lzfs.SetDatasetAsMounted(tc.mountedDataset, true)
+			}
 
-			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			mounter := &mounterMock{failOn: tc.failMountOn}
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs),
+				machines.WithMounter(mounter))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
+			if tc.key != "" {
+				if err := ms.LoadUserKeys(context.Background(), tc.user, []byte(tc.key)); err != nil {
+					t.Fatalf("setup failed: couldn't load user keys: %v", err)
+				}
+			}
 
 			initMachines := ms.CopyForTests(t)
 
 			lzfs.ErrOnScan(tc.scanErr)
-			lzfs.ErrOnSetProperty(tc.setPropertyErr)
 
-			err = ms.DissociateUser(context.Background(), tc.user, tc.removehome)
+			err = ms.MountUserData(context.Background(), getDefaultValue(tc.user, "user1"), getDefaultValue(tc.homePath, "/home/foo"))
+			assert.Equal(t, tc.wantMounts, mounter.calls, "unexpected mounts")
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
 				}
+				assertMachinesEquals(t, initMachines, ms)
 				return
 			}
 			if err == nil && tc.wantErr {
@@ -796,60 +1126,87 @@ func TestDissociateUser(t *testing.T) {
 				assertMachinesToGolden(t, ms)
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
-
-			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
-			if err != nil {
-				t.Error("expected success but got an error scanning for machines", err)
-			}
-			assertMachinesEquals(t, machinesAfterRescan, ms)
 		})
 	}
 }
 
-func TestCurrentIsZsys(t *testing.T) {
+func TestLoadUserKeys(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		cmdline string
+		def        string
+		user       string
+		passphrase string
 
-		wantIsZsys bool
+		wantErr bool
+		isNoOp  bool
 	}{
-		"Zsys machine is current":         {cmdline: generateCmdLine("rpool"), wantIsZsys: true},
-		"Zfs non zsys machine is current": {cmdline: generateCmdLine("rpool2"), wantIsZsys: false},
-		"No current machine":              {cmdline: generateCmdLine("something that doesn’t match"), wantIsZsys: false},
+		"Load user key":                           {def: "m_with_encrypted_userdata.yaml"},
+		"User key already loaded":                 {def: "m_with_encrypted_userdata.yaml", user: "user1", isNoOp: true},
+		"User without encrypted user data":        {def: "m_with_userdata.yaml", user: "user1", isNoOp: true},
+		"User without any user data":              {def: "m_with_encrypted_userdata.yaml", user: "userfoo", isNoOp: true},
+		"User key loaded with a wrong passphrase": {def: "m_with_encrypted_userdata.yaml", passphrase: "not my passphrase", wantErr: true, isNoOp: true},
+
+		// Error cases
+		"No user set":     {def: "m_with_encrypted_userdata.yaml", user: "[empty]", wantErr: true, isNoOp: true},
+		"System not zsys": {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
-
 			libzfs := testutils.GetMockZFS(t)
-			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "d_two_machines_one_zsys_one_non_zsys.yaml"), testutils.WithLibZFS(libzfs))
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			/*if tc.mountedDataset != "" {
-				lzfs := libzfs.(*mock.LibZFS)
-				lzfs.SetDatasetAsMounted(tc.mountedDataset, true)
-			}*/
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
 
-			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
 
-			assert.Equal(t, tc.wantIsZsys, ms.CurrentIsZsys(), "Expected current is zsys returned value")
+			initMachines := ms.CopyForTests(t)
+
+			err = ms.LoadUserKeys(context.Background(), getDefaultValue(tc.user, "user2"), []byte(getDefaultValue(tc.passphrase, "my secret passphrase")))
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
 		})
 	}
 }
 
-func TestChangeHomeOnUserData(t *testing.T) {
+func TestDissociateUser(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def     string
-		home    string
-		newHome string
+		def        string
+		user       string
+		cmdline    string
+		removehome bool
 
 		setPropertyErr bool
 		scanErr        bool
@@ -857,23 +1214,40 @@ func TestChangeHomeOnUserData(t *testing.T) {
 		wantErr bool
 		isNoOp  bool
 	}{
-		"Rename home": {def: "m_with_userdata.yaml"},
+		"Dissociate user": {def: "m_with_userdata.yaml"},
 
-		// Argument or matching issue
-		"Home doesn't match": {def: "m_with_userdata.yaml", home: "/home/userabcd", wantErr: true, isNoOp: true},
-		"System not zsys":    {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
-		"Old home empty":     {def: "m_with_userdata.yaml", home: "[empty]", wantErr: true, isNoOp: true},
-		"New home empty":     {def: "m_with_userdata.yaml", newHome: "[empty]", wantErr: true, isNoOp: true},
+		"Dissociate user but keep snapshot attached":         {def: "m_with_userdata_dataset_and_snapshot_attached.yaml"},
+		"Dissociate user with no associated snapshot":        {def: "m_with_userdata_user_snapshot.yaml"},
+		"Dissociate user with children datasets (inherited)": {def: "m_with_userdata_children_on_user.yaml"},
+		"Dissociate user with children datasets (local)":     {def: "m_with_userdata_clone_attached.yaml"},
+		"Dissociate user having shared states":               {def: "m_two_machines_with_same_userdata.yaml"},
 
-		// Errors
-		"Set property fails":            {def: "m_with_userdata.yaml", setPropertyErr: true, wantErr: true, isNoOp: true},
-		"Scan fails does trigger error": {def: "m_with_userdata.yaml", scanErr: true, wantErr: true, isNoOp: true},
+		// TODO: really test directory cleanup? Same for set-user home
+		// check that unmounts are done in correct order (children first)
+		// Remove home use cases
+		// unmounted datasets
+		// mounted datasets without remove
+		// mounted datasets with remove
+		"Dissociate user removing home": {def: "m_with_userdata.yaml", removehome: true},
+
+		"User has no state associated with current machine": {def: "m_with_userdata.yaml", user: "doesntexist", wantErr: true},
+		"Empty user name":            {def: "m_with_userdata.yaml", user: "-", wantErr: true},
+		"SetProperty fails":          {def: "m_with_userdata.yaml", setPropertyErr: true, wantErr: true},
+		"Scanning fails":             {def: "m_with_userdata.yaml", scanErr: true, wantErr: true},
+		"Current machine isn’t zsys": {def: "m_with_userdata.yaml", cmdline: "foo", wantErr: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+			tc.cmdline = getDefaultValue(tc.cmdline, generateCmdLine("rpool/ROOT/ubuntu_1234"))
+			if tc.user == "-" {
+				tc.user = ""
+			} else {
+				tc.user = getDefaultValue(tc.user, "user1")
+			}
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
 			libzfs := testutils.GetMockZFS(t)
@@ -883,7 +1257,7 @@ func TestChangeHomeOnUserData(t *testing.T) {
 			lzfs := libzfs.(*mock.LibZFS)
 			lzfs.ForceLastUsedTime(true)
 
-			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
@@ -893,7 +1267,7 @@ func TestChangeHomeOnUserData(t *testing.T) {
 			lzfs.ErrOnScan(tc.scanErr)
 			lzfs.ErrOnSetProperty(tc.setPropertyErr)
 
-			err = ms.ChangeHomeOnUserData(context.Background(), getDefaultValue(tc.home, "/home/user1"), getDefaultValue(tc.newHome, "/home/foo"))
+			err = ms.DissociateUser(context.Background(), tc.user, tc.removehome)
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
@@ -911,8 +1285,7 @@ func TestChangeHomeOnUserData(t *testing.T) {
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
 
-			// finale rescan uneeded if last one failed
-			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
@@ -921,103 +1294,106 @@ func TestChangeHomeOnUserData(t *testing.T) {
 	}
 }
 
-func TestCreateSystemSnapshot(t *testing.T) {
+func TestRestoreUserState(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def          string
-		cmdline      string
-		snapshotName string
+		def     string
+		user    string
+		state   string
+		cmdline string
 
-		setCapOnPool string
-		capValue     string
+		mounted        []string
+		setPropertyErr bool
+		cloneErr       bool
+		failMountOn    string
 
-		wantErr bool
-		isNoOp  bool
+		wantMounts []string
+		wantErr    bool
 	}{
-		"Take one snapshot":       {def: "m_with_userdata.yaml"},
-		"Give a name to snapshot": {def: "m_with_userdata.yaml", snapshotName: "my_snapshot"},
-
-		"Children on system datasets": {def: "m_with_userdata_children_on_system.yaml"},
-		"Children on user datasets":   {def: "m_with_userdata_children_on_user.yaml"},
-		"Children on user datasets with one child non associated with current machine": {def: "m_with_userdata_child_associated_one_state.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_9999")},
-
-		"No associated userdata": {def: "d_one_machine_with_children.yaml", cmdline: generateCmdLine("rpool")},
-
-		// Free space handling
-		"Not enough free space on system pool":                {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "99", wantErr: true},
-		"Not enough free space on user pool":                  {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "99", wantErr: true},
-		"Capacity is invalid":                                 {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "NaN", wantErr: true},
-		"Take snapshot, not enough free space on other pools": {def: "m_without_userdata_prefer_system_pool.yaml", setCapOnPool: "rpool2", capValue: "99"},
-
-		// error cases with snapshot exists on root. on userdataset. on system child. on user child
-		"Error on existing snapshot on system root":  {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_root_snapshot", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on system child": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_child_snapshot", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on user root":    {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_root_snapshot", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on user child":   {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_child_snapshot", wantErr: true, isNoOp: true},
-
-		"Error when name starts with dash":            {def: "m_with_userdata.yaml", snapshotName: "-my_snapshot", wantErr: true, isNoOp: true},
-		"Error when name contains invalid characters": {def: "m_with_userdata.yaml", snapshotName: "my, snäpshôt, is beautiful,", wantErr: true, isNoOp: true},
-
-		"Non zsys":   {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
-		"No machine": {def: "m_with_userdata_no_zsys.yaml", cmdline: generateCmdLine("rpool/ROOT/nomachine"), wantErr: true, isNoOp: true},
+		"Restore user state attached to a system state": {def: "m_snapshot_with_userdata.yaml", state: "rpool/USERDATA/user1_abcd@snap1"},
+		"Restore user state with children":              {def: "m_snapshot_with_userdata_with_children.yaml", state: "snap1"},
+		"Restore user only state":                       {def: "m_with_userdata_user_snapshot.yaml", state: "automatedusersnapshot"},
+		"Swap mounted user datasets": {def: "m_snapshot_with_userdata_with_children.yaml", state: "snap1",
+			mounted: []string{"rpool/USERDATA/user1_abcd", "rpool/USERDATA/user1_abcd/tools"},
+			wantMounts: []string{"unmount /home/user1/tools", "unmount /home/user1",
+				"mount rpool/USERDATA/user1_xxxxxx on /home/user1", "mount rpool/USERDATA/user1_xxxxxx/tools on /home/user1/tools"}},
+
+		"Error on restoring a filesystem state":          {def: "m_snapshot_with_userdata.yaml", state: "rpool/USERDATA/user1_abcd", wantErr: true},
+		"Error on no matching state":                     {def: "m_snapshot_with_userdata.yaml", state: "doesntexist", wantErr: true},
+		"Error on state of another user":                 {def: "m_snapshot_with_userdata.yaml", state: "rpool/USERDATA/user1_abcd@snap1", user: "root", wantErr: true},
+		"Error on user has no state on current machine":  {def: "m_snapshot_with_userdata.yaml", state: "snap1", user: "doesntexist", wantErr: true},
+		"Error on empty user name":                       {def: "m_snapshot_with_userdata.yaml", state: "snap1", user: "-", wantErr: true},
+		"Error on current machine isn't zsys":            {def: "m_snapshot_with_userdata.yaml", state: "snap1", cmdline: "foo", wantErr: true},
+		"Error on clone fails":                           {def: "m_snapshot_with_userdata.yaml", state: "snap1", cloneErr: true, wantErr: true},
+		"Error on SetProperty fails, nothing is changed": {def: "m_snapshot_with_userdata.yaml", state: "snap1", setPropertyErr: true, wantErr: true},
+		"Error on unmount fails, nothing is changed": {def: "m_snapshot_with_userdata_with_children.yaml", state: "snap1",
+			mounted: []string{"rpool/USERDATA/user1_abcd", "rpool/USERDATA/user1_abcd/tools"}, failMountOn: "/home/user1",
+			wantMounts: []string{"unmount /home/user1/tools", "unmount /home/user1",
+				"mount rpool/USERDATA/user1_abcd/tools on /home/user1/tools"}, wantErr: true},
+		"Error on mount fails, nothing is changed": {def: "m_snapshot_with_userdata_with_children.yaml", state: "snap1",
+			mounted: []string{"rpool/USERDATA/user1_abcd", "rpool/USERDATA/user1_abcd/tools"}, failMountOn: "rpool/USERDATA/user1_xxxxxx/tools",
+			wantMounts: []string{"unmount /home/user1/tools", "unmount /home/user1",
+				"mount rpool/USERDATA/user1_xxxxxx on /home/user1", "mount rpool/USERDATA/user1_xxxxxx/tools on /home/user1/tools",
+				"unmount /home/user1", "mount rpool/USERDATA/user1_abcd on /home/user1", "mount rpool/USERDATA/user1_abcd/tools on /home/user1/tools"}, wantErr: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+			tc.cmdline = getDefaultValue(tc.cmdline, generateCmdLine("rpool/ROOT/ubuntu_1234"))
+			if tc.user == "-" {
+				tc.user = ""
+			} else {
+				tc.user = getDefaultValue(tc.user, "user1")
+			}
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
 			libzfs := testutils.GetMockZFS(t)
 			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			if tc.cmdline == "" {
-				tc.cmdline = generateCmdLine("rpool/ROOT/ubuntu_1234")
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+			for _, n := range tc.mounted {
+				lzfs.SetDatasetAsMounted(n, true)
 			}
 
-			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			mounter := &mounterMock{failOn: tc.failMountOn}
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs), machines.WithTime(testutils.FixedTime{}),
+				machines.WithMounter(mounter))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
-			lzfs := libzfs.(*mock.LibZFS)
-
-			lzfs.ForceLastUsedTime(true)
-			if tc.setCapOnPool != "" {
-				lzfs.SetPoolCapacity(tc.setCapOnPool, tc.capValue)
-			}
 
 			initMachines := ms.CopyForTests(t)
 
-			snapshotName, err := ms.CreateSystemSnapshot(context.Background(), tc.snapshotName)
+			lzfs.ErrOnClone(tc.cloneErr)
+			lzfs.ErrOnSetProperty(tc.setPropertyErr)
+
+			id, err := ms.RestoreUserState(context.Background(), tc.user, tc.state)
+			assert.Equal(t, tc.wantMounts, mounter.calls, "unexpected mounts and unmounts")
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
 				}
+				lzfs.ErrOnSetProperty(false)
+				machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+				if err != nil {
+					t.Error("expected success but got an error scanning for machines", err)
+				}
+				assertMachinesEquals(t, initMachines, machinesAfterRescan)
 				return
 			}
 			if err == nil && tc.wantErr {
 				t.Fatal("expected an error but got none")
 			}
 
-			if tc.snapshotName != "" {
-				if snapshotName != tc.snapshotName {
-					t.Errorf("provided snapshotname isn't the one used. Want: %s, got: %s", tc.snapshotName, snapshotName)
-				}
-			} else {
-				if !strings.HasPrefix(snapshotName, machines.AutomatedSnapshotPrefix) {
-					t.Errorf("generated snapshotname should start with %s, but got: %s", machines.AutomatedSnapshotPrefix, snapshotName)
-				}
-			}
-
-			if tc.isNoOp {
-				assertMachinesEquals(t, initMachines, ms)
-			} else {
-				assertMachinesToGolden(t, ms)
-				assertMachinesNotEquals(t, initMachines, ms)
-			}
+			assert.Equal(t, "rpool/USERDATA/user1_xxxxxx", id, "unexpected restored user state id")
+			assertMachinesToGolden(t, ms)
+			assertMachinesNotEquals(t, initMachines, ms)
 
-			// finale rescan uneeded if last one failed
 			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
@@ -1027,106 +1403,138 @@ func TestCreateSystemSnapshot(t *testing.T) {
 	}
 }
 
-func TestCreateUserSnapshot(t *testing.T) {
+func TestRevertDatasets(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def          string
-		cmdline      string
-		snapshotName string
-		userName     string
-
-		setCapOnPool string
-		capValue     string
-
-		wantErr bool
-		isNoOp  bool
-	}{
-		"Take one snapshot":                                   {def: "m_with_userdata.yaml"},
-		"Give a name to snapshot":                             {def: "m_with_userdata.yaml", snapshotName: "my_snapshot"},
-		"Take one snapshot on a machine with other snapshots": {def: "m_snapshot_with_userdata.yaml"},
-
-		"Children on user datasets": {def: "m_with_userdata_children_on_user.yaml"},
-		"Children on user datasets with one child non associated with current machine": {def: "m_with_userdata_child_associated_one_state.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_9999")},
+		def     string
+		state   string
+		routes  []string
+		cmdline string
+		mounted []string
 
-		// Space handling
-		"Not enough free space on user pool":                       {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "99", wantErr: true},
-		"Take user snapshot, not enough free space on other pools": {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "99"},
-		"Capacity is invalid":                                      {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "NaN", wantErr: true},
+		failMountOn string
 
-		// Error cases with non-existent user, snapshot exists on root, on userdataset, on system child, on user child
-		"Error on empty user":                       {def: "m_with_userdata.yaml", userName: "-", wantErr: true, isNoOp: true},
-		"Error on non existent user":                {def: "m_with_userdata.yaml", userName: "nonexistent", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on system root": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_root_snapshot", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on user root":   {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_root_snapshot", wantErr: true, isNoOp: true},
-		"Error on existing snapshot on user child":  {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_child_snapshot", wantErr: true, isNoOp: true},
-		// We don’t handle that case: the snapshot is only on a child, so it’s not part of history of this machine, and can’t be automatically reverted to it
-		//"Error on existing snapshot on system child": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_child_snapshot", wantErr: true, isNoOp: true},
+		cloneErr        bool
+		promoteErr      bool
+		setPropertyErr  bool
+		stopServicesErr bool
 
-		"Non zsys":   {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
-		"No machine": {def: "m_with_userdata_no_zsys.yaml", cmdline: generateCmdLine("rpool/ROOT/nomachine"), wantErr: true, isNoOp: true},
+		wantStopped []string
+		wantMounts  []string
+		wantErr     bool
+	}{
+		"Revert a dataset by mountpoint": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"},
+			wantStopped: []string{"/srv"}},
+		"Revert a dataset by name": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"rpool/ROOT/ubuntu_1234/srv"},
+			wantStopped: []string{"/srv"}},
+		"Revert a dataset with its children": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/var/lib/"},
+			wantStopped: []string{"/var/lib", "/var/lib/AccountsService", "/var/lib/NetworkManager", "/var/lib/apt", "/var/lib/aptitude", "/var/lib/dpkg"}},
+		"Revert multiple datasets": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "snap2", routes: []string{"/var/log", "/srv"},
+			wantStopped: []string{"/srv", "/var/log"}},
+		"Swap mounted datasets": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/var/lib/"},
+			mounted:     []string{"rpool/ROOT/ubuntu_1234/var/lib", "rpool/ROOT/ubuntu_1234/var/lib/apt"},
+			wantStopped: []string{"/var/lib", "/var/lib/AccountsService", "/var/lib/NetworkManager", "/var/lib/apt", "/var/lib/aptitude", "/var/lib/dpkg"},
+			wantMounts: []string{"unmount /var/lib/apt", "unmount /var/lib",
+				"mount rpool/ROOT/ubuntu_1234/var/lib_xxxxxx on /var/lib", "mount rpool/ROOT/ubuntu_1234/var/lib_xxxxxx/apt on /var/lib/apt"}},
+		"Datasets needed by more recent states are kept": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap2", routes: []string{"/srv"},
+			wantStopped: []string{"/srv"}},
+		"Children are reverted with their parent": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/var/lib/apt", "/var/lib"},
+			wantStopped: []string{"/var/lib", "/var/lib/AccountsService", "/var/lib/NetworkManager", "/var/lib/apt", "/var/lib/aptitude", "/var/lib/dpkg"}},
+
+		"Error on root dataset":                 {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/"}, wantErr: true},
+		"Error on root dataset by name":         {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"rpool/ROOT/ubuntu_1234"}, wantErr: true},
+		"Error on no matching dataset":          {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv", "/doesntexist"}, wantErr: true},
+		"Error on no dataset to revert":         {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", wantErr: true},
+		"Error on current state":                {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234", routes: []string{"/srv"}, wantErr: true},
+		"Error on saved state of another state": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_5678@snap3", routes: []string{"/srv"}, wantErr: true},
+		"Error on no matching state":            {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "doesntexist", routes: []string{"/srv"}, wantErr: true},
+		"Error on current machine isn't zsys":   {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"}, cmdline: "foo", wantErr: true},
+		"Error on stopping services, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"}, stopServicesErr: true,
+			wantStopped: []string{"/srv"}, wantErr: true},
+		"Error on clone fails, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"}, cloneErr: true,
+			wantStopped: []string{"/srv"}, wantErr: true},
+		"Error on promote fails, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"}, promoteErr: true,
+			wantStopped: []string{"/srv"}, wantErr: true},
+		"Error on SetProperty fails, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/srv"}, setPropertyErr: true,
+			wantStopped: []string{"/srv"}, wantErr: true},
+		"Error on unmount fails, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/var/lib/"},
+			mounted: []string{"rpool/ROOT/ubuntu_1234/var/lib", "rpool/ROOT/ubuntu_1234/var/lib/apt"}, failMountOn: "/var/lib",
+			wantStopped: []string{"/var/lib", "/var/lib/AccountsService", "/var/lib/NetworkManager", "/var/lib/apt", "/var/lib/aptitude", "/var/lib/dpkg"},
+			wantMounts: []string{"unmount /var/lib/apt", "unmount /var/lib",
+				"mount rpool/ROOT/ubuntu_1234/var/lib/apt on /var/lib/apt"}, wantErr: true},
+		"Error on mount fails, nothing is changed": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", routes: []string{"/var/lib/"},
+			mounted: []string{"rpool/ROOT/ubuntu_1234/var/lib", "rpool/ROOT/ubuntu_1234/var/lib/apt"}, failMountOn: "rpool/ROOT/ubuntu_1234/var/lib_xxxxxx/apt",
+			wantStopped: []string{"/var/lib", "/var/lib/AccountsService", "/var/lib/NetworkManager", "/var/lib/apt", "/var/lib/aptitude", "/var/lib/dpkg"},
+			wantMounts: []string{"unmount /var/lib/apt", "unmount /var/lib",
+				"mount rpool/ROOT/ubuntu_1234/var/lib_xxxxxx on /var/lib", "mount rpool/ROOT/ubuntu_1234/var/lib_xxxxxx/apt on /var/lib/apt",
+				"unmount /var/lib", "mount rpool/ROOT/ubuntu_1234/var/lib on /var/lib", "mount rpool/ROOT/ubuntu_1234/var/lib/apt on /var/lib/apt"}, wantErr: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+			tc.cmdline = getDefaultValue(tc.cmdline, generateCmdLine("rpool/ROOT/ubuntu_1234"))
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
 			libzfs := testutils.GetMockZFS(t)
 			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			if tc.userName == "" {
-				tc.userName = "user1"
-			} else if tc.userName == "-" {
-				tc.userName = ""
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+			for _, n := range tc.mounted {
+				lzfs.SetDatasetAsMounted(n, true)
 			}
-			if tc.cmdline == "" {
-				tc.cmdline = generateCmdLine("rpool/ROOT/ubuntu_1234")
+
+			mounter := &mounterMock{failOn: tc.failMountOn}
+			var stopped []string
+			var started bool
+			stopServices := func(ctx context.Context, mountpoints []string) (func(), error) {
+				stopped = append(stopped, mountpoints...)
+				if tc.stopServicesErr {
+					return nil, errors.New("services stop error")
+				}
+				return func() { started = true }, nil
 			}
 
-			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs), machines.WithTime(testutils.FixedTime{}),
+				machines.WithServicesStopper(stopServices), machines.WithMounter(mounter))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
-			lzfs := libzfs.(*mock.LibZFS)
-
-			lzfs.ForceLastUsedTime(true)
-			if tc.setCapOnPool != "" {
-				lzfs.SetPoolCapacity(tc.setCapOnPool, tc.capValue)
-			}
 
 			initMachines := ms.CopyForTests(t)
 
-			snapshotName, err := ms.CreateUserSnapshot(context.Background(), tc.userName, tc.snapshotName)
+			lzfs.ErrOnClone(tc.cloneErr)
+			lzfs.ErrOnPromote(tc.promoteErr)
+			lzfs.ErrOnSetProperty(tc.setPropertyErr)
+
+			err = ms.RevertDatasets(context.Background(), tc.state, tc.routes)
+			sort.Strings(stopped)
+			assert.Equal(t, tc.wantStopped, stopped, "unexpected stopped mountpoints")
+			assert.Equal(t, len(tc.wantStopped) > 0 && !tc.stopServicesErr, started, "services should be started back once stopped")
+			assert.Equal(t, tc.wantMounts, mounter.calls, "unexpected mounts and unmounts")
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
 				}
+				lzfs.ErrOnSetProperty(false)
+				machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+				if err != nil {
+					t.Error("expected success but got an error scanning for machines", err)
+				}
+				assertMachinesEquals(t, initMachines, machinesAfterRescan)
 				return
 			}
 			if err == nil && tc.wantErr {
 				t.Fatal("expected an error but got none")
 			}
 
-			if tc.snapshotName != "" {
-				if snapshotName != tc.snapshotName {
-					t.Errorf("provided snapshotname isn't the one used. Want: %s, got: %s", tc.snapshotName, snapshotName)
-				}
-			} else {
-				if !strings.HasPrefix(snapshotName, machines.AutomatedSnapshotPrefix) {
-					t.Errorf("generated snapshotname should start with %s, but got: %s", machines.AutomatedSnapshotPrefix, snapshotName)
-				}
-			}
-
-			if tc.isNoOp {
-				assertMachinesEquals(t, initMachines, ms)
-			} else {
-				assertMachinesToGolden(t, ms)
-				assertMachinesNotEquals(t, initMachines, ms)
-			}
+			assertMachinesToGolden(t, ms)
+			assertMachinesNotEquals(t, initMachines, ms)
 
-			// finale rescan uneeded if last one failed
 			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
@@ -1135,68 +1543,1469 @@ func TestCreateUserSnapshot(t *testing.T) {
 		})
 	}
 }
-
-func TestRemoveState(t *testing.T) {
+func TestCurrentIsZsys(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		def            string
-		currentStateID string
-		state          string
-		user           string
-		force          bool
-
-		destroyErrDS []string
+		cmdline string
 
-		isNoOp              bool
-		wantErr             bool
-		wantConfirmationErr bool
+		wantIsZsys bool
 	}{
-		"Remove system state, one dataset": {def: "m_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234"},
+		"Zsys machine is current":         {cmdline: generateCmdLine("rpool"), wantIsZsys: true},
+		"Zfs non zsys machine is current": {cmdline: generateCmdLine("rpool2"), wantIsZsys: false},
+		"No current machine":              {cmdline: generateCmdLine("something that doesn’t match"), wantIsZsys: false},
+	}
 
-		// FIXME: miss bpool and bpool/BOOT from golden file
-		"Remove system state, complex with boot, children and user datasets": {def: "m_layout1_one_machine.yaml", state: "rpool/ROOT/ubuntu_1234"},
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "d_two_machines_one_zsys_one_non_zsys.yaml"), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			/*if tc.mountedDataset != "" {
+				lzfs := libzfs.(*mock.LibZFS)
+				lzfs.SetDatasetAsMounted(tc.mountedDataset, true)
+			}*/
+
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			assert.Equal(t, tc.wantIsZsys, ms.CurrentIsZsys(), "Expected current is zsys returned value")
+		})
+	}
+}
+
+func TestChangeHomeOnUserData(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def     string
+		home    string
+		newHome string
+
+		setPropertyErr bool
+		scanErr        bool
+
+		wantErr bool
+		isNoOp  bool
+	}{
+		"Rename home": {def: "m_with_userdata.yaml"},
+
+		// Argument or matching issue
+		"Home doesn't match": {def: "m_with_userdata.yaml", home: "/home/userabcd", wantErr: true, isNoOp: true},
+		"System not zsys":    {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
+		"Old home empty":     {def: "m_with_userdata.yaml", home: "[empty]", wantErr: true, isNoOp: true},
+		"New home empty":     {def: "m_with_userdata.yaml", newHome: "[empty]", wantErr: true, isNoOp: true},
+
+		// Errors
+		"Set property fails":            {def: "m_with_userdata.yaml", setPropertyErr: true, wantErr: true, isNoOp: true},
+		"Scan fails does trigger error": {def: "m_with_userdata.yaml", scanErr: true, wantErr: true, isNoOp: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			initMachines := ms.CopyForTests(t)
+
+			lzfs.ErrOnScan(tc.scanErr)
+			lzfs.ErrOnSetProperty(tc.setPropertyErr)
+
+			err = ms.ChangeHomeOnUserData(context.Background(), getDefaultValue(tc.home, "/home/user1"), getDefaultValue(tc.newHome, "/home/foo"))
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			// finale rescan uneeded if last one failed
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestCreateSystemSnapshot(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def          string
+		cmdline      string
+		snapshotName string
+		meta         machines.StateMetadata
+		configPath   string
+
+		setCapOnPool string
+		capValue     string
+
+		wantErr bool
+		isNoOp  bool
+	}{
+		"Take one snapshot":       {def: "m_with_userdata.yaml"},
+		"Give a name to snapshot": {def: "m_with_userdata.yaml", snapshotName: "my_snapshot"},
+		"Record package changes": {def: "m_with_userdata.yaml", meta: machines.StateMetadata{PackageChanges: machines.PackageChanges{
+			Upgraded: []string{"linux-image-generic", "firefox"}, Installed: []string{"linux-image-5.4.0-42-generic"}, Removed: []string{"gedit"}}}},
+		"Record package changes, truncating long lists": {def: "m_with_userdata.yaml", meta: machines.StateMetadata{PackageChanges: machines.PackageChanges{Upgraded: manyPackages(1000)}}},
+		"Record description and tags": {def: "m_with_userdata.yaml", meta: machines.StateMetadata{
+			Description: "Before release upgrade", Tags: map[string]string{"release": "2026.10", "keep": ""}}},
+		"Package changes start a trial boot": {def: "m_with_userdata.yaml", configPath: "trial_boots.conf", snapshotName: "before_upgrade",
+			meta: machines.StateMetadata{PackageChanges: machines.PackageChanges{Upgraded: []string{"linux-image-generic"}}}},
+		"Package changes keep the fallback of a trial not booted yet": {def: "m_snapshot_with_userdata_trial_not_booted.yaml", configPath: "trial_boots.conf", snapshotName: "before_upgrade",
+			meta: machines.StateMetadata{PackageChanges: machines.PackageChanges{Upgraded: []string{"linux-image-generic"}}}},
+		"Package changes restart a failed trial": {def: "m_snapshot_with_userdata_trial_failed.yaml", configPath: "trial_boots.conf", snapshotName: "before_upgrade",
+			meta: machines.StateMetadata{PackageChanges: machines.PackageChanges{Upgraded: []string{"linux-image-generic"}}}},
+
+		"Children on system datasets": {def: "m_with_userdata_children_on_system.yaml"},
+		"Children on user datasets":   {def: "m_with_userdata_children_on_user.yaml"},
+		"Children on user datasets with one child non associated with current machine": {def: "m_with_userdata_child_associated_one_state.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_9999")},
+
+		"No associated userdata": {def: "d_one_machine_with_children.yaml", cmdline: generateCmdLine("rpool")},
+
+		// Free space handling
+		"Not enough free space on system pool":                {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "99", wantErr: true},
+		"Not enough free space on user pool":                  {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "99", wantErr: true},
+		"Capacity is invalid":                                 {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "NaN", wantErr: true},
+		"Take snapshot, not enough free space on other pools": {def: "m_without_userdata_prefer_system_pool.yaml", setCapOnPool: "rpool2", capValue: "99"},
+		"Remove states to free up space":                      {def: "gc_space.yaml", configPath: "space.conf"},
+		"Not enough free space after removing states":         {def: "gc_space_not_enough.yaml", configPath: "space.conf", wantErr: true},
+
+		// error cases with snapshot exists on root. on userdataset. on system child. on user child
+		"Error on existing snapshot on system root":  {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_root_snapshot", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on system child": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_child_snapshot", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on user root":    {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_root_snapshot", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on user child":   {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_child_snapshot", wantErr: true, isNoOp: true},
+
+		"Error when name starts with dash":            {def: "m_with_userdata.yaml", snapshotName: "-my_snapshot", wantErr: true, isNoOp: true},
+		"Error when name contains invalid characters": {def: "m_with_userdata.yaml", snapshotName: "my, snäpshôt, is beautiful,", wantErr: true, isNoOp: true},
+		"Error on invalid tag key":                    {def: "m_with_userdata.yaml", meta: machines.StateMetadata{Tags: map[string]string{"my tag": ""}}, wantErr: true, isNoOp: true},
+		"Error on multiline description":              {def: "m_with_userdata.yaml", meta: machines.StateMetadata{Description: "line1\nline2"}, wantErr: true, isNoOp: true},
+
+		"Non zsys":   {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
+		"No machine": {def: "m_with_userdata_no_zsys.yaml", cmdline: generateCmdLine("rpool/ROOT/nomachine"), wantErr: true, isNoOp: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			if tc.cmdline == "" {
+				tc.cmdline = generateCmdLine("rpool/ROOT/ubuntu_1234")
+			}
+
+			configPath := config.DefaultPath
+			if tc.configPath != "" {
+				configPath = filepath.Join("testdata", "confs", tc.configPath)
+			}
+
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs), machines.WithConfig(configPath))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			lzfs := libzfs.(*mock.LibZFS)
+
+			lzfs.ForceLastUsedTime(true)
+			if tc.setCapOnPool != "" {
+				lzfs.SetPoolCapacity(tc.setCapOnPool, tc.capValue)
+			}
+
+			initMachines := ms.CopyForTests(t)
+
+			snapshotName, err := ms.CreateSystemSnapshot(context.Background(), tc.snapshotName, tc.meta)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.snapshotName != "" {
+				if snapshotName != tc.snapshotName {
+					t.Errorf("provided snapshotname isn't the one used. Want: %s, got: %s", tc.snapshotName, snapshotName)
+				}
+			} else {
+				if !strings.HasPrefix(snapshotName, machines.AutomatedSnapshotPrefix) {
+					t.Errorf("generated snapshotname should start with %s, but got: %s", machines.AutomatedSnapshotPrefix, snapshotName)
+				}
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			// finale rescan uneeded if last one failed
+			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestCreateUserSnapshot(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def          string
+		cmdline      string
+		snapshotName string
+		userName     string
+		meta         machines.StateMetadata
+
+		setCapOnPool string
+		capValue     string
+
+		wantErr bool
+		isNoOp  bool
+	}{
+		"Take one snapshot":                                   {def: "m_with_userdata.yaml"},
+		"Give a name to snapshot":                             {def: "m_with_userdata.yaml", snapshotName: "my_snapshot"},
+		"Take one snapshot on a machine with other snapshots": {def: "m_snapshot_with_userdata.yaml"},
+		"Record description and tags": {def: "m_with_userdata.yaml", meta: machines.StateMetadata{
+			Description: "Before cleaning", Tags: map[string]string{"project": "zsys"}}},
+		"Package changes aren't recorded on user states": {def: "m_with_userdata.yaml", meta: machines.StateMetadata{
+			PackageChanges: machines.PackageChanges{Upgraded: []string{"firefox"}}}},
+
+		"Children on user datasets": {def: "m_with_userdata_children_on_user.yaml"},
+		"Children on user datasets with one child non associated with current machine": {def: "m_with_userdata_child_associated_one_state.yaml", cmdline: generateCmdLine("rpool/ROOT/ubuntu_9999")},
+
+		// Space handling
+		"Not enough free space on user pool":                       {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "99", wantErr: true},
+		"Take user snapshot, not enough free space on other pools": {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool", capValue: "99"},
+		"Capacity is invalid":                                      {def: "m_with_userdata_on_other_pool.yaml", setCapOnPool: "rpool2", capValue: "NaN", wantErr: true},
+
+		// Error cases with non-existent user, snapshot exists on root, on userdataset, on system child, on user child
+		"Error on empty user":                       {def: "m_with_userdata.yaml", userName: "-", wantErr: true, isNoOp: true},
+		"Error on non existent user":                {def: "m_with_userdata.yaml", userName: "nonexistent", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on system root": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_root_snapshot", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on user root":   {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_root_snapshot", wantErr: true, isNoOp: true},
+		"Error on existing snapshot on user child":  {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "user_child_snapshot", wantErr: true, isNoOp: true},
+		// We don’t handle that case: the snapshot is only on a child, so it’s not part of history of this machine, and can’t be automatically reverted to it
+		//"Error on existing snapshot on system child": {def: "m_with_userdata_and_multiple_snapshots.yaml", snapshotName: "system_child_snapshot", wantErr: true, isNoOp: true},
+
+		"Non zsys":   {def: "m_with_userdata_no_zsys.yaml", wantErr: true, isNoOp: true},
+		"No machine": {def: "m_with_userdata_no_zsys.yaml", cmdline: generateCmdLine("rpool/ROOT/nomachine"), wantErr: true, isNoOp: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			if tc.userName == "" {
+				tc.userName = "user1"
+			} else if tc.userName == "-" {
+				tc.userName = ""
+			}
+			if tc.cmdline == "" {
+				tc.cmdline = generateCmdLine("rpool/ROOT/ubuntu_1234")
+			}
+
+			ms, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			lzfs := libzfs.(*mock.LibZFS)
+
+			lzfs.ForceLastUsedTime(true)
+			if tc.setCapOnPool != "" {
+				lzfs.SetPoolCapacity(tc.setCapOnPool, tc.capValue)
+			}
+
+			initMachines := ms.CopyForTests(t)
+
+			snapshotName, err := ms.CreateUserSnapshot(context.Background(), tc.userName, tc.snapshotName, tc.meta)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.snapshotName != "" {
+				if snapshotName != tc.snapshotName {
+					t.Errorf("provided snapshotname isn't the one used. Want: %s, got: %s", tc.snapshotName, snapshotName)
+				}
+			} else {
+				if !strings.HasPrefix(snapshotName, machines.AutomatedSnapshotPrefix) {
+					t.Errorf("generated snapshotname should start with %s, but got: %s", machines.AutomatedSnapshotPrefix, snapshotName)
+				}
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			// finale rescan uneeded if last one failed
+			machinesAfterRescan, err := machines.New(context.Background(), tc.cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestRemoveState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def            string
+		currentStateID string
+		state          string
+		user           string
+		force          bool
+
+		destroyErrDS []string
+
+		isNoOp              bool
+		wantErr             bool
+		wantConfirmationErr bool
+	}{
+		"Remove system state, one dataset": {def: "m_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234"},
+
+		// FIXME: miss bpool and bpool/BOOT from golden file
+		"Remove system state, complex with boot, children and user datasets": {def: "m_layout1_one_machine.yaml", state: "rpool/ROOT/ubuntu_1234"},
 		"Remove one system snapshot only":                                    {def: "state_remove_internal.yaml", state: "rpool/ROOT/ubuntu_1234@snap3"},
 		"Removing system state try to delete its snapshots":                  {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_6789", wantErr: true, wantConfirmationErr: true},
 		"Removing system state deletes its snapshots":                        {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_6789", force: true},
 
-		"Remove system state, with system and users snapshots and clones":         {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove system state, with system and users snapshots and clones, forced": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234", force: true},
-		"Remove system state when user datasets are linked to 2 states":           {def: "state_remove_internal.yaml", state: "rpool/ROOT/ubuntu_5678"},
-		"Remove system state, with datasets":                                      {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_1234", wantConfirmationErr: true, wantErr: true, isNoOp: true},
-		"Remove system state, with datasets, forced":                              {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_1234", force: true},
+		"Remove system state, with system and users snapshots and clones":         {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove system state, with system and users snapshots and clones, forced": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234", force: true},
+		"Remove system state when user datasets are linked to 2 states":           {def: "state_remove_internal.yaml", state: "rpool/ROOT/ubuntu_5678"},
+		"Remove system state, with datasets":                                      {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_1234", wantConfirmationErr: true, wantErr: true, isNoOp: true},
+		"Remove system state, with datasets, forced":                              {def: "state_remove.yaml", state: "rpool/ROOT/ubuntu_1234", force: true},
+
+		"Remove user state, one dataset":                       {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", user: "user4"},
+		"Remove user state, one dataset, no user":              {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", wantErr: true, isNoOp: true},
+		"Remove user state, one dataset, wrong user":           {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", user: "root", wantErr: true, isNoOp: true},
+		"Remove user state, with snapshots and clones":         {def: "state_remove.yaml", user: "user6", state: "rpool/USERDATA/user6_clone1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove user state, with snapshots and clones, forced": {def: "state_remove.yaml", user: "user6", state: "rpool/USERDATA/user6_clone1", force: true},
+		"Remove user state, with datasets":                     {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone@snapuser5", user: "user5", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove user state, with datasets, forced":             {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone@snapuser5", user: "user5", force: true},
+		"Remove user snapshot state":                           {def: "state_remove.yaml", state: "rpool/USERDATA/user1_efgh@snapuser2", user: "user1"},
+
+		"Can’t remove user leaf snapshot linked to system state":                                 {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd@snap1", user: "user1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove user leaf snapshot linked to system state, forced":                               {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd@snap1", user: "user1", force: true},
+		"Can’t remove user leaf filesystem state linked to system state":                         {def: "state_remove.yaml", state: "rpool/USERDATA/user3_3333", user: "user3", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove user leaf filesystem state linked to system state, forced":                       {def: "state_remove.yaml", state: "rpool/USERDATA/user3_3333", user: "user3", force: true},
+		"Can’t remove user filesystem linked to system state with deps linked to system state":   {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd", user: "user1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
+		"Remove user filesystem linked to system state with deps linked to system state, forced": {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd", user: "user1", force: true},
+
+		// Shared user state handling
+		"Remove shared user state linked to a history state. Deps are not removed":                         {def: "state_remove.yaml", state: "rpool/USERDATA/root_bcde-rpool.ROOT.ubuntu-5678", user: "root", force: true},
+		"Remove shared user state linked to current machine state. Deps are not removed":                   {def: "state_remove.yaml", state: "rpool/USERDATA/root_bcde-rpool.ROOT.ubuntu-1234", user: "root", force: true},
+		"Remove shared user state as a dependency of other state. Deps are removed":                        {def: "m_shared_userstate_on_clones.yaml", state: "rpool/USERDATA/user_abcd", user: "user", force: true},
+		"Remove shared user state on different matchines as a dependency of other state. Deps are removed": {def: "m_shared_userstate_on_two_machines.yaml", state: "rpool/USERDATA/user_abcd", user: "user", force: true},
+
+		"No state given": {def: "m_with_userdata.yaml", wantErr: true, isNoOp: true},
+		"Error on trying to remove current state":    {def: "m_with_userdata.yaml", currentStateID: "rpool/ROOT/ubuntu_1234", state: "rpool/ROOT/ubuntu_1234", wantErr: true, isNoOp: true},
+		"Error on destroy state, one dataset":        {def: "m_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234", destroyErrDS: []string{}, wantErr: true, isNoOp: true},
+		"Error on destroy user state, with datasets": {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone", user: "user5", force: true, destroyErrDS: []string{}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), generateCmdLine(tc.currentStateID), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			initMachines := ms.CopyForTests(t)
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ErrOnDestroyDS(tc.destroyErrDS)
+
+			err = ms.RemoveState(context.Background(), tc.state, tc.user, tc.force, false)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				var e *machines.ErrStateRemovalNeedsConfirmation
+
+				if tc.wantConfirmationErr {
+					assert.True(t, errors.As(err, &e), "expected ErrStateRemovalNeedsConfirmation error type")
+				} else {
+					assert.False(t, errors.As(err, &e), "don't expect ErrStateRemovalNeedsConfirmation error type")
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine(tc.currentStateID), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestIDToState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		name string
+		user string
+
+		wantState string
+		wantErr   bool
+	}{
+		"Match full system path ID": {name: "rpool/ROOT/ubuntu_1234", wantState: "rpool/ROOT/ubuntu_1234"},
+		"Match full user path ID":   {name: "rpool/USERDATA/user1_abcd", user: "user1", wantState: "rpool/USERDATA/user1_abcd"},
+
+		"Match suffix system ID":       {name: "5678", wantState: "rpool/ROOT/ubuntu_5678"},
+		"Match dataset path system ID": {name: "ubuntu_5678", wantState: "rpool/ROOT/ubuntu_5678"},
+		"Match unique system snapshot": {name: "snap1", wantState: "rpool/ROOT/ubuntu_1234@snap1"},
+
+		"Limit search on duplicated snapshot name to a single user": {name: "snap1", user: "user1", wantState: "rpool/USERDATA/user1_abcd@snap1"},
+
+		// User datasets shared between machines
+		"Match on user generated ID":                                                         {name: "jklm-rpool.ROOT.ubuntu-1234", user: "user2", wantState: "rpool/USERDATA/user2_jklm"},
+		"Doesn’t match on user dataset regular name":                                         {name: "rpool/USERDATA/user2_jklm", user: "user2", wantErr: true},
+		"User data attached to 2 machines but only linked to one system state isn’t renamed": {name: "rpool/USERDATA/user3_mnop", user: "user3", wantState: "rpool/USERDATA/user3_mnop"},
+
+		// Multiple matches
+		"Multiple states match system suffix ID": {name: "1234", wantErr: true},
+		"Multiple states match user suffix ID":   {name: "abcd", user: "user1", wantErr: true},
+
+		// No match
+		"Empty name":         {name: "", wantErr: true},
+		"No match at all":    {name: "/doesntexists", wantErr: true},
+		"User doesn’t exist": {name: "foo", user: "userfoo", wantErr: true},
+		"No match on full path ID search without user provided":    {name: "rpool/USERDATA/user1_abcd", wantErr: true},
+		"No match on full path ID search with wrong user provided": {name: "rpool/USERDATA/user1_abcd", user: "userfoo", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "state_idtostate.yaml"), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			_, err := zfs.New(context.Background(), zfs.WithLibZFS(libzfs))
+			if err != nil {
+				t.Fatalf("couldn’t create original zfs datasets state")
+			}
+
+			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			got, err := ms.IDToState(context.Background(), tc.name, tc.user)
+
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("Got an error when expecting none: %v", err)
+				}
+				return
+			} else if tc.wantErr {
+				t.Fatalf("Expected an error but got none")
+			}
+
+			assert.Equal(t, tc.wantState, got.ID, "didn't get expected state")
+		})
+	}
+}
+
+func TestTagState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def   string
+		name  string
+		user  string
+		tags  map[string]string
+		untag []string
+
+		wantErr bool
+	}{
+		"Tag a system state":                     {name: "rpool/ROOT/ubuntu_1234@snap1", tags: map[string]string{"reviewed": "yes"}},
+		"Replace an existing tag value":          {name: "rpool/ROOT/ubuntu_1234@snap1", tags: map[string]string{"release": "2026.11"}},
+		"Tag a user state":                       {name: "snap1", user: "user1", tags: map[string]string{"project": "zsys"}},
+		"Tag current state":                      {def: "m_snapshot_with_userdata_with_children.yaml", name: "rpool/ROOT/ubuntu_1234", tags: map[string]string{"stable": ""}},
+		"Tag a state without tags":               {def: "m_snapshot_with_userdata.yaml", name: "rpool/ROOT/ubuntu_1234@snap1", tags: map[string]string{"release": "2026.10"}},
+		"Untag a system state":                   {name: "rpool/ROOT/ubuntu_1234@snap1", untag: []string{"keep"}},
+		"Untag all tags of a system state":       {name: "rpool/ROOT/ubuntu_1234@snap1", untag: []string{"keep", "release"}},
+		"Untag a user state":                     {name: "snap1", user: "user1", untag: []string{"important"}},
+		"Error on invalid tag key":               {name: "rpool/ROOT/ubuntu_1234@snap1", tags: map[string]string{"in valid": ""}, wantErr: true},
+		"Error on no tag to set":                 {name: "rpool/ROOT/ubuntu_1234@snap1", tags: map[string]string{}, wantErr: true},
+		"Error on untagging a tag not on state":  {name: "rpool/ROOT/ubuntu_1234@snap1", untag: []string{"doesntexist"}, wantErr: true},
+		"Error on tagging a non existing state":  {name: "doesntexist", tags: map[string]string{"a": "b"}, wantErr: true},
+		"Error on untagging a non existing user": {name: "snap1", user: "userfoo", untag: []string{"important"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "m_snapshot_with_userdata_tagged.yaml")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			if tc.untag != nil {
+				err = ms.UntagState(context.Background(), tc.name, tc.user, tc.untag)
+			} else {
+				err = ms.TagState(context.Background(), tc.name, tc.user, tc.tags)
+			}
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			assertMachinesToGolden(t, ms)
+			assertMachinesNotEquals(t, initMachines, ms)
+
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestPinState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def   string
+		name  string
+		user  string
+		unpin bool
+
+		isNoOp  bool
+		wantErr bool
+	}{
+		"Pin a system state":                    {name: "rpool/ROOT/ubuntu_1234@snap1"},
+		"Pin a user state":                      {name: "snap1", user: "user1"},
+		"Pin current state":                     {def: "m_snapshot_with_userdata_with_children.yaml", name: "rpool/ROOT/ubuntu_1234"},
+		"Unpin a system state":                  {def: "m_snapshot_with_userdata_pinned.yaml", name: "rpool/ROOT/ubuntu_1234@snap1", unpin: true},
+		"Unpin a user state":                    {def: "m_snapshot_with_userdata_pinned.yaml", name: "snap1", user: "user1", unpin: true},
+		"Pin an already pinned state":           {def: "m_snapshot_with_userdata_pinned.yaml", name: "rpool/ROOT/ubuntu_1234@snap1", isNoOp: true},
+		"Unpin a state which isn't pinned":      {name: "rpool/ROOT/ubuntu_1234@snap1", unpin: true, isNoOp: true},
+		"Error on pinning a non existing state": {name: "doesntexist", wantErr: true},
+		"Error on pinning a non existing user":  {name: "snap1", user: "userfoo", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "m_snapshot_with_userdata.yaml")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			err = ms.PinState(context.Background(), tc.name, tc.user, !tc.unpin)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+				return
+			}
+
+			assertMachinesToGolden(t, ms)
+			assertMachinesNotEquals(t, initMachines, ms)
+
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestStateUsage(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def  string
+		name string
+		user string
+
+		want    machines.Usage
+		wantErr bool
+	}{
+		"Current system state":                        {name: "rpool/ROOT/ubuntu_1234", want: machines.Usage{Unique: 850, Shared: 50, Freed: 1290}},
+		"System snapshot state":                       {name: "rpool/ROOT/ubuntu_1234@snap1", want: machines.Usage{Unique: 100, Shared: 400, Freed: 140}},
+		"System clone state":                          {name: "rpool/ROOT/ubuntu_5678", want: machines.Usage{Unique: 300, Shared: 100, Freed: 380}},
+		"User snapshot state":                         {name: "snap2", user: "user1", want: machines.Usage{Unique: 20, Shared: 170, Freed: 20}},
+		"User filesystem state":                       {name: "rpool/USERDATA/user1_efgh", user: "user1", want: machines.Usage{Unique: 80, Freed: 80}},
+		"User state linked to multiple system states": {name: "rpool/USERDATA/user2_ijkl-rpool.ROOT.ubuntu-1234", user: "user2", want: machines.Usage{Unique: 60, Freed: 60}},
+		"No space reported":                           {def: "m_snapshot_with_userdata.yaml", name: "rpool/ROOT/ubuntu_1234@snap1"},
+		"Error on non existing state":                 {name: "doesntexist", wantErr: true},
+		"Error on non existing user":                  {name: "snap1", user: "userfoo", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "state_usage.yaml")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			got, err := ms.StateUsage(context.Background(), tc.name, tc.user)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			assert.Equal(t, tc.want, got, "Disk usage of the state")
+		})
+	}
+}
+
+func TestStatePath(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def        string
+		name       string
+		user       string
+		path       string
+		notMounted bool
+
+		wantPath string
+		wantErr  bool
+	}{
+		"System state root":                         {name: "rpool/ROOT/ubuntu_1234@snap1", wantPath: "/.zfs/snapshot/snap1"},
+		"System state file":                         {name: "snap1", path: "/etc/hosts", wantPath: "/.zfs/snapshot/snap1/etc/hosts"},
+		"System state file on user child dataset":   {name: "snap1", path: "/home/user1/tools/a", wantPath: "/home/user1/tools/.zfs/snapshot/snap1/a"},
+		"System state file on user dataset":         {name: "snap1", path: "/home/user1/docs/a", wantPath: "/home/user1/.zfs/snapshot/snap1/docs/a"},
+		"User state root":                           {name: "snap1", user: "user1", wantPath: "/home/user1/.zfs/snapshot/snap1"},
+		"User state file":                           {name: "snap1", user: "user1", path: "/home/user1/docs/a", wantPath: "/home/user1/.zfs/snapshot/snap1/docs/a"},
+		"User state mountpoint of child dataset":    {name: "snap1", user: "user1", path: "/home/user1/tools", wantPath: "/home/user1/tools/.zfs/snapshot/snap1"},
+		"Path is cleaned":                           {name: "snap1", user: "user1", path: "/home/user1/tools/../docs//a", wantPath: "/home/user1/.zfs/snapshot/snap1/docs/a"},
+		"Home moved since the state was saved":      {def: "m_snapshot_with_userdata_with_children_home_moved.yaml", name: "snap1", user: "user1", path: "/home/user1/docs/a", wantPath: "/home/user1new/.zfs/snapshot/snap1/docs/a"},
+		"Home moved, file on inherited mountpoint":  {def: "m_snapshot_with_userdata_with_children_home_moved.yaml", name: "snap1", user: "user1", path: "/home/user1/tools/a", wantPath: "/home/user1new/tools/.zfs/snapshot/snap1/a"},
+		"Error on path outside of user state":       {name: "snap1", user: "user1", path: "/etc/hosts", wantErr: true},
+		"Error on path prefix of a mountpoint only": {name: "snap1", user: "user1", path: "/home/user1foo", wantErr: true},
+		"Error on relative path":                    {name: "snap1", path: "etc/hosts", wantErr: true},
+		"Error on filesystem state":                 {name: "rpool/ROOT/ubuntu_1234", path: "/etc/hosts", wantErr: true},
+		"Error on no matching state":                {name: "doesntexist", wantErr: true},
+		"Error on dataset not mounted":              {name: "snap1", path: "/etc/hosts", notMounted: true, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "m_snapshot_with_userdata_with_children.yaml")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+			if !tc.notMounted {
+				lzfs := libzfs.(*mock.LibZFS)
+				for _, n := range []string{"rpool/ROOT/ubuntu_1234", "rpool/USERDATA/user1_abcd", "rpool/USERDATA/user1_abcd/tools"} {
+					lzfs.SetDatasetAsMounted(n, true)
+				}
+			}
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			got, err := ms.StatePath(context.Background(), tc.name, tc.user, tc.path)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			assert.Equal(t, tc.wantPath, got, "didn't get expected path")
+		})
+	}
+}
+
+func TestStateDiff(t *testing.T) {
+	t.Parallel()
+	diffs := map[[2]string][]libzfsadapter.DiffEntry{
+		{"rpool/ROOT/ubuntu_1234@snap1", "rpool/ROOT/ubuntu_1234"}: {
+			{Change: libzfsadapter.DiffModified, Path: "/etc/hosts"},
+			{Change: libzfsadapter.DiffAdded, Path: "/etc/new"},
+			{Change: libzfsadapter.DiffRenamed, Path: "/etc/a", NewPath: "/etc/b"},
+			{Change: libzfsadapter.DiffRemoved, Path: "/usr/old"},
+		},
+		{"rpool/ROOT/ubuntu_1234@snap1", "rpool/ROOT/ubuntu_1234@snap2"}:         {{Change: libzfsadapter.DiffModified, Path: "/etc/hosts"}},
+		{"rpool/ROOT/ubuntu_1234/var@snap1", "rpool/ROOT/ubuntu_1234/var@snap2"}: {{Change: libzfsadapter.DiffAdded, Path: "/var/log/new"}},
+		{"bpool/BOOT/ubuntu_1234@snap1", "bpool/BOOT/ubuntu_1234@snap2"}:         {{Change: libzfsadapter.DiffModified, Path: "/boot/grub/grub.cfg"}},
+		{"rpool/USERDATA/user1_abcd@snap1", "rpool/USERDATA/user1_abcd"}:         {{Change: libzfsadapter.DiffModified, Path: "/home/user1/doc"}},
+		{"rpool/USERDATA/user1_abcd@snap1", "rpool/USERDATA/user1_abcd@snap2"}:   {{Change: libzfsadapter.DiffRemoved, Path: "/home/user1/doc"}},
+	}
+
+	tests := map[string]struct {
+		def  string
+		from string
+		to   string
+		user string
+
+		wantErr bool
+	}{
+		"Compare saved state to current state":            {from: "snap1"},
+		"Compare saved state to current state by name":    {from: "snap1", to: "current"},
+		"Compare two saved states":                        {from: "rpool/ROOT/ubuntu_1234@snap1", to: "snap2"},
+		"Compare with datasets only in one of the states": {def: "m_snapshot_with_userdata_with_children.yaml", from: "snap1"},
+		"No changes between states":                       {from: "snap2"},
+		"Error on comparing a filesystem state":           {from: "rpool/ROOT/ubuntu_1234", wantErr: true},
+		"Error on comparing to a more ancient state":      {from: "snap2", to: "snap1", wantErr: true},
+		"Error on comparing a state to itself":            {from: "snap1", to: "snap1", wantErr: true},
+		"Error on no matching state":                      {from: "doesntexist", wantErr: true},
+		"Error on no matching state to compare to":        {from: "snap1", to: "doesntexist", wantErr: true},
+
+		// User states
+		"Compare user saved state to current user state": {from: "snap1", user: "user1"},
+		"Compare two user saved states":                  {from: "snap1", to: "snap2", user: "user1"},
+		"Error on comparing a user filesystem state":     {from: "rpool/USERDATA/user1_abcd", user: "user1", wantErr: true},
+		"Error on no matching state for user":            {from: "snap1", user: "userfoo", wantErr: true},
+		"Error on comparing user state to system state":  {from: "snap1", to: "rpool/ROOT/ubuntu_1234@snap2", user: "user1", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "m_two_snapshots_with_separate_boot_and_userdata.yaml")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+			lzfs := libzfs.(*mock.LibZFS)
+			for k, entries := range diffs {
+				lzfs.SetDiff(k[0], k[1], entries)
+			}
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			got, err := ms.StateDiff(context.Background(), tc.from, tc.to, tc.user)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			// Diff is read only
+			assertMachinesEquals(t, initMachines, ms)
+
+			var want string
+			testutils.LoadFromGoldenFile(t, got, &want)
+			assert.Equal(t, want, got, "state diff doesn't match golden file")
+		})
+	}
+}
+
+func TestExportState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def   string
+		state string
+		from  string
+
+		wantErr bool
+	}{
+		"Export system snapshot with users":                               {def: "m_snapshot_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234@snap1"},
+		"Export system snapshot with separate boot":                       {def: "m_snapshot_with_separate_boot_with_children.yaml", state: "snap1"},
+		"Export system snapshot with children, boot, users":               {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap2"},
+		"Export system snapshot with children, boot, users incrementally": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", from: "rpool/ROOT/ubuntu_1234@snap2"},
+
+		"Error on exporting a filesystem state":          {def: "m_snapshot_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234", wantErr: true},
+		"Error on no matching state":                     {def: "m_snapshot_with_userdata.yaml", state: "doesntexist", wantErr: true},
+		"Error on empty state name":                      {def: "m_snapshot_with_userdata.yaml", wantErr: true},
+		"Error on no matching incremental base":          {def: "m_snapshot_with_userdata.yaml", state: "snap1", from: "doesntexist", wantErr: true},
+		"Error on incremental base being a filesystem":   {def: "m_snapshot_with_userdata.yaml", state: "snap1", from: "rpool/ROOT/ubuntu_1234", wantErr: true},
+		"Error on incremental base being the same state": {def: "m_snapshot_with_userdata.yaml", state: "snap1", from: "snap1", wantErr: true},
+		"Error on incremental base more recent":          {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap2", from: "rpool/ROOT/ubuntu_1234@snap1", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			var b bytes.Buffer
+			err = ms.ExportState(context.Background(), tc.state, tc.from, &b)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			// Export is read only
+			assertMachinesEquals(t, initMachines, ms)
+
+			tr := tar.NewReader(&b)
+			hdr, err := tr.Next()
+			if err != nil {
+				t.Fatalf("couldn't read archive: %v", err)
+			}
+			assert.Equal(t, "manifest.json", hdr.Name, "manifest should be the first archive entry")
+			var got map[string]interface{}
+			if err := json.NewDecoder(tr).Decode(&got); err != nil {
+				t.Fatalf("couldn't decode manifest: %v", err)
+			}
+			want := make(map[string]interface{})
+			testutils.LoadFromGoldenFile(t, got, &want)
+			assert.Equal(t, want, got, "manifest doesn't match golden file")
+
+			var wantStreams []string
+			datasets := got["Datasets"].([]interface{})
+			if users, ok := got["Users"].(map[string]interface{}); ok {
+				var names []string
+				for n := range users {
+					names = append(names, n)
+				}
+				sort.Strings(names)
+				for _, n := range names {
+					datasets = append(datasets, users[n].([]interface{})...)
+				}
+			}
+			allBases := make(map[string]bool)
+			for _, d := range datasets {
+				base := strings.Split(d.(map[string]interface{})["Name"].(string), "@")[0]
+				allBases[base] = true
+			}
+			seenBases := make(map[string]bool)
+			for _, d := range datasets {
+				base := strings.Split(d.(map[string]interface{})["Name"].(string), "@")[0]
+				if parent := filepath.Dir(base); allBases[parent] {
+					assert.True(t, seenBases[parent], "%s should be listed after its parent %s", base, parent)
+				}
+				seenBases[base] = true
+				wantStreams = append(wantStreams, d.(map[string]interface{})["Stream"].(string))
+			}
+
+			var gotStreams []string
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("couldn't read archive: %v", err)
+				}
+				if hdr.Size == 0 {
+					t.Errorf("stream %s is empty", hdr.Name)
+				}
+				gotStreams = append(gotStreams, hdr.Name)
+			}
+			assert.Equal(t, wantStreams, gotStreams, "archive should contain one stream per dataset")
+		})
+	}
+}
+
+func TestImportState(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def          string
+		saveState    string
+		state        string
+		from         string
+		importDef    string
+		asNewMachine bool
+		archive      string
+
+		wantErr bool
+	}{
+		"Import as history":                                {def: "m_snapshot_with_userdata.yaml", saveState: "snap2", state: "snap2", from: "snap1"},
+		"Import as new machine":                            {def: "m_snapshot_with_userdata.yaml", state: "snap1", asNewMachine: true},
+		"Import with separate boot as history":             {def: "m_snapshot_with_separate_boot_with_children.yaml", saveState: "snap2", state: "snap2", from: "snap1"},
+		"Import with children, boot, users as history":     {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap1", from: "rpool/ROOT/ubuntu_1234@snap2"},
+		"Import with children, boot, users as new machine": {def: "m_layout1_machines_with_snapshots_clones.yaml", state: "rpool/ROOT/ubuntu_1234@snap2", asNewMachine: true},
+		"Import as new machine on another system":          {def: "m_snapshot_with_userdata.yaml", state: "snap1", importDef: "m_with_userdata.yaml", asNewMachine: true},
+
+		"Error on importing a full archive as history":                  {def: "m_snapshot_with_userdata.yaml", state: "snap1", wantErr: true},
+		"Error on importing an incremental archive as new machine":      {def: "m_snapshot_with_userdata.yaml", saveState: "snap2", state: "snap2", from: "snap1", asNewMachine: true, wantErr: true},
+		"Error on importing as history without the incremental base":    {def: "m_snapshot_with_userdata.yaml", saveState: "snap2", state: "snap2", from: "snap1", importDef: "m_with_userdata.yaml", wantErr: true},
+		"Error on importing as history on non zsys machine":             {def: "m_snapshot_with_userdata.yaml", saveState: "snap2", state: "snap2", from: "snap1", importDef: "m_with_userdata_no_zsys.yaml", wantErr: true},
+		"Error on importing on a system without the dataset containers": {def: "m_snapshot_with_separate_boot_with_children.yaml", state: "snap1", importDef: "m_with_userdata.yaml", asNewMachine: true, wantErr: true},
+		"Error on invalid archive":                                      {def: "m_snapshot_with_userdata.yaml", state: "snap1", archive: "invalid", wantErr: true},
+		"Error on archive without manifest":                             {def: "m_snapshot_with_userdata.yaml", state: "snap1", archive: "nomanifest", wantErr: true},
+		"Error on archive with missing streams":                         {def: "m_snapshot_with_userdata.yaml", state: "snap1", archive: "manifestonly", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			cmdline := generateCmdLine("rpool/ROOT/ubuntu_1234")
+			ms, err := machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			if tc.saveState != "" {
+				libzfs.(*mock.LibZFS).ForceLastUsedTime(true)
+				if _, err := ms.CreateSystemSnapshot(context.Background(), tc.saveState, machines.StateMetadata{}); err != nil {
+					t.Fatalf("couldn't save state %s: %v", tc.saveState, err)
+				}
+			}
+
+			var archive bytes.Buffer
+			if err := ms.ExportState(context.Background(), tc.state, tc.from, &archive); err != nil {
+				t.Fatalf("couldn't export %s: %v", tc.state, err)
+			}
+			switch tc.archive {
+			case "invalid":
+				archive.Reset()
+				archive.WriteString("this isn't an archive")
+			case "nomanifest", "manifestonly":
+				archive = rewriteArchive(t, archive, tc.archive == "manifestonly")
+			}
+
+			if tc.importDef != "" {
+				importDir, cleanup := testutils.TempDir(t)
+				defer cleanup()
+				libzfs = testutils.GetMockZFS(t)
+				fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.importDef), testutils.WithLibZFS(libzfs))
+				defer fPools.Create(importDir)()
+				ms, err = machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs))
+				if err != nil {
+					t.Error("expected success but got an error scanning for machines", err)
+				}
+			}
+			initMachines := ms.CopyForTests(t)
+
+			stateID, err := ms.ImportState(context.Background(), &archive, tc.asNewMachine)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.wantErr {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				if _, err := ms.IDToState(context.Background(), stateID, ""); err != nil {
+					t.Errorf("imported state %s isn't a known system state: %v", stateID, err)
+				}
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestGC(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def        string
+		all        bool
+		configPath string
+
+		destroyErrDS []string
+
+		isNoOp  bool
+		wantErr bool
+	}{
+		/***** System states only tests *****/
+		"Follow bucket policy":                       {def: "gc_system_only.yaml"},
+		"Follow bucket policy with one empty bucket": {def: "gc_system_only.yaml", configPath: "one_empty_bucket.conf"},
+		"Existing buckets have enough capacity":      {def: "gc_system_only.yaml", configPath: "not_enough_snapshots.conf"},
 
-		"Remove user state, one dataset":                       {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", user: "user4"},
-		"Remove user state, one dataset, no user":              {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", wantErr: true, isNoOp: true},
-		"Remove user state, one dataset, wrong user":           {def: "state_remove.yaml", state: "rpool/USERDATA/user4_clone", user: "root", wantErr: true, isNoOp: true},
-		"Remove user state, with snapshots and clones":         {def: "state_remove.yaml", user: "user6", state: "rpool/USERDATA/user6_clone1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove user state, with snapshots and clones, forced": {def: "state_remove.yaml", user: "user6", state: "rpool/USERDATA/user6_clone1", force: true},
-		"Remove user state, with datasets":                     {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone@snapuser5", user: "user5", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove user state, with datasets, forced":             {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone@snapuser5", user: "user5", force: true},
-		"Remove user snapshot state":                           {def: "state_remove.yaml", state: "rpool/USERDATA/user1_efgh@snapuser2", user: "user1"},
+		"No snapshot, keep everything":                         {def: "m_with_userdata.yaml", isNoOp: true},
+		"Keep previous and current day, purge everything else": {def: "gc_system_only.yaml", configPath: "purge_all_zsys.conf"},
+		"Keep current day, purge everything else":              {def: "gc_system_only.yaml", configPath: "purge_but_previous_zsys.conf"},
+		"Non zsys systems are ignored":                         {def: "gc_system_only_non_zsys.yaml", isNoOp: true},
+		"Keep more snapshots than simply last day has":         {def: "gc_system_only.yaml", configPath: "keep_many_snapshots.conf"},
 
-		"Can’t remove user leaf snapshot linked to system state":                                 {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd@snap1", user: "user1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove user leaf snapshot linked to system state, forced":                               {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd@snap1", user: "user1", force: true},
-		"Can’t remove user leaf filesystem state linked to system state":                         {def: "state_remove.yaml", state: "rpool/USERDATA/user3_3333", user: "user3", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove user leaf filesystem state linked to system state, forced":                       {def: "state_remove.yaml", state: "rpool/USERDATA/user3_3333", user: "user3", force: true},
-		"Can’t remove user filesystem linked to system state with deps linked to system state":   {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd", user: "user1", wantErr: true, wantConfirmationErr: true, isNoOp: true},
-		"Remove user filesystem linked to system state with deps linked to system state, forced": {def: "state_remove.yaml", state: "rpool/USERDATA/user1_abcd", user: "user1", force: true},
+		"Manual snapshot which should be deleted is kept":     {def: "gc_system_only_with_manual_snapshot.yaml"},
+		"Manual snapshot which should be deleted isnt't kept": {def: "gc_system_only_with_manual_snapshot.yaml", all: true},
+		"Snapshots with tags to keep are kept":                {def: "gc_system_only_with_tags.yaml", configPath: "keep_tags.conf"},
+		"Snapshots with tags to keep are kept with all":       {def: "gc_system_only_with_tags.yaml", configPath: "keep_tags.conf", all: true},
+		"Snapshots with other tags are collected":             {def: "gc_system_only_with_tags.yaml"},
+		"Pinned snapshots are kept":                           {def: "gc_system_only_with_pinned.yaml"},
+		"Pinned snapshots are kept with all":                  {def: "gc_system_only_with_pinned.yaml", all: true},
 
-		// Shared user state handling
-		"Remove shared user state linked to a history state. Deps are not removed":                         {def: "state_remove.yaml", state: "rpool/USERDATA/root_bcde-rpool.ROOT.ubuntu-5678", user: "root", force: true},
-		"Remove shared user state linked to current machine state. Deps are not removed":                   {def: "state_remove.yaml", state: "rpool/USERDATA/root_bcde-rpool.ROOT.ubuntu-1234", user: "root", force: true},
-		"Remove shared user state as a dependency of other state. Deps are removed":                        {def: "m_shared_userstate_on_clones.yaml", state: "rpool/USERDATA/user_abcd", user: "user", force: true},
-		"Remove shared user state on different matchines as a dependency of other state. Deps are removed": {def: "m_shared_userstate_on_two_machines.yaml", state: "rpool/USERDATA/user_abcd", user: "user", force: true},
+		"Clone and dependencies are collected within the same bucket":                  {def: "gc_system_only_with_clone_same_bucket.yaml"},
+		"Manual clone without last used is collected":                                  {def: "gc_system_only_with_manual_clone.yaml"},
+		"Clone having snapshots and dependencies are collected within the same bucket": {def: "gc_system_only_with_clone_same_bucket_with_dep.yaml"},
+		"Keep clone and dependencies having manual snapshots":                          {def: "gc_system_only_with_clone_same_bucket_with_manual_dep.yaml"},
+		"Clone cloned and all dependencies are collected within the same bucket":       {def: "gc_system_only_with_clone_same_bucket_with_clone.yaml"},
+		"Clone and dependencies are collected trans bucket (clone after snapshot)":     {def: "gc_system_only_with_clone_different_buckets.yaml"},
+		"Clone with dependencies in bucket before, same and after":                     {def: "gc_system_only_with_clone_and_snapshots_different_buckets.yaml"},
 
-		"No state given": {def: "m_with_userdata.yaml", wantErr: true, isNoOp: true},
-		"Error on trying to remove current state":    {def: "m_with_userdata.yaml", currentStateID: "rpool/ROOT/ubuntu_1234", state: "rpool/ROOT/ubuntu_1234", wantErr: true, isNoOp: true},
-		"Error on destroy state, one dataset":        {def: "m_with_userdata.yaml", state: "rpool/ROOT/ubuntu_1234", destroyErrDS: []string{}, wantErr: true, isNoOp: true},
-		"Error on destroy user state, with datasets": {def: "state_remove.yaml", state: "rpool/USERDATA/user5_for-manual-clone", user: "user5", force: true, destroyErrDS: []string{}, wantErr: true},
+		"Subdataset with some snapshots shared with it":              {def: "gc_system_only_with_children.yaml"},
+		"Subdataset with some snapshots only it are kept":            {def: "gc_system_only_with_children_snapshots_only_on_subdataset.yaml"},
+		"Subdataset with snapshot keeps clone":                       {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset.yaml"},
+		"Subdataset and clone are collected":                         {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset_and_main_state.yaml"},
+		"Subdataset with manual clone on subdataset isn’t collected": {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset_and_main_state_with_manual_clone_on_subdataset.yaml"},
+
+		/***** User states tests *****/
+		"Follow bucket policy with users":                      {def: "gc_system_with_users.yaml"},
+		"User snapshots with tags to keep are kept":            {def: "gc_system_with_users_with_tags.yaml", configPath: "keep_tags.conf"},
+		"Pinned user snapshots are kept":                       {def: "gc_system_with_users_with_pinned.yaml"},
+		"Follow bucket policy with users and one empty bucket": {def: "gc_system_with_users_one_empty_bucket.yaml", configPath: "one_empty_bucket.conf", isNoOp: true},
+		"Keep more user snapshots than simply last day has":    {def: "gc_system_with_users.yaml", configPath: "keep_many_snapshots.conf"},
+		"Follow bucket policy with frequent user states":       {def: "gc_system_with_users_hourly.yaml"},
+		"User states follow their own history rules":           {def: "gc_system_with_users_hourly.yaml", configPath: "user_history.conf"},
+		"User states follow per user history rules":            {def: "gc_system_with_users_hourly.yaml", configPath: "user_history_per_user.conf"},
+
+		// User clones
+		"Remove user clone state":                                                                     {def: "gc_system_with_users_clone.yaml"},
+		"Remove user clone state with subdataset":                                                     {def: "gc_system_with_users_clone_subdataset.yaml"},
+		"Don't remove user clone state with snapshot on it kept":                                      {def: "gc_system_with_users_clone_with_manual_snapshot.yaml", isNoOp: true},
+		"Don't remove user clone state with snapshot on subdataset":                                   {def: "gc_system_with_users_clone_subdataset_with_manual_snapshot.yaml", isNoOp: true},
+		"Don't remove user clone state linked to a system state":                                      {def: "gc_system_with_users_clone_linked_to_system_state.yaml", isNoOp: true},
+		"Ensure user clones are accounted by policy":                                                  {def: "gc_system_with_users_with_untagged_clones.yaml"},
+		"Remove unassociated user clone after deleting its snapshot":                                  {def: "gc_system_with_users_clone_with_auto_snapshot.yaml"},
+		"Remove unassociated user clone after deleting its snapshot which was linked to system state": {def: "gc_system_with_users_clone_with_auto_snapshot_attached_to_system_state.yaml"},
+
+		// User clone attached to multiple states/machines
+		"Users and clones on shared system state":                     {def: "gc_system_with_users_and_clones_shared_system_state.yaml"},
+		"Users and clones on different machines history":              {def: "gc_system_with_users_and_clones_different_machines_history_only.yaml"},
+		"Users and clones on different machines, one is active state": {def: "gc_system_with_users_and_clones_different_machines.yaml"},
+
+		// Unlinked user test cases
+		"Remove simple unlinked user dataset":                                             {def: "gc_system_with_unlinked_users.yaml"},
+		"Don't remove pinned unlinked user dataset":                                       {def: "gc_system_with_unlinked_users_pinned.yaml", isNoOp: true},
+		"Remove unlinked user dataset and any snapshot":                                   {def: "gc_system_with_unlinked_users_and_snapshot.yaml"},
+		"Remove unlinked user dataset and any unmanaged user clone":                       {def: "gc_system_with_unlinked_users_unmanaged_clone.yaml"},
+		"Can't remove unlinked user dataset if it has a manual clone in USERDATA":         {def: "gc_system_with_unlinked_users_unmanaged_clone_nobootfs_dataset.yaml", isNoOp: true},
+		"Can't remove unlinked user dataset if it has a manual clone outside of USERDATA": {def: "gc_system_with_unlinked_users_unmanaged_user_clone.yaml", isNoOp: true},
+		"Remove unlinked clone of a manual filesystem dataset":                            {def: "gc_system_with_unlinked_users_unmanaged_clone_bootfs_on_clone.yaml"},
+
+		// Failed revert
+		"Failed revert - no lastused on system":        {def: "gc_system_failed_revert.yaml"},
+		"Failed boot - no lastused on user and system": {def: "gc_system_with_users_failed_boot.yaml"},
+
+		// Deletion prevention (no infinite loop)
+		"Manual user snapshot which should be deleted is kept": {def: "gc_system_with_users_manual_snapshots.yaml", isNoOp: true},
+		"Users and clones with undeletable snapshot":           {def: "gc_system_with_users_and_clones_undeletable_snapshot.yaml"},
+		"Destroy failed on system dataset":                     {def: "gc_system_with_users_clone_with_auto_snapshot_attached_to_system_state.yaml", destroyErrDS: []string{"rpool/ROOT/ubuntu_1234@autozsys_20191230-1710"}, isNoOp: true},
+		"Destroy failed on user dataset":                       {def: "gc_system_with_users_clone.yaml", destroyErrDS: []string{"rpool/USERDATA/user1_clone"}, isNoOp: true},
+		"Destroy failed on unlinked user dataset":              {def: "gc_system_with_unlinked_users_unmanaged_clone_bootfs_on_clone.yaml", destroyErrDS: []string{"rpool/USERDATA/user2_clone"}, isNoOp: true},
+
+		// Error cases
+		"Error fails to destroy state are kept": {def: "gc_system_with_users.yaml", destroyErrDS: []string{}, isNoOp: true},
+		"Error on invalid user history rules":   {def: "gc_system_with_users.yaml", configPath: "user_history_invalid_tags.conf", isNoOp: true, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			if tc.configPath == "" {
+				tc.configPath = "default.conf"
+			}
+			tc.configPath = filepath.Join("testdata", "confs", tc.configPath)
+
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(libzfs))
+			if err != nil {
+				t.Fatalf("couldn’t create original zfs datasets state")
+			}
+			for _, d := range z.Datasets() {
+				if d.BootfsDatasets == "-" {
+					tz, _ := z.NewTransaction(context.Background())
+					defer tz.Done()
+					if err := tz.SetProperty(libzfsadapter.BootfsDatasetsProp, "", d.Name, false); err != nil {
+						t.Fatalf("couldn’t erase  BootfsDatasetsProp: %v", err)
+					}
+					tz.Done()
+				}
+			}
+			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs),
+				machines.WithTime(testutils.FixedTime{}), machines.WithConfig(tc.configPath))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+
+			initMachines := ms.CopyForTests(t)
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ErrOnDestroyDS(tc.destroyErrDS)
+
+			err = ms.GC(context.Background(), tc.all)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertMachinesEquals(t, initMachines, ms)
+			} else {
+				assertMachinesToGolden(t, ms)
+				assertMachinesNotEquals(t, initMachines, ms)
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
+		})
+	}
+}
+
+func TestGCPlan(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def        string
+		all        bool
+		configPath string
+	}{
+		"Follow bucket policy":                             {def: "gc_system_only.yaml"},
+		"Keep more snapshots than simply last day has":     {def: "gc_system_only.yaml", configPath: "keep_many_snapshots.conf"},
+		"No snapshot, keep everything":                     {def: "m_with_userdata.yaml"},
+		"Manual snapshot is kept":                          {def: "gc_system_only_with_manual_snapshot.yaml"},
+		"Manual snapshot is collected with all":            {def: "gc_system_only_with_manual_snapshot.yaml", all: true},
+		"Snapshots with tags to keep are kept":             {def: "gc_system_only_with_tags.yaml", configPath: "keep_tags.conf"},
+		"Pinned snapshots are kept":                        {def: "gc_system_only_with_pinned.yaml"},
+		"Fallback of a trial boot is kept":                 {def: "gc_system_only_with_trial.yaml"},
+		"Clone and dependencies are collected":             {def: "gc_system_only_with_clone_same_bucket.yaml"},
+		"Keep clone and dependencies with manual snapshot": {def: "gc_system_only_with_clone_same_bucket_with_manual_dep.yaml"},
+		"Follow bucket policy with users":                  {def: "gc_system_with_users.yaml"},
+		"Pinned user snapshots are kept":                   {def: "gc_system_with_users_with_pinned.yaml"},
+		"User clone linked to a system state is kept":      {def: "gc_system_with_users_clone_linked_to_system_state.yaml"},
+		"User clone unlinked by removed system states":     {def: "gc_system_with_users_and_clones_shared_system_state.yaml"},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.configPath = getDefaultValue(tc.configPath, "default.conf")
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs),
+				machines.WithTime(testutils.FixedTime{}), machines.WithConfig(filepath.Join("testdata", "confs", tc.configPath)))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			got, err := ms.GCPlan(context.Background(), tc.all)
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			assertMachinesEquals(t, initMachines, ms)
+
+			want := []machines.GCDecision{}
+			testutils.LoadFromGoldenFile(t, got, &want)
+			for i := range got {
+				got[i].LastUsed = got[i].LastUsed.UTC()
+			}
+			for i := range want {
+				want[i].LastUsed = want[i].LastUsed.UTC()
+			}
+			assert.Equal(t, want, got, "GC plan should match golden file")
+
+			// The plan matches what garbage collection removes on system states
+			if err := ms.GC(context.Background(), tc.all); err != nil {
+				t.Fatalf("expected no error on GC but got: %v", err)
+			}
+			for _, d := range got {
+				if d.User != "" {
+					continue
+				}
+				_, err := ms.IDToState(context.Background(), d.ID, "")
+				assert.Equal(t, d.Remove, err != nil, "State %s removal doesn't match the plan", d.ID)
+			}
+		})
+	}
+}
+
+func TestSimulateGC(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def        string
+		noCurrent  bool
+		configPath string
+		days       int
+		every      time.Duration
+		all        bool
+
+		wantErr bool
+	}{
+		"Follow bucket policy over time":               {},
+		"Follow bucket policy with users":              {def: "gc_system_with_users.yaml"},
+		"Keep more snapshots than simply last day has": {configPath: "keep_many_snapshots.conf"},
+		"Save states less often than daily":            {every: 36 * time.Hour},
+		"Manual snapshot is kept":                      {def: "gc_system_only_with_manual_snapshot.yaml"},
+		"Manual snapshot is collected with all":        {def: "gc_system_only_with_manual_snapshot.yaml", all: true},
+		"Pinned snapshots are kept":                    {def: "gc_system_only_with_pinned.yaml"},
+
+		"Error on no current machine":             {noCurrent: true, wantErr: true},
+		"Error on no day to simulate":             {days: -1, wantErr: true},
+		"Error on interval shorter than a minute": {every: time.Second, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "gc_system_only.yaml")
+			tc.configPath = getDefaultValue(tc.configPath, "default.conf")
+			cmdline := generateCmdLine("rpool/ROOT/ubuntu_1234")
+			if tc.noCurrent {
+				cmdline = ""
+			}
+			if tc.days == 0 {
+				tc.days = 10
+			}
+			if tc.days < 0 {
+				tc.days = 0
+			}
+			if tc.every == 0 {
+				tc.every = 6 * time.Hour
+			}
+
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			libzfs := testutils.GetMockZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
+			defer fPools.Create(dir)()
+
+			ms, err := machines.New(context.Background(), cmdline, machines.WithLibZFS(libzfs), machines.WithTime(testutils.FixedTime{}))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			initMachines := ms.CopyForTests(t)
+
+			conf, err := config.Load(context.Background(), filepath.Join("testdata", "confs", tc.configPath))
+			if err != nil {
+				t.Fatalf("couldn't load configuration: %v", err)
+			}
+
+			got, err := ms.SimulateGC(context.Background(), &conf, tc.days, tc.every, tc.all)
+			if tc.wantErr {
+				assert.Error(t, err, "SimulateGC should return an error")
+				return
+			}
+			assert.NoError(t, err, "SimulateGC should not return an error")
+			assertMachinesEquals(t, initMachines, ms)
+
+			want := []machines.SimulationDay{}
+			testutils.LoadFromGoldenFile(t, got, &want)
+			for i := range got {
+				got[i].Date = got[i].Date.UTC()
+			}
+			for i := range want {
+				want[i].Date = want[i].Date.UTC()
+			}
+			assert.Equal(t, want, got, "Simulation should match golden file")
+		})
+	}
+}
+
+func TestGCSpace(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		def        string
+		all        bool
+		configPath string
+
+		destroyErrDS []string
+
+		isNoOp  bool
+		wantErr bool
+	}{
+		"Remove least valuable states until target is reached":   {},
+		"Remove manual states with all":                          {all: true},
+		"Remove all removable states if target can't be reached": {def: "gc_space_not_enough.yaml"},
+		"Target is already reached":                              {def: "gc_space_target_reached.yaml", isNoOp: true},
+		"No target configured":                                   {configPath: "default.conf", isNoOp: true},
+		"Destroy failed on a state, remove next ones":            {destroyErrDS: []string{"rpool/USERDATA/user1_abcd@autozsys_user1-20191230-1200"}},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+			tc.def = getDefaultValue(tc.def, "gc_space.yaml")
+			tc.configPath = getDefaultValue(tc.configPath, "space.conf")
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
 
@@ -1204,7 +3013,8 @@ func TestRemoveState(t *testing.T) {
 			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			ms, err := machines.New(context.Background(), generateCmdLine(tc.currentStateID), machines.WithLibZFS(libzfs))
+			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs),
+				machines.WithTime(testutils.FixedTime{}), machines.WithConfig(filepath.Join("testdata", "confs", tc.configPath)))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
@@ -1213,21 +3023,14 @@ func TestRemoveState(t *testing.T) {
 			lzfs := libzfs.(*mock.LibZFS)
 			lzfs.ErrOnDestroyDS(tc.destroyErrDS)
 
-			err = ms.RemoveState(context.Background(), tc.state, tc.user, tc.force, false)
+			err = ms.GCSpace(context.Background(), tc.all)
 			if err != nil {
 				if !tc.wantErr {
 					t.Fatalf("expected no error but got: %v", err)
 				}
-				var e *machines.ErrStateRemovalNeedsConfirmation
-
-				if tc.wantConfirmationErr {
-					assert.True(t, errors.As(err, &e), "expected ErrStateRemovalNeedsConfirmation error type")
-				} else {
-					assert.False(t, errors.As(err, &e), "don't expect ErrStateRemovalNeedsConfirmation error type")
-				}
 				return
 			}
-			if err == nil && tc.wantErr {
+			if tc.wantErr {
 				t.Fatal("expected an error but got none")
 			}
 
@@ -1238,7 +3041,7 @@ func TestRemoveState(t *testing.T) {
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
 
-			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine(tc.currentStateID), machines.WithLibZFS(libzfs))
+			machinesAfterRescan, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
@@ -1247,211 +3050,247 @@ func TestRemoveState(t *testing.T) {
 	}
 }
 
-func TestIDToState(t *testing.T) {
+func TestReplicate(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		name string
-		user string
+		def           string
+		configPath    string
+		saveStateThen bool
 
-		wantState string
-		wantErr   bool
+		isNoOp  bool
+		wantErr bool
 	}{
-		"Match full system path ID": {name: "rpool/ROOT/ubuntu_1234", wantState: "rpool/ROOT/ubuntu_1234"},
-		"Match full user path ID":   {name: "rpool/USERDATA/user1_abcd", user: "user1", wantState: "rpool/USERDATA/user1_abcd"},
-
-		"Match suffix system ID":       {name: "5678", wantState: "rpool/ROOT/ubuntu_5678"},
-		"Match dataset path system ID": {name: "ubuntu_5678", wantState: "rpool/ROOT/ubuntu_5678"},
-		"Match unique system snapshot": {name: "snap1", wantState: "rpool/ROOT/ubuntu_1234@snap1"},
-
-		"Limit search on duplicated snapshot name to a single user": {name: "snap1", user: "user1", wantState: "rpool/USERDATA/user1_abcd@snap1"},
-
-		// User datasets shared between machines
-		"Match on user generated ID":                                                         {name: "jklm-rpool.ROOT.ubuntu-1234", user: "user2", wantState: "rpool/USERDATA/user2_jklm"},
-		"Doesn’t match on user dataset regular name":                                         {name: "rpool/USERDATA/user2_jklm", user: "user2", wantErr: true},
-		"User data attached to 2 machines but only linked to one system state isn’t renamed": {name: "rpool/USERDATA/user3_mnop", user: "user3", wantState: "rpool/USERDATA/user3_mnop"},
-
-		// Multiple matches
-		"Multiple states match system suffix ID": {name: "1234", wantErr: true},
-		"Multiple states match user suffix ID":   {name: "abcd", user: "user1", wantErr: true},
-
-		// No match
-		"Empty name":         {name: "", wantErr: true},
-		"No match at all":    {name: "/doesntexists", wantErr: true},
-		"User doesn’t exist": {name: "foo", user: "userfoo", wantErr: true},
-		"No match on full path ID search without user provided":    {name: "rpool/USERDATA/user1_abcd", wantErr: true},
-		"No match on full path ID search with wrong user provided": {name: "rpool/USERDATA/user1_abcd", user: "userfoo", wantErr: true},
+		"Replicate system and user states":                  {def: "replication_system_with_users.yaml"},
+		"Keep only the most recent replicated states":       {def: "replication_system_with_users.yaml", configPath: "replication_keep_one.conf"},
+		"Replicate new state incrementally on second run":   {def: "replication_system_with_users.yaml", saveStateThen: true},
+		"Replicate again dataset without shared state":      {def: "replication_system_with_users_partially_replicated.yaml"},
+		"Roll back diverged dataset to latest shared state": {def: "replication_system_with_users_diverged.yaml"},
+		"Replicate clone incrementally from its origin":     {def: "replication_system_with_clone.yaml"},
+		"Keep replicated clone origins":                     {def: "replication_system_with_clone.yaml", configPath: "replication_keep_one.conf"},
+		"No replication target configured, nothing to do":   {def: "replication_system_with_users.yaml", configPath: "default.conf", isNoOp: true},
+		"Error on replication target doesn't exist":         {def: "replication_system_with_users.yaml", configPath: "replication_target_doesnt_exist.conf", isNoOp: true, wantErr: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
 
 			libzfs := testutils.GetMockZFS(t)
-			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "state_idtostate.yaml"), testutils.WithLibZFS(libzfs))
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			_, err := zfs.New(context.Background(), zfs.WithLibZFS(libzfs))
-			if err != nil {
-				t.Fatalf("couldn’t create original zfs datasets state")
+			if tc.configPath == "" {
+				tc.configPath = "replication.conf"
 			}
+			tc.configPath = filepath.Join("testdata", "confs", tc.configPath)
 
-			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs))
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs),
+				machines.WithTime(testutils.FixedTime{}), machines.WithConfig(tc.configPath))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
+			initMachines := ms.CopyForTests(t)
 
-			got, err := ms.IDToState(context.Background(), tc.name, tc.user)
+			replicate := func() error {
+				r := ms.PrepareReplication(context.Background())
+				errRun := r.Run(context.Background())
+				if err := ms.RefreshReplicated(context.Background(), r); err != nil {
+					t.Fatalf("expected no error refreshing replicated datasets but got: %v", err)
+				}
+				return errRun
+			}
 
-			if err != nil {
-				if !tc.wantErr {
-					t.Fatalf("Got an error when expecting none: %v", err)
+			err = replicate()
+			if tc.saveStateThen {
+				if _, err := ms.CreateSystemSnapshot(context.Background(), "snap3", machines.StateMetadata{}); err != nil {
+					t.Fatalf("couldn't save state between replications: %v", err)
 				}
-				return
-			} else if tc.wantErr {
-				t.Fatalf("Expected an error but got none")
+				initMachines = ms.CopyForTests(t)
+				err = replicate()
+			}
+			if err != nil && !tc.wantErr {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
 			}
 
-			assert.Equal(t, tc.wantState, got.ID, "didn't get expected state")
+			// Replication doesn't change any machine
+			assertMachinesEquals(t, initMachines, ms)
+
+			if !tc.isNoOp {
+				z, err := zfs.New(context.Background(), zfs.WithLibZFS(libzfs))
+				if err != nil {
+					t.Fatalf("couldn't scan datasets: %v", err)
+				}
+				var got []string
+				for _, d := range z.Datasets() {
+					if !strings.HasPrefix(d.Name, "backup/zsys/") {
+						continue
+					}
+					if d.IsSnapshot {
+						got = append(got, d.Name)
+						continue
+					}
+					if d.Origin != "" {
+						got = append(got, fmt.Sprintf("%s (canmount: %s, origin: %s)", d.Name, d.CanMount, d.Origin))
+						continue
+					}
+					got = append(got, fmt.Sprintf("%s (canmount: %s)", d.Name, d.CanMount))
+				}
+				sort.Strings(got)
+				var want []string
+				testutils.LoadFromGoldenFile(t, got, &want)
+				assert.Equal(t, want, got, "replicated datasets don't match golden file")
+			}
+
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs),
+				machines.WithConfig(tc.configPath))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
+			}
+			assertMachinesEquals(t, machinesAfterRescan, ms)
 		})
 	}
 }
 
-func TestGC(t *testing.T) {
-	t.Parallel()
-	tests := map[string]struct {
-		def        string
-		all        bool
-		configPath string
+func BenchmarkNewDesktop(b *testing.B) {
+	config.SetVerboseMode(0)
+	defer func() { config.SetVerboseMode(1) }()
 
-		destroyErrDS []string
+	dir, cleanup := testutils.TempDir(b)
+	defer cleanup()
 
-		isNoOp  bool
-		wantErr bool
-	}{
-		/***** System states only tests *****/
-		"Follow bucket policy":                       {def: "gc_system_only.yaml"},
-		"Follow bucket policy with one empty bucket": {def: "gc_system_only.yaml", configPath: "one_empty_bucket.conf"},
-		"Existing buckets have enough capacity":      {def: "gc_system_only.yaml", configPath: "not_enough_snapshots.conf"},
+	libzfs := testutils.GetMockZFS(b)
+	fPools := testutils.NewFakePools(b, filepath.Join("testdata", "m_layout1_machines_with_snapshots_clones.yaml"), testutils.WithLibZFS(libzfs))
+	defer fPools.Create(dir)()
 
-		"No snapshot, keep everything":                         {def: "m_with_userdata.yaml", isNoOp: true},
-		"Keep previous and current day, purge everything else": {def: "gc_system_only.yaml", configPath: "purge_all_zsys.conf"},
-		"Keep current day, purge everything else":              {def: "gc_system_only.yaml", configPath: "purge_but_previous_zsys.conf"},
-		"Non zsys systems are ignored":                         {def: "gc_system_only_non_zsys.yaml", isNoOp: true},
-		"Keep more snapshots than simply last day has":         {def: "gc_system_only.yaml", configPath: "keep_many_snapshots.conf"},
+	for n := 0; n < b.N; n++ {
+		machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_5678"), machines.WithLibZFS(libzfs))
+	}
+}
 
-		"Manual snapshot which should be deleted is kept":     {def: "gc_system_only_with_manual_snapshot.yaml"},
-		"Manual snapshot which should be deleted isnt't kept": {def: "gc_system_only_with_manual_snapshot.yaml", all: true},
+func BenchmarkNewServer(b *testing.B) {
+	config.SetVerboseMode(0)
+	defer func() { config.SetVerboseMode(1) }()
 
-		"Clone and dependencies are collected within the same bucket":                  {def: "gc_system_only_with_clone_same_bucket.yaml"},
-		"Manual clone without last used is collected":                                  {def: "gc_system_only_with_manual_clone.yaml"},
-		"Clone having snapshots and dependencies are collected within the same bucket": {def: "gc_system_only_with_clone_same_bucket_with_dep.yaml"},
-		"Keep clone and dependencies having manual snapshots":                          {def: "gc_system_only_with_clone_same_bucket_with_manual_dep.yaml"},
-		"Clone cloned and all dependencies are collected within the same bucket":       {def: "gc_system_only_with_clone_same_bucket_with_clone.yaml"},
-		"Clone and dependencies are collected trans bucket (clone after snapshot)":     {def: "gc_system_only_with_clone_different_buckets.yaml"},
-		"Clone with dependencies in bucket before, same and after":                     {def: "gc_system_only_with_clone_and_snapshots_different_buckets.yaml"},
+	dir, cleanup := testutils.TempDir(b)
+	defer cleanup()
 
-		"Subdataset with some snapshots shared with it":              {def: "gc_system_only_with_children.yaml"},
-		"Subdataset with some snapshots only it are kept":            {def: "gc_system_only_with_children_snapshots_only_on_subdataset.yaml"},
-		"Subdataset with snapshot keeps clone":                       {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset.yaml"},
-		"Subdataset and clone are collected":                         {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset_and_main_state.yaml"},
-		"Subdataset with manual clone on subdataset isn’t collected": {def: "gc_system_only_with_clone_same_bucket_with_snapshot_on_subdataset_and_main_state_with_manual_clone_on_subdataset.yaml"},
+	libzfs := testutils.GetMockZFS(b)
+	fPools := testutils.NewFakePools(b, filepath.Join("testdata", "m_layout2_machines_with_snapshots_clones.yaml"), testutils.WithLibZFS(libzfs))
+	defer fPools.Create(dir)()
 
-		/***** User states tests *****/
-		"Follow bucket policy with users":                      {def: "gc_system_with_users.yaml"},
-		"Follow bucket policy with users and one empty bucket": {def: "gc_system_with_users_one_empty_bucket.yaml", configPath: "one_empty_bucket.conf", isNoOp: true},
-		"Keep more user snapshots than simply last day has":    {def: "gc_system_with_users.yaml", configPath: "keep_many_snapshots.conf"},
+	for n := 0; n < b.N; n++ {
+		machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_5678"), machines.WithLibZFS(libzfs))
+	}
+}
 
-		// User clones
-		"Remove user clone state":                                                                     {def: "gc_system_with_users_clone.yaml"},
-		"Remove user clone state with subdataset":                                                     {def: "gc_system_with_users_clone_subdataset.yaml"},
-		"Don't remove user clone state with snapshot on it kept":                                      {def: "gc_system_with_users_clone_with_manual_snapshot.yaml", isNoOp: true},
-		"Don't remove user clone state with snapshot on subdataset":                                   {def: "gc_system_with_users_clone_subdataset_with_manual_snapshot.yaml", isNoOp: true},
-		"Don't remove user clone state linked to a system state":                                      {def: "gc_system_with_users_clone_linked_to_system_state.yaml", isNoOp: true},
-		"Ensure user clones are accounted by policy":                                                  {def: "gc_system_with_users_with_untagged_clones.yaml"},
-		"Remove unassociated user clone after deleting its snapshot":                                  {def: "gc_system_with_users_clone_with_auto_snapshot.yaml"},
-		"Remove unassociated user clone after deleting its snapshot which was linked to system state": {def: "gc_system_with_users_clone_with_auto_snapshot_attached_to_system_state.yaml"},
+func TestApplyEvents(t *testing.T) {
+	t.Parallel()
 
-		// User clone attached to multiple states/machines
-		"Users and clones on shared system state":                     {def: "gc_system_with_users_and_clones_shared_system_state.yaml"},
-		"Users and clones on different machines history":              {def: "gc_system_with_users_and_clones_different_machines_history_only.yaml"},
-		"Users and clones on different machines, one is active state": {def: "gc_system_with_users_and_clones_different_machines.yaml"},
+	historyEvent := func(operation, dataset string) libzfsadapter.Event {
+		return libzfsadapter.Event{Class: "sysevent.fs.zfs.history_event", Pool: "rpool", Dataset: dataset, Operation: operation}
+	}
 
-		// Unlinked user test cases
-		"Remove simple unlinked user dataset":                                             {def: "gc_system_with_unlinked_users.yaml"},
-		"Remove unlinked user dataset and any snapshot":                                   {def: "gc_system_with_unlinked_users_and_snapshot.yaml"},
-		"Remove unlinked user dataset and any unmanaged user clone":                       {def: "gc_system_with_unlinked_users_unmanaged_clone.yaml"},
-		"Can't remove unlinked user dataset if it has a manual clone in USERDATA":         {def: "gc_system_with_unlinked_users_unmanaged_clone_nobootfs_dataset.yaml", isNoOp: true},
-		"Can't remove unlinked user dataset if it has a manual clone outside of USERDATA": {def: "gc_system_with_unlinked_users_unmanaged_user_clone.yaml", isNoOp: true},
-		"Remove unlinked clone of a manual filesystem dataset":                            {def: "gc_system_with_unlinked_users_unmanaged_clone_bootfs_on_clone.yaml"},
+	tests := map[string]struct {
+		def string
 
-		// Failed revert
-		"Failed revert - no lastused on system":        {def: "gc_system_failed_revert.yaml"},
-		"Failed boot - no lastused on user and system": {def: "gc_system_with_users_failed_boot.yaml"},
+		// changes done outside of zsys, or by ourselves
+		ourselves     bool
+		snapshot      string
+		cloneSuffix   string
+		setPropertyOn string
 
-		// Deletion prevention (no infinite loop)
-		"Manual user snapshot which should be deleted is kept": {def: "gc_system_with_users_manual_snapshots.yaml", isNoOp: true},
-		"Users and clones with undeletable snapshot":           {def: "gc_system_with_users_and_clones_undeletable_snapshot.yaml"},
-		"Destroy failed on system dataset":                     {def: "gc_system_with_users_clone_with_auto_snapshot_attached_to_system_state.yaml", destroyErrDS: []string{"rpool/ROOT/ubuntu_1234@autozsys_20191230-1710"}, isNoOp: true},
-		"Destroy failed on user dataset":                       {def: "gc_system_with_users_clone.yaml", destroyErrDS: []string{"rpool/USERDATA/user1_clone"}, isNoOp: true},
-		"Destroy failed on unlinked user dataset":              {def: "gc_system_with_unlinked_users_unmanaged_clone_bootfs_on_clone.yaml", destroyErrDS: []string{"rpool/USERDATA/user2_clone"}, isNoOp: true},
+		events []libzfsadapter.Event
 
-		// Error cases
-		"Error fails to destroy state are kept": {def: "gc_system_with_users.yaml", destroyErrDS: []string{}, isNoOp: true},
+		wantIncremental bool
+		isNoOp          bool
+	}{
+		"State saved outside of zsys": {def: "m_with_userdata.yaml", snapshot: "outside",
+			events:          []libzfsadapter.Event{historyEvent("snapshot", "rpool/ROOT/ubuntu_1234@outside")},
+			wantIncremental: true},
+		"Description set outside of zsys": {def: "m_with_userdata.yaml", setPropertyOn: "rpool/ROOT/ubuntu_1234",
+			events:          []libzfsadapter.Event{historyEvent("set", "rpool/ROOT/ubuntu_1234")},
+			wantIncremental: true},
+		"Machine cloned outside of zsys": {def: "m_with_userdata.yaml", snapshot: "outside", cloneSuffix: "5678",
+			events: []libzfsadapter.Event{historyEvent("snapshot", "rpool/ROOT/ubuntu_1234@outside"), historyEvent("clone", "rpool/ROOT/ubuntu_5678")}},
+
+		"Own state saved is ignored": {def: "m_with_userdata.yaml", ourselves: true, snapshot: "outside",
+			events:          []libzfsadapter.Event{historyEvent("snapshot", "rpool/ROOT/ubuntu_1234@outside")},
+			wantIncremental: true, isNoOp: true},
+		"Own description set is ignored": {def: "m_with_userdata.yaml", ourselves: true, setPropertyOn: "rpool/ROOT/ubuntu_1234",
+			events:          []libzfsadapter.Event{historyEvent("set", "rpool/ROOT/ubuntu_1234")},
+			wantIncremental: true, isNoOp: true},
+		"Unrelated event is ignored": {def: "m_with_userdata.yaml", events: []libzfsadapter.Event{{Class: "ereport.fs.zfs.checksum", Pool: "rpool"}},
+			wantIncremental: true, isNoOp: true},
 	}
 
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+
 			dir, cleanup := testutils.TempDir(t)
 			defer cleanup()
-
 			libzfs := testutils.GetMockZFS(t)
 			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(libzfs))
 			defer fPools.Create(dir)()
 
-			if tc.configPath == "" {
-				tc.configPath = "default.conf"
+			lzfs := libzfs.(*mock.LibZFS)
+			lzfs.ForceLastUsedTime(true)
+
+			ms, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
+			if err != nil {
+				t.Error("expected success but got an error scanning for machines", err)
 			}
-			tc.configPath = filepath.Join("testdata", "confs", tc.configPath)
 
+			// Change the system through another zfs object, as another tool would do, or through our own.
 			z, err := zfs.New(context.Background(), zfs.WithLibZFS(libzfs))
 			if err != nil {
-				t.Fatalf("couldn’t create original zfs datasets state")
+				t.Fatalf("couldn't scan datasets: %v", err)
 			}
-			for _, d := range z.Datasets() {
-				if d.BootfsDatasets == "-" {
-					tz, _ := z.NewTransaction(context.Background())
-					defer tz.Done()
-					if err := tz.SetProperty(libzfsadapter.BootfsDatasetsProp, "", d.Name, false); err != nil {
-						t.Fatalf("couldn’t erase  BootfsDatasetsProp: %v", err)
-					}
-					tz.Done()
+			if tc.ourselves {
+				z = ms.Z()
+			}
+			trans, _ := z.NewTransaction(context.Background())
+			if tc.snapshot != "" {
+				if err := trans.Snapshot(tc.snapshot, "rpool/ROOT/ubuntu_1234", false); err != nil {
+					t.Fatalf("couldn't setup testbed when snapshotting: %v", err)
 				}
 			}
-			ms, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs),
-				machines.WithTime(testutils.FixedTime{}), machines.WithConfig(tc.configPath))
-			if err != nil {
-				t.Error("expected success but got an error scanning for machines", err)
+			if tc.cloneSuffix != "" {
+				if err := trans.Clone("rpool/ROOT/ubuntu_1234@"+tc.snapshot, tc.cloneSuffix, false, true); err != nil {
+					t.Fatalf("couldn't setup testbed when cloning: %v", err)
+				}
+			}
+			if tc.setPropertyOn != "" {
+				if err := trans.SetProperty(libzfsadapter.DescriptionProp, "New description", tc.setPropertyOn, false); err != nil {
+					t.Fatalf("couldn't setup testbed when setting property: %v", err)
+				}
+			}
+			trans.Done()
+			if tc.ourselves {
+				if err := ms.Refresh(context.Background()); err != nil {
+					t.Fatalf("couldn't setup testbed when refreshing machines: %v", err)
+				}
 			}
 
 			initMachines := ms.CopyForTests(t)
-			lzfs := libzfs.(*mock.LibZFS)
-			lzfs.ErrOnDestroyDS(tc.destroyErrDS)
+			current := ms.AllMachines()["rpool/ROOT/ubuntu_1234"]
 
-			err = ms.GC(context.Background(), tc.all)
-			if err != nil {
-				if !tc.wantErr {
-					t.Fatalf("expected no error but got: %v", err)
-				}
-				return
-			}
-			if err == nil && tc.wantErr {
-				t.Fatal("expected an error but got none")
+			if err := ms.ApplyEvents(context.Background(), tc.events); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
 			}
 
+			if tc.wantIncremental {
+				assert.Same(t, current, ms.AllMachines()["rpool/ROOT/ubuntu_1234"], "Events are applied to the existing machines")
+			} else {
+				assert.NotSame(t, current, ms.AllMachines()["rpool/ROOT/ubuntu_1234"], "Machines are reloaded")
+			}
 			if tc.isNoOp {
 				assertMachinesEquals(t, initMachines, ms)
 			} else {
@@ -1459,7 +3298,7 @@ func TestGC(t *testing.T) {
 				assertMachinesNotEquals(t, initMachines, ms)
 			}
 
-			machinesAfterRescan, err := machines.New(context.Background(), "", machines.WithLibZFS(libzfs))
+			machinesAfterRescan, err := machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_1234"), machines.WithLibZFS(libzfs))
 			if err != nil {
 				t.Error("expected success but got an error scanning for machines", err)
 			}
@@ -1468,36 +3307,58 @@ func TestGC(t *testing.T) {
 	}
 }
 
-func BenchmarkNewDesktop(b *testing.B) {
-	config.SetVerboseMode(0)
-	defer func() { config.SetVerboseMode(1) }()
-
-	dir, cleanup := testutils.TempDir(b)
-	defer cleanup()
+// mounterMock records mounts and unmounts instead of doing them.
+type mounterMock struct {
+	// failOn is the dataset or mountpoint failing to be mounted or unmounted.
+	failOn string
 
-	libzfs := testutils.GetMockZFS(b)
-	fPools := testutils.NewFakePools(b, filepath.Join("testdata", "m_layout1_machines_with_snapshots_clones.yaml"), testutils.WithLibZFS(libzfs))
-	defer fPools.Create(dir)()
+	calls []string
+}
 
-	for n := 0; n < b.N; n++ {
-		machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_5678"), machines.WithLibZFS(libzfs))
+func (m *mounterMock) Mount(dataset, mountpoint string) error {
+	m.calls = append(m.calls, fmt.Sprintf("mount %s on %s", dataset, mountpoint))
+	if dataset == m.failOn {
+		return errors.New("Mount failed")
 	}
+	return nil
 }
 
-func BenchmarkNewServer(b *testing.B) {
-	config.SetVerboseMode(0)
-	defer func() { config.SetVerboseMode(1) }()
-
-	dir, cleanup := testutils.TempDir(b)
-	defer cleanup()
+func (m *mounterMock) Unmount(mountpoint string) error {
+	m.calls = append(m.calls, fmt.Sprintf("unmount %s", mountpoint))
+	if mountpoint == m.failOn {
+		return errors.New("Unmount failed")
+	}
+	return nil
+}
 
-	libzfs := testutils.GetMockZFS(b)
-	fPools := testutils.NewFakePools(b, filepath.Join("testdata", "m_layout2_machines_with_snapshots_clones.yaml"), testutils.WithLibZFS(libzfs))
-	defer fPools.Create(dir)()
+// rewriteArchive returns a copy of archive either without its manifest or with only its manifest.
+func rewriteArchive(t *testing.T, archive bytes.Buffer, manifestOnly bool) (r bytes.Buffer) {
+	t.Helper()
 
-	for n := 0; n < b.N; n++ {
-		machines.New(context.Background(), generateCmdLine("rpool/ROOT/ubuntu_5678"), machines.WithLibZFS(libzfs))
+	tr := tar.NewReader(&archive)
+	tw := tar.NewWriter(&r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("couldn't read archive: %v", err)
+		}
+		if (i == 0) == !manifestOnly {
+			continue
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("couldn't write archive: %v", err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			t.Fatalf("couldn't write archive: %v", err)
+		}
 	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("couldn't write archive: %v", err)
+	}
+	return r
 }
 
 // assertMachinesToGolden compares got slice of machines to reference files, based on test name.
@@ -1549,6 +3410,14 @@ func generateCmdLineWithRevert(datasetAndBoot string) string {
 	return generateCmdLine(datasetAndBoot) + " " + machines.RevertUserDataTag
 }
 
+// manyPackages returns a list of n fake package names
+func manyPackages(n int) (pkgs []string) {
+	for i := 0; i < n; i++ {
+		pkgs = append(pkgs, fmt.Sprintf("package-%04d", i))
+	}
+	return pkgs
+}
+
 // getDefaultValue returns default value for this parameter
 func getDefaultValue(v, defaultVal string) string {
 	if v == "" {
@@ -0,0 +1,76 @@
+package machines
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+)
+
+// Mounter mounts and unmounts filesystem datasets on the running system.
+type Mounter interface {
+	Mount(dataset, mountpoint string) error
+	Unmount(mountpoint string) error
+}
+
+// syscallMounter mounts datasets like zfs mount does.
+type syscallMounter struct{}
+
+func (syscallMounter) Mount(dataset, mountpoint string) error {
+	return syscall.Mount(dataset, mountpoint, "zfs", 0, "zfsutil")
+}
+
+func (syscallMounter) Unmount(mountpoint string) error {
+	return syscall.Unmount(mountpoint, 0)
+}
+
+// swapMounts unmounts the mounted datasets of previous, children first, and mounts in place the datasets of replacement
+// which can be mounted, parents first. previous and replacement are ordered with parents first.
+// If any step fails, the previous mounts are restored.
+func (ms *Machines) swapMounts(ctx context.Context, previous, replacement []*zfs.Dataset) (err error) {
+	var unmounted, mounted []*zfs.Dataset
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(mounted) - 1; i >= 0; i-- {
+			if err := ms.mount.Unmount(mounted[i].Mountpoint); err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't unmount %s while restoring previous mounts: %v"), mounted[i].Mountpoint, err)
+			}
+		}
+		for i := len(unmounted) - 1; i >= 0; i-- {
+			if err := ms.mount.Mount(unmounted[i].Name, unmounted[i].Mountpoint); err != nil {
+				log.Warningf(ctx, i18n.G("Couldn't mount %s while restoring previous mounts: %v"), unmounted[i].Mountpoint, err)
+			}
+		}
+	}()
+
+	mountpoints := make(map[string]bool)
+	for i := len(previous) - 1; i >= 0; i-- {
+		d := previous[i]
+		if d.IsSnapshot || !d.Mounted || d.Mountpoint == "" {
+			continue
+		}
+		if err := ms.mount.Unmount(d.Mountpoint); err != nil {
+			return fmt.Errorf(i18n.G("couldn't unmount %s: ")+config.ErrorFormat, d.Mountpoint, err)
+		}
+		unmounted = append(unmounted, d)
+		mountpoints[d.Mountpoint] = true
+	}
+
+	for _, d := range replacement {
+		if d.IsSnapshot || d.CanMount == "off" || !mountpoints[d.Mountpoint] {
+			continue
+		}
+		if err := ms.mount.Mount(d.Name, d.Mountpoint); err != nil {
+			return fmt.Errorf(i18n.G("couldn't mount %s on %s: ")+config.ErrorFormat, d.Name, d.Mountpoint, err)
+		}
+		mounted = append(mounted, d)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package machines
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+)
+
+// PackageChanges lists the packages which were about to be upgraded, installed or removed when a state was saved.
+type PackageChanges struct {
+	Upgraded  []string
+	Installed []string
+	Removed   []string
+
+	// omitted is the number of packages, per action, which couldn't be stored.
+	omitted [3]int
+}
+
+const (
+	// maxPackageChangesLen is the maximum length of stored package changes, as ZFS limits user property values.
+	maxPackageChangesLen = 4096
+	// summaryPackagesPerAction is the number of package names per action displayed in a summary.
+	summaryPackagesPerAction = 2
+)
+
+// packageChangesActions are the keys of each list of PackageChanges when stored.
+var packageChangesActions = [3]string{"upgraded", "installed", "removed"}
+
+// lists returns package lists in the order of packageChangesActions.
+func (c *PackageChanges) lists() [3]*[]string {
+	return [3]*[]string{&c.Upgraded, &c.Installed, &c.Removed}
+}
+
+// isEmpty returns if no package change is recorded.
+func (c PackageChanges) isEmpty() bool {
+	return len(c.Upgraded) == 0 && len(c.Installed) == 0 && len(c.Removed) == 0
+}
+
+// encode serializes package changes as "upgraded=pkg1,pkg2;installed=pkg3;removed=pkg4" to store them as a user
+// property. Package names which don't fit are replaced by their count, as "+N", at the end of their list.
+// Each action gets an even share of the available space, which it gives back to the next ones if unused.
+func (c PackageChanges) encode() string {
+	// Keep room for the separators and omitted package counts of each action.
+	budget := maxPackageChangesLen
+	var nonEmpty int
+	for i, pkgs := range c.lists() {
+		budget -= len(";" + packageChangesActions[i] + "=,+99999")
+		if len(*pkgs) > 0 {
+			nonEmpty++
+		}
+	}
+
+	var parts []string
+	for i, pkgs := range c.lists() {
+		if len(*pkgs) == 0 {
+			continue
+		}
+		share := budget / nonEmpty
+		nonEmpty--
+
+		var kept []string
+		for j, p := range *pkgs {
+			if len(p)+1 > share {
+				kept = append(kept, fmt.Sprintf("+%d", len(*pkgs)-j))
+				break
+			}
+			share -= len(p) + 1
+			budget -= len(p) + 1
+			kept = append(kept, p)
+		}
+		parts = append(parts, packageChangesActions[i]+"="+strings.Join(kept, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// decodePackageChanges parses package changes stored by encode. Unknown actions are ignored.
+func decodePackageChanges(s string) (c PackageChanges) {
+	lists := c.lists()
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		for i, a := range packageChangesActions {
+			if kv[0] != a {
+				continue
+			}
+			for _, p := range strings.Split(kv[1], ",") {
+				if strings.HasPrefix(p, "+") {
+					if n, err := strconv.Atoi(p[1:]); err == nil {
+						c.omitted[i] += n
+						continue
+					}
+				}
+				if p != "" {
+					*lists[i] = append(*lists[i], p)
+				}
+			}
+		}
+	}
+	return c
+}
+
+// summary returns a short description of package changes, like "before upgrading linux-image, firefox (+12)".
+func (c PackageChanges) summary() string {
+	verbs := [3]string{i18n.G("upgrading"), i18n.G("installing"), i18n.G("removing")}
+
+	var parts []string
+	var remaining int
+	for i, pkgs := range c.lists() {
+		if len(*pkgs) == 0 && c.omitted[i] == 0 {
+			continue
+		}
+		shown := *pkgs
+		if len(shown) > summaryPackagesPerAction {
+			shown = shown[:summaryPackagesPerAction]
+		}
+		remaining += len(*pkgs) - len(shown) + c.omitted[i]
+		if len(shown) == 0 {
+			// Only omitted packages for this action: they are part of the remaining count.
+			continue
+		}
+		parts = append(parts, verbs[i]+" "+strings.Join(shown, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	s := fmt.Sprintf(i18n.G("before %s"), strings.Join(parts, ", "))
+	if remaining > 0 {
+		s += fmt.Sprintf(" (+%d)", remaining)
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package machines
+
+import (
+	"context"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// pinnedValue is the value of the pinned user property on pinned states.
+const pinnedValue = "yes"
+
+// PinState marks the state name as pinned, so that garbage collection never removes it, or unpins it if pin is false.
+// user limits the research on the given user state, otherwise the state is a system one.
+func (ms *Machines) PinState(ctx context.Context, name, user string, pin bool) error {
+	s, err := ms.IDToState(ctx, name, user)
+	if err != nil {
+		return err
+	}
+
+	if s.isPinned() == pin {
+		if pin {
+			log.Infof(ctx, i18n.G("%s is already pinned"), s.ID)
+		} else {
+			log.Infof(ctx, i18n.G("%s isn't pinned"), s.ID)
+		}
+		return nil
+	}
+
+	var v string
+	if pin {
+		log.Infof(ctx, i18n.G("Pinning %s"), s.ID)
+		v = pinnedValue
+	} else {
+		log.Infof(ctx, i18n.G("Unpinning %s"), s.ID)
+	}
+
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+	if err := t.SetProperty(libzfs.PinnedProp, v, s.ID, true); err != nil {
+		cancel()
+		return err
+	}
+
+	// Children datasets of filesystem states inherit the property.
+	return ms.applyChanges(ctx)
+}
+
+// isPinned returns if this state should never be garbage collected.
+func (s State) isPinned() bool {
+	ds, ok := s.Datasets[s.ID]
+	if !ok || len(ds) == 0 {
+		return false
+	}
+	return ds[0].Pinned == pinnedValue
+}
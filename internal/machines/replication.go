@@ -0,0 +1,408 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// Replication is the list of saved states to mirror to the replication target, captured from the machines.
+// It doesn't reference the machines, so that streaming the states doesn't need to hold them.
+type Replication struct {
+	target   string
+	keepLast int
+	sources  []replicationSource
+	z        *zfs.Zfs
+}
+
+// replicationSource is a system or user filesystem dataset to replicate.
+type replicationSource struct {
+	name string
+	// origin is the snapshot this dataset is a clone of, if any.
+	origin string
+	// snapshots are the snapshot names of this dataset, from the oldest to the most recent.
+	snapshots []string
+}
+
+// PrepareReplication lists all system and user saved states to replicate to the replication target of the
+// configuration. Parents and clone origins are listed before the datasets depending on them.
+func (ms *Machines) PrepareReplication(ctx context.Context) *Replication {
+	r := &Replication{
+		target:   ms.conf.Replication.Target,
+		keepLast: ms.conf.Replication.KeepLast,
+		z:        ms.z,
+	}
+	if r.target == "" {
+		return r
+	}
+
+	snapshots := make(map[string][]*zfs.Dataset)
+	origins := make(map[string]string)
+	for _, d := range append(append([]*zfs.Dataset(nil), ms.allSystemDatasets...), ms.allUsersDatasets...) {
+		if !d.IsSnapshot {
+			origins[d.Name] = d.Origin
+			continue
+		}
+		base, _ := splitSnapshotName(d.Name)
+		snapshots[base] = append(snapshots[base], d)
+	}
+
+	sources := make(map[string]replicationSource)
+	for n, ds := range snapshots {
+		s := replicationSource{name: n, origin: origins[n]}
+		for _, d := range sortedSnapshots(ds) {
+			s.snapshots = append(s.snapshots, d.Name)
+		}
+		sources[n] = s
+	}
+	r.sources = replicationOrder(sources)
+
+	return r
+}
+
+// Configured returns if a replication target is configured.
+func (r *Replication) Configured() bool {
+	return r.target != ""
+}
+
+// Run mirrors the saved states to the replication target. Each filesystem dataset is replicated under the target with
+// its full name. The first replication of a dataset sends a full stream of its oldest snapshot, or a stream
+// incremental from its origin if it's a clone of a replicated dataset. Next ones are incremental from the latest
+// snapshot shared with the target. Only the most recent replicated states are kept on the target, as configured.
+// It works on its own view of the datasets: the machines aren't used and need to be refreshed once done.
+func (r *Replication) Run(ctx context.Context) error {
+	if r.target == "" {
+		log.Info(ctx, i18n.G("No replication target configured, nothing to do"))
+		return nil
+	}
+
+	z, err := r.z.Fork(ctx)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't scan datasets to replicate: ")+config.ErrorFormat, err)
+	}
+	if !datasetExists(z, r.target) {
+		return fmt.Errorf(i18n.G("replication target %q doesn't exist"), r.target)
+	}
+
+	log.Infof(ctx, i18n.G("Replicating states to %s"), r.target)
+
+	var errs []string
+	for _, s := range r.sources {
+		if err := r.replicateDataset(ctx, z, s); err != nil {
+			log.Warningf(ctx, i18n.G("couldn't replicate %s: %v"), s.name, err)
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if err := r.pruneReplicatedStates(ctx, z); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(i18n.G("replication failed for some datasets:\n%s"), strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// RefreshReplicated rescans the replication target after r ran, so that the machines know about the replicated
+// datasets.
+func (ms *Machines) RefreshReplicated(ctx context.Context, r *Replication) error {
+	if r.target == "" || !datasetExists(ms.z, r.target) {
+		return nil
+	}
+	if err := ms.z.RefreshDataset(ctx, r.target); err != nil {
+		return fmt.Errorf(i18n.G("couldn't refresh replicated datasets: ")+config.ErrorFormat, err)
+	}
+	return ms.applyChanges(ctx)
+}
+
+// replicateDataset sends all snapshots of the filesystem dataset s which are more recent than the latest replicated
+// one to the replication target.
+// If the replica has diverged from s, it's rolled back to the most recent snapshot still shared with s, or replicated
+// again from scratch if there is none.
+func (r *Replication) replicateDataset(ctx context.Context, z *zfs.Zfs, s replicationSource) (err error) {
+	target := filepath.Join(r.target, s.name)
+
+	var replicated []*zfs.Dataset
+	for _, d := range z.Datasets() {
+		if base, _ := splitSnapshotName(d.Name); d.IsSnapshot && base == target {
+			replicated = append(replicated, d)
+		}
+	}
+	replicated = sortedSnapshots(replicated)
+
+	onSource := make(map[string]int)
+	for i, n := range s.snapshots {
+		onSource[n] = i
+	}
+
+	// Start after the latest replicated snapshot still on the source, to send an incremental stream.
+	var from string
+	toSend := s.snapshots
+	if len(replicated) > 0 {
+		common := -1
+		for i := len(replicated) - 1; i >= 0; i-- {
+			_, snapshot := splitSnapshotName(replicated[i].Name)
+			if _, ok := onSource[s.name+"@"+snapshot]; ok {
+				common = i
+				break
+			}
+		}
+
+		nt := z.NewNoTransaction(ctx)
+		if common < 0 {
+			log.Warningf(ctx, i18n.G("%s has diverged from %s: no saved state in common anymore, replicating it again"), target, s.name)
+			if err := destroyReplica(nt, z, target); err != nil {
+				return err
+			}
+		} else {
+			for _, d := range replicated[common+1:] {
+				log.Warningf(ctx, i18n.G("%s has diverged from %s: removing %s which doesn't exist on the source anymore"), target, s.name, d.Name)
+				if err := nt.Destroy(d.Name); err != nil {
+					return fmt.Errorf(i18n.G("couldn't destroy diverged %s: ")+config.ErrorFormat, d.Name, err)
+				}
+			}
+			_, snapshot := splitSnapshotName(replicated[common].Name)
+			from = s.name + "@" + snapshot
+			toSend = s.snapshots[onSource[from]+1:]
+		}
+	}
+	if len(toSend) == 0 {
+		log.Debugf(ctx, i18n.G("%s is already up to date"), target)
+		return nil
+	}
+
+	t, cancel := z.NewTransaction(ctx)
+	defer t.Done()
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	// A new replica of a clone is cloned from the replica of its origin, to share its data.
+	var cloneOrigin string
+	if from == "" {
+		if err := r.createReplicationContainers(t, z, filepath.Dir(target)); err != nil {
+			return err
+		}
+		if s.origin != "" && datasetExists(z, filepath.Join(r.target, s.origin)) {
+			from, cloneOrigin = s.origin, filepath.Join(r.target, s.origin)
+		}
+	}
+
+	for _, n := range toSend {
+		log.RemotePrintf(ctx, i18n.G("Replicating %s\n"), n)
+		if err := sendReceive(ctx, z, t, n, from, target, cloneOrigin); err != nil {
+			return err
+		}
+		from, cloneOrigin = n, ""
+	}
+
+	// Replicated datasets should never be mounted on the host.
+	return t.SetProperty(libzfs.CanmountProp, "off", target, true)
+}
+
+// destroyReplica destroys the replicated dataset target, its children and all their snapshots.
+// Children are replicated again from scratch afterwards.
+func destroyReplica(nt *zfs.NoTransaction, z *zfs.Zfs, target string) error {
+	var snapshots []string
+	for _, d := range z.Datasets() {
+		if base, _ := splitSnapshotName(d.Name); d.IsSnapshot && (base == target || strings.HasPrefix(base, target+"/")) {
+			snapshots = append(snapshots, d.Name)
+		}
+	}
+	sort.Strings(snapshots)
+
+	for _, n := range snapshots {
+		// Destroying a snapshot destroys the snapshots with the same name on its children.
+		if !datasetExists(z, n) {
+			continue
+		}
+		if err := nt.Destroy(n); err != nil {
+			return fmt.Errorf(i18n.G("couldn't destroy diverged %s: ")+config.ErrorFormat, n, err)
+		}
+	}
+	if err := nt.Destroy(target); err != nil {
+		return fmt.Errorf(i18n.G("couldn't destroy diverged %s: ")+config.ErrorFormat, target, err)
+	}
+	return nil
+}
+
+// sendReceive streams the snapshot name, incremental from from if not empty, into target, created as a clone of
+// origin if not empty.
+func sendReceive(ctx context.Context, z *zfs.Zfs, t *zfs.Transaction, name, from, target, origin string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(z.Send(ctx, pw, name, from))
+	}()
+
+	err := t.Receive(target, origin, pr)
+	// Unblock the sender if the receiver stopped before the end of the stream.
+	pr.CloseWithError(errors.New(i18n.G("receiver stopped")))
+	return err
+}
+
+// createReplicationContainers creates path and its missing parents on the replication target, as unmountable
+// datasets.
+func (r *Replication) createReplicationContainers(t *zfs.Transaction, z *zfs.Zfs, path string) error {
+	if !isUnderTarget(r.target, path) || datasetExists(z, path) {
+		return nil
+	}
+	if err := r.createReplicationContainers(t, z, filepath.Dir(path)); err != nil {
+		return err
+	}
+	return t.Create(path, "", "off")
+}
+
+// pruneReplicatedStates only keeps the configured number of most recent snapshots on each replicated dataset
+// tree. Destroying a snapshot destroys the snapshots with the same name on its children.
+func (r *Replication) pruneReplicatedStates(ctx context.Context, z *zfs.Zfs) error {
+	if r.keepLast <= 0 {
+		return nil
+	}
+
+	replicated := make(map[string][]*zfs.Dataset)
+	for _, d := range z.Datasets() {
+		if !d.IsSnapshot || !isUnderTarget(r.target, d.Name) {
+			continue
+		}
+		base, _ := splitSnapshotName(d.Name)
+		replicated[base] = append(replicated[base], d)
+	}
+
+	var bases []string
+	for base := range replicated {
+		// Children snapshots are handled with their parent.
+		if _, ok := replicated[filepath.Dir(base)]; ok {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	var errs []string
+	nt := z.NewNoTransaction(ctx)
+	for _, base := range bases {
+		snapshots := sortedSnapshots(replicated[base])
+		if len(snapshots) <= r.keepLast {
+			continue
+		}
+		for _, s := range snapshots[:len(snapshots)-r.keepLast] {
+			if hasReplicatedClones(z, s.Name) {
+				log.Debugf(ctx, i18n.G("Keeping replicated state %s: it's the origin of other replicated datasets"), s.Name)
+				continue
+			}
+			log.Infof(ctx, i18n.G("Removing replicated state %s"), s.Name)
+			if err := nt.Destroy(s.Name); err != nil {
+				log.Warningf(ctx, i18n.G("couldn't destroy %s: %v"), s.Name, err)
+				errs = append(errs, fmt.Sprintf(i18n.G("couldn't destroy %s: ")+config.ErrorFormat, s.Name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// hasReplicatedClones returns if the snapshot name, or the snapshot with the same name on any of its children, is the
+// origin of another dataset.
+func hasReplicatedClones(z *zfs.Zfs, name string) bool {
+	base, snapshot := splitSnapshotName(name)
+	for _, d := range z.Datasets() {
+		if d.Origin == "" {
+			continue
+		}
+		originBase, originSnapshot := splitSnapshotName(d.Origin)
+		if originSnapshot == snapshot && (originBase == base || strings.HasPrefix(originBase, base+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicationOrder returns sources sorted by name, with parents and clone origins always before the datasets depending
+// on them.
+func replicationOrder(sources map[string]replicationSource) (r []replicationSource) {
+	var names []string
+	for n := range sources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]bool)
+	isPending := func(n string) bool {
+		_, ok := sources[n]
+		return ok && !done[n]
+	}
+	for len(r) < len(names) {
+		progress := false
+		for _, n := range names {
+			originBase, _ := splitSnapshotName(sources[n].origin)
+			if done[n] || isPending(filepath.Dir(n)) || isPending(originBase) {
+				continue
+			}
+			done[n] = true
+			progress = true
+			r = append(r, sources[n])
+		}
+		// Dependency loop: only possible after renames, keep the remaining ones in name order.
+		if !progress {
+			for _, n := range names {
+				if !done[n] {
+					done[n] = true
+					r = append(r, sources[n])
+				}
+			}
+		}
+	}
+	return r
+}
+
+// isReplicatedDataset returns if name is under the replication target.
+func (ms *Machines) isReplicatedDataset(name string) bool {
+	return isUnderTarget(ms.conf.Replication.Target, name)
+}
+
+// isUnderTarget returns if name is the replication target or one of its datasets.
+func isUnderTarget(target, name string) bool {
+	if target == "" {
+		return false
+	}
+	return name == target || strings.HasPrefix(name, target+"/") || strings.HasPrefix(name, target+"@")
+}
+
+// datasetExists returns if the dataset name is known by z.
+func datasetExists(z *zfs.Zfs, name string) bool {
+	for _, d := range z.Datasets() {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSnapshots returns a copy of snapshots sorted from the oldest to the most recent.
+func sortedSnapshots(snapshots []*zfs.Dataset) []*zfs.Dataset {
+	r := make([]*zfs.Dataset, len(snapshots))
+	copy(r, snapshots)
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].LastUsed != r[j].LastUsed {
+			return r[i].LastUsed < r[j].LastUsed
+		}
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
@@ -0,0 +1,235 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// datasetRevert is a dataset of the current state, with its children, to replace by a clone of a saved one.
+type datasetRevert struct {
+	// snapshot is the saved dataset to clone.
+	snapshot *zfs.Dataset
+	// old are the current datasets to replace, parents first.
+	old []*zfs.Dataset
+}
+
+// RevertDatasets reverts in place the datasets of the current system state matching routes, and their children, to
+// the saved state name, without any reboot.
+// routes are dataset names or mountpoints, like /var/lib/docker. The root datasets of the state can only be reverted by
+// booting on the saved state.
+// Services using those mountpoints are stopped while the saved datasets are cloned, promoted and remounted.
+// If any step fails, the datasets and their mounts are restored.
+// Replaced datasets are destroyed, unless more recent saved states depend on them: they are then kept unmounted, with
+// canmount=off, next to the new ones.
+func (ms *Machines) RevertDatasets(ctx context.Context, name string, routes []string) error {
+	if !ms.current.isZsys() {
+		return errors.New(i18n.G("Current machine isn't Zsys, nothing to revert"))
+	}
+	if len(routes) == 0 {
+		return errors.New(i18n.G("Needs at least one dataset to revert, got nothing"))
+	}
+
+	s, err := ms.IDToState(ctx, name, "")
+	if err != nil {
+		return err
+	}
+	if base, _ := splitSnapshotName(s.ID); !s.isSnapshot() || base != ms.current.ID {
+		return fmt.Errorf(i18n.G("%s isn't a saved state of the current system state"), s.ID)
+	}
+
+	reverts, err := ms.current.State.datasetsToRevert(*s, routes)
+	if err != nil {
+		return err
+	}
+
+	var mountpoints []string
+	for _, r := range reverts {
+		for _, d := range r.old {
+			if d.CanMount == "off" || d.Mountpoint == "" {
+				continue
+			}
+			mountpoints = append(mountpoints, d.Mountpoint)
+		}
+	}
+	startServices, err := ms.stopServices(ctx, mountpoints)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't stop services using datasets to revert: ")+config.ErrorFormat, err)
+	}
+	defer startServices()
+
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+
+	suffix := t.Zfs.GenerateID(6)
+	var newDatasets []*zfs.Dataset
+	for _, r := range reverts {
+		top := r.old[0]
+		target := renameWithID(top.Name, suffix)
+		log.Infof(ctx, i18n.G("Reverting %s to %s"), top.Mountpoint, s.ID)
+
+		if err := t.CloneTo(r.snapshot.Name, target, true); err != nil {
+			cancel()
+			return fmt.Errorf(i18n.G("couldn't create new datasets from %q: %v"), r.snapshot.Name, err)
+		}
+		if err := t.Promote(target); err != nil {
+			cancel()
+			return fmt.Errorf(i18n.G("couldn't promote %q: %v"), target, err)
+		}
+		// The clone is next to the dataset it replaces, and so would inherit a different mountpoint.
+		if err := t.SetProperty(libzfs.MountPointProp, top.Mountpoint, target, true); err != nil {
+			cancel()
+			return fmt.Errorf(i18n.G("couldn't set mountpoint of %q to %q: ")+config.ErrorFormat, target, top.Mountpoint, err)
+		}
+
+		// Replaced datasets should never be mounted again, even when booting on the current state.
+		for _, d := range r.old {
+			if d.CanMount == "off" {
+				continue
+			}
+			if err := t.SetProperty(libzfs.CanmountProp, "off", d.Name, true); err != nil {
+				cancel()
+				return fmt.Errorf(i18n.G("couldn't switch %q canmount property to %q: ")+config.ErrorFormat, d.Name, "off", err)
+			}
+		}
+
+		var ds []*zfs.Dataset
+		for _, d := range ms.z.Datasets() {
+			if d.IsSnapshot || (d.Name != target && !strings.HasPrefix(d.Name, target+"/")) {
+				continue
+			}
+			ds = append(ds, d)
+		}
+		if _, err := switchDatasetsCanMount(t, ds, "on"); err != nil {
+			cancel()
+			return err
+		}
+		newDatasets = append(newDatasets, ds...)
+	}
+
+	var oldDatasets []*zfs.Dataset
+	for _, r := range reverts {
+		oldDatasets = append(oldDatasets, r.old...)
+	}
+	if err := ms.swapMounts(ctx, oldDatasets, newDatasets); err != nil {
+		cancel()
+		return fmt.Errorf(i18n.G("couldn't remount reverted datasets: ")+config.ErrorFormat, err)
+	}
+	t.Done()
+
+	destroyErr := ms.destroyReplacedDatasets(ctx, reverts)
+	if err := ms.Refresh(ctx); err != nil {
+		return err
+	}
+	return destroyErr
+}
+
+// destroyReplacedDatasets destroys the datasets replaced by a revert which no saved state depends on anymore.
+// Others are kept with canmount=off until their last snapshot is removed.
+func (ms *Machines) destroyReplacedDatasets(ctx context.Context, reverts []datasetRevert) error {
+	nt := ms.z.NewNoTransaction(ctx)
+	for _, r := range reverts {
+		top := r.old[0]
+		var hasSnapshots bool
+		for _, d := range ms.z.Datasets() {
+			if base, _ := splitSnapshotName(d.Name); d.IsSnapshot && (base == top.Name || strings.HasPrefix(base, top.Name+"/")) {
+				hasSnapshots = true
+				break
+			}
+		}
+		if hasSnapshots {
+			log.Infof(ctx, i18n.G("Keeping %s as more recent saved states depend on it"), top.Name)
+			continue
+		}
+		if err := nt.Destroy(top.Name); err != nil {
+			return fmt.Errorf(i18n.G("couldn't destroy replaced dataset %q: ")+config.ErrorFormat, top.Name, err)
+		}
+	}
+	return nil
+}
+
+// datasetsToRevert returns the datasets of the saved state matching routes, with the datasets of s they replace.
+// Datasets which are children of another reverted one are reverted with their parent.
+func (s State) datasetsToRevert(saved State, routes []string) ([]datasetRevert, error) {
+	var snapshots []*zfs.Dataset
+	for _, route := range routes {
+		route = strings.TrimSuffix(route, "/")
+		if route == "" {
+			route = "/"
+		}
+
+		var found *zfs.Dataset
+		for root, ds := range saved.Datasets {
+			for _, d := range ds {
+				base, _ := splitSnapshotName(d.Name)
+				if d.CanMount == "off" || (base != route && d.Mountpoint != route) {
+					continue
+				}
+				if d.Name == root || d.Mountpoint == "/" {
+					return nil, fmt.Errorf(i18n.G("%s is a root dataset of the state, revert it by booting on %s"), route, saved.ID)
+				}
+				found = d
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf(i18n.G("no dataset of %s matches %q"), saved.ID, route)
+		}
+		snapshots = append(snapshots, found)
+	}
+
+	// Parents are handled first, reverting their children with them.
+	sort.Slice(snapshots, func(i, j int) bool {
+		bi, _ := splitSnapshotName(snapshots[i].Name)
+		bj, _ := splitSnapshotName(snapshots[j].Name)
+		return bi < bj
+	})
+	var reverts []datasetRevert
+	var handled []string
+	for _, snapshot := range snapshots {
+		base, _ := splitSnapshotName(snapshot.Name)
+		var isHandled bool
+		for _, h := range handled {
+			if base == h || strings.HasPrefix(base, h+"/") {
+				isHandled = true
+				break
+			}
+		}
+		if isHandled {
+			continue
+		}
+		handled = append(handled, base)
+
+		r := datasetRevert{snapshot: snapshot}
+		for _, d := range s.getDatasets() {
+			if d.IsSnapshot || d.CanMount == "off" || d.Mountpoint != snapshot.Mountpoint {
+				continue
+			}
+			r.old = append(r.old, d)
+		}
+		if len(r.old) == 0 {
+			return nil, fmt.Errorf(i18n.G("no dataset of the current state is mounted on %s"), snapshot.Mountpoint)
+		}
+		if len(r.old) > 1 {
+			return nil, fmt.Errorf(i18n.G("multiple datasets of the current state are mounted on %s"), snapshot.Mountpoint)
+		}
+		for _, d := range s.getDatasets() {
+			if d.IsSnapshot || !strings.HasPrefix(d.Name, r.old[0].Name+"/") {
+				continue
+			}
+			r.old = append(r.old, d)
+		}
+		sort.Slice(r.old[1:], func(i, j int) bool { return r.old[1+i].Name < r.old[1+j].Name })
+
+		reverts = append(reverts, r)
+	}
+
+	return reverts, nil
+}
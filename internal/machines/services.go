@@ -0,0 +1,122 @@
+package machines
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+const (
+	procDir = "/proc"
+	// systemSlice is the cgroup of system services.
+	systemSlice = "/system.slice/"
+)
+
+// ServicesStopper stops the services using files under mountpoints. It returns a function to start them back.
+type ServicesStopper func(ctx context.Context, mountpoints []string) (start func(), err error)
+
+// stopServicesUsing stops the system services of every process with opened files or a working directory under
+// mountpoints. Processes which aren't part of a system service are only reported.
+func stopServicesUsing(ctx context.Context, mountpoints []string) (func(), error) {
+	units, err := unitsUsing(ctx, mountpoints)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return func() {}, nil
+	}
+
+	log.Infof(ctx, i18n.G("Stopping %s"), strings.Join(units, ", "))
+	if out, err := exec.CommandContext(ctx, systemctlCmd, append([]string{"stop"}, units...)...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't stop %s: %v: %s"), strings.Join(units, ", "), err, strings.TrimSpace(string(out)))
+	}
+
+	return func() {
+		log.Infof(ctx, i18n.G("Starting %s"), strings.Join(units, ", "))
+		// Services are started back even if the request was cancelled.
+		if out, err := exec.Command(systemctlCmd, append([]string{"start"}, units...)...).CombinedOutput(); err != nil {
+			log.Warningf(ctx, i18n.G("Couldn't start %s: %v: %s"), strings.Join(units, ", "), err, strings.TrimSpace(string(out)))
+		}
+	}, nil
+}
+
+// unitsUsing returns the sorted system services with a process using files under mountpoints.
+func unitsUsing(ctx context.Context, mountpoints []string) ([]string, error) {
+	procs, err := filepath.Glob(filepath.Join(procDir, "[0-9]*"))
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't list processes: %v"), err)
+	}
+
+	found := make(map[string]bool)
+	for _, p := range procs {
+		path := processUses(p, mountpoints)
+		if path == "" {
+			continue
+		}
+		unit := processUnit(p)
+		if unit == "" {
+			log.Warningf(ctx, i18n.G("Process %s uses %s but isn't part of a system service: it may keep the dataset busy"), filepath.Base(p), path)
+			continue
+		}
+		log.Debugf(ctx, "Process %s of %s uses %s", filepath.Base(p), unit, path)
+		found[unit] = true
+	}
+
+	var units []string
+	for u := range found {
+		units = append(units, u)
+	}
+	sort.Strings(units)
+	return units, nil
+}
+
+// processUses returns the first path under mountpoints used by the process in proc, if any.
+func processUses(proc string, mountpoints []string) string {
+	links := []string{filepath.Join(proc, "cwd"), filepath.Join(proc, "exe")}
+	fds, _ := filepath.Glob(filepath.Join(proc, "fd", "*"))
+	links = append(links, fds...)
+
+	for _, l := range links {
+		path, err := os.Readlink(l)
+		if err != nil {
+			continue
+		}
+		for _, m := range mountpoints {
+			if path == m || strings.HasPrefix(path, m+"/") {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// processUnit returns the system service the process in proc is part of, or an empty string.
+func processUnit(proc string) string {
+	f, err := os.Open(filepath.Join(proc, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// Each line is hierarchy-ID:controller-list:cgroup-path.
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		elems := strings.SplitN(s.Text(), ":", 3)
+		if len(elems) != 3 || !strings.HasPrefix(elems[2], systemSlice) {
+			continue
+		}
+		unit := strings.SplitN(strings.TrimPrefix(elems[2], systemSlice), "/", 2)[0]
+		if strings.HasSuffix(unit, ".service") {
+			return unit
+		}
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs/memory"
+)
+
+// SimulationDay is the outcome of one simulated day of garbage collection.
+type SimulationDay struct {
+	// Date is the end of the simulated day, when garbage collection runs.
+	Date time.Time
+	// Created is the number of system states saved during the day.
+	Created int
+	// Removed is the number of system and user states removed by garbage collection.
+	Removed int
+	// States are the system states of the current machine surviving garbage collection, from the most recent one.
+	States []string
+	// UserStates is the number of user states of the current machine surviving garbage collection.
+	UserStates int
+}
+
+// simulatedTime is a clock which only moves when the simulation advances it.
+type simulatedTime struct {
+	now time.Time
+}
+
+func (t *simulatedTime) Now() time.Time {
+	return t.now
+}
+
+// simulatedMounter doesn't mount anything: simulated datasets only exist in memory.
+type simulatedMounter struct{}
+
+func (simulatedMounter) Mount(dataset, mountpoint string) error {
+	return nil
+}
+
+func (simulatedMounter) Unmount(mountpoint string) error {
+	return nil
+}
+
+// SimulateGC replays garbage collection with conf for the given number of days, on an in memory copy of the datasets.
+// If conf is nil, the current configuration is used.
+// A system state is saved on the current machine every interval and garbage collection runs at the end of each day.
+// If all is set manual snapshots are considered too.
+// The system is left untouched.
+func (ms *Machines) SimulateGC(ctx context.Context, conf *config.ZConfig, days int, every time.Duration, all bool) ([]SimulationDay, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf(i18n.G("number of days to simulate must be positive, got %d"), days)
+	}
+	if every < time.Minute {
+		return nil, fmt.Errorf(i18n.G("interval between saved states must be at least a minute, got %s"), every)
+	}
+	if !ms.current.isZsys() {
+		return nil, errors.New(i18n.G("Current machine isn't Zsys, nothing to simulate"))
+	}
+
+	if conf == nil {
+		conf = &ms.conf
+	}
+
+	clock := &simulatedTime{now: ms.time.Now()}
+	lz := memory.New()
+	lz.SetClock(clock.Now)
+	if err := copyDatasets(&lz, ms.z.Datasets()); err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't copy datasets for simulation: ")+config.ErrorFormat, err)
+	}
+	z, err := zfs.New(ctx, zfs.WithLibZFS(&lz))
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't scan copied datasets: ")+config.ErrorFormat, err)
+	}
+
+	// Nothing on the running system is checked, stopped or mounted by the simulation.
+	sim := Machines{
+		all:     make(map[string]*Machine),
+		cmdline: ms.cmdline,
+		z:       z,
+		conf:    *conf,
+		time:    clock,
+		healthCheck: func(context.Context, config.HealthChecks) error {
+			return nil
+		},
+		stopServices: func(context.Context, []string) (func(), error) {
+			return func() {}, nil
+		},
+		mount: simulatedMounter{},
+	}
+	sim.refresh(ctx)
+	if sim.current == nil || sim.current.ID != ms.current.ID {
+		return nil, fmt.Errorf(i18n.G("couldn't find current machine %s in copied datasets"), ms.current.ID)
+	}
+
+	log.Infof(ctx, i18n.G("Simulating garbage collection for %d days, saving a state every %s"), days, every)
+
+	start := clock.now
+	next := start.Add(every)
+	var r []SimulationDay
+	for i := 1; i <= days; i++ {
+		end := start.Add(time.Duration(i) * 24 * time.Hour)
+		day := SimulationDay{Date: end}
+
+		for ; !next.After(end); next = next.Add(every) {
+			clock.now = next
+			name := automatedSnapshotPrefix + "sim-" + next.UTC().Format("20060102-150405")
+			if _, err := sim.CreateSystemSnapshot(ctx, name, StateMetadata{}); err != nil {
+				return nil, fmt.Errorf(i18n.G("couldn't save simulated state %s: ")+config.ErrorFormat, name, err)
+			}
+			day.Created++
+		}
+
+		clock.now = end
+		before := sim.countSavedStates()
+		if err := sim.GC(ctx, all); err != nil {
+			return nil, fmt.Errorf(i18n.G("garbage collection failed on %s: ")+config.ErrorFormat, end.Format(time.RFC3339), err)
+		}
+		day.Removed = before - sim.countSavedStates()
+		log.RemotePrintf(ctx, i18n.G("Simulated day %d/%d\n"), i, days)
+
+		var states sortedReverseByTimeStates
+		for _, s := range sim.current.History {
+			states = append(states, s)
+		}
+		sort.Sort(states)
+		for _, s := range states {
+			day.States = append(day.States, s.ID)
+		}
+		day.UserStates = sim.current.countSavedUserStates()
+
+		r = append(r, day)
+	}
+
+	return r, nil
+}
+
+// countSavedStates returns the number of system and user states of all machines, excluding their current ones.
+func (ms *Machines) countSavedStates() (n int) {
+	for _, m := range ms.all {
+		n += len(m.History) + m.countSavedUserStates()
+	}
+	return n
+}
+
+// countSavedUserStates returns the number of user states of the machine, excluding the current ones.
+func (m *Machine) countSavedUserStates() (n int) {
+	for user, us := range m.AllUsersStates {
+		n += len(us)
+		if cur, ok := m.State.Users[user]; ok && us[cur.ID] == cur {
+			n--
+		}
+	}
+	return n
+}
+
+// copyDatasets recreates the datasets hierarchy with its properties, but not its content, in the in memory lz.
+func copyDatasets(lz *memory.LibZFS, datasets []*zfs.Dataset) error {
+	datasets = append([]*zfs.Dataset(nil), datasets...)
+	// Parents are sorted before their children and snapshots.
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].Name < datasets[j].Name })
+
+	for _, d := range datasets {
+		if d.IsSnapshot {
+			userProps := map[string]string{
+				libzfs.SnapshotMountpointProp: d.Mountpoint,
+				libzfs.SnapshotCanmountProp:   d.CanMount,
+				libzfs.BootfsProp:             boolToYesNo(d.BootFS),
+			}
+			for k, v := range map[string]string{
+				libzfs.LastBootedKernelProp: d.LastBootedKernel,
+				libzfs.PackageChangesProp:   d.PackageChanges,
+				libzfs.DescriptionProp:      d.Description,
+				libzfs.TagsProp:             d.Tags,
+				libzfs.PinnedProp:           d.Pinned,
+			} {
+				if v != "" {
+					userProps[k] = v
+				}
+			}
+			// User properties on snapshots store their value with its source.
+			for k, v := range userProps {
+				userProps[k] = v + ":local"
+			}
+			props := map[libzfs.Prop]libzfs.Property{
+				libzfs.DatasetPropCreation: {Value: strconv.Itoa(d.LastUsed)},
+			}
+			if _, err := lz.DatasetSnapshot(d.Name, false, props, userProps); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var dZFS libzfs.DZFSInterface
+		var err error
+		if !strings.Contains(d.Name, "/") {
+			if _, err := lz.PoolCreate(d.Name, libzfs.VDevTree{}, nil, nil, nil); err != nil {
+				return err
+			}
+			dZFS, err = lz.DatasetOpen(d.Name)
+		} else {
+			dZFS, err = lz.DatasetCreate(d.Name, libzfs.DatasetTypeFilesystem, make(map[libzfs.Prop]libzfs.Property))
+		}
+		if err != nil {
+			return err
+		}
+
+		props := map[libzfs.Prop]string{
+			libzfs.DatasetPropMountpoint: d.Mountpoint,
+			libzfs.DatasetPropCanmount:   d.CanMount,
+			libzfs.DatasetPropOrigin:     d.Origin,
+		}
+		if d.Mounted {
+			props[libzfs.DatasetPropMounted] = "yes"
+		}
+		for k, v := range props {
+			if v == "" {
+				continue
+			}
+			if err := dZFS.SetProperty(k, v); err != nil {
+				return err
+			}
+		}
+
+		userProps := map[string]string{
+			libzfs.BootfsProp:           boolToYesNo(d.BootFS),
+			libzfs.LastBootedKernelProp: d.LastBootedKernel,
+			libzfs.BootfsDatasetsProp:   d.BootfsDatasets,
+			libzfs.DescriptionProp:      d.Description,
+			libzfs.TagsProp:             d.Tags,
+			libzfs.PinnedProp:           d.Pinned,
+			libzfs.TrialProp:            d.Trial,
+			libzfs.BootNextProp:         d.BootNext,
+		}
+		if d.LastUsed != 0 {
+			userProps[libzfs.LastUsedProp] = strconv.Itoa(d.LastUsed)
+		}
+		for k, v := range userProps {
+			if v == "" {
+				continue
+			}
+			if err := dZFS.SetUserProperty(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// boolToYesNo returns the zfs representation of a boolean property.
+func boolToYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
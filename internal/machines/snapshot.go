@@ -7,27 +7,41 @@ import (
 	"strings"
 
 	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
 	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
 )
 
 const automatedSnapshotPrefix = "autozsys_"
 
+// StateMetadata is optional information recorded on a new state.
+type StateMetadata struct {
+	// Description is a free text, single line, description of the state.
+	Description string
+	// Tags are key/value tags of the state. Values can be empty.
+	Tags map[string]string
+	// PackageChanges lists the packages about to be changed. It is only recorded on system states.
+	PackageChanges PackageChanges
+}
+
 // CreateSystemSnapshot creates a snapshot of a system and all users datasets.
 // If snapshotname is not empty, it is used as the id of the snapshot otherwise an id
 // is generated with a random string.
-func (ms *Machines) CreateSystemSnapshot(ctx context.Context, snapshotname string) (string, error) {
-	return ms.createSnapshot(ctx, snapshotname, "")
+// meta is recorded on the new state.
+func (ms *Machines) CreateSystemSnapshot(ctx context.Context, snapshotname string, meta StateMetadata) (string, error) {
+	return ms.createSnapshot(ctx, snapshotname, "", meta)
 }
 
 // CreateUserSnapshot creates a snapshot for the provided user.
 // If snapshotName is not empty, it is used as the id of the snapshot otherwise an id
 // is generated with a random string.
 // userName is the name of the user to snapshot the datasets from.
-func (ms *Machines) CreateUserSnapshot(ctx context.Context, userName, snapshotName string) (string, error) {
+// The description and tags of meta are recorded on the new state.
+func (ms *Machines) CreateUserSnapshot(ctx context.Context, userName, snapshotName string, meta StateMetadata) (string, error) {
 	if userName == "" {
 		return "", errors.New(i18n.G("Needs a valid user name, got nothing"))
 	}
-	return ms.createSnapshot(ctx, snapshotName, userName)
+	return ms.createSnapshot(ctx, snapshotName, userName, meta)
 }
 
 // createSnapshot creates a snapshot of a system and all users datasets.
@@ -35,7 +49,8 @@ func (ms *Machines) CreateUserSnapshot(ctx context.Context, userName, snapshotNa
 // is generated with a random string.
 // If onlyUser is empty a snapshot of all the system datasets is taken,
 // otherwise only a snapshot of the given username is done
-func (ms *Machines) createSnapshot(ctx context.Context, name string, onlyUser string) (string, error) {
+// Metadata is recorded on the root dataset snapshot of the state.
+func (ms *Machines) createSnapshot(ctx context.Context, name string, onlyUser string, meta StateMetadata) (string, error) {
 	m := ms.current
 	if !m.isZsys() {
 		return "", errors.New(i18n.G("Current machine isn't Zsys, nothing to create"))
@@ -47,11 +62,17 @@ func (ms *Machines) createSnapshot(ctx context.Context, name string, onlyUser st
 	if err := validateStateName(name); err != nil {
 		return "", err
 	}
-
-	t, cancel := ms.z.NewTransaction(ctx)
-	defer t.Done()
+	if err := validateDescription(meta.Description); err != nil {
+		return "", err
+	}
+	for k := range meta.Tags {
+		if err := validateTagKey(k); err != nil {
+			return "", err
+		}
+	}
 
 	var toSnapshot []*zfs.Dataset
+	root := m.ID
 	if onlyUser != "" {
 		userState, ok := m.State.Users[onlyUser]
 		if !ok {
@@ -64,22 +85,35 @@ func (ms *Machines) createSnapshot(ctx context.Context, name string, onlyUser st
 			}
 		}
 		toSnapshot = userState.getDatasets()
+		root = userState.ID
 	} else {
 		toSnapshot = append(m.State.getDatasets(), m.State.getUsersDatasets()...)
 	}
 
-	// check pool capacity before saving state
+	// check pool capacity before saving state, removing states to free up space if configured
 	pools := make(map[string]bool)
 	for _, d := range toSnapshot {
 		pools[strings.Split(d.Name, "/")[0]] = true
 	}
 
+	var spaceFreed bool
 	for p := range pools {
 		free, err := ms.z.GetPoolFreeSpace(p)
 		if err != nil {
 			return "", err
 		}
 
+		if free <= ms.conf.General.MinFreePoolSpace && ms.conf.General.TargetFreePoolSpace > 0 && !spaceFreed {
+			log.Infof(ctx, i18n.G("Free space on pool %q is %d%%, removing states to free up space"), p, free)
+			if err := ms.freePoolsSpace(ctx, pools, false); err != nil {
+				return "", err
+			}
+			spaceFreed = true
+			if free, err = ms.z.GetPoolFreeSpace(p); err != nil {
+				return "", err
+			}
+		}
+
 		if free <= ms.conf.General.MinFreePoolSpace {
 			return "", fmt.Errorf(i18n.G(`Minimum free space to take a snapshot and preserve ZFS performance is %d%%.
 Free space on pool %q is %d%%.
@@ -87,6 +121,9 @@ Please remove some states manually to free up space.`), ms.conf.General.MinFreeP
 		}
 	}
 
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+
 	for _, d := range toSnapshot {
 		if err := t.Snapshot(name, d.Name, false); err != nil {
 			cancel()
@@ -94,7 +131,34 @@ Please remove some states manually to free up space.`), ms.conf.General.MinFreeP
 		}
 	}
 
-	ms.refresh(ctx)
+	var props [][2]string
+	if onlyUser == "" && !meta.PackageChanges.isEmpty() {
+		props = append(props, [2]string{libzfs.PackageChangesProp, meta.PackageChanges.encode()})
+	}
+	if meta.Description != "" {
+		props = append(props, [2]string{libzfs.DescriptionProp, meta.Description})
+	}
+	if len(meta.Tags) > 0 {
+		props = append(props, [2]string{libzfs.TagsProp, encodeTags(meta.Tags)})
+	}
+	for _, p := range props {
+		if err := t.SetProperty(p[0], p[1], root+"@"+name, true); err != nil {
+			cancel()
+			return "", err
+		}
+	}
+
+	// System states saved before upgrading packages are the fallback of the next boots.
+	if onlyUser == "" && !meta.PackageChanges.isEmpty() && ms.conf.Boot.TrialBoots > 0 {
+		if err := ms.startTrial(ctx, t, root+"@"+name); err != nil {
+			cancel()
+			return "", err
+		}
+	}
+
+	if err := ms.applyChanges(ctx); err != nil {
+		return "", err
+	}
 	return name, nil
 }
 
@@ -290,8 +290,7 @@ func (ms *Machines) RemoveState(ctx context.Context, name, user string, force, d
 		}
 	}
 
-	ms.refresh(ctx)
-	return nil
+	return ms.applyChanges(ctx)
 }
 
 // Remove removes a given state by deleting all of its system datasets and unlink user states
@@ -341,16 +340,6 @@ func (s *State) remove(ctx context.Context, ms *Machines, linkedStateID string)
 		}
 	}
 
-	// Unlink from parent
-	if ps := s.parentSystemState(ms); ps != nil {
-		for user, us := range ps.Users {
-			if us == s {
-				delete(ps.Users, user)
-				break
-			}
-		}
-	}
-
 	// If we have a system state, request user cleaning (untag and maybe deletion)
 	for _, us := range s.Users {
 		if err := us.remove(ctx, ms, s.ID); err != nil {
@@ -371,6 +360,16 @@ func (s *State) remove(ctx context.Context, ms *Machines, linkedStateID string)
 		}
 	}
 
+	// Unlink from parent, now that the state is gone
+	if ps := s.parentSystemState(ms); ps != nil {
+		for user, us := range ps.Users {
+			if us == s {
+				delete(ps.Users, user)
+				break
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -434,6 +433,70 @@ func (s *State) parentSystemState(ms *Machines) *State {
 	return nil
 }
 
+// StatePath returns where path, as it was in the saved state name, can be read on the system, through the .zfs
+// snapshot directory of the dataset containing it.
+// user limits the research on the given user state, otherwise system states (including their user datasets) are
+// searched. If path is empty, the root of the state is returned.
+func (ms *Machines) StatePath(ctx context.Context, name, user, path string) (string, error) {
+	s, err := ms.IDToState(ctx, name, user)
+	if err != nil {
+		return "", err
+	}
+	if !s.isSnapshot() {
+		return "", fmt.Errorf(i18n.G("%s isn't a saved state. Its files can be accessed directly"), s.ID)
+	}
+
+	datasets := append(s.getDatasets(), s.getUsersDatasets()...)
+	if path == "" {
+		path = s.Datasets[s.ID][0].Mountpoint
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf(i18n.G("%s isn't an absolute path"), path)
+	}
+	path = filepath.Clean(path)
+
+	// Find the dataset containing path, as mounted when the state was saved.
+	var d *zfs.Dataset
+	for _, candidate := range datasets {
+		mp := candidate.Mountpoint
+		if candidate.CanMount == "off" || mp == "" || mp == "none" {
+			continue
+		}
+		if path != mp && !strings.HasPrefix(path, strings.TrimSuffix(mp, "/")+"/") {
+			continue
+		}
+		if d == nil || len(mp) > len(d.Mountpoint) {
+			d = candidate
+		}
+	}
+	if d == nil {
+		return "", fmt.Errorf(i18n.G("%s isn't part of %s"), path, s.ID)
+	}
+	log.Debugf(ctx, i18n.G("%s is on %s, saved with mountpoint %s"), path, d.Name, d.Mountpoint)
+
+	// Snapshot content is available under the current mountpoint of its filesystem dataset.
+	base, snapshot := splitSnapshotName(d.Name)
+	var fs *zfs.Dataset
+	for _, candidate := range ms.z.Datasets() {
+		if candidate.Name == base {
+			fs = candidate
+			break
+		}
+	}
+	if fs == nil {
+		return "", fmt.Errorf(i18n.G("couldn't find filesystem dataset for %s"), d.Name)
+	}
+	if !fs.Mounted {
+		return "", fmt.Errorf(i18n.G("%s isn't mounted: content of %s can't be accessed"), fs.Name, d.Name)
+	}
+
+	rel, err := filepath.Rel(d.Mountpoint, path)
+	if err != nil {
+		return "", fmt.Errorf(i18n.G("couldn't compute %s path relative to %s: %v"), path, d.Mountpoint, err)
+	}
+	return filepath.Join(fs.Mountpoint, ".zfs", "snapshot", snapshot, rel), nil
+}
+
 // IDToState returns a state object from an Id and an error if there are many
 // name can be:
 // - the full path of a state
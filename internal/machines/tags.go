@@ -0,0 +1,209 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+const (
+	// maxTagsLen is the maximum length of stored tags, as ZFS limits user property values.
+	maxTagsLen = 4096
+	// maxDescriptionLen is the maximum length of a state description.
+	maxDescriptionLen = 1024
+)
+
+// TagState adds or replaces tags on the state name. Tags with an empty value only have a key.
+// user limits the research on the given user state, otherwise the state is a system one.
+func (ms *Machines) TagState(ctx context.Context, name, user string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return errors.New(i18n.G("no tag to set"))
+	}
+	for k := range tags {
+		if err := validateTagKey(k); err != nil {
+			return err
+		}
+	}
+
+	s, err := ms.IDToState(ctx, name, user)
+	if err != nil {
+		return err
+	}
+
+	newTags := s.tags()
+	for k, v := range tags {
+		newTags[k] = v
+	}
+
+	log.Infof(ctx, i18n.G("Tagging %s with %s"), s.ID, formatTags(tags))
+	return ms.setStateTags(ctx, s, newTags)
+}
+
+// UntagState removes the tags with keys from the state name.
+// user limits the research on the given user state, otherwise the state is a system one.
+func (ms *Machines) UntagState(ctx context.Context, name, user string, keys []string) error {
+	if len(keys) == 0 {
+		return errors.New(i18n.G("no tag to remove"))
+	}
+
+	s, err := ms.IDToState(ctx, name, user)
+	if err != nil {
+		return err
+	}
+
+	tags := s.tags()
+	for _, k := range keys {
+		if _, ok := tags[k]; !ok {
+			return fmt.Errorf(i18n.G("%s has no tag %q"), s.ID, k)
+		}
+		delete(tags, k)
+	}
+
+	log.Infof(ctx, i18n.G("Removing tags %s from %s"), strings.Join(keys, ", "), s.ID)
+	return ms.setStateTags(ctx, s, tags)
+}
+
+// setStateTags stores tags on the root dataset of s.
+func (ms *Machines) setStateTags(ctx context.Context, s *State, tags map[string]string) error {
+	v := encodeTags(tags)
+	if len(v) > maxTagsLen {
+		return fmt.Errorf(i18n.G("tags of %s are too long: %d characters, maximum is %d"), s.ID, len(v), maxTagsLen)
+	}
+
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+	if err := t.SetProperty(libzfs.TagsProp, v, s.ID, true); err != nil {
+		cancel()
+		return err
+	}
+
+	// Children datasets of filesystem states inherit the property.
+	return ms.applyChanges(ctx)
+}
+
+// tags returns a copy of the tags of this state.
+func (s State) tags() map[string]string {
+	ds, ok := s.Datasets[s.ID]
+	if !ok || len(ds) == 0 {
+		return make(map[string]string)
+	}
+	return decodeTags(ds[0].Tags)
+}
+
+// description returns the description of this state.
+func (s State) description() string {
+	ds, ok := s.Datasets[s.ID]
+	if !ok || len(ds) == 0 {
+		return ""
+	}
+	return ds[0].Description
+}
+
+// matchesAllTags returns if this state has all tags of filters. An empty filter value matches any tag value.
+func (s State) matchesAllTags(filters map[string]string) bool {
+	tags := s.tags()
+	for k, v := range filters {
+		if tv, ok := tags[k]; !ok || (v != "" && tv != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAnyTag returns if this state has at least one tag of filters. An empty filter value matches any tag value.
+func (s State) matchesAnyTag(filters map[string]string) bool {
+	tags := s.tags()
+	for k, v := range filters {
+		if tv, ok := tags[k]; ok && (v == "" || tv == v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTags parses a list of "key" or "key=value" elements into tags.
+func parseTags(elems []string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, e := range elems {
+		kv := strings.SplitN(e, "=", 2)
+		if err := validateTagKey(kv[0]); err != nil {
+			return nil, err
+		}
+		var v string
+		if len(kv) == 2 {
+			v = kv[1]
+		}
+		tags[kv[0]] = v
+	}
+	return tags, nil
+}
+
+// validateTagKey ensures a tag key isn't empty and only contains alphanumerical, "-", "_" and "." characters.
+func validateTagKey(k string) error {
+	if k == "" {
+		return errors.New(i18n.G("tag key cannot be empty"))
+	}
+	for _, c := range k {
+		if !((c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.') {
+			return fmt.Errorf(i18n.G("tag key %q contains invalid character %q"), k, c)
+		}
+	}
+	return nil
+}
+
+// validateDescription ensures a description is a single line of reasonable length.
+func validateDescription(d string) error {
+	if len(d) > maxDescriptionLen {
+		return fmt.Errorf(i18n.G("description is too long: %d characters, maximum is %d"), len(d), maxDescriptionLen)
+	}
+	if strings.ContainsAny(d, "\n\r") {
+		return errors.New(i18n.G("description must be a single line"))
+	}
+	return nil
+}
+
+// encodeTags serializes tags as a query string, sorted by key.
+func encodeTags(tags map[string]string) string {
+	v := make(url.Values)
+	for k, val := range tags {
+		v.Set(k, val)
+	}
+	return v.Encode()
+}
+
+// decodeTags parses tags serialized by encodeTags. Invalid elements are ignored.
+func decodeTags(s string) map[string]string {
+	tags := make(map[string]string)
+	v, _ := url.ParseQuery(s)
+	for k, vals := range v {
+		if len(vals) == 0 || validateTagKey(k) != nil {
+			continue
+		}
+		tags[k] = vals[0]
+	}
+	return tags
+}
+
+// formatTags returns tags as "key1=value1, key2" sorted by key.
+func formatTags(tags map[string]string) string {
+	var r []string
+	for k, v := range tags {
+		if v == "" {
+			r = append(r, k)
+			continue
+		}
+		r = append(r, k+"="+v)
+	}
+	sort.Strings(r)
+	return strings.Join(r, ", ")
+}
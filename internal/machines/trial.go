@@ -0,0 +1,182 @@
+package machines
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/config"
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+const (
+	// trialSeparator separates the number of boots, or trialFailed, from the fallback state in the trial user property.
+	trialSeparator = ":"
+	// trialFailed replaces the number of boots in the trial user property once the trial failed.
+	trialFailed = "failed"
+)
+
+// ErrTrialFailed is returned when committing a trial boot which didn't pass health checks.
+type ErrTrialFailed struct {
+	// Exhausted is set when the state used all its trial boots and the next boot should fall back.
+	Exhausted bool
+	s         string
+}
+
+func (e *ErrTrialFailed) Error() string {
+	return e.s
+}
+
+// trial is the boot trial of a system state, stored on its root dataset.
+type trial struct {
+	// boots is the number of boots started on the state since it was marked as trial, without passing health checks.
+	boots int
+	// failed is set once the state used all its trial boots.
+	failed bool
+	// fallback is the state to boot on when the trial fails.
+	fallback string
+}
+
+// parseTrial decodes the trial user property. It returns false if the state isn't on trial.
+func parseTrial(v string) (trial, bool) {
+	elems := strings.SplitN(v, trialSeparator, 2)
+	if len(elems) != 2 || elems[1] == "" {
+		return trial{}, false
+	}
+
+	tr := trial{fallback: elems[1]}
+	if elems[0] == trialFailed {
+		tr.failed = true
+		return tr, true
+	}
+	boots, err := strconv.Atoi(elems[0])
+	if err != nil || boots < 0 {
+		return trial{}, false
+	}
+	tr.boots = boots
+	return tr, true
+}
+
+// String encodes the trial for its user property.
+func (tr trial) String() string {
+	if tr.failed {
+		return trialFailed + trialSeparator + tr.fallback
+	}
+	return strconv.Itoa(tr.boots) + trialSeparator + tr.fallback
+}
+
+// trial returns the boot trial of a filesystem state and if it's on trial.
+func (s State) trial() (trial, bool) {
+	if s.isSnapshot() {
+		return trial{}, false
+	}
+	ds, ok := s.Datasets[s.ID]
+	if !ok || len(ds) == 0 {
+		return trial{}, false
+	}
+	return parseTrial(ds[0].Trial)
+}
+
+// setTrial records tr on the root dataset of the state. An empty trial ends it.
+func (s State) setTrial(t *zfs.Transaction, tr *trial) error {
+	var v string
+	if tr != nil {
+		v = tr.String()
+	}
+	// Children datasets inherit the property.
+	if err := t.SetProperty(libzfs.TrialProp, v, s.ID, true); err != nil {
+		return fmt.Errorf(i18n.G("couldn't set trial property on %q: ")+config.ErrorFormat, s.ID, err)
+	}
+	return nil
+}
+
+// startTrial marks the current state as booting on trial after the system state fallback was saved.
+// The fallback of a trial which didn't start yet is kept, as it's the last committed state.
+func (ms *Machines) startTrial(ctx context.Context, t *zfs.Transaction, fallback string) error {
+	s := ms.current.State
+	if tr, ok := s.trial(); ok && !tr.failed && tr.boots == 0 {
+		log.Debugf(ctx, "%s is already on trial, falling back to %s", s.ID, tr.fallback)
+		return nil
+	}
+
+	log.Infof(ctx, i18n.G("Next boots of %s are on trial, falling back to %s after %d failed boots"), s.ID, fallback, ms.conf.Boot.TrialBoots)
+	return s.setTrial(t, &trial{fallback: fallback})
+}
+
+// countTrialBoot records a new boot on s if it's on trial.
+// If all trial boots were used without passing health checks, the trial fails.
+func (ms *Machines) countTrialBoot(ctx context.Context, t *zfs.Transaction, s *State) error {
+	tr, ok := s.trial()
+	if !ok || ms.conf.Boot.TrialBoots <= 0 {
+		return nil
+	}
+	if tr.failed {
+		log.Warningf(ctx, i18n.G("Booting on %s, which failed its trial. It should fall back to %s"), s.ID, tr.fallback)
+		return nil
+	}
+
+	if tr.boots >= ms.conf.Boot.TrialBoots {
+		log.Warningf(ctx, i18n.G("%s didn't pass health checks in %d boots, falling back to %s"), s.ID, tr.boots, tr.fallback)
+		tr.failed = true
+		return s.setTrial(t, &tr)
+	}
+
+	tr.boots++
+	log.Infof(ctx, i18n.G("Trial boot %d of %d on %s"), tr.boots, ms.conf.Boot.TrialBoots, s.ID)
+	return s.setTrial(t, &tr)
+}
+
+// checkTrial runs health checks on s if it booted on trial. The trial ends when they pass.
+// Otherwise, an ErrTrialFailed is returned and the trial fails if this was its last boot.
+func (ms *Machines) checkTrial(ctx context.Context, t *zfs.Transaction, s *State) error {
+	tr, ok := s.trial()
+	if !ok {
+		return nil
+	}
+	if ms.conf.Boot.TrialBoots <= 0 {
+		log.Infof(ctx, i18n.G("Trial boots are disabled, ending trial of %s"), s.ID)
+		return s.setTrial(t, nil)
+	}
+	// The state was marked as trial but we didn't reboot on it yet.
+	if !tr.failed && tr.boots == 0 {
+		return nil
+	}
+
+	log.Infof(ctx, i18n.G("Running health checks on %s"), s.ID)
+	err := ms.healthCheck(ctx, ms.conf.Boot.HealthChecks)
+	if err == nil {
+		log.Infof(ctx, i18n.G("Health checks passed, ending trial of %s"), s.ID)
+		return s.setTrial(t, nil)
+	}
+
+	e := &ErrTrialFailed{s: fmt.Sprintf(i18n.G("health checks failed on %s: %v"), s.ID, err)}
+	if !tr.failed && tr.boots >= ms.conf.Boot.TrialBoots {
+		log.Warningf(ctx, i18n.G("%s didn't pass health checks in %d boots, falling back to %s"), s.ID, tr.boots, tr.fallback)
+		tr.failed = true
+		if err := s.setTrial(t, &tr); err != nil {
+			return err
+		}
+	}
+	e.Exhausted = tr.failed
+	return e
+}
+
+// trialFallbacks returns the states which trials fall back to.
+func (ms *Machines) trialFallbacks() map[string]bool {
+	r := make(map[string]bool)
+	for _, m := range ms.all {
+		if tr, ok := m.State.trial(); ok {
+			r[tr.fallback] = true
+		}
+		for _, s := range m.History {
+			if tr, ok := s.trial(); ok {
+				r[tr.fallback] = true
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,173 @@
+package machines
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+)
+
+// Usage is the disk space in bytes held by a state.
+type Usage struct {
+	// Unique is the space only referenced by the state.
+	Unique uint64
+	// Shared is the space referenced by the state which is also referenced by other states or datasets.
+	Shared uint64
+	// Freed is the space released by removing the state, including the user states only linked to it.
+	Freed uint64
+}
+
+// StateUsage returns the disk space held by the state name.
+// user limits the research on the given user state, otherwise the state is a system one.
+func (ms *Machines) StateUsage(ctx context.Context, name, user string) (Usage, error) {
+	s, err := ms.IDToState(ctx, name, user)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	for _, m := range ms.all {
+		if m.hasState(s) {
+			log.Debugf(ctx, "State %s belongs to machine %s", s.ID, m.ID)
+			return m.stateUsage(s), nil
+		}
+	}
+	return Usage{}, fmt.Errorf(i18n.G("no machine found for state %s"), s.ID)
+}
+
+// hasState returns if s is the current state of the machine, or one of its history or user states.
+func (m *Machine) hasState(s *State) bool {
+	if s == &m.State {
+		return true
+	}
+	for _, h := range m.History {
+		if h == s {
+			return true
+		}
+	}
+	for _, ustates := range m.AllUsersStates {
+		for _, us := range ustates {
+			if us == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stateUsage computes the disk space held by s, which belongs to the machine.
+func (m *Machine) stateUsage(s *State) Usage {
+	// Space only referenced by the snapshots of each dataset of the machine.
+	snapshotsUsed := make(map[string]uint64)
+	seen := make(map[string]bool)
+	addSnapshots := func(st *State) {
+		if !st.isSnapshot() {
+			return
+		}
+		for _, d := range st.getDatasets() {
+			if seen[d.Name] {
+				continue
+			}
+			seen[d.Name] = true
+			base, _ := splitSnapshotName(d.Name)
+			snapshotsUsed[base] += d.Used
+		}
+	}
+	for _, h := range m.History {
+		addSnapshots(h)
+	}
+	for _, ustates := range m.AllUsersStates {
+		for _, us := range ustates {
+			addSnapshots(us)
+		}
+	}
+
+	u := Usage{Unique: uniqueUsage(s, snapshotsUsed)}
+	var referenced uint64
+	for _, d := range s.getDatasets() {
+		referenced += d.Referenced
+	}
+	if referenced > u.Unique {
+		u.Shared = referenced - u.Unique
+	}
+
+	u.Freed = u.Unique
+	for _, us := range s.Users {
+		if us.isLinkedToMultipleStates() {
+			continue
+		}
+		u.Freed += uniqueUsage(us, snapshotsUsed)
+	}
+
+	return u
+}
+
+// uniqueUsage returns the space only referenced by s.
+// For filesystem states, this is the space used by the datasets of each route, minus the space only referenced by
+// their snapshots, which belong to other states.
+func uniqueUsage(s *State, snapshotsUsed map[string]uint64) uint64 {
+	var used uint64
+	if s.isSnapshot() {
+		for _, d := range s.getDatasets() {
+			used += d.Used
+		}
+		return used
+	}
+
+	var snapshots uint64
+	for _, ds := range s.Datasets {
+		used += ds[0].Used
+		for _, d := range ds {
+			snapshots += snapshotsUsed[d.Name]
+		}
+	}
+	if snapshots > used {
+		return 0
+	}
+	return used - snapshots
+}
+
+// isEmpty returns if no space is accounted, like on pools not reporting it.
+func (u Usage) isEmpty() bool {
+	return u.Unique == 0 && u.Shared == 0 && u.Freed == 0
+}
+
+// String returns a human readable summary of the space held by a state.
+func (u Usage) String() string {
+	return fmt.Sprintf(i18n.G("%s unique, %s shared, %s freed on removal"), formatSize(u.Unique), formatSize(u.Shared), formatSize(u.Freed))
+}
+
+// formatSize returns a size in bytes with a binary unit, like "1.5 GiB".
+func formatSize(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// isLinkedToMultipleStates returns if a filesystem user state is attached to more than one system state.
+// Snapshot user states are only attached to the system state of the same name.
+func (s State) isLinkedToMultipleStates() bool {
+	if s.isSnapshot() {
+		return false
+	}
+	for _, ds := range s.Datasets {
+		bootfsDatasets := make(map[string]bool)
+		for _, b := range strings.Split(ds[0].BootfsDatasets, bootfsdatasetsSeparator) {
+			if b = strings.TrimSpace(b); b != "" {
+				bootfsDatasets[b] = true
+			}
+		}
+		if len(bootfsDatasets) > 1 {
+			return true
+		}
+	}
+	return false
+}
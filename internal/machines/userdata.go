@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -22,7 +23,9 @@ import (
 
 // CreateUserData creates a new dataset for homepath and attach to current system.
 // It creates intermediates user datasets if needed.
-func (ms *Machines) CreateUserData(ctx context.Context, user, homepath string) error {
+// If passphrase isn't empty, the new dataset is an encryption root with passphrase as key. Its key is then loaded on
+// login by LoadUserKeys, so passphrase should be the login password of user.
+func (ms *Machines) CreateUserData(ctx context.Context, user, homepath string, passphrase []byte) error {
 	if !ms.current.isZsys() {
 		return errors.New(i18n.G("Current machine isn't Zsys, nothing to create"))
 	}
@@ -42,7 +45,10 @@ func (ms *Machines) CreateUserData(ctx context.Context, user, homepath string) e
 		cancel()
 		return err
 	} else if reused {
-		return ms.Refresh(ctx)
+		if len(passphrase) > 0 {
+			log.Warningf(ctx, i18n.G("Reusing existing user dataset for %q: its encryption isn't changed"), user)
+		}
+		return ms.applyChanges(ctx)
 	}
 
 	log.Infof(ctx, i18n.G("Create user dataset for %q"), homepath)
@@ -88,7 +94,13 @@ selectUserDataset:
 	}
 
 	userdataset := filepath.Join(userdatasetRoot, fmt.Sprintf("%s_%s", user, t.Zfs.GenerateID(6)))
-	if err := t.Create(userdataset, homepath, "on"); err != nil {
+	if len(passphrase) > 0 {
+		log.Infof(ctx, i18n.G("Encrypt user dataset with its own key"))
+		err = t.CreateEncrypted(userdataset, homepath, "on", passphrase)
+	} else {
+		err = t.Create(userdataset, homepath, "on")
+	}
+	if err != nil {
 		cancel()
 		return err
 	}
@@ -109,6 +121,53 @@ selectUserDataset:
 		return fmt.Errorf(i18n.G("couldn't set last used time to %q: ")+config.ErrorFormat, currentTime, err)
 	}
 
+	return ms.applyChanges(ctx)
+}
+
+// MountUserData mounts the user datasets of user attached to the current system which aren't mounted yet.
+// It's called on login: if user has no dataset attached to the current system, a new one is created for homepath.
+// Keys of encrypted user datasets have to be loaded first, with LoadUserKeys.
+func (ms *Machines) MountUserData(ctx context.Context, user, homepath string) error {
+	if !ms.current.isZsys() {
+		return errors.New(i18n.G("Current machine isn't Zsys, nothing to mount"))
+	}
+	if user == "" {
+		return errors.New(i18n.G("Needs a valid user name, got nothing"))
+	}
+
+	us, ok := ms.current.Users[user]
+	if !ok {
+		if homepath == "" {
+			return fmt.Errorf(i18n.G("user %q has no dataset attached to the current system and no home path was given"), user)
+		}
+		log.Infof(ctx, i18n.G("No user dataset for %q attached to the current system"), user)
+		return ms.CreateUserData(ctx, user, homepath, nil)
+	}
+
+	var toMount []*zfs.Dataset
+	for _, d := range us.getDatasets() {
+		if d.IsSnapshot || d.Mounted || d.CanMount != "on" || d.Mountpoint == "" {
+			continue
+		}
+		if d.KeyStatus == keyUnavailable {
+			return fmt.Errorf(i18n.G("couldn't mount %q: its encryption key isn't loaded"), d.Name)
+		}
+		toMount = append(toMount, d)
+	}
+	if len(toMount) == 0 {
+		log.Debugf(ctx, "User datasets of %q are already mounted", user)
+		return nil
+	}
+
+	// Parents are mounted before their children.
+	sort.Slice(toMount, func(i, j int) bool { return toMount[i].Mountpoint < toMount[j].Mountpoint })
+	for _, d := range toMount {
+		log.Infof(ctx, i18n.G("Mounting %q on %s"), d.Name, d.Mountpoint)
+		if err := ms.mount.Mount(d.Name, d.Mountpoint); err != nil {
+			return fmt.Errorf(i18n.G("couldn't mount %q on %s: ")+config.ErrorFormat, d.Name, d.Mountpoint, err)
+		}
+	}
+
 	return ms.Refresh(ctx)
 }
 
@@ -138,7 +197,7 @@ func (ms *Machines) ChangeHomeOnUserData(ctx context.Context, home, newHome stri
 		cancel()
 		return fmt.Errorf(i18n.G("didn't find any existing dataset matching %q"), home)
 	}
-	return ms.Refresh(ctx)
+	return ms.applyChanges(ctx)
 }
 
 // DissociateUser tries to unattach current user dataset to current system state
@@ -218,7 +277,7 @@ func (ms *Machines) DissociateUser(ctx context.Context, username string, removeH
 		}
 	}
 
-	return ms.Refresh(ctx)
+	return ms.applyChanges(ctx)
 }
 
 func getUserDatasetRoot(path string) string {
@@ -294,3 +353,82 @@ func (ms *Machines) tryReuseUserDataSet(t *zfs.Transaction, user string, oldhome
 
 	return false, nil
 }
+
+// RestoreUserState replaces the active datasets of user on the current system state by clones of its saved state
+// stateName, without any reboot. Previous user datasets are detached from the current system state and will be
+// collected by the garbage collector if they aren't linked to any other state.
+// Mounted user datasets are replaced by the new ones: if any of them can't be swapped, nothing is changed.
+// It returns the id of the new active user state.
+func (ms *Machines) RestoreUserState(ctx context.Context, user, stateName string) (string, error) {
+	if !ms.current.isZsys() {
+		return "", errors.New(i18n.G("Current machine isn't Zsys, nothing to restore"))
+	}
+	if user == "" {
+		return "", errors.New(i18n.G("Needs a valid user name, got nothing"))
+	}
+	cur, ok := ms.current.Users[user]
+	if !ok {
+		return "", fmt.Errorf(i18n.G("user %q not found on current state"), user)
+	}
+
+	s, err := ms.IDToState(ctx, stateName, user)
+	if err != nil {
+		return "", err
+	}
+	if !s.isSnapshot() {
+		return "", fmt.Errorf(i18n.G("%s isn't a saved state. Only saved user states can be restored"), s.ID)
+	}
+
+	log.Infof(ctx, i18n.G("Restoring user %q to state %s"), user, s.ID)
+
+	t, cancel := ms.z.NewTransaction(ctx)
+	defer t.Done()
+
+	userDataSuffix := t.Zfs.GenerateID(6)
+	if err := t.Clone(s.ID, userDataSuffix, false, true); err != nil {
+		cancel()
+		return "", fmt.Errorf(i18n.G("couldn't create new user datasets from %q: %v"), s.ID, err)
+	}
+	base, _ := splitSnapshotName(s.ID)
+	newID := renameWithID(base, userDataSuffix)
+
+	var newDatasets []*zfs.Dataset
+	for _, d := range ms.z.Datasets() {
+		if d.IsSnapshot || (d.Name != newID && !strings.HasPrefix(d.Name, newID+"/")) {
+			continue
+		}
+		newDatasets = append(newDatasets, d)
+	}
+	oldDatasets := cur.getDatasets()
+
+	// Associate new user datasets to current system state only, and detach previous ones.
+	if err := switchUsersDatasetsTags(t, ms.current.ID, oldDatasets, nil); err != nil {
+		cancel()
+		return "", err
+	}
+	if err := t.SetProperty(libzfs.BootfsDatasetsProp, ms.current.ID, newID, false); err != nil {
+		cancel()
+		return "", fmt.Errorf(i18n.G("couldn't add %q to BootfsDatasets property of %q: ")+config.ErrorFormat, ms.current.ID, newID, err)
+	}
+	currentTime := strconv.Itoa(int(ms.time.Now().Unix()))
+	if err := t.SetProperty(libzfs.LastUsedProp, currentTime, newID, false); err != nil {
+		cancel()
+		return "", fmt.Errorf(i18n.G("couldn't set last used time to %q: ")+config.ErrorFormat, currentTime, err)
+	}
+
+	if _, err := switchDatasetsCanMount(t, oldDatasets, "noauto"); err != nil {
+		cancel()
+		return "", err
+	}
+	if _, err := switchDatasetsCanMount(t, newDatasets, "on"); err != nil {
+		cancel()
+		return "", err
+	}
+
+	if err := ms.swapMounts(ctx, oldDatasets, newDatasets); err != nil {
+		cancel()
+		return "", err
+	}
+
+	return newID, ms.Refresh(ctx)
+}
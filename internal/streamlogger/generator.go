@@ -201,11 +201,18 @@ func main() {
 
 						fields := callFunc.Type.(*ast.FuncType).Params.List
 
-						if len(fields) != 2 {
+						// Server streaming calls get their request as first argument, client streaming ones only
+						// have a stream.
+						var reqType, origStream string
+						switch len(fields) {
+						case 1:
+							origStream = fields[0].Type.(*ast.Ident).Name
+						case 2:
+							reqType = fields[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+							origStream = fields[1].Type.(*ast.Ident).Name
+						default:
 							continue
 						}
-						reqType := fields[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
-						origStream := fields[1].Type.(*ast.Ident).Name
 
 						// Per function call server
 						r := strings.SplitN(origStream, "_", 2)
@@ -231,6 +238,10 @@ func main() {
 			}
 
 			stream := m.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+			// Client streams are sending requests, which don't carry logs.
+			if strings.HasSuffix(stream, "Client") {
+				continue
+			}
 			messageType := m.Type.Params.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
 
 			messageDetails, ok := messageLogs[messageType]
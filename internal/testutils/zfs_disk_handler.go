@@ -33,6 +33,8 @@ type FakePools struct {
 type fakePool struct {
 	Name       string
 	NoDefaults bool
+	Size       uint64 // Pool size in bytes, only work for mock usage. Capacity is then computed from allocated space.
+	Allocated  uint64 // Allocated space in bytes, only work for mock usage.
 	Datasets   []struct {
 		Name             string
 		IsVolume         bool
@@ -42,7 +44,19 @@ type fakePool struct {
 		LastUsed         time.Time `yaml:"last_used"`
 		LastBootedKernel string    `yaml:"last_booted_kernel"`
 		BootfsDatasets   string    `yaml:"bootfs_datasets"`
-		Origin           string    `yaml:"origin"`
+		Description      string
+		Tags             string
+		Pinned           string
+		Trial            string
+		BootNext         string
+		Origin           string `yaml:"origin"`
+		Used             uint64 // Used space in bytes, only work for mock usage.
+		Written          uint64 // Written space in bytes, only work for mock usage.
+		Referenced       uint64 // Referenced space in bytes, only work for mock usage.
+		LogicalUsed      uint64 `yaml:"logicalused"`    // Used space before compression in bytes, only work for mock usage.
+		EncryptionRoot   string `yaml:"encryptionroot"` // Encryption root of the dataset, only work for mock usage.
+		KeyStatus        string `yaml:"keystatus"`      // Status of the encryption key, defaults to available, only work for mock usage.
+		Key              string `yaml:"key"`            // Passphrase of the encryption root, checked when loading its key, only work for mock usage.
 		Snapshots        orderedSnapshots
 	}
 }
@@ -51,10 +65,18 @@ type orderedSnapshots []struct {
 	Name             string
 	Mountpoint       string
 	CanMount         string
-	ZsysBootfs       string     `yaml:"zsys_bootfs"`
-	LastBootedKernel string     `yaml:"last_booted_kernel"`
-	BootfsDatasets   string     `yaml:"bootfs_datasets"`
+	ZsysBootfs       string `yaml:"zsys_bootfs"`
+	LastBootedKernel string `yaml:"last_booted_kernel"`
+	BootfsDatasets   string `yaml:"bootfs_datasets"`
+	PackageChanges   string `yaml:"package_changes"`
+	Description      string
+	Tags             string
+	Pinned           string
 	CreationTime     *time.Time `yaml:"creation_time"` // Snapshot creation time, only work for mock usage.
+	Used             uint64     // Used space in bytes, only work for mock usage.
+	Written          uint64     // Written space in bytes, only work for mock usage.
+	Referenced       uint64     // Referenced space in bytes, only work for mock usage.
+	LogicalUsed      uint64     `yaml:"logicalused"` // Used space before compression in bytes, only work for mock usage.
 	//TODO: one libzfs support bookmarks
 	//BookMarks        []string
 }
@@ -201,6 +223,14 @@ func (fpools FakePools) Create(path string) func() {
 			fpools.tempPools = append(fpools.tempPools, fpool.Name)
 			defer pool.Close()
 
+			if fpool.Size > 0 {
+				m, ok := fpools.libzfs.(*mock.LibZFS)
+				if !ok {
+					fpools.Fatalf("trying to set pool size for %q on real ZFS run. This is not possible", fpool.Name)
+				}
+				m.SetPoolSpace(fpool.Name, fpool.Size, fpool.Allocated)
+			}
+
 			dType := libzfs.DatasetTypeFilesystem
 			for _, dataset := range fpool.Datasets {
 				datasetName := fpool.Name + "/" + dataset.Name
@@ -218,11 +248,30 @@ func (fpools FakePools) Create(path string) func() {
 						strSize := "819200"
 						props[libzfs.DatasetPropVolsize] = libzfs.Property{Value: strSize}
 					}
+					if dataset.Used > 0 || dataset.Written > 0 || dataset.Referenced > 0 || dataset.LogicalUsed > 0 {
+						if _, ok := fpools.libzfs.(*mock.LibZFS); !ok {
+							fpools.Fatalf("trying to set used space for %q on real ZFS run. This is not possible", datasetName)
+						}
+						setSpaceProps(props, dataset.Used, dataset.Written, dataset.Referenced, dataset.LogicalUsed)
+					}
+					if dataset.EncryptionRoot != "" {
+						if _, ok := fpools.libzfs.(*mock.LibZFS); !ok {
+							fpools.Fatalf("trying to set encryption for %q on real ZFS run. This is not possible", datasetName)
+						}
+						setEncryptionProps(props, datasetName, dataset.EncryptionRoot, dataset.KeyStatus)
+					}
 
 					d, err = fpools.libzfs.DatasetCreate(datasetName, dType, props)
 					if err != nil {
 						fpools.Fatalf("couldn't create dataset %q: %v", datasetName, err)
 					}
+					if dataset.Key != "" {
+						m, ok := fpools.libzfs.(*mock.LibZFS)
+						if !ok {
+							fpools.Fatalf("trying to set encryption key for %q on real ZFS run. This is not possible", datasetName)
+						}
+						m.SetKey(datasetName, dataset.Key)
+					}
 				}
 
 				if dType != libzfs.DatasetTypeFilesystem {
@@ -251,6 +300,21 @@ func (fpools FakePools) Create(path string) func() {
 				if dataset.BootfsDatasets != "" {
 					d.SetUserProperty(libzfs.BootfsDatasetsProp, dataset.BootfsDatasets)
 				}
+				if dataset.Description != "" {
+					d.SetUserProperty(libzfs.DescriptionProp, dataset.Description)
+				}
+				if dataset.Tags != "" {
+					d.SetUserProperty(libzfs.TagsProp, dataset.Tags)
+				}
+				if dataset.Pinned != "" {
+					d.SetUserProperty(libzfs.PinnedProp, dataset.Pinned)
+				}
+				if dataset.Trial != "" {
+					d.SetUserProperty(libzfs.TrialProp, dataset.Trial)
+				}
+				if dataset.BootNext != "" {
+					d.SetUserProperty(libzfs.BootNextProp, dataset.BootNext)
+				}
 				if dataset.Origin != "" {
 					if _, ok := fpools.libzfs.(*mock.LibZFS); !ok {
 						fpools.Fatalf("trying to set origin on clone for %q on real ZFS run. This is not possible", datasetName)
@@ -276,6 +340,12 @@ func (fpools FakePools) Create(path string) func() {
 							}
 							props[libzfs.DatasetPropCreation] = libzfs.Property{Value: strconv.FormatInt(s.CreationTime.Unix(), 10)}
 						}
+						if s.Used > 0 || s.Written > 0 || s.Referenced > 0 || s.LogicalUsed > 0 {
+							if _, ok := fpools.libzfs.(*mock.LibZFS); !ok {
+								fpools.Fatalf("trying to set used space for %q on real ZFS run. This is not possible", datasetName)
+							}
+							setSpaceProps(props, s.Used, s.Written, s.Referenced, s.LogicalUsed)
+						}
 						userProps := make(map[string]string)
 						if s.Mountpoint != "" {
 							userProps[libzfs.SnapshotMountpointProp] = s.Mountpoint
@@ -292,6 +362,18 @@ func (fpools FakePools) Create(path string) func() {
 						if s.BootfsDatasets != "" {
 							userProps[libzfs.BootfsDatasetsProp] = s.BootfsDatasets
 						}
+						if s.PackageChanges != "" {
+							userProps[libzfs.PackageChangesProp] = s.PackageChanges
+						}
+						if s.Description != "" {
+							userProps[libzfs.DescriptionProp] = s.Description
+						}
+						if s.Tags != "" {
+							userProps[libzfs.TagsProp] = s.Tags
+						}
+						if s.Pinned != "" {
+							userProps[libzfs.PinnedProp] = s.Pinned
+						}
 						d, err := fpools.libzfs.DatasetSnapshot(datasetName+"@"+s.Name, false, props, userProps)
 						if err != nil {
 							fmt.Fprintf(os.Stderr, "Couldn't create snapshot %q: %v\n", datasetName+"@"+s.Name, err)
@@ -306,3 +388,28 @@ func (fpools FakePools) Create(path string) func() {
 	snapshotWG.Wait()
 	return fpools.cleanup
 }
+
+// setEncryptionProps sets encryption read-only properties. datasetName is a new encryption root if it's root, otherwise
+// it shares the key of root, like clones.
+func setEncryptionProps(props map[libzfs.Prop]libzfs.Property, datasetName, root, keyStatus string) {
+	if keyStatus == "" {
+		keyStatus = "available"
+	}
+	props[libzfs.DatasetPropEncryption] = libzfs.Property{Value: "aes-256-gcm", Source: "-"}
+	props[libzfs.DatasetPropKeyFormat] = libzfs.Property{Value: "passphrase", Source: "-"}
+	props[libzfs.DatasetPropKeyStatus] = libzfs.Property{Value: keyStatus, Source: "-"}
+	if root == datasetName {
+		props[libzfs.DatasetPropKeyLocation] = libzfs.Property{Value: "prompt"}
+		return
+	}
+	props[libzfs.DatasetPropEncryptionRoot] = libzfs.Property{Value: root, Source: "-"}
+	props[libzfs.DatasetPropKeyLocation] = libzfs.Property{Value: "none", Source: "default"}
+}
+
+// setSpaceProps sets space read-only properties, so that they are not inherited.
+func setSpaceProps(props map[libzfs.Prop]libzfs.Property, used, written, referenced, logicalUsed uint64) {
+	props[libzfs.DatasetPropUsed] = libzfs.Property{Value: strconv.FormatUint(used, 10), Source: "-"}
+	props[libzfs.DatasetPropWritten] = libzfs.Property{Value: strconv.FormatUint(written, 10), Source: "-"}
+	props[libzfs.DatasetPropReferenced] = libzfs.Property{Value: strconv.FormatUint(referenced, 10), Source: "-"}
+	props[libzfs.DatasetPropLogicalused] = libzfs.Property{Value: strconv.FormatUint(logicalUsed, 10), Source: "-"}
+}
@@ -0,0 +1,74 @@
+package zfs
+
+// Changes are the datasets created, destroyed or modified in the local cache since they were last taken.
+type Changes struct {
+	// Created are the names of new datasets.
+	Created map[string]bool
+	// Destroyed are the names of removed datasets.
+	Destroyed map[string]bool
+	// Modified are the names of datasets which properties may have changed, with the names of those properties.
+	Modified map[string]map[string]bool
+	// Everything is set when any dataset may have changed, like after a rescan or a promotion.
+	Everything bool
+}
+
+// IsEmpty returns if no dataset has changed.
+func (c Changes) IsEmpty() bool {
+	return !c.Everything && len(c.Created) == 0 && len(c.Destroyed) == 0 && len(c.Modified) == 0
+}
+
+// TakeChanges returns the changes done by transactions, rescans and events since last call, and starts recording
+// new ones.
+func (z *Zfs) TakeChanges() Changes {
+	c := z.changes
+	z.changes = Changes{}
+	return c
+}
+
+// recordCreated records that the dataset name was added to the cache.
+func (z *Zfs) recordCreated(name string) {
+	if z.changes.Created == nil {
+		z.changes.Created = make(map[string]bool)
+	}
+	z.changes.Created[name] = true
+}
+
+// recordDestroyed records that the dataset name was removed from the cache.
+// A dataset created since last changes were taken, like on a transaction revert, is simply forgotten.
+func (z *Zfs) recordDestroyed(name string) {
+	delete(z.changes.Modified, name)
+	if z.changes.Created[name] {
+		delete(z.changes.Created, name)
+		return
+	}
+	if z.changes.Destroyed == nil {
+		z.changes.Destroyed = make(map[string]bool)
+	}
+	z.changes.Destroyed[name] = true
+}
+
+// recordModified records that the property prop changed on d and on its children which may inherit it.
+func (z *Zfs) recordModified(d *Dataset, prop string) {
+	z.recordModifiedDataset(d.Name, prop)
+
+	for _, c := range d.children {
+		if c.IsSnapshot {
+			continue
+		}
+		z.recordModified(c, prop)
+	}
+}
+
+// recordModifiedDataset records that the property prop changed on the dataset name only.
+func (z *Zfs) recordModifiedDataset(name, prop string) {
+	if z.changes.Created[name] {
+		return
+	}
+	if z.changes.Modified == nil {
+		z.changes.Modified = make(map[string]map[string]bool)
+	}
+	if z.changes.Modified[name] == nil {
+		z.changes.Modified[name] = make(map[string]bool)
+	}
+	z.changes.Modified[name][prop] = true
+}
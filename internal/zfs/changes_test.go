@@ -0,0 +1,122 @@
+package zfs_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubuntu/zsys/internal/testutils"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+func TestTakeChanges(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+
+	tests := map[string]struct {
+		create        string
+		snapshot      string
+		setPropertyOn string
+		destroy       string
+		takeChanges   bool
+		cancel        bool
+		refresh       bool
+
+		want zfs.Changes
+	}{
+		"Nothing changed": {},
+		"Create":          {create: "rpool/ROOT/ubuntu_1234/srv", want: zfs.Changes{Created: map[string]bool{"rpool/ROOT/ubuntu_1234/srv": true}}},
+		"Recursive snapshot": {snapshot: "rpool/ROOT/ubuntu_1234/var@snap1", want: zfs.Changes{Created: map[string]bool{
+			"rpool/ROOT/ubuntu_1234/var@snap1":         true,
+			"rpool/ROOT/ubuntu_1234/var/lib@snap1":     true,
+			"rpool/ROOT/ubuntu_1234/var/lib/apt@snap1": true}}},
+		"Set property is recorded on children": {setPropertyOn: "rpool/ROOT/ubuntu_1234", want: zfs.Changes{Modified: map[string]map[string]bool{
+			"rpool/ROOT/ubuntu_1234":             {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/opt":         {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var":         {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var/lib":     {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var/lib/apt": {libzfs.BootfsDatasetsProp: true}}}},
+		"Destroy":                               {destroy: "rpool/ROOT/ubuntu_1234/var/lib/apt", want: zfs.Changes{Destroyed: map[string]bool{"rpool/ROOT/ubuntu_1234/var/lib/apt": true}}},
+		"Destroy hierarchy":                     {destroy: "rpool/ROOT/ubuntu_1234/var/lib", want: zfs.Changes{Destroyed: map[string]bool{"rpool/ROOT/ubuntu_1234/var/lib": true, "rpool/ROOT/ubuntu_1234/var/lib/apt": true}}},
+		"Destroy a created dataset forgets it":  {create: "rpool/ROOT/ubuntu_1234/srv", destroy: "rpool/ROOT/ubuntu_1234/srv"},
+		"Property set on created dataset":       {create: "rpool/ROOT/ubuntu_1234/srv", setPropertyOn: "rpool/ROOT/ubuntu_1234/srv", want: zfs.Changes{Created: map[string]bool{"rpool/ROOT/ubuntu_1234/srv": true}}},
+		"Cancelled transaction forgets changes": {create: "rpool/ROOT/ubuntu_1234/srv", snapshot: "rpool/ROOT/ubuntu_1234/var@snap1", cancel: true},
+		"Cancelled property change is recorded once taken": {setPropertyOn: "rpool/ROOT/ubuntu_1234", takeChanges: true, cancel: true, want: zfs.Changes{Modified: map[string]map[string]bool{
+			"rpool/ROOT/ubuntu_1234":             {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/opt":         {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var":         {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var/lib":     {libzfs.BootfsDatasetsProp: true},
+			"rpool/ROOT/ubuntu_1234/var/lib/apt": {libzfs.BootfsDatasetsProp: true}}}},
+		"Refresh changes everything": {create: "rpool/ROOT/ubuntu_1234/srv", refresh: true, want: zfs.Changes{Everything: true}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			adapter := testutils.GetLibZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", "layout1__one_pool_n_datasets.yaml"), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			assert.Equal(t, zfs.Changes{Everything: true}, z.TakeChanges(), "New loads every dataset")
+
+			trans, cancel := z.NewTransaction(context.Background())
+			if tc.create != "" {
+				if err := trans.Create(tc.create, "/"+filepath.Base(tc.create), "on"); err != nil {
+					t.Fatalf("couldn't setup testbed when creating %q: %v", tc.create, err)
+				}
+			}
+			if tc.snapshot != "" {
+				base, snapshot := zfs.SplitSnapshotName(tc.snapshot)
+				if err := trans.Snapshot(snapshot, base, true); err != nil {
+					t.Fatalf("couldn't setup testbed when snapshotting: %v", err)
+				}
+			}
+			if tc.setPropertyOn != "" {
+				if err := trans.SetProperty(libzfs.BootfsDatasetsProp, "New value", tc.setPropertyOn, false); err != nil {
+					t.Fatalf("couldn't setup testbed when setting property: %v", err)
+				}
+			}
+			if tc.takeChanges {
+				z.TakeChanges()
+			}
+			if tc.cancel {
+				cancel()
+			}
+			trans.Done()
+			if tc.destroy != "" {
+				if err := z.NewNoTransaction(context.Background()).Destroy(tc.destroy); err != nil {
+					t.Fatalf("couldn't setup testbed when destroying %q: %v", tc.destroy, err)
+				}
+			}
+			if tc.refresh {
+				if err := z.Refresh(context.Background()); err != nil {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+			}
+
+			got := z.TakeChanges()
+			assert.Equal(t, tc.want.IsEmpty(), got.IsEmpty(), "IsEmpty returns if nothing has changed")
+			assert.Equal(t, len(tc.want.Created), len(got.Created), "Created datasets count")
+			for n := range tc.want.Created {
+				assert.True(t, got.Created[n], "%s is recorded as created", n)
+			}
+			assert.Equal(t, len(tc.want.Destroyed), len(got.Destroyed), "Destroyed datasets count")
+			for n := range tc.want.Destroyed {
+				assert.True(t, got.Destroyed[n], "%s is recorded as destroyed", n)
+			}
+			assert.Equal(t, len(tc.want.Modified), len(got.Modified), "Modified datasets count")
+			for n, props := range tc.want.Modified {
+				assert.Equal(t, props, got.Modified[n], "%s is recorded as modified", n)
+			}
+			assert.Equal(t, tc.want.Everything, got.Everything, "Everything is recorded as changed")
+
+			assert.True(t, z.TakeChanges().IsEmpty(), "Changes are reset once taken")
+		})
+	}
+}
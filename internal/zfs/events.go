@@ -0,0 +1,238 @@
+package zfs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ubuntu/zsys/internal/i18n"
+	"github.com/ubuntu/zsys/internal/log"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+const (
+	// historyEventClass is the class of events recording a change on a dataset.
+	historyEventClass = "sysevent.fs.zfs.history_event"
+	// poolEventPrefix starts the class of events on a whole pool, like import, export or destroy.
+	poolEventPrefix = "sysevent.fs.zfs.pool_"
+)
+
+// Events follows the ZFS events of the system until ctx is cancelled.
+func (z *Zfs) Events(ctx context.Context) (<-chan libzfs.Event, error) {
+	return z.libzfs.Events(ctx)
+}
+
+// ApplyEvents updates the local cache from a batch of ZFS events, rescanning only the datasets they touched when
+// possible.
+// Cached datasets which still exist are updated in place and only the differences with the cache are recorded as
+// changes: events caused by our own transactions, already in the cache, don't change anything.
+func (z *Zfs) ApplyEvents(ctx context.Context, events []libzfs.Event) error {
+	names := make(map[string]bool)
+	for _, e := range events {
+		switch {
+		case e.Class == historyEventClass:
+			// Renames and promotions change other datasets than the one of the event.
+			if e.Dataset == "" || e.Operation == "rename" || e.Operation == "promote" {
+				log.Debugf(ctx, i18n.G("ZFS: %s on %q, refreshing all datasets"), e.Operation, e.Dataset)
+				return z.rescan(ctx)
+			}
+			names[e.Dataset] = true
+
+		case strings.HasPrefix(e.Class, poolEventPrefix):
+			log.Debugf(ctx, i18n.G("ZFS: %s on pool %q, refreshing all datasets"), e.Class, e.Pool)
+			return z.rescan(ctx)
+		}
+	}
+
+	// Parents are refreshed with their children, and before them.
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+	refreshed := make(map[string]bool)
+nextDataset:
+	for _, n := range sorted {
+		for p := n; p != "." && p != "/"; p = parentDatasetName(p) {
+			if refreshed[p] {
+				continue nextDataset
+			}
+		}
+		log.Debugf(ctx, i18n.G("ZFS: event on %q, refreshing dataset"), n)
+		if err := z.refreshDataset(ctx, n); err != nil {
+			return err
+		}
+		refreshed[n] = true
+	}
+	return nil
+}
+
+// rescan scans all datasets again and merges them in the cache, recording only their differences as changes.
+func (z *Zfs) rescan(ctx context.Context) error {
+	dsZFS, err := z.libzfs.DatasetOpenAll()
+	if err != nil {
+		return fmt.Errorf(i18n.G("can't list datasets: %v"), err)
+	}
+
+	root := &Dataset{Name: z.root.Name}
+	datasets := make(map[string]*Dataset)
+	for _, dZFS := range dsZFS {
+		c, err := newDatasetTree(ctx, dZFS, &datasets)
+		if err != nil {
+			return fmt.Errorf("couldn't scan all datasets: %v", err)
+		}
+		root.children = append(root.children, c)
+	}
+
+	z.mergeDatasetTree(z.root, root)
+	return nil
+}
+
+// refreshDataset rescans name and its children, removing them from the cache if name doesn't exist anymore.
+func (z *Zfs) refreshDataset(ctx context.Context, name string) error {
+	parentName := parentDatasetName(name)
+
+	dZFS, err := z.libzfs.DatasetOpen(name)
+	if err != nil {
+		log.Debugf(ctx, i18n.G("ZFS: %q not found, removing it from cache: %v"), name, err)
+		z.removeDatasetTree(name, parentName)
+		return nil
+	}
+
+	parent, err := z.findDatasetByName(parentName)
+	if err != nil {
+		log.Debugf(ctx, i18n.G("ZFS: parent of %q not in cache, refreshing all datasets"), name)
+		return z.rescan(ctx)
+	}
+
+	datasets := make(map[string]*Dataset)
+	d, err := newDatasetTree(ctx, dZFS, &datasets)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't scan dataset %q: %v"), name, err)
+	}
+	// Not a filesystem or snapshot dataset: nothing to cache
+	if d == nil {
+		z.removeDatasetTree(name, parentName)
+		return nil
+	}
+
+	if old, exists := z.allDatasets[name]; exists {
+		z.mergeDatasetTree(old, d)
+		return nil
+	}
+	z.addDatasetTree(d)
+	parent.children = append(parent.children, d)
+
+	return nil
+}
+
+// mergeDatasetTree updates the cached dataset old and its children with the dataset scanned.
+// Cached datasets which still exist are kept, so that their references stay valid.
+func (z *Zfs) mergeDatasetTree(old, scanned *Dataset) {
+	for _, p := range changedProperties(old.DatasetProp, scanned.DatasetProp) {
+		z.recordModifiedDataset(old.Name, p)
+	}
+	old.DatasetProp = scanned.DatasetProp
+	if scanned.dZFS != nil {
+		old.dZFS = scanned.dZFS
+	}
+
+	oldChildren := make(map[string]*Dataset)
+	for _, c := range old.children {
+		oldChildren[c.Name] = c
+	}
+	children := make([]*Dataset, 0, len(scanned.children))
+	for _, c := range scanned.children {
+		if oc, ok := oldChildren[c.Name]; ok {
+			z.mergeDatasetTree(oc, c)
+			children = append(children, oc)
+			delete(oldChildren, c.Name)
+			continue
+		}
+		z.addDatasetTree(c)
+		children = append(children, c)
+	}
+	for _, c := range old.children {
+		if _, removed := oldChildren[c.Name]; removed {
+			z.forgetDatasetTree(c)
+		}
+	}
+	old.children = children
+}
+
+// addDatasetTree adds d and its children to the cache, parents first.
+func (z *Zfs) addDatasetTree(d *Dataset) {
+	z.allDatasets[d.Name] = d
+	z.recordCreated(d.Name)
+	for _, c := range d.children {
+		z.addDatasetTree(c)
+	}
+}
+
+// forgetDatasetTree removes d and its children from the cache, without detaching d from its parent.
+func (z *Zfs) forgetDatasetTree(d *Dataset) {
+	for _, c := range d.children {
+		z.forgetDatasetTree(c)
+	}
+	delete(z.allDatasets, d.Name)
+	z.recordDestroyed(d.Name)
+}
+
+// changedProperties returns the names of the properties which value or source differ between old and scanned.
+func changedProperties(old, scanned DatasetProp) (props []string) {
+	for p, changed := range map[string]bool{
+		libzfs.MountPointProp:       old.Mountpoint != scanned.Mountpoint || old.sources.Mountpoint != scanned.sources.Mountpoint,
+		libzfs.CanmountProp:         old.CanMount != scanned.CanMount || old.sources.CanMount != scanned.sources.CanMount,
+		MountedProp:                 old.Mounted != scanned.Mounted,
+		libzfs.BootfsProp:           old.BootFS != scanned.BootFS || old.sources.BootFS != scanned.sources.BootFS,
+		libzfs.LastUsedProp:         old.LastUsed != scanned.LastUsed || old.sources.LastUsed != scanned.sources.LastUsed,
+		libzfs.LastBootedKernelProp: old.LastBootedKernel != scanned.LastBootedKernel || old.sources.LastBootedKernel != scanned.sources.LastBootedKernel,
+		libzfs.BootfsDatasetsProp:   old.BootfsDatasets != scanned.BootfsDatasets || old.sources.BootfsDatasets != scanned.sources.BootfsDatasets,
+		libzfs.PackageChangesProp:   old.PackageChanges != scanned.PackageChanges || old.sources.PackageChanges != scanned.sources.PackageChanges,
+		libzfs.DescriptionProp:      old.Description != scanned.Description || old.sources.Description != scanned.sources.Description,
+		libzfs.TagsProp:             old.Tags != scanned.Tags || old.sources.Tags != scanned.sources.Tags,
+		libzfs.PinnedProp:           old.Pinned != scanned.Pinned || old.sources.Pinned != scanned.sources.Pinned,
+		libzfs.TrialProp:            old.Trial != scanned.Trial || old.sources.Trial != scanned.sources.Trial,
+		libzfs.BootNextProp:         old.BootNext != scanned.BootNext || old.sources.BootNext != scanned.sources.BootNext,
+		OriginProp:                  old.Origin != scanned.Origin,
+		EncryptionRootProp:          old.EncryptionRoot != scanned.EncryptionRoot,
+		KeyStatusProp:               old.KeyStatus != scanned.KeyStatus,
+		KeyLocationProp:             old.KeyLocation != scanned.KeyLocation,
+		UsedProp:                    old.Used != scanned.Used,
+		WrittenProp:                 old.Written != scanned.Written,
+		ReferencedProp:              old.Referenced != scanned.Referenced,
+		LogicalUsedProp:             old.LogicalUsed != scanned.LogicalUsed,
+	} {
+		if changed {
+			props = append(props, p)
+		}
+	}
+	sort.Strings(props)
+	return props
+}
+
+// parentDatasetName returns the name of the parent of the dataset name, which is the filesystem dataset of a
+// snapshot.
+func parentDatasetName(name string) string {
+	if base, snapshot := splitSnapshotName(name); snapshot != "" {
+		return base
+	}
+	return filepath.Dir(name)
+}
+
+// removeDatasetTree removes name and all its children from the cache, if present.
+func (z *Zfs) removeDatasetTree(name, parentName string) {
+	d, exists := z.allDatasets[name]
+	if !exists {
+		return
+	}
+
+	z.forgetDatasetTree(d)
+
+	if parent, err := z.findDatasetByName(parentName); err == nil {
+		// The dataset is already detached from its parent if this fails.
+		_ = parent.removeChild(name)
+	}
+}
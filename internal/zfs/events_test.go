@@ -0,0 +1,243 @@
+package zfs_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubuntu/zsys/internal/testutils"
+	"github.com/ubuntu/zsys/internal/zfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+	"github.com/ubuntu/zsys/internal/zfs/libzfs/mock"
+)
+
+func TestApplyEvents(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+
+	historyEvent := func(operation, dataset string) libzfs.Event {
+		return libzfs.Event{Class: "sysevent.fs.zfs.history_event", Pool: "rpool", Dataset: dataset, Operation: operation}
+	}
+
+	tests := map[string]struct {
+		def       string
+		cloneFrom string
+
+		// changes done outside of our zfs object, or by ourselves
+		ourselves        bool
+		snapshot         string
+		create           []string
+		destroy          string
+		setPropertyOn    string
+		cloneOutside     string
+		promote          string
+		destroyUnnoticed string
+
+		events []libzfs.Event
+
+		wantChanged   bool
+		wantCreated   []string
+		wantDestroyed []string
+	}{
+		"Snapshot created": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", snapshot: "rpool/ROOT/ubuntu_1234/var@snap_r3",
+			events:      []libzfs.Event{historyEvent("snapshot", "rpool/ROOT/ubuntu_1234/var@snap_r3")},
+			wantChanged: true, wantCreated: []string{"rpool/ROOT/ubuntu_1234/var@snap_r3"}},
+		"Dataset created": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", create: []string{"rpool/ROOT/ubuntu_1234/srv"},
+			events:      []libzfs.Event{historyEvent("create", "rpool/ROOT/ubuntu_1234/srv")},
+			wantChanged: true, wantCreated: []string{"rpool/ROOT/ubuntu_1234/srv"}},
+		"Dataset destroyed": {def: "layout1__one_pool_n_datasets.yaml", destroy: "rpool/ROOT/ubuntu_1234/var/lib/apt",
+			events:      []libzfs.Event{historyEvent("destroy", "rpool/ROOT/ubuntu_1234/var/lib/apt")},
+			wantChanged: true, wantDestroyed: []string{"rpool/ROOT/ubuntu_1234/var/lib/apt"}},
+		"Dataset hierarchy destroyed": {def: "layout1__one_pool_n_datasets.yaml", destroy: "rpool/ROOT/ubuntu_1234/var",
+			events:      []libzfs.Event{historyEvent("destroy", "rpool/ROOT/ubuntu_1234/var")},
+			wantChanged: true, wantDestroyed: []string{"rpool/ROOT/ubuntu_1234/var", "rpool/ROOT/ubuntu_1234/var/lib", "rpool/ROOT/ubuntu_1234/var/lib/apt"}},
+		"Snapshot destroyed": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", destroy: "rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1",
+			events:      []libzfs.Event{historyEvent("destroy", "rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1")},
+			wantChanged: true, wantDestroyed: []string{"rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1"}},
+		"Property set is inherited": {def: "layout1__one_pool_n_datasets_one_main_snapshots_inherited.yaml", setPropertyOn: "rpool/ROOT/ubuntu_1234",
+			events:      []libzfs.Event{historyEvent("set", "rpool/ROOT/ubuntu_1234")},
+			wantChanged: true},
+		"Hierarchy cloned": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", cloneOutside: "rpool/ROOT/ubuntu_1234@snap_r1",
+			events:      []libzfs.Event{historyEvent("clone", "rpool/ROOT/ubuntu_5678")},
+			wantChanged: true, wantCreated: []string{"rpool/ROOT/ubuntu_5678", "rpool/ROOT/ubuntu_5678/opt", "rpool/ROOT/ubuntu_5678/var", "rpool/ROOT/ubuntu_5678/var/lib", "rpool/ROOT/ubuntu_5678/var/lib/apt"}},
+		"Clone promoted": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1", promote: "rpool/ROOT/ubuntu_5678",
+			events:      []libzfs.Event{historyEvent("promote", "rpool/ROOT/ubuntu_5678")},
+			wantChanged: true,
+			wantCreated: []string{"rpool/ROOT/ubuntu_5678@snap_r1", "rpool/ROOT/ubuntu_5678/opt@snap_r1", "rpool/ROOT/ubuntu_5678/var@snap_r1",
+				"rpool/ROOT/ubuntu_5678/var/lib@snap_r1", "rpool/ROOT/ubuntu_5678/var/lib/apt@snap_r1"},
+			wantDestroyed: []string{"rpool/ROOT/ubuntu_1234@snap_r1", "rpool/ROOT/ubuntu_1234/opt@snap_r1", "rpool/ROOT/ubuntu_1234/var@snap_r1",
+				"rpool/ROOT/ubuntu_1234/var/lib@snap_r1", "rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1"}},
+		"Batch of events on the same hierarchy": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", cloneOutside: "rpool/ROOT/ubuntu_1234@snap_r1",
+			events: []libzfs.Event{historyEvent("clone", "rpool/ROOT/ubuntu_5678/var/lib/apt"), historyEvent("clone", "rpool/ROOT/ubuntu_5678/var/lib"),
+				historyEvent("clone", "rpool/ROOT/ubuntu_5678/var"), historyEvent("clone", "rpool/ROOT/ubuntu_5678")},
+			wantChanged: true, wantCreated: []string{"rpool/ROOT/ubuntu_5678", "rpool/ROOT/ubuntu_5678/opt", "rpool/ROOT/ubuntu_5678/var", "rpool/ROOT/ubuntu_5678/var/lib", "rpool/ROOT/ubuntu_5678/var/lib/apt"}},
+
+		"Pool event refreshes everything": {def: "layout1__one_pool_n_datasets.yaml", destroyUnnoticed: "rpool/ROOT/ubuntu_1234/var/lib/apt",
+			events:      []libzfs.Event{{Class: "sysevent.fs.zfs.pool_import", Pool: "rpool"}},
+			wantChanged: true, wantDestroyed: []string{"rpool/ROOT/ubuntu_1234/var/lib/apt"}},
+		"History event without dataset refreshes everything": {def: "layout1__one_pool_n_datasets.yaml", destroyUnnoticed: "rpool/ROOT/ubuntu_1234/var/lib/apt",
+			events:      []libzfs.Event{historyEvent("destroy", "")},
+			wantChanged: true, wantDestroyed: []string{"rpool/ROOT/ubuntu_1234/var/lib/apt"}},
+		"Unknown parent refreshes everything": {def: "layout1__one_pool_n_datasets.yaml", create: []string{"rpool/ROOT/ubuntu_1234/srv", "rpool/ROOT/ubuntu_1234/srv/www"},
+			events:      []libzfs.Event{historyEvent("create", "rpool/ROOT/ubuntu_1234/srv/www")},
+			wantChanged: true, wantCreated: []string{"rpool/ROOT/ubuntu_1234/srv", "rpool/ROOT/ubuntu_1234/srv/www"}},
+
+		// Events caused by ourselves are already in our cache
+		"Our own snapshot is ignored": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", ourselves: true, snapshot: "rpool/ROOT/ubuntu_1234/var@snap_r3",
+			events: []libzfs.Event{historyEvent("snapshot", "rpool/ROOT/ubuntu_1234/var@snap_r3")}},
+		"Our own property set is ignored": {def: "layout1__one_pool_n_datasets_one_main_snapshots_inherited.yaml", ourselves: true, setPropertyOn: "rpool/ROOT/ubuntu_1234",
+			events: []libzfs.Event{historyEvent("set", "rpool/ROOT/ubuntu_1234")}},
+		"Our own promotion is ignored": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1", ourselves: true, promote: "rpool/ROOT/ubuntu_5678",
+			events: []libzfs.Event{historyEvent("promote", "rpool/ROOT/ubuntu_5678")}},
+		"Our own destruction is ignored": {def: "layout1__one_pool_n_datasets.yaml", ourselves: true, destroy: "rpool/ROOT/ubuntu_1234/var/lib/apt",
+			events: []libzfs.Event{historyEvent("destroy", "rpool/ROOT/ubuntu_1234/var/lib/apt")}},
+		"Event on dataset not in cache": {def: "layout1__one_pool_n_datasets.yaml", events: []libzfs.Event{historyEvent("destroy", "rpool/doesntexist")}},
+		"Unrelated event is ignored": {def: "layout1__one_pool_n_datasets.yaml", destroyUnnoticed: "rpool/ROOT/ubuntu_1234/var/lib/apt",
+			events: []libzfs.Event{{Class: "ereport.fs.zfs.checksum", Pool: "rpool"}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			ta := timeAsserter(time.Now())
+			adapter := testutils.GetLibZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithWaitBetweenSnapshots(), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if tc.cloneFrom != "" {
+				trans, _ := z.NewTransaction(context.Background())
+				if err := trans.Clone(tc.cloneFrom, "5678", false, true); err != nil {
+					t.Fatalf("couldn't setup testbed when cloning: %v", err)
+				}
+				trans.Done()
+			}
+
+			// Change the system through another zfs object, as another tool would do.
+			outside := z
+			if !tc.ourselves {
+				outside, err = zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+				if err != nil {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+			}
+			initState := copyState(z)
+			trans, _ := outside.NewTransaction(context.Background())
+			if tc.snapshot != "" {
+				base, snapshot := zfs.SplitSnapshotName(tc.snapshot)
+				if err := trans.Snapshot(snapshot, base, false); err != nil {
+					t.Fatalf("couldn't setup testbed when snapshotting: %v", err)
+				}
+			}
+			for _, n := range tc.create {
+				if err := trans.Create(n, "/"+filepath.Base(n), "on"); err != nil {
+					t.Fatalf("couldn't setup testbed when creating %q: %v", n, err)
+				}
+			}
+			if tc.setPropertyOn != "" {
+				if err := trans.SetProperty(libzfs.BootfsDatasetsProp, "New value", tc.setPropertyOn, false); err != nil {
+					t.Fatalf("couldn't setup testbed when setting property: %v", err)
+				}
+			}
+			if tc.cloneOutside != "" {
+				if err := trans.Clone(tc.cloneOutside, "5678", false, true); err != nil {
+					t.Fatalf("couldn't setup testbed when cloning: %v", err)
+				}
+			}
+			if tc.promote != "" {
+				if err := trans.Promote(tc.promote); err != nil {
+					t.Fatalf("couldn't setup testbed when promoting: %v", err)
+				}
+			}
+			trans.Done()
+			for _, n := range []string{tc.destroy, tc.destroyUnnoticed} {
+				if n == "" {
+					continue
+				}
+				if err := outside.NewNoTransaction(context.Background()).Destroy(n); err != nil {
+					t.Fatalf("couldn't setup testbed when destroying %q: %v", n, err)
+				}
+			}
+			if tc.ourselves {
+				initState = copyState(z)
+			}
+			z.TakeChanges()
+
+			err = z.ApplyEvents(context.Background(), tc.events)
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			c := z.TakeChanges()
+			assert.Equal(t, tc.wantChanged, !c.IsEmpty(), "ApplyEvents records changes only if the cache has changed")
+			assert.False(t, c.Everything, "ApplyEvents records the changed datasets, not that everything has changed")
+			assert.ElementsMatch(t, tc.wantCreated, keys(c.Created), "ApplyEvents records created datasets")
+			assert.ElementsMatch(t, tc.wantDestroyed, keys(c.Destroyed), "ApplyEvents records destroyed datasets")
+			if !tc.wantChanged {
+				assertDatasetsEquals(t, ta, initState, z.Datasets())
+				return
+			}
+
+			zfs.AssertNoZFSChildren(t, z)
+			assertIdempotentWithNew(t, ta, z.Datasets(), adapter)
+		})
+	}
+}
+
+// keys returns the keys of a set.
+func keys(m map[string]bool) (r []string) {
+	for k := range m {
+		r = append(r, k)
+	}
+	return r
+}
+
+func TestEvents(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	adapter := testutils.GetLibZFS(t)
+	if _, ok := adapter.(*mock.LibZFS); !ok {
+		t.Skip("Can only be called with the mock libzfs")
+	}
+	fPools := testutils.NewFakePools(t, filepath.Join("testdata", "layout1__one_pool_n_datasets.yaml"), testutils.WithLibZFS(adapter))
+	defer fPools.Create(dir)()
+	z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := z.Events(ctx)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	trans, _ := z.NewTransaction(context.Background())
+	if err := trans.Snapshot("snap_r1", "rpool/ROOT/ubuntu_1234/var/lib/apt", false); err != nil {
+		t.Fatalf("couldn't setup testbed when snapshotting: %v", err)
+	}
+	trans.Done()
+
+	select {
+	case e := <-events:
+		assert.Equal(t, libzfs.Event{
+			Class:     "sysevent.fs.zfs.history_event",
+			Pool:      "rpool",
+			Dataset:   "rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1",
+			Operation: "snapshot",
+		}, e, "Snapshot event is sent")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a snapshot event but got none")
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -58,8 +58,9 @@ func (d *Dataset) refreshProperties(ctx context.Context) error {
 			mounted = true
 		}
 	}
+	// Properties received from a send stream are set on the dataset itself.
 	switch sourceMountPoint {
-	case "local":
+	case "local", "received":
 		sources.Mountpoint = "local"
 	case "default":
 		sources.Mountpoint = "inherited"
@@ -70,7 +71,7 @@ func (d *Dataset) refreshProperties(ctx context.Context) error {
 	}
 
 	switch sourceCanMount {
-	case "local":
+	case "local", "received":
 		sources.CanMount = "local"
 	case "default":
 		sources.CanMount = ""
@@ -83,6 +84,9 @@ func (d *Dataset) refreshProperties(ctx context.Context) error {
 	}
 
 	origin := dZFSprops[libzfs.DatasetPropOrigin].Value
+	encryptionRoot := getNativePropertyFromSys(libzfs.DatasetPropEncryptionRoot, dZFSprops)
+	keyStatus := getNativePropertyFromSys(libzfs.DatasetPropKeyStatus, dZFSprops)
+	keyLocation := getNativePropertyFromSys(libzfs.DatasetPropKeyLocation, dZFSprops)
 
 	bfs, srcBootFS, err := getUserPropertyFromSys(ctx, libzfs.BootfsProp, d.dZFS)
 	if err != nil {
@@ -139,6 +143,49 @@ func (d *Dataset) refreshProperties(ctx context.Context) error {
 	}
 	sources.BootfsDatasets = srcBootfsDatasets
 
+	var packageChanges, srcPackageChanges string
+	if d.IsSnapshot {
+		if packageChanges, srcPackageChanges, err = getUserPropertyFromSys(ctx, libzfs.PackageChangesProp, d.dZFS); err != nil {
+			log.Warningf(ctx, i18n.G("can't read package changes property, ignoring: ")+config.ErrorFormat, err)
+		}
+	}
+	sources.PackageChanges = srcPackageChanges
+
+	description, srcDescription, err := getUserPropertyFromSys(ctx, libzfs.DescriptionProp, d.dZFS)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("can't read description property, ignoring: ")+config.ErrorFormat, err)
+	}
+	sources.Description = srcDescription
+
+	tags, srcTags, err := getUserPropertyFromSys(ctx, libzfs.TagsProp, d.dZFS)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("can't read tags property, ignoring: ")+config.ErrorFormat, err)
+	}
+	sources.Tags = srcTags
+
+	pinned, srcPinned, err := getUserPropertyFromSys(ctx, libzfs.PinnedProp, d.dZFS)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("can't read pinned property, ignoring: ")+config.ErrorFormat, err)
+	}
+	sources.Pinned = srcPinned
+
+	trial, srcTrial, err := getUserPropertyFromSys(ctx, libzfs.TrialProp, d.dZFS)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("can't read trial property, ignoring: ")+config.ErrorFormat, err)
+	}
+	sources.Trial = srcTrial
+
+	bootNext, srcBootNext, err := getUserPropertyFromSys(ctx, libzfs.BootNextProp, d.dZFS)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("can't read boot next property, ignoring: ")+config.ErrorFormat, err)
+	}
+	sources.BootNext = srcBootNext
+
+	used := getSpacePropertyFromSys(ctx, libzfs.DatasetPropUsed, dZFSprops)
+	written := getSpacePropertyFromSys(ctx, libzfs.DatasetPropWritten, dZFSprops)
+	referenced := getSpacePropertyFromSys(ctx, libzfs.DatasetPropReferenced, dZFSprops)
+	logicalUsed := getSpacePropertyFromSys(ctx, libzfs.DatasetPropLogicalused, dZFSprops)
+
 	d.DatasetProp = DatasetProp{
 		Mountpoint:       mountpoint,
 		CanMount:         canMount,
@@ -147,12 +194,49 @@ func (d *Dataset) refreshProperties(ctx context.Context) error {
 		LastUsed:         lastUsed,
 		LastBootedKernel: lastBootedKernel,
 		BootfsDatasets:   bootfsDatasets,
+		PackageChanges:   packageChanges,
+		Description:      description,
+		Tags:             tags,
+		Pinned:           pinned,
+		Trial:            trial,
+		BootNext:         bootNext,
 		Origin:           origin,
+		EncryptionRoot:   encryptionRoot,
+		KeyStatus:        keyStatus,
+		KeyLocation:      keyLocation,
+		Used:             used,
+		Written:          written,
+		Referenced:       referenced,
+		LogicalUsed:      logicalUsed,
 		sources:          sources,
 	}
 	return nil
 }
 
+// getNativePropertyFromSys returns the value of a native property. Unset properties, reported as "-" or "none", are
+// empty.
+func getNativePropertyFromSys(prop libzfs.Prop, props map[libzfs.Prop]libzfs.Property) string {
+	v := props[prop].Value
+	if v == "-" || v == "none" {
+		return ""
+	}
+	return v
+}
+
+// getSpacePropertyFromSys returns the value in bytes of a native space property. Unset properties are 0.
+func getSpacePropertyFromSys(ctx context.Context, prop libzfs.Prop, props map[libzfs.Prop]libzfs.Property) uint64 {
+	v := props[prop].Value
+	if v == "" || v == "-" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		log.Warningf(ctx, i18n.G("space property of %q isn't a number, ignoring: ")+config.ErrorFormat, props[libzfs.DatasetPropName].Value, err)
+		return 0
+	}
+	return n
+}
+
 // getUserPropertyFromSys returns the value of a user property and its source from the underlying
 // ZFS system dataset state.
 // It also sanitize the sources to only return "local" or "inherited".
@@ -169,6 +253,11 @@ func getUserPropertyFromSys(ctx context.Context, prop string, dZFS libzfs.DZFSIn
 	if p.Value == "-" && (p.Source == "-" || p.Source == "none") {
 		return "", "", nil
 	}
+	// Properties received from a send stream are set explicitly on the dataset, as local ones.
+	if p.Source == "received" {
+		p.Source = "local"
+	}
+
 	// The user property isn't set explicitly on the snapshot (inherited from non snapshot parent): ignore it.
 	if dZFS.IsSnapshot() && p.Source != "local" {
 		return "", "", nil
@@ -540,6 +629,24 @@ func (d *Dataset) stringToProp(name string) (nativeProp libzfs.Prop, userProp st
 	case libzfs.LastBootedKernelProp:
 		value = &d.LastBootedKernel
 		simplifiedSource = &d.sources.LastBootedKernel
+	case libzfs.PackageChangesProp:
+		value = &d.PackageChanges
+		simplifiedSource = &d.sources.PackageChanges
+	case libzfs.DescriptionProp:
+		value = &d.Description
+		simplifiedSource = &d.sources.Description
+	case libzfs.TagsProp:
+		value = &d.Tags
+		simplifiedSource = &d.sources.Tags
+	case libzfs.PinnedProp:
+		value = &d.Pinned
+		simplifiedSource = &d.sources.Pinned
+	case libzfs.TrialProp:
+		value = &d.Trial
+		simplifiedSource = &d.sources.Trial
+	case libzfs.BootNextProp:
+		value = &d.BootNext
+		simplifiedSource = &d.sources.BootNext
 	default:
 		panic(fmt.Sprintf("unsupported property %q", name))
 	}
@@ -613,6 +720,17 @@ func (t *nestedTransaction) inverseOrigin(oldOrigDataset, newOrigDataset *Datase
 	oldOrigDataset.Origin = baseSnapshot.Name
 	newOrigDataset.Origin = orig
 
+	// Promoting a clone of an encryption root moves the encryption root, with its key location, to the clone.
+	if oldOrigDataset.EncryptionRoot == oldOrigDataset.Name && newOrigDataset.EncryptionRoot == oldOrigDataset.Name {
+		oldRoot := oldOrigDataset.Name
+		for _, d := range t.Zfs.allDatasets {
+			if d.EncryptionRoot == oldRoot {
+				d.EncryptionRoot = newOrigDataset.Name
+			}
+		}
+		newOrigDataset.KeyLocation, oldOrigDataset.KeyLocation = oldOrigDataset.KeyLocation, ""
+	}
+
 	return nil
 }
 
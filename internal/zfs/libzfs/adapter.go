@@ -1,6 +1,9 @@
 package libzfs
 
 import (
+	"context"
+	"io"
+
 	golibzfs "github.com/bicomsystems/go-libzfs"
 )
 
@@ -55,6 +58,24 @@ const (
 	DatasetPropCreation = golibzfs.DatasetPropCreation
 	// DatasetPropVolsize is the volume size property for the dataset
 	DatasetPropVolsize = golibzfs.DatasetPropVolsize
+	// DatasetPropUsed is the space used by the dataset, and only by it for snapshots
+	DatasetPropUsed = golibzfs.DatasetPropUsed
+	// DatasetPropWritten is the space written to the dataset since its previous snapshot
+	DatasetPropWritten = golibzfs.DatasetPropWritten
+	// DatasetPropReferenced is the space referenced by the dataset, which can be shared with other datasets
+	DatasetPropReferenced = golibzfs.DatasetPropReferenced
+	// DatasetPropLogicalused is the space used by the dataset before compression
+	DatasetPropLogicalused = golibzfs.DatasetPropLogicalused
+	// DatasetPropEncryption is the encryption algorithm of the dataset, or off
+	DatasetPropEncryption = golibzfs.DatasetPropEncryption
+	// DatasetPropKeyFormat is the format of the encryption key of an encryption root
+	DatasetPropKeyFormat = golibzfs.DatasetPropKeyFormat
+	// DatasetPropKeyLocation is where the encryption key of an encryption root is loaded from
+	DatasetPropKeyLocation = golibzfs.DatasetPropKeyLocation
+	// DatasetPropEncryptionRoot is the dataset holding the encryption key of the dataset
+	DatasetPropEncryptionRoot = golibzfs.DatasetPropEncryptionRoot
+	// DatasetPropKeyStatus is the status of the encryption key of the dataset: available or unavailable
+	DatasetPropKeyStatus = golibzfs.DatasetPropKeyStatus
 )
 
 const (
@@ -67,6 +88,18 @@ const (
 	BootfsDatasetsProp = zsysPrefix + "bootfs-datasets"
 	// LastBootedKernelProp string value
 	LastBootedKernelProp = zsysPrefix + "last-booted-kernel"
+	// PackageChangesProp string value
+	PackageChangesProp = zsysPrefix + "package-changes"
+	// DescriptionProp string value
+	DescriptionProp = zsysPrefix + "description"
+	// TagsProp string value
+	TagsProp = zsysPrefix + "tags"
+	// PinnedProp string value
+	PinnedProp = zsysPrefix + "pinned"
+	// TrialProp string value
+	TrialProp = zsysPrefix + "trial"
+	// BootNextProp string value
+	BootNextProp = zsysPrefix + "bootnext"
 	// CanmountProp string value
 	CanmountProp = "canmount"
 	// SnapshotCanmountProp is the equivalent to CanmountProp, but as a user property to store on zsys snapshot
@@ -77,6 +110,41 @@ const (
 	SnapshotMountpointProp = zsysPrefix + MountPointProp
 )
 
+// DiffChange is the type of change of a file between a snapshot and a more recent snapshot or filesystem.
+type DiffChange string
+
+const (
+	// DiffAdded is a file which has been created
+	DiffAdded DiffChange = "+"
+	// DiffRemoved is a file which has been removed
+	DiffRemoved DiffChange = "-"
+	// DiffModified is a file which has been modified
+	DiffModified DiffChange = "M"
+	// DiffRenamed is a file which has been renamed
+	DiffRenamed DiffChange = "R"
+)
+
+// DiffEntry is a file change, as reported by zfs diff.
+type DiffEntry struct {
+	Change DiffChange
+	// Path is the absolute path of the file, under the current mountpoint of the dataset.
+	Path string
+	// NewPath is the new path of a renamed file.
+	NewPath string `json:",omitempty"`
+}
+
+// Event is a ZFS event, as reported by zpool events.
+type Event struct {
+	// Class is the kind of event, like sysevent.fs.zfs.history_event or sysevent.fs.zfs.pool_import.
+	Class string
+	// Pool is the name of the pool the event happened on.
+	Pool string
+	// Dataset is the dataset or snapshot changed by a history event.
+	Dataset string
+	// Operation is the change recorded by a history event, like snapshot, destroy or set.
+	Operation string
+}
+
 // Interface is the interface to use real libzfs or our in memory mock.
 type Interface interface {
 	PoolOpen(name string) (pool Pool, err error)
@@ -84,7 +152,9 @@ type Interface interface {
 	DatasetOpen(name string) (d DZFSInterface, err error)
 	DatasetCreate(path string, dtype DatasetType, props map[Prop]Property) (d DZFSInterface, err error)
 	DatasetSnapshot(path string, recur bool, props map[Prop]Property, userProps map[string]string) (rd DZFSInterface, err error)
+	DatasetReceive(target, origin string, r io.Reader) (d DZFSInterface, err error)
 	GenerateID(length int) string
+	Events(ctx context.Context) (<-chan Event, error)
 }
 
 // DZFSInterface is the interface to use real libzfs Dataset object or in memory mock.
@@ -95,12 +165,15 @@ type DZFSInterface interface {
 	Clones() (clones []string, err error)
 	Close()
 	Destroy(Defer bool) (err error)
+	Diff(to string) (entries []DiffEntry, err error)
 	GetUserProperty(p string) (prop Property, err error)
 	IsSnapshot() (ok bool)
+	LoadKey(key io.Reader) (err error)
 	Pool() (p Pool, err error)
 	Promote() (err error)
 	Properties() *map[Prop]Property
 	ReloadProperties() (err error)
+	Send(w io.Writer, fromSnapshot string) (err error)
 	SetUserProperty(prop, value string) error
 	SetProperty(p Prop, value string) error
 	Type() DatasetType
@@ -1,7 +1,20 @@
 package libzfs
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
 	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -62,6 +75,142 @@ func (*Adapter) DatasetSnapshot(path string, recur bool, props map[Prop]Property
 	return dZFSAdapter{&d}, nil
 }
 
+// DatasetReceive receives a send stream from r into target.
+// If target already exists, the stream is expected to be incremental from its latest snapshot.
+// Otherwise, target is created from a full stream.
+// If origin is not empty, target is created as a clone of this snapshot from a stream incremental from origin.
+// Received datasets are never mounted.
+func (l *Adapter) DatasetReceive(target, origin string, r io.Reader) (DZFSInterface, error) {
+	flags := golibzfs.RecvFlags{NoMount: true}
+
+	if origin != "" {
+		return l.receiveAsClone(target, origin, r, flags)
+	}
+
+	dest, err := golibzfs.DatasetOpen(target)
+	if err == nil {
+		defer dest.Close()
+		if err := receive(&dest, r, flags); err != nil {
+			return dZFSAdapter{}, err
+		}
+		return l.DatasetOpen(target)
+	}
+
+	// Full stream: libzfs needs an existing dataset to receive into. Receive in the parent, using the last element of
+	// the sent dataset name, and rename it to target if needed.
+	br := bufio.NewReaderSize(r, drrBeginSize)
+	sentName, err := streamSentName(br)
+	if err != nil {
+		return dZFSAdapter{}, err
+	}
+	parent, err := golibzfs.DatasetOpen(filepath.Dir(target))
+	if err != nil {
+		return dZFSAdapter{}, err
+	}
+	defer parent.Close()
+
+	flags.IsTail = true
+	if err := receive(&parent, br, flags); err != nil {
+		return dZFSAdapter{}, err
+	}
+
+	received := filepath.Join(filepath.Dir(target), filepath.Base(sentName))
+	if received != target {
+		d, err := golibzfs.DatasetOpen(received)
+		if err != nil {
+			return dZFSAdapter{}, err
+		}
+		defer d.Close()
+		if err := d.Rename(target, false, false); err != nil {
+			return dZFSAdapter{}, fmt.Errorf("couldn't rename %q to %q: %v", received, target, err)
+		}
+	}
+
+	return l.DatasetOpen(target)
+}
+
+// receiveAsClone receives an incremental stream, sent from origin, into target created as a clone of origin.
+// libzfs bindings don't allow passing the origin property to the receive call: clone origin first. The origin is the
+// most recent snapshot of a fresh clone, so the incremental stream applies on top of it and shares its blocks.
+func (l *Adapter) receiveAsClone(target, origin string, r io.Reader, flags golibzfs.RecvFlags) (DZFSInterface, error) {
+	o, err := golibzfs.DatasetOpen(origin)
+	if err != nil {
+		return dZFSAdapter{}, err
+	}
+	defer o.Close()
+
+	dest, err := o.Clone(target, make(map[golibzfs.Prop]golibzfs.Property))
+	if err != nil {
+		return dZFSAdapter{}, fmt.Errorf("couldn't clone %q to %q: %v", origin, target, err)
+	}
+	defer dest.Close()
+
+	if err := receive(&dest, r, flags); err != nil {
+		if errDestroy := dest.Destroy(false); errDestroy != nil {
+			return dZFSAdapter{}, fmt.Errorf("%v. Couldn't destroy %q for cleanup: %v", err, target, errDestroy)
+		}
+		return dZFSAdapter{}, err
+	}
+
+	return l.DatasetOpen(target)
+}
+
+// receive bridges r to the file descriptor libzfs is reading from.
+func receive(d *golibzfs.Dataset, r io.Reader, flags golibzfs.RecvFlags) error {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	errCopy := make(chan error)
+	go func() {
+		_, err := io.Copy(pw, r)
+		pw.Close()
+		errCopy <- err
+	}()
+
+	err = d.Receive(pr, flags)
+	// Unblock any pending write if libzfs stopped reading before the end of the stream.
+	pr.Close()
+	if errC := <-errCopy; err == nil {
+		err = errC
+	}
+	return err
+}
+
+const (
+	// drrBeginSize is the size of the DRR_BEGIN record heading any send stream.
+	drrBeginSize = 312
+	// drrToNameOffset is the offset of the sent snapshot name in the DRR_BEGIN record.
+	drrToNameOffset = 56
+	// drrBeginMagic is the magic number identifying a send stream.
+	drrBeginMagic = 0x2F5bacbac
+)
+
+// streamSentName returns the snapshot name the stream was sent from, peeking at its DRR_BEGIN record.
+func streamSentName(br *bufio.Reader) (string, error) {
+	header, err := br.Peek(drrBeginSize)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read stream header: %v", err)
+	}
+
+	// The stream is in the byte order of the sending system.
+	magic := binary.LittleEndian.Uint64(header[8:16])
+	if magic != drrBeginMagic && bits.ReverseBytes64(magic) != drrBeginMagic {
+		return "", errors.New("invalid stream: bad magic number")
+	}
+
+	toName := header[drrToNameOffset:]
+	if i := bytes.IndexByte(toName, 0); i >= 0 {
+		toName = toName[:i]
+	}
+	name := strings.Split(string(toName), "@")[0]
+	if name == "" {
+		return "", errors.New("invalid stream: no dataset name")
+	}
+	return name, nil
+}
+
 var seedOnce = sync.Once{}
 
 // GenerateID with n ascii or digits, lowercase, characters
@@ -77,6 +226,98 @@ func (*Adapter) GenerateID(length int) string {
 	return string(b)
 }
 
+// Events follows the ZFS events until ctx is cancelled. Events which happened before this call are skipped.
+// libzfs bindings don't expose the zevent API, so this relies on the zpool command.
+func (*Adapter) Events(ctx context.Context) (<-chan Event, error) {
+	cmd := exec.CommandContext(ctx, "zpool", "events", "-f", "-H", "-v")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("couldn't follow zpool events: %v", err)
+	}
+
+	since := time.Now().Unix()
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer cmd.Wait()
+		parseEvents(out, since, func(e Event) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return events, nil
+}
+
+// parseEvents parses the verbose scriptable output of zpool events, calling send for each event which happened
+// since the given unix time, until send returns false.
+// Each event starts with a time and class line, followed by its indented "name = value" pairs, up to an empty line.
+func parseEvents(r io.Reader, since int64, send func(Event) bool) {
+	var e Event
+	var eventTime int64
+	var inEvent bool
+	flush := func() bool {
+		if !inEvent {
+			return true
+		}
+		inEvent = false
+		if e.Class == "" || eventTime < since {
+			return true
+		}
+		return send(e)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if !flush() {
+				return
+			}
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			if !flush() {
+				return
+			}
+			e, eventTime, inEvent = Event{}, 0, true
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimSpace(line), " = ", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.Trim(kv[1], `"`)
+		switch kv[0] {
+		case "class":
+			e.Class = v
+		case "pool":
+			e.Pool = v
+		case "history_dsname":
+			e.Dataset = v
+		case "history_internal_name":
+			e.Operation = v
+		case "time":
+			// Seconds and nanoseconds, in hexadecimal.
+			fields := strings.Fields(v)
+			if len(fields) == 0 {
+				continue
+			}
+			if t, err := strconv.ParseInt(strings.TrimPrefix(fields[0], "0x"), 16, 64); err == nil {
+				eventTime = t
+			}
+		}
+	}
+	flush()
+}
+
 type dZFSAdapter struct {
 	*golibzfs.Dataset
 }
@@ -108,3 +349,119 @@ func (d dZFSAdapter) Clone(target string, props map[Prop]Property) (DZFSInterfac
 	}
 	return dZFSAdapter{&c}, nil
 }
+
+// Diff returns all file changes between this snapshot and to, a more recent snapshot or filesystem of the same
+// dataset or of one of its clones.
+// libzfs doesn't expose any stable diff API, so this relies on the zfs command.
+func (d dZFSAdapter) Diff(to string) ([]DiffEntry, error) {
+	from := d.Dataset.Properties[DatasetPropName].Value
+	var stderr bytes.Buffer
+	cmd := exec.Command("zfs", "diff", "-H", from, to)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("zfs diff %s %s failed: %v: %s", from, to, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseDiff(bytes.NewReader(out))
+}
+
+// parseDiff parses the scriptable output of zfs diff.
+func parseDiff(r io.Reader) (entries []DiffEntry, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid zfs diff line: %q", scanner.Text())
+		}
+		e := DiffEntry{Change: DiffChange(fields[0]), Path: unescapeDiffPath(fields[1])}
+		if e.Change == DiffRenamed {
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid zfs diff rename line: %q", scanner.Text())
+			}
+			e.NewPath = unescapeDiffPath(fields[2])
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// unescapeDiffPath decodes the \NNNN octal escaping of non printable characters in zfs diff paths.
+func unescapeDiffPath(p string) string {
+	if !strings.Contains(p, "\\") {
+		return p
+	}
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] == '\\' && i+5 <= len(p) {
+			if c, err := strconv.ParseUint(p[i+1:i+5], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 4
+				continue
+			}
+		}
+		b.WriteByte(p[i])
+	}
+	return b.String()
+}
+
+// LoadKey loads the encryption key of this encryption root, reading its passphrase from key.
+// libzfs doesn't expose any key loading API, so this relies on the zfs command.
+func (d dZFSAdapter) LoadKey(key io.Reader) error {
+	name := d.Dataset.Properties[DatasetPropName].Value
+	var stderr bytes.Buffer
+	cmd := exec.Command("zfs", "load-key", "-L", "prompt", name)
+	cmd.Stdin = key
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("zfs load-key %s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return d.Dataset.ReloadProperties()
+}
+
+// Send writes a send stream of this snapshot to w, including its properties.
+// If fromSnapshot is not empty, the stream is incremental from this previous snapshot of the same dataset, or from
+// the origin of the dataset if it's a clone.
+func (d dZFSAdapter) Send(w io.Writer, fromSnapshot string) error {
+	fromOrigin := false
+	if fromSnapshot != "" {
+		base := strings.Split(d.Dataset.Properties[DatasetPropName].Value, "@")[0]
+		fs, err := golibzfs.DatasetOpen(base)
+		if err != nil {
+			return err
+		}
+		fromOrigin = fs.Properties[DatasetPropOrigin].Value == fromSnapshot
+		fs.Close()
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	errCopy := make(chan error)
+	go func() {
+		_, err := io.Copy(w, pr)
+		pr.Close()
+		errCopy <- err
+	}()
+
+	flags := golibzfs.SendFlags{Props: true}
+	switch {
+	case fromSnapshot == "":
+		err = d.Dataset.Send(pw, flags)
+	case fromOrigin:
+		// The origin isn't a snapshot of this dataset: send a clone stream.
+		flags.FromOrigin = true
+		err = d.Dataset.Send(pw, flags)
+	default:
+		err = d.Dataset.SendFrom(fromSnapshot, pw, flags)
+	}
+	pw.Close()
+	if errC := <-errCopy; err == nil {
+		err = errC
+	}
+	return err
+}
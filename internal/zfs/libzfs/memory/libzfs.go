@@ -0,0 +1,1207 @@
+// Package memory is an in memory implementation of libzfs, which only keeps datasets and their properties, not their
+// content.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ubuntu/zsys/internal/zfs/libzfs"
+)
+
+// Operation is an operation of the in memory libzfs which hooks can fail.
+type Operation int
+
+const (
+	// OpScan opens all datasets.
+	OpScan Operation = iota
+	// OpCreate creates a dataset or a snapshot.
+	OpCreate
+	// OpClone clones a snapshot.
+	OpClone
+	// OpSetProperty sets a property or a user property.
+	OpSetProperty
+	// OpDestroy destroys a dataset or a snapshot.
+	OpDestroy
+	// OpPromote promotes a clone.
+	OpPromote
+)
+
+// Hooks can fail or alter the operations of the in memory libzfs.
+type Hooks interface {
+	// Before is called before running op on the dataset name, empty for OpScan. An error fails the operation.
+	Before(op Operation, name string) error
+	// UserProperty returns the value stored when the user property prop is set to value.
+	UserProperty(prop, value string) string
+	// SnapshotCreation returns the creation time stored for a new snapshot, requested at creation, which can be empty.
+	// An empty result keeps the default creation time.
+	SnapshotCreation(creation string) string
+}
+
+// LibZFS is the in memory implementation of libzfs
+type LibZFS struct {
+	mu       sync.RWMutex
+	datasets map[string]*dZFS
+	pools    map[string]libzfs.Pool
+
+	hooks Hooks
+	// now returns the creation time of new datasets. Defaults to the current time.
+	now func() time.Time
+
+	diffs map[[2]string][]libzfs.DiffEntry
+	// poolSpaces are the size and allocated space of pools, in bytes, when the capacity is computed from them.
+	poolSpaces map[string]*[2]uint64
+	// keys are the passphrases of encryption roots, when known.
+	keys map[string]string
+
+	// eventsMu protects the history events recorded while someone follows them.
+	eventsMu      sync.Mutex
+	pendingEvents []libzfs.Event
+	eventsNotify  chan struct{}
+}
+
+// PoolOpen opens given pool
+func (l *LibZFS) PoolOpen(name string) (pool libzfs.Pool, err error) {
+	pool, ok := l.pools[name]
+	if !ok {
+		return pool, fmt.Errorf("No pool found %q", name)
+	}
+	return pool, nil
+}
+
+// PoolCreate creates a zfs pool
+func (l *LibZFS) PoolCreate(name string, vdev libzfs.VDevTree, features map[string]string, props libzfs.PoolProperties, fsprops libzfs.DatasetProperties) (pool libzfs.Pool, err error) {
+	p := libzfs.Pool{
+		Properties: make([]libzfs.Property, libzfs.PoolNumProps+1),
+	}
+	p.Properties[libzfs.PoolPropCapacity] = libzfs.Property{Value: "30"}
+	for i, prop := range props {
+		p.Properties[i] = libzfs.Property{Value: prop}
+	}
+	l.mu.Lock()
+	l.pools[name] = p
+	l.mu.Unlock()
+
+	datasetProps := make(map[libzfs.Prop]libzfs.Property)
+	for i, prop := range fsprops {
+		datasetProps[i] = libzfs.Property{Value: prop}
+	}
+	l.DatasetCreate(name, libzfs.DatasetTypeFilesystem, datasetProps)
+
+	return p, nil
+}
+
+// DatasetOpenAll opens all the dataset recursively
+func (l *LibZFS) DatasetOpenAll() (datasets []libzfs.DZFSInterface, err error) {
+	if err := l.before(OpScan, ""); err != nil {
+		return nil, err
+	}
+
+	// This is the only place where we can clean the global datasets from datasets to remove as libzfs doesn't do that right on Promote.
+	// zfs.New() is calling DatasetOpenAll to load the whole new state from zfs kernel state.
+	for n := range l.pools {
+		d, err := l.DatasetOpen(n)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open datasets for pool %q", n)
+		}
+		datasets = append(datasets, d)
+	}
+	return datasets, nil
+}
+
+// DatasetOpen opens a dataset
+func (l *LibZFS) DatasetOpen(name string) (libzfs.DZFSInterface, error) {
+	l.mu.RLock()
+	d, ok := l.datasets[name]
+	l.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("No dataset found with name %q", name)
+	}
+
+	l.openChildrenFor(d)
+	return d, nil
+}
+
+func (l *LibZFS) openChildrenFor(dm *dZFS) {
+	name := dm.Dataset.Properties[libzfs.DatasetPropName].Value
+	dm.children = nil
+	dm.Dataset.Children = nil
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for k := range l.datasets {
+		d := l.datasets[k]
+
+		/* Only consider potential children
+		   Retrieve direct children name from the dataset name with 2 cases to handle for a dataset and a snapshot
+		   eg:
+			 dataset name: rpool/ROOT/ubuntu
+			 rpool/ROOT/ubuntu/var -> var
+			 rpool/ROOT/ubuntu@snap1 -> @snap1
+
+			We skip rpool/ROOT/ubuntu as child of rpool/ROOT/ubuntu
+		*/
+		isSnapshotDesc := strings.Contains(k, "@") && strings.HasPrefix(k, name+"@")
+		isDatasetDesc := !strings.Contains(k, "@") && strings.HasPrefix(k, name+"/")
+		if (!isSnapshotDesc && !isDatasetDesc) || dm == d {
+			continue
+		}
+
+		if !isSnapshotDesc && strings.Contains(strings.TrimPrefix(k, name+"/"), "/") {
+			continue
+		}
+		dm.children = append(dm.children, d)
+		dm.Dataset.Children = append(dm.Dataset.Children, *d.Dataset)
+		l.openChildrenFor(d)
+	}
+}
+
+// DatasetCreate creates a dataset
+func (l *LibZFS) DatasetCreate(path string, dtype libzfs.DatasetType, props map[libzfs.Prop]libzfs.Property) (libzfs.DZFSInterface, error) {
+	if err := l.before(OpCreate, path); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	if _, ok := l.datasets[path]; ok {
+		l.mu.Unlock()
+		return nil, fmt.Errorf("dataset %q already exists", path)
+	}
+	l.mu.Unlock()
+	poolName := strings.Split(path, "/")[0]
+	// snapshot on root dataset
+	if !strings.Contains(path, "/") && strings.Contains(path, "@") {
+		poolName = strings.Split(path, "@")[0]
+	}
+	l.mu.RLock()
+	if _, ok := l.pools[poolName]; !ok {
+		return nil, fmt.Errorf("pool %q doesn't exists", poolName)
+	}
+	l.mu.RUnlock()
+
+	now := time.Now
+	if l.now != nil {
+		now = l.now
+	}
+	ctime := fmt.Sprintf("%d", now().Unix())
+	if t, ok := props[libzfs.DatasetPropCreation]; ok {
+		ctime = t.Value
+	}
+	props[libzfs.DatasetPropCreation] = libzfs.Property{
+		Value:  ctime,
+		Source: "none",
+	}
+	props[libzfs.DatasetPropName] = libzfs.Property{Value: path}
+	userProperties := make(map[string]libzfs.Property)
+
+	if mp, ok := props[libzfs.DatasetPropMountpoint]; ok {
+		mp.Source = "local"
+		props[libzfs.DatasetPropMountpoint] = mp
+	}
+
+	cm, ok := props[libzfs.DatasetPropCanmount]
+	if ok {
+		cm.Source = "local"
+	} else {
+		var v, s string
+		if dtype == libzfs.DatasetTypeFilesystem {
+			v = "on"
+			s = "default"
+		}
+
+		cm = libzfs.Property{
+			Value:  v,
+			Source: s,
+		}
+	}
+	props[libzfs.DatasetPropCanmount] = cm
+
+	parentName := filepath.Dir(path)
+	if dtype == libzfs.DatasetTypeSnapshot {
+		parentName = strings.Split(path, "@")[0]
+	}
+
+	// Copy all the parent properties to the dataset and set them to local to override the parent
+	l.mu.RLock()
+	parent, hasParent := l.datasets[parentName]
+	l.mu.RUnlock()
+	if hasParent {
+		// ZFS Properties
+		pprops := parent.Dataset.Properties
+		for k, pp := range pprops {
+			// Overridden local property
+			if _, ok := props[k]; ok {
+				p := props[k]
+				if p.Source == "" {
+					p.Source = "local"
+				}
+				props[k] = p
+				continue
+			}
+			// Read only properties are not inherited
+			if pp.Source == "-" {
+				continue
+			}
+			if pp.Source == "local" || pp.Source == "received" {
+				pp.Source = "inherited"
+			}
+			// Transform mountpoint
+			if k == libzfs.DatasetPropMountpoint {
+				pp.Value = filepath.Join(pprops[k].Value, filepath.Base(path))
+			}
+			props[k] = pp
+		}
+
+		// User properties (can only be from parent at creation time)
+		for _, k := range []string{libzfs.BootfsProp, libzfs.LastUsedProp, libzfs.BootfsDatasetsProp, libzfs.LastBootedKernelProp,
+			libzfs.CanmountProp, libzfs.SnapshotCanmountProp, libzfs.MountPointProp, libzfs.SnapshotMountpointProp} {
+			if _, ok := parent.userProperties[k]; ok {
+				p := parent.userProperties[k]
+				if p.Source == "local" || p.Source == "received" {
+					p.Source = "inherited"
+				}
+				userProperties[k] = p
+			}
+		}
+	} else {
+		if _, ok := props[libzfs.DatasetPropMountpoint]; !ok {
+			props[libzfs.DatasetPropMountpoint] = libzfs.Property{
+				Value:  "/" + path,
+				Source: "default",
+			}
+		}
+	}
+
+	// Encryption properties are read only: new encryption roots set them, other datasets share the ones of their
+	// parent, or origin for clones.
+	_, hasEncryptionRoot := props[libzfs.DatasetPropEncryptionRoot]
+	if enc, ok := props[libzfs.DatasetPropEncryption]; ok && enc.Value != "off" && !hasEncryptionRoot {
+		if err := l.newEncryptionRoot(path, props); err != nil {
+			return nil, err
+		}
+	} else if hasParent && !hasEncryptionRoot && parent.Dataset.Properties[libzfs.DatasetPropEncryptionRoot].Value != "" {
+		copyEncryption(parent.Dataset.Properties, props)
+	}
+
+	d := dZFS{
+		Dataset: &libzfs.Dataset{
+			Type:       dtype,
+			Properties: props,
+		},
+		libZFS:         l,
+		userProperties: userProperties,
+	}
+	if hasParent {
+		var found bool
+		l.mu.Lock()
+		pc := make([]*dZFS, len(parent.children))
+		copy(pc, parent.children)
+		for _, c := range pc {
+			if c == &d {
+				found = true
+				break
+			}
+		}
+		if !found {
+			parent.children = append(parent.children, &d)
+		}
+		l.mu.Unlock()
+	}
+
+	l.mu.Lock()
+	l.datasets[path] = &d
+	l.mu.Unlock()
+
+	if dtype == libzfs.DatasetTypeSnapshot {
+		l.emitEvent("snapshot", path)
+	} else {
+		l.emitEvent("create", path)
+	}
+
+	return &d, nil
+}
+
+// encryptedProps are the read only properties shared by all datasets of an encryption root.
+var encryptedProps = []libzfs.Prop{libzfs.DatasetPropEncryption, libzfs.DatasetPropKeyFormat,
+	libzfs.DatasetPropEncryptionRoot, libzfs.DatasetPropKeyStatus}
+
+// newEncryptionRoot sets props of the new encryption root path, loading its key.
+// Like ZFS, a key stored in a file is read, checked and recorded. Any other key location is considered as loaded.
+func (l *LibZFS) newEncryptionRoot(path string, props map[libzfs.Prop]libzfs.Property) error {
+	kl := props[libzfs.DatasetPropKeyLocation]
+	if strings.HasPrefix(kl.Value, "file://") {
+		key, err := ioutil.ReadFile(strings.TrimPrefix(kl.Value, "file://"))
+		if err != nil {
+			return fmt.Errorf("couldn't load key for %q: %v", path, err)
+		}
+		if len(key) < 8 {
+			return fmt.Errorf("passphrase for %q is too short (min 8)", path)
+		}
+		l.SetKey(path, string(key))
+	}
+
+	for _, p := range []libzfs.Prop{libzfs.DatasetPropEncryption, libzfs.DatasetPropKeyFormat} {
+		props[p] = libzfs.Property{Value: props[p].Value, Source: "-"}
+	}
+	props[libzfs.DatasetPropKeyLocation] = libzfs.Property{Value: kl.Value, Source: "local"}
+	props[libzfs.DatasetPropEncryptionRoot] = libzfs.Property{Value: path, Source: "-"}
+	if _, ok := props[libzfs.DatasetPropKeyStatus]; !ok {
+		props[libzfs.DatasetPropKeyStatus] = libzfs.Property{Value: "available", Source: "-"}
+	}
+	return nil
+}
+
+// copyEncryption shares the encryption root of from with props.
+func copyEncryption(from, props map[libzfs.Prop]libzfs.Property) {
+	for _, p := range encryptedProps {
+		props[p] = from[p]
+	}
+	props[libzfs.DatasetPropKeyLocation] = libzfs.Property{Value: "none", Source: "default"}
+}
+
+// DatasetSnapshot creates a snapshot
+func (l *LibZFS) DatasetSnapshot(path string, recur bool, props map[libzfs.Prop]libzfs.Property, userProps map[string]string) (libzfs.DZFSInterface, error) {
+	if len(strings.Split(path, "@")) != 2 || strings.Split(path, "@")[1] == "" {
+		return nil, fmt.Errorf("%q is not a valid snapshot name", path)
+	}
+	return l.createSnapshot(path, recur, props, userProps)
+}
+
+func (l *LibZFS) createSnapshot(path string, recur bool, props map[libzfs.Prop]libzfs.Property, userProps map[string]string) (libzfs.DZFSInterface, error) {
+	if l.hooks != nil {
+		if c := l.hooks.SnapshotCreation(props[libzfs.DatasetPropCreation].Value); c != "" {
+			props[libzfs.DatasetPropCreation] = libzfs.Property{Value: c}
+		}
+	}
+
+	dinterface, err := l.DatasetCreate(path, libzfs.DatasetTypeSnapshot, props)
+	if err != nil {
+		return nil, err
+	}
+
+	d := dinterface.(*dZFS)
+	for k, v := range userProps {
+		if err := d.SetUserProperty(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if !recur {
+		return d, nil
+	}
+	snapshotName := strings.Split(path, "@")[1]
+	for _, c := range d.children {
+		if c.IsSnapshot() {
+			continue
+		}
+
+		childPath := c.Dataset.Properties[libzfs.DatasetPropName].Value + "@" + snapshotName
+		_, err := l.createSnapshot(childPath, recur, props, userProps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// sendStream is the in memory send stream of a snapshot, with its properties.
+type sendStream struct {
+	Snapshot          string
+	FromSnapshot      string `json:",omitempty"`
+	Creation          string
+	Props             map[libzfs.Prop]string
+	UserProps         map[string]string
+	SnapshotUserProps map[string]string
+}
+
+// DatasetReceive receives a send stream from r into target.
+// If target already exists, the stream is expected to be incremental from its latest snapshot.
+// Otherwise, target is created from a full stream, or as a clone of origin from an incremental stream whose source
+// is origin.
+func (l *LibZFS) DatasetReceive(target, origin string, r io.Reader) (libzfs.DZFSInterface, error) {
+	var s sendStream
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid stream: %v", err)
+	}
+
+	l.mu.RLock()
+	dest, exists := l.datasets[target]
+	_, snapshotExists := l.datasets[target+"@"+s.Snapshot]
+	l.mu.RUnlock()
+
+	if snapshotExists {
+		return nil, fmt.Errorf("destination snapshot %q already exists", target+"@"+s.Snapshot)
+	}
+
+	if !exists {
+		if s.FromSnapshot != "" && origin == "" {
+			return nil, fmt.Errorf("destination %q doesn't exist for incremental stream", target)
+		}
+		if s.FromSnapshot == "" && origin != "" {
+			return nil, fmt.Errorf("full stream can't be received as a clone of %q", origin)
+		}
+		if strings.Contains(target, "@") {
+			return nil, fmt.Errorf("%q is not a valid filesystem dataset name", target)
+		}
+		l.mu.RLock()
+		_, hasParent := l.datasets[filepath.Dir(target)]
+		l.mu.RUnlock()
+		if !hasParent {
+			return nil, fmt.Errorf("parent of %q doesn't exist", target)
+		}
+
+		props := make(map[libzfs.Prop]libzfs.Property)
+		if origin != "" {
+			if err := l.checkCloneOrigin(target, origin); err != nil {
+				return nil, err
+			}
+			if strings.Split(origin, "@")[1] != s.FromSnapshot {
+				return nil, fmt.Errorf("incremental source %q doesn't match clone origin %q", s.FromSnapshot, origin)
+			}
+			props[libzfs.DatasetPropOrigin] = libzfs.Property{Value: origin, Source: "-"}
+		}
+
+		d, err := l.DatasetCreate(target, libzfs.DatasetTypeFilesystem, props)
+		if err != nil {
+			return nil, err
+		}
+		dest = d.(*dZFS)
+	} else {
+		if origin != "" {
+			return nil, fmt.Errorf("destination %q already exists, can't receive it as a clone", target)
+		}
+		if s.FromSnapshot == "" {
+			return nil, fmt.Errorf("destination %q already exists", target)
+		}
+		if err := l.checkIncrementalTarget(dest, s.FromSnapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	// Received properties are set on the filesystem dataset itself.
+	for k, v := range s.Props {
+		if err := dest.setPropertyWithSource(k, v, "received"); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range s.UserProps {
+		if err := dest.setUserPropertyWithSource(k, v, "received"); err != nil {
+			return nil, err
+		}
+	}
+
+	props := map[libzfs.Prop]libzfs.Property{libzfs.DatasetPropCreation: {Value: s.Creation}}
+	snap, err := l.DatasetCreate(target+"@"+s.Snapshot, libzfs.DatasetTypeSnapshot, props)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.SnapshotUserProps {
+		if err := snap.(*dZFS).setUserPropertyWithSource(k, v, "received"); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.DatasetOpen(target)
+}
+
+// checkCloneOrigin ensures that origin is an existing snapshot on the same pool than target.
+func (l *LibZFS) checkCloneOrigin(target, origin string) error {
+	l.mu.RLock()
+	o, ok := l.datasets[origin]
+	l.mu.RUnlock()
+	if !ok || !o.IsSnapshot() {
+		return fmt.Errorf("origin %q isn't an existing snapshot", origin)
+	}
+	if strings.Split(origin, "/")[0] != strings.Split(target, "/")[0] {
+		return fmt.Errorf("origin %q and %q aren't on the same pool", origin, target)
+	}
+	return nil
+}
+
+// checkIncrementalTarget ensures that fromSnapshot is the most recent snapshot of dest.
+func (l *LibZFS) checkIncrementalTarget(dest *dZFS, fromSnapshot string) error {
+	name := dest.Dataset.Properties[libzfs.DatasetPropName].Value
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	from, ok := l.datasets[name+"@"+fromSnapshot]
+	if !ok {
+		return fmt.Errorf("destination %q doesn't have incremental source snapshot %q", name, fromSnapshot)
+	}
+	fromCreation, err := strconv.Atoi(from.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+	if err != nil {
+		return fmt.Errorf("cannot convert date to int for %q", name+"@"+fromSnapshot)
+	}
+
+	for n, ds := range l.datasets {
+		if !strings.HasPrefix(n, name+"@") || ds == from {
+			continue
+		}
+		creation, err := strconv.Atoi(ds.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+		if err != nil {
+			return fmt.Errorf("cannot convert date to int for %q", n)
+		}
+		if creation > fromCreation {
+			return fmt.Errorf("destination %q has been modified since most recent snapshot %q", name, fromSnapshot)
+		}
+	}
+	return nil
+}
+
+// SetDatasetAsMounted forces one dataset to be seen as mounted or not
+func (l *LibZFS) SetDatasetAsMounted(name string, mounted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d := l.datasets[name]
+	m := "no"
+	if mounted {
+		m = "yes"
+	}
+	d.setPropertyWithSource(libzfs.DatasetPropMounted, m, "")
+}
+
+// SetDiff sets the file changes returned by a diff from a snapshot to a more recent
+// snapshot or filesystem.
+func (l *LibZFS) SetDiff(from, to string, entries []libzfs.DiffEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.diffs == nil {
+		l.diffs = make(map[[2]string][]libzfs.DiffEntry)
+	}
+	l.diffs[[2]string{from, to}] = entries
+}
+
+// SetKey sets the passphrase of the encryption root name, checked when loading its key.
+func (l *LibZFS) SetKey(name, passphrase string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.keys == nil {
+		l.keys = make(map[string]string)
+	}
+	l.keys[name] = passphrase
+}
+
+// SetPoolCapacity allows forcing a capabity value on a pool
+func (l *LibZFS) SetPoolCapacity(name, cap string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pools[name].Properties[libzfs.PoolPropCapacity] = libzfs.Property{Value: cap}
+}
+
+// SetPoolSpace sets the size and allocated space, in bytes, of a pool. Its capacity is
+// then computed from them and destroying a dataset frees its used space.
+func (l *LibZFS) SetPoolSpace(name string, size, allocated uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.poolSpaces == nil {
+		l.poolSpaces = make(map[string]*[2]uint64)
+	}
+	l.poolSpaces[name] = &[2]uint64{size, allocated}
+	l.refreshPoolCapacity(name)
+}
+
+// refreshPoolCapacity computes the capacity of a pool from its size and allocated space, if set.
+func (l *LibZFS) refreshPoolCapacity(name string) {
+	space, ok := l.poolSpaces[name]
+	if !ok || space[0] == 0 {
+		return
+	}
+	l.pools[name].Properties[libzfs.PoolPropCapacity] = libzfs.Property{Value: strconv.FormatUint(space[1]*100/space[0], 10)}
+}
+
+// before runs the hook before op on name, if any.
+func (l *LibZFS) before(op Operation, name string) error {
+	if l.hooks == nil {
+		return nil
+	}
+	return l.hooks.Before(op, name)
+}
+
+// SetClock uses now to get the creation time of new datasets, instead of the current time
+func (l *LibZFS) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// Events follows the history events of the datasets changed in memory until ctx is cancelled.
+// Only the last call follows the events.
+func (l *LibZFS) Events(ctx context.Context) (<-chan libzfs.Event, error) {
+	notify := make(chan struct{}, 1)
+	l.eventsMu.Lock()
+	l.pendingEvents = nil
+	l.eventsNotify = notify
+	l.eventsMu.Unlock()
+
+	events := make(chan libzfs.Event)
+	go func() {
+		defer close(events)
+		defer func() {
+			l.eventsMu.Lock()
+			defer l.eventsMu.Unlock()
+			if l.eventsNotify == notify {
+				l.eventsNotify = nil
+				l.pendingEvents = nil
+			}
+		}()
+
+		for {
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+
+			l.eventsMu.Lock()
+			pending := l.pendingEvents
+			l.pendingEvents = nil
+			l.eventsMu.Unlock()
+
+			for _, e := range pending {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// emitEvent records a history event for operation on name, if someone follows the events.
+func (l *LibZFS) emitEvent(operation, name string) {
+	l.eventsMu.Lock()
+	defer l.eventsMu.Unlock()
+	if l.eventsNotify == nil {
+		return
+	}
+
+	l.pendingEvents = append(l.pendingEvents, libzfs.Event{
+		Class:     "sysevent.fs.zfs.history_event",
+		Pool:      strings.Split(strings.Split(name, "@")[0], "/")[0],
+		Dataset:   name,
+		Operation: operation,
+	})
+	select {
+	case l.eventsNotify <- struct{}{}:
+	default:
+	}
+}
+
+// GenerateID returns from a given length a string known in advance
+func (*LibZFS) GenerateID(length int) string {
+	return strings.Repeat("x", length)
+}
+
+type dZFS struct {
+	*libzfs.Dataset
+	children       []*dZFS
+	libZFS         *LibZFS
+	userProperties map[string]libzfs.Property
+	isClosed       bool
+	tempOrigin     string
+}
+
+func (d dZFS) assertDatasetOpened() {
+	if d.isClosed {
+		panic(fmt.Sprintf("operation on closed dataset %q is prohibited", d.Dataset.Properties[libzfs.DatasetPropName].Value))
+	}
+}
+func (d dZFS) Children() (children []libzfs.DZFSInterface) {
+	d.assertDatasetOpened()
+	var r []libzfs.DZFSInterface
+	for i := range d.children {
+		r = append(r, d.children[i])
+	}
+	return r
+}
+
+func (d dZFS) DZFSChildren() *[]libzfs.Dataset {
+	return &d.Dataset.Children
+}
+
+func (d dZFS) Properties() *map[libzfs.Prop]libzfs.Property {
+	d.assertDatasetOpened()
+	return &d.Dataset.Properties
+}
+
+func (d dZFS) Type() libzfs.DatasetType {
+	d.assertDatasetOpened()
+	return d.Dataset.Type
+}
+
+func (d dZFS) Clone(target string, props map[libzfs.Prop]libzfs.Property) (libzfs.DZFSInterface, error) {
+	d.assertDatasetOpened()
+	name := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	if err := d.libZFS.before(OpClone, name); err != nil {
+		return nil, err
+	}
+	props[libzfs.DatasetPropOrigin] = libzfs.Property{
+		Value:  name,
+		Source: "-",
+	}
+
+	// Clones share the encryption root of their origin, which needs its key loaded.
+	if root := d.Dataset.Properties[libzfs.DatasetPropEncryptionRoot].Value; root != "" {
+		if d.Dataset.Properties[libzfs.DatasetPropKeyStatus].Value != "available" {
+			return nil, fmt.Errorf("key of %q must be loaded to clone %q", root, name)
+		}
+		copyEncryption(d.Dataset.Properties, props)
+	}
+
+	dinterface, err := d.libZFS.DatasetCreate(target, libzfs.DatasetTypeFilesystem, props)
+	if err != nil {
+		return nil, err
+	}
+
+	di := dinterface.(*dZFS)
+	return di, nil
+}
+
+func (d dZFS) Pool() (p libzfs.Pool, err error) {
+	d.assertDatasetOpened()
+	name := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	poolName := strings.Split(name, "/")[0]
+	p, ok := d.libZFS.pools[poolName]
+	if !ok {
+		return libzfs.Pool{}, fmt.Errorf("No pool found for dataset %q", name)
+	}
+
+	return p, nil
+}
+
+func (d dZFS) GetUserProperty(p string) (prop libzfs.Property, err error) {
+	d.assertDatasetOpened()
+	prop, ok := d.userProperties[p]
+	if !ok {
+		return libzfs.Property{Value: "-", Source: "-"}, nil
+	}
+	return prop, nil
+}
+
+func (d *dZFS) SetUserProperty(prop, value string) error {
+	if err := d.libZFS.before(OpSetProperty, d.Dataset.Properties[libzfs.DatasetPropName].Value); err != nil {
+		return err
+	}
+	d.assertDatasetOpened()
+
+	if d.libZFS.hooks != nil {
+		value = d.libZFS.hooks.UserProperty(prop, value)
+	}
+
+	if err := d.setUserPropertyWithSource(prop, value, "local"); err != nil {
+		return err
+	}
+	d.libZFS.emitEvent("set", d.Dataset.Properties[libzfs.DatasetPropName].Value)
+	return nil
+}
+
+func (d *dZFS) setUserPropertyWithSource(prop, value, source string) error {
+	d.userProperties[prop] = libzfs.Property{Value: value, Source: source}
+	// refresh children
+	for _, c := range d.children {
+		if src := c.userProperties[prop].Source; src == "local" || src == "received" {
+			continue
+		}
+		if err := c.setUserPropertyWithSource(prop, value, "inherited"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *dZFS) SetProperty(p libzfs.Prop, value string) error {
+	if err := d.libZFS.before(OpSetProperty, d.Dataset.Properties[libzfs.DatasetPropName].Value); err != nil {
+		return err
+	}
+	d.assertDatasetOpened()
+	if err := d.setPropertyWithSource(p, value, "local"); err != nil {
+		return err
+	}
+	d.libZFS.emitEvent("set", d.Dataset.Properties[libzfs.DatasetPropName].Value)
+	return nil
+}
+
+func (d *dZFS) setPropertyWithSource(p libzfs.Prop, value, source string) error {
+	// Those properties don't propagate to children
+	if p == libzfs.DatasetPropMounted || p == libzfs.DatasetPropOrigin {
+		source = "-"
+	}
+
+	d.Dataset.Properties[p] = libzfs.Property{Value: value, Source: source}
+
+	if source == "-" {
+		return nil
+	}
+
+	// refresh children
+	for i := range d.children {
+		c := d.children[i]
+		src := c.Dataset.Properties[p].Source
+		if src == "local" || src == "received" || src == "default" || src == "none" {
+			continue
+		}
+
+		v := value
+		if p == libzfs.DatasetPropMountpoint {
+			v = filepath.Join(value, strings.TrimPrefix(strings.Split(c.Dataset.Properties[libzfs.DatasetPropName].Value, "@")[0], d.Dataset.Properties[libzfs.DatasetPropName].Value))
+		}
+
+		if err := c.setPropertyWithSource(p, v, "inherited"); err != nil {
+			return err
+		}
+		d.children[i] = c
+	}
+	return nil
+}
+
+func (d *dZFS) Destroy(Defer bool) (err error) {
+	d.assertDatasetOpened()
+	n := d.Dataset.Properties[libzfs.DatasetPropName].Value
+
+	if err := d.libZFS.before(OpDestroy, n); err != nil {
+		return err
+	}
+
+	d.libZFS.mu.Lock()
+	defer d.libZFS.mu.Unlock()
+	for name, dataset := range d.libZFS.datasets {
+		if n == name {
+			continue
+		}
+		if strings.HasPrefix(name, n+"/") || strings.HasPrefix(name, n+"@") {
+			return fmt.Errorf("can't remove %s: it has at least one child: %s", n, name)
+		}
+		if dataset.Dataset.Properties[libzfs.DatasetPropOrigin].Value == n {
+			return fmt.Errorf("can't remove %s: it has at least one clone: %s", n, name)
+		}
+	}
+	delete(d.libZFS.datasets, n)
+	d.libZFS.emitEvent("destroy", n)
+
+	// Free the space only used by this dataset
+	poolName := strings.Split(strings.Split(n, "@")[0], "/")[0]
+	if space, ok := d.libZFS.poolSpaces[poolName]; ok {
+		if used, err := strconv.ParseUint(d.Dataset.Properties[libzfs.DatasetPropUsed].Value, 10, 64); err == nil {
+			if used > space[1] {
+				used = space[1]
+			}
+			space[1] -= used
+			d.libZFS.refreshPoolCapacity(poolName)
+		}
+	}
+	return nil
+}
+
+func (d *dZFS) Clones() (clones []string, err error) {
+	d.assertDatasetOpened()
+	d.libZFS.mu.Lock()
+	defer d.libZFS.mu.Unlock()
+
+	for _, c := range d.children {
+		if !c.IsSnapshot() {
+			continue
+		}
+		name := c.Dataset.Properties[libzfs.DatasetPropName].Value
+		for cloneName, clone := range d.libZFS.datasets {
+			if clone.Dataset.Properties[libzfs.DatasetPropOrigin].Value != name {
+				continue
+			}
+			clones = append(clones, cloneName)
+		}
+	}
+	return clones, nil
+}
+
+func (d *dZFS) Promote() (err error) {
+	d.assertDatasetOpened()
+	datasetName := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	if err := d.libZFS.before(OpPromote, datasetName); err != nil {
+		return err
+	}
+
+	origin := d.Dataset.Properties[libzfs.DatasetPropOrigin].Value
+	if origin == "" {
+		return nil
+	}
+
+	d.libZFS.mu.Lock()
+	origSnapshot := d.libZFS.datasets[origin]
+	d.libZFS.mu.Unlock()
+
+	origSnapshotCreation, err := strconv.Atoi(origSnapshot.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+	if err != nil {
+		return fmt.Errorf("cannot convert date to int for %q", origin)
+	}
+	// Collect snapshots to migrate
+	var snapshotsToMigrate []*dZFS
+	d.libZFS.mu.Lock()
+	for name, ds := range d.libZFS.datasets {
+		if !strings.HasPrefix(name, strings.Split(origin, "@")[0]+"@") {
+			continue
+		}
+
+		dsCreation, err := strconv.Atoi(ds.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+		if err != nil {
+			return fmt.Errorf("cannot convert date to int for %q", name)
+		}
+		if dsCreation > origSnapshotCreation {
+			continue
+		}
+		snapshotsToMigrate = append(snapshotsToMigrate, ds)
+	}
+	d.libZFS.mu.Unlock()
+
+	var newOrig string
+	for _, snap := range snapshotsToMigrate {
+		// Create new snapshots for every snapshots to migrate
+		oldDatasetName := snap.Dataset.Properties[libzfs.DatasetPropName].Value
+		newName := datasetName + "@" + strings.Split(oldDatasetName, "@")[1]
+
+		// Pass a copy of properties to not alter - soon deleted - old snapshot on previously promoted dataset
+		newDProps := make(map[libzfs.Prop]libzfs.Property)
+		for k, v := range snap.Dataset.Properties {
+			newDProps[k] = v
+		}
+		newD, err := d.libZFS.DatasetCreate(newName, libzfs.DatasetTypeSnapshot, newDProps)
+		if err != nil {
+			return err
+		}
+		newDUserProps := make(map[string]libzfs.Property)
+		for k, v := range snap.userProperties {
+			newDUserProps[k] = v
+		}
+		newD.(*dZFS).userProperties = newDUserProps
+
+		// Old promoted dataset should now point to new one
+		if snap == origSnapshot {
+			newOrig = (*d.libZFS.datasets[strings.Split(oldDatasetName, "@")[0]].Properties())[libzfs.DatasetPropOrigin].Value
+			d.libZFS.datasets[strings.Split(oldDatasetName, "@")[0]].tempOrigin = newName
+		}
+
+		// All datasets pointing to those snapshots to Migrate should point to new snapshots
+		d.libZFS.mu.Lock()
+		for dName, ds := range d.libZFS.datasets {
+			if ds.IsSnapshot() {
+				continue
+			}
+			if ds.Dataset.Properties[libzfs.DatasetPropOrigin].Value != oldDatasetName || strings.HasPrefix(newName, dName+"@") {
+				continue
+			}
+			ds.tempOrigin = newName
+		}
+		d.libZFS.mu.Unlock()
+
+		// Mark this snapshot to be deleted on next DatasetOpenAll. The libzfs lib keep them attached to Children
+		d.libZFS.mu.Lock()
+		delete(d.libZFS.datasets, oldDatasetName)
+		d.libZFS.mu.Unlock()
+	}
+
+	// When its origin dataset was the encryption root, the promoted dataset replaces it, with its key location.
+	if oldRoot := strings.Split(origin, "@")[0]; d.Dataset.Properties[libzfs.DatasetPropEncryptionRoot].Value == oldRoot {
+		d.libZFS.moveEncryptionRoot(oldRoot, datasetName)
+	}
+
+	// Reset promoted snapshot. This simulates ReloadProperties called only on this dataset in libzfz
+	d.Dataset.Properties[libzfs.DatasetPropOrigin] = libzfs.Property{
+		Value:  newOrig,
+		Source: "-",
+	}
+	d.libZFS.emitEvent("promote", datasetName)
+	return nil
+}
+
+// moveEncryptionRoot makes newRoot the encryption root of all datasets of oldRoot.
+func (l *LibZFS) moveEncryptionRoot(oldRoot, newRoot string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, ds := range l.datasets {
+		p := ds.Dataset.Properties[libzfs.DatasetPropEncryptionRoot]
+		if p.Value != oldRoot {
+			continue
+		}
+		p.Value = newRoot
+		ds.Dataset.Properties[libzfs.DatasetPropEncryptionRoot] = p
+	}
+
+	if k, ok := l.keys[oldRoot]; ok {
+		l.keys[newRoot] = k
+		delete(l.keys, oldRoot)
+	}
+
+	o, n := l.datasets[oldRoot], l.datasets[newRoot]
+	n.Dataset.Properties[libzfs.DatasetPropKeyLocation] = o.Dataset.Properties[libzfs.DatasetPropKeyLocation]
+	o.Dataset.Properties[libzfs.DatasetPropKeyLocation] = libzfs.Property{Value: "none", Source: "default"}
+}
+
+// LoadKey loads the key of this encryption root from key, checking it against its passphrase if known.
+// All datasets sharing this encryption root have their key loaded.
+func (d *dZFS) LoadKey(key io.Reader) error {
+	d.assertDatasetOpened()
+	name := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	if d.Dataset.Properties[libzfs.DatasetPropEncryptionRoot].Value != name {
+		return fmt.Errorf("%q isn't an encryption root", name)
+	}
+	k, err := ioutil.ReadAll(key)
+	if err != nil {
+		return fmt.Errorf("couldn't read key for %q: %v", name, err)
+	}
+	if len(k) < 8 {
+		return fmt.Errorf("passphrase for %q is too short (min 8)", name)
+	}
+
+	d.libZFS.mu.Lock()
+	defer d.libZFS.mu.Unlock()
+	if p, ok := d.libZFS.keys[name]; ok && p != string(k) {
+		return fmt.Errorf("incorrect key provided for %q", name)
+	}
+	for _, ds := range d.libZFS.datasets {
+		if ds.Dataset.Properties[libzfs.DatasetPropEncryptionRoot].Value != name {
+			continue
+		}
+		ds.Dataset.Properties[libzfs.DatasetPropKeyStatus] = libzfs.Property{Value: "available", Source: "-"}
+	}
+	return nil
+}
+
+// Send writes a send stream of this snapshot to w, including its properties.
+// If fromSnapshot is not empty, the stream is incremental from this previous snapshot of the same dataset, or from
+// the origin of the dataset if it's a clone.
+func (d *dZFS) Send(w io.Writer, fromSnapshot string) error {
+	d.assertDatasetOpened()
+	name := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	if !d.IsSnapshot() {
+		return fmt.Errorf("%q is not a snapshot", name)
+	}
+	base := strings.Split(name, "@")[0]
+
+	d.libZFS.mu.RLock()
+	parent, ok := d.libZFS.datasets[base]
+	d.libZFS.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("No dataset found with name %q", base)
+	}
+
+	s := sendStream{
+		Snapshot:          strings.Split(name, "@")[1],
+		Creation:          d.Dataset.Properties[libzfs.DatasetPropCreation].Value,
+		Props:             make(map[libzfs.Prop]string),
+		UserProps:         make(map[string]string),
+		SnapshotUserProps: make(map[string]string),
+	}
+
+	if fromSnapshot != "" {
+		isOrigin := fromSnapshot == parent.Dataset.Properties[libzfs.DatasetPropOrigin].Value
+		if !isOrigin && (strings.Split(fromSnapshot, "@")[0] != base || !strings.Contains(fromSnapshot, "@")) {
+			return fmt.Errorf("incremental source %q must be a snapshot of %q or its origin", fromSnapshot, base)
+		}
+		d.libZFS.mu.RLock()
+		from, ok := d.libZFS.datasets[fromSnapshot]
+		d.libZFS.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("No dataset found with name %q", fromSnapshot)
+		}
+		fromCreation, err := strconv.Atoi(from.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+		if err != nil {
+			return fmt.Errorf("cannot convert date to int for %q", fromSnapshot)
+		}
+		creation, err := strconv.Atoi(s.Creation)
+		if err != nil {
+			return fmt.Errorf("cannot convert date to int for %q", name)
+		}
+		if fromCreation > creation {
+			return fmt.Errorf("incremental source %q is more recent than %q", fromSnapshot, name)
+		}
+		s.FromSnapshot = strings.Split(fromSnapshot, "@")[1]
+	}
+
+	// Only properties explicitly set on the dataset are part of the stream.
+	for k, p := range parent.Dataset.Properties {
+		if k == libzfs.DatasetPropName {
+			continue
+		}
+		if p.Source == "local" || p.Source == "received" {
+			s.Props[k] = p.Value
+		}
+	}
+	for k, p := range parent.userProperties {
+		if p.Source == "local" || p.Source == "received" {
+			s.UserProps[k] = p.Value
+		}
+	}
+	for k, p := range d.userProperties {
+		if p.Source == "local" || p.Source == "received" {
+			s.SnapshotUserProps[k] = p.Value
+		}
+	}
+
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Diff returns the file changes from this snapshot to to, as set by SetDiff.
+// to must be a more recent snapshot or the filesystem of the same dataset, or of one of its clones.
+func (d *dZFS) Diff(to string) ([]libzfs.DiffEntry, error) {
+	d.assertDatasetOpened()
+	name := d.Dataset.Properties[libzfs.DatasetPropName].Value
+	if !d.IsSnapshot() {
+		return nil, fmt.Errorf("%q is not a snapshot", name)
+	}
+	base := strings.Split(name, "@")[0]
+	toBase := strings.Split(to, "@")[0]
+
+	d.libZFS.mu.RLock()
+	defer d.libZFS.mu.RUnlock()
+	toD, ok := d.libZFS.datasets[to]
+	if !ok {
+		return nil, fmt.Errorf("No dataset found with name %q", to)
+	}
+	toParent, ok := d.libZFS.datasets[toBase]
+	if !ok {
+		return nil, fmt.Errorf("No dataset found with name %q", toBase)
+	}
+	if toBase != base && !strings.HasPrefix(toParent.Dataset.Properties[libzfs.DatasetPropOrigin].Value, base+"@") {
+		return nil, fmt.Errorf("%q is not related to %q", to, name)
+	}
+	if toD.IsSnapshot() {
+		creation, _ := strconv.Atoi(d.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+		toCreation, _ := strconv.Atoi(toD.Dataset.Properties[libzfs.DatasetPropCreation].Value)
+		if creation > toCreation {
+			return nil, fmt.Errorf("%q is more recent than %q", name, to)
+		}
+	}
+
+	return append([]libzfs.DiffEntry(nil), d.libZFS.diffs[[2]string{name, to}]...), nil
+}
+
+// ReloadProperties: set orig to new thing
+// This is to mock libZFS only reloading the orig property at this time
+func (d *dZFS) ReloadProperties() (err error) {
+	if d.tempOrigin != "" {
+		d.Dataset.Properties[libzfs.DatasetPropOrigin] = libzfs.Property{
+			Value:  d.tempOrigin,
+			Source: "-",
+		}
+	}
+	return nil
+}
+
+// New returns an initialized and empty in memory LibZFS
+func New() LibZFS {
+	return NewWithHooks(nil)
+}
+
+// NewWithHooks returns an initialized and empty in memory LibZFS, whose operations can be failed or altered by hooks
+func NewWithHooks(hooks Hooks) LibZFS {
+	return LibZFS{
+		datasets: make(map[string]*dZFS),
+		pools:    make(map[string]libzfs.Pool),
+		hooks:    hooks,
+	}
+}
@@ -1,8 +1,11 @@
 package zfs
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -15,6 +18,30 @@ import (
 const (
 	// UserdataPrefix is the part of the path of the dataset that contains user data
 	UserdataPrefix = "USERDATA"
+	// minPassphraseLength is the minimum length of passphrases accepted by ZFS
+	minPassphraseLength = 8
+	// maxPassphraseLength is the maximum length of passphrases accepted by ZFS
+	maxPassphraseLength = 512
+	// keyUnavailable is the key status of encrypted datasets which key isn't loaded
+	keyUnavailable = "unavailable"
+	// KeyStatusProp is the name recorded in changes when the encryption key of a dataset is loaded
+	KeyStatusProp = "keystatus"
+	// KeyLocationProp is the name recorded in changes when the key location of an encryption root changes
+	KeyLocationProp = "keylocation"
+	// EncryptionRootProp is the name recorded in changes when the encryption root of a dataset changes
+	EncryptionRootProp = "encryptionroot"
+	// MountedProp is the name recorded in changes when a dataset is mounted or unmounted
+	MountedProp = "mounted"
+	// OriginProp is the name recorded in changes when the origin of a dataset changes, like on promotion
+	OriginProp = "origin"
+	// UsedProp is the name recorded in changes when the used space of a dataset changes
+	UsedProp = "used"
+	// WrittenProp is the name recorded in changes when the written space of a dataset changes
+	WrittenProp = "written"
+	// ReferencedProp is the name recorded in changes when the referenced space of a dataset changes
+	ReferencedProp = "referenced"
+	// LogicalUsedProp is the name recorded in changes when the logical used space of a dataset changes
+	LogicalUsedProp = "logicalused"
 )
 
 // Dataset is the abstraction of a physical dataset and exposes only properties that must are accessible by the user.
@@ -44,8 +71,38 @@ type DatasetProp struct {
 	LastBootedKernel string `json:",omitempty"`
 	// BootfsDatasets is a user property for user datasets, linking them to relevant system bootfs datasets.
 	BootfsDatasets string `json:",omitempty"`
+	// PackageChanges is a user property on snapshots listing packages which were about to be changed when it was taken.
+	PackageChanges string `json:",omitempty"`
+	// Description is a user property storing a free text description of a state.
+	Description string `json:",omitempty"`
+	// Tags is a user property storing key/value tags of a state, query encoded.
+	Tags string `json:",omitempty"`
+	// Pinned is a user property set to "yes" on states which should never be garbage collected.
+	Pinned string `json:",omitempty"`
+	// Trial is a user property on root system datasets booting as a trial, counting boots and storing the state to
+	// fall back to.
+	Trial string `json:",omitempty"`
+	// BootNext is a user property on root system datasets storing the state to boot on once, on the next boot.
+	BootNext string `json:",omitempty"`
 	// Origin points to the dataset snapshot this one was clone from.
 	Origin string `json:",omitempty"`
+	// EncryptionRoot is the dataset holding the encryption key of this dataset. It's empty on unencrypted datasets.
+	EncryptionRoot string `json:",omitempty"`
+	// KeyStatus is "available" when the encryption key of this dataset is loaded, "unavailable" otherwise. It's empty on
+	// unencrypted datasets.
+	KeyStatus string `json:",omitempty"`
+	// KeyLocation is where the key of an encryption root is loaded from, like prompt or file:///path. It's empty on
+	// other datasets.
+	KeyLocation string `json:",omitempty"`
+	// Used is the space in bytes freed by destroying this dataset, its children and snapshots. For snapshots, it’s
+	// the space only referenced by this snapshot.
+	Used uint64 `json:",omitempty"`
+	// Written is the space in bytes written to this dataset since its previous snapshot.
+	Written uint64 `json:",omitempty"`
+	// Referenced is the space in bytes of the data accessible by this dataset, which may be shared with others.
+	Referenced uint64 `json:",omitempty"`
+	// LogicalUsed is the space in bytes used by this dataset, its children and snapshots before compression.
+	LogicalUsed uint64 `json:",omitempty"`
 
 	// Here are the sources (not exposed to the public API) for each property
 	// Used mostly for tests
@@ -60,6 +117,12 @@ type datasetSources struct {
 	LastUsed         string `json:",omitempty"`
 	LastBootedKernel string `json:",omitempty"`
 	BootfsDatasets   string `json:",omitempty"`
+	PackageChanges   string `json:",omitempty"`
+	Description      string `json:",omitempty"`
+	Tags             string `json:",omitempty"`
+	Pinned           string `json:",omitempty"`
+	Trial            string `json:",omitempty"`
+	BootNext         string `json:",omitempty"`
 }
 
 // Zfs is a system handler talking to zfs linux module.
@@ -68,6 +131,8 @@ type Zfs struct {
 	// root is a virtual dataset to which all top dataset of all pools are attached
 	root        *Dataset
 	allDatasets map[string]*Dataset
+	// changes are the datasets changed in the cache since they were last taken
+	changes Changes
 
 	libzfs libzfs.Interface
 }
@@ -97,6 +162,12 @@ func New(ctx context.Context, options ...func(*Zfs)) (*Zfs, error) {
 	return &z, nil
 }
 
+// Fork returns a new zfs system handler with the same libzfs, scanning all datasets. Its cache is independent of z,
+// so that it can be used for long running operations while z is used by other requests.
+func (z *Zfs) Fork(ctx context.Context) (*Zfs, error) {
+	return New(ctx, WithLibZFS(z.libzfs))
+}
+
 // Refresh rescans all the datasets for the zfs instance.
 func (z *Zfs) Refresh(ctx context.Context) error {
 	log.Debug(ctx, i18n.G("ZFS: refresh dataset list"))
@@ -104,6 +175,7 @@ func (z *Zfs) Refresh(ctx context.Context) error {
 	newZ := Zfs{
 		root:        &Dataset{Name: "/"},
 		allDatasets: make(map[string]*Dataset),
+		changes:     Changes{Everything: true},
 		libzfs:      z.libzfs,
 	}
 
@@ -127,6 +199,12 @@ func (z *Zfs) Refresh(ctx context.Context) error {
 	return nil
 }
 
+// RefreshDataset rescans only name and its children, which were changed outside of this zfs instance.
+func (z *Zfs) RefreshDataset(ctx context.Context, name string) error {
+	log.Debugf(ctx, i18n.G("ZFS: refresh dataset %q"), name)
+	return z.refreshDataset(ctx, name)
+}
+
 // Datasets returns all datasets on the system, where parent will always be before children.
 func (z Zfs) Datasets() []*Dataset {
 	ds := make(chan *Dataset)
@@ -321,7 +399,58 @@ func (t *Transaction) Create(path, mountpoint, canmount string) error {
 
 	log.Debugf(t.ctx, i18n.G("ZFS: trying to Create %q with mountpoint %q"), path, mountpoint)
 
-	props := make(map[libzfs.Prop]libzfs.Property)
+	_, err := t.create(path, mountpoint, canmount, make(map[libzfs.Prop]libzfs.Property))
+	return err
+}
+
+// CreateEncrypted creates a dataset for that path as a new encryption root, with passphrase as key.
+// The key is loaded once the dataset is created. It's then prompted for, like on login, to load it again.
+func (t *Transaction) CreateEncrypted(path, mountpoint, canmount string, passphrase []byte) error {
+	t.checkValid()
+
+	log.Debugf(t.ctx, i18n.G("ZFS: trying to Create encrypted %q with mountpoint %q"), path, mountpoint)
+
+	if len(passphrase) < minPassphraseLength || len(passphrase) > maxPassphraseLength {
+		return fmt.Errorf(i18n.G("passphrase for %q must be between %d and %d characters long"), path, minPassphraseLength, maxPassphraseLength)
+	}
+
+	// ZFS loads the key of new encryption roots from its key location: the passphrase is only ever in a pipe, readable
+	// by this process.
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't create key pipe: ")+config.ErrorFormat, err)
+	}
+	defer r.Close()
+	_, err = w.Write(passphrase)
+	if errClose := w.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't write key to pipe: ")+config.ErrorFormat, err)
+	}
+
+	props := map[libzfs.Prop]libzfs.Property{
+		libzfs.DatasetPropEncryption:  {Value: "on"},
+		libzfs.DatasetPropKeyFormat:   {Value: "passphrase"},
+		libzfs.DatasetPropKeyLocation: {Value: fmt.Sprintf("file:///proc/self/fd/%d", r.Fd())},
+	}
+	d, err := t.create(path, mountpoint, canmount, props)
+	if err != nil {
+		return err
+	}
+
+	if err := d.dZFS.SetProperty(libzfs.DatasetPropKeyLocation, "prompt"); err != nil {
+		return fmt.Errorf(i18n.G("couldn't set key location of %q: ")+config.ErrorFormat, path, err)
+	}
+	if err := d.refreshProperties(t.ctx); err != nil {
+		log.Warningf(t.ctx, i18n.G("couldn't fetch property of newly created dataset: %v"), err)
+	}
+
+	return nil
+}
+
+// create creates a filesystem dataset for that path with additional props and adds it to our cache.
+func (t *Transaction) create(path, mountpoint, canmount string, props map[libzfs.Prop]libzfs.Property) (*Dataset, error) {
 	if mountpoint != "" {
 		props[libzfs.DatasetPropMountpoint] = libzfs.Property{Value: mountpoint}
 	}
@@ -329,7 +458,7 @@ func (t *Transaction) Create(path, mountpoint, canmount string) error {
 
 	dZFS, err := t.Zfs.libzfs.DatasetCreate(path, libzfs.DatasetTypeFilesystem, props)
 	if err != nil {
-		return fmt.Errorf(i18n.G("can't create %q: %v"), path, err)
+		return nil, fmt.Errorf(i18n.G("can't create %q: %v"), path, err)
 	}
 
 	d := Dataset{
@@ -348,14 +477,15 @@ func (t *Transaction) Create(path, mountpoint, canmount string) error {
 		log.Warningf(t.ctx, i18n.G("couldn't fetch property of newly created dataset: %v"), err)
 	}
 	t.Zfs.allDatasets[d.Name] = &d
+	t.Zfs.recordCreated(d.Name)
 
 	parent, err := t.Zfs.findDatasetByName(filepath.Dir(d.Name))
 	if err != nil {
-		return fmt.Errorf(i18n.G("cannot find parent for %q: %v"), d.Name, err)
+		return nil, fmt.Errorf(i18n.G("cannot find parent for %q: %v"), d.Name, err)
 	}
 	parent.children = append(parent.children, &d)
 
-	return nil
+	return &d, nil
 }
 
 // Snapshot creates a new snapshot for dataset (and children if recursive is true) with the given name.
@@ -426,6 +556,7 @@ func (t *nestedTransaction) snapshotRecursive(parent *Dataset, snapName string,
 		log.Warningf(t.ctx, i18n.G("couldn't fetch property of newly created snapshot: %v"), err)
 	}
 	t.Zfs.allDatasets[d.Name] = &d
+	t.Zfs.recordCreated(d.Name)
 	parent.children = append(parent.children, &d)
 
 	if !recursive {
@@ -486,9 +617,40 @@ func (t *Transaction) Clone(name, suffix string, ignoreErrorOnExists, recursive
 		newRootName += "_" + suffix
 	}
 
+	return nestedT.clone(*d, rootName, snapshotName, newRootName, ignoreErrorOnExists, recursive)
+}
+
+// CloneTo creates a new dataset named target from a snapshot (and children if recursive is true).
+// Contrary to Clone, the new dataset can be anywhere in the pool, like a sibling of the snapshotted dataset.
+func (t *Transaction) CloneTo(name, target string, recursive bool) (errClone error) {
+	t.checkValid()
+
+	log.Debugf(t.ctx, i18n.G("ZFS: trying to clone %q to %q"), name, target)
+
+	d, err := t.Zfs.findDatasetByName(name)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot find %q: %v"), name, err)
+	}
+
+	if !d.IsSnapshot {
+		return fmt.Errorf(i18n.G("%q isn't a snapshot"), name)
+	}
+	if t.Zfs.datasetExists(target) {
+		return fmt.Errorf(i18n.G("can't clone %q to %q: it already exists"), name, target)
+	}
+
+	nestedT := t.newNestedTransaction()
+	defer nestedT.Done(&errClone)
+
+	rootName, snapshotName := splitSnapshotName(name)
+	return nestedT.clone(*d, rootName, snapshotName, target, false, recursive)
+}
+
+// clone checks the snapshot hierarchy of rootName and clones d to newRootName.
+func (t *nestedTransaction) clone(d Dataset, rootName, snapshotName, newRootName string, ignoreErrorOnExists, recursive bool) error {
 	parent, err := t.Zfs.findDatasetByName(rootName)
 	if err != nil {
-		return fmt.Errorf(i18n.G("cannot find parent for %q: %v"), name, err)
+		return fmt.Errorf(i18n.G("cannot find parent for %q: %v"), d.Name, err)
 	}
 
 	if recursive {
@@ -496,7 +658,48 @@ func (t *Transaction) Clone(name, suffix string, ignoreErrorOnExists, recursive
 			return fmt.Errorf(i18n.G("integrity check failed: %v"), err)
 		}
 	}
-	return nestedT.cloneRecursive(*d, snapshotName, rootName, newRootName, ignoreErrorOnExists, recursive)
+	if err := t.checkCloneEncryption(d, parent, snapshotName, newRootName, recursive); err != nil {
+		return err
+	}
+	return t.cloneRecursive(d, snapshotName, rootName, newRootName, ignoreErrorOnExists, recursive)
+}
+
+// checkCloneEncryption ensures that every snapshot to clone can be cloned to newRootName before creating any dataset.
+// Clones share the encryption root of their origin, which key needs to be loaded, and unencrypted snapshots can't
+// be cloned under an encrypted dataset.
+func (t *nestedTransaction) checkCloneEncryption(d Dataset, parent *Dataset, snapshotName, newRootName string, recursive bool) error {
+	if d.EncryptionRoot == "" {
+		if target, err := t.Zfs.findDatasetByName(filepath.Dir(newRootName)); err == nil && target.EncryptionRoot != "" {
+			return fmt.Errorf(i18n.G("can't clone unencrypted %q under encrypted %q"), d.Name, target.Name)
+		}
+	}
+
+	snapshots := []*Dataset{&d}
+	if recursive {
+		var collect func(*Dataset)
+		collect = func(p *Dataset) {
+			for _, c := range p.children {
+				if c.IsSnapshot {
+					continue
+				}
+				for _, s := range c.children {
+					if !s.IsSnapshot || !strings.HasSuffix(s.Name, "@"+snapshotName) {
+						continue
+					}
+					snapshots = append(snapshots, s)
+					collect(c)
+				}
+			}
+		}
+		collect(parent)
+	}
+
+	for _, s := range snapshots {
+		if s.KeyStatus == keyUnavailable {
+			return fmt.Errorf(i18n.G("can't clone %q: encryption key of %q isn't loaded"), s.Name, s.EncryptionRoot)
+		}
+	}
+	return nil
 }
 
 // cloneRecursive recursively clones all children and store "revert" operations by cleaning newly
@@ -586,6 +789,7 @@ func (t *nestedTransaction) cloneDataset(d Dataset, target string, ignoreErrorOn
 		return nil
 	})
 	t.Zfs.allDatasets[newDataset.Name] = &newDataset
+	t.Zfs.recordCreated(newDataset.Name)
 
 	parent, err := t.Zfs.findDatasetByName(filepath.Dir(newDataset.Name))
 	if err != nil {
@@ -659,6 +863,9 @@ func (t *Transaction) Promote(name string) (errPromote error) {
 		return fmt.Errorf(i18n.G("integrity check failed: %v"), err)
 	}
 
+	// Promoting moves snapshots and origins between both hierarchies.
+	t.Zfs.changes.Everything = true
+
 	nestedT.registerRevert(func() error {
 		// Create our own "temporary" transaction to not attach to main one
 		tempT, _ := t.Zfs.NewTransaction(context.Background())
@@ -707,6 +914,157 @@ func (t *nestedTransaction) promoteRecursive(d *Dataset) error {
 	return nil
 }
 
+// Send writes to w a send stream of the snapshot name, including its properties.
+// If from is not empty, the stream is incremental from this previous snapshot of the same dataset, or from the origin
+// of the dataset if it is a clone.
+func (z *Zfs) Send(ctx context.Context, w io.Writer, name, from string) error {
+	log.Debugf(ctx, i18n.G("ZFS: trying to send %q, incremental from %q"), name, from)
+
+	d, err := z.findDatasetByName(name)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot find %q: %v"), name, err)
+	}
+	if !d.IsSnapshot {
+		return fmt.Errorf(i18n.G("%q isn't a snapshot"), name)
+	}
+
+	if from != "" {
+		fromD, err := z.findDatasetByName(from)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot find %q: %v"), from, err)
+		}
+		base, _ := splitSnapshotName(name)
+		fromBase, _ := splitSnapshotName(from)
+		baseD, err := z.findDatasetByName(base)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot find %q: %v"), base, err)
+		}
+		if !fromD.IsSnapshot || (base != fromBase && from != baseD.Origin) {
+			return fmt.Errorf(i18n.G("%q isn't a snapshot of %q or its origin"), from, base)
+		}
+	}
+
+	if err := d.dZFS.Send(w, from); err != nil {
+		return fmt.Errorf(i18n.G("couldn't send %q: ")+config.ErrorFormat, name, err)
+	}
+	return nil
+}
+
+// Diff returns all file changes between the snapshot from and to, a more recent snapshot or a filesystem dataset
+// of the same dataset or one of its clones.
+func (z *Zfs) Diff(ctx context.Context, from, to string) ([]libzfs.DiffEntry, error) {
+	log.Debugf(ctx, i18n.G("ZFS: trying to diff %q to %q"), from, to)
+
+	d, err := z.findDatasetByName(from)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot find %q: %v"), from, err)
+	}
+	if !d.IsSnapshot {
+		return nil, fmt.Errorf(i18n.G("%q isn't a snapshot"), from)
+	}
+	if _, err := z.findDatasetByName(to); err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot find %q: %v"), to, err)
+	}
+
+	entries, err := d.dZFS.Diff(to)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("couldn't diff %q to %q: ")+config.ErrorFormat, from, to, err)
+	}
+	return entries, nil
+}
+
+// Receive reads a send stream from r into target filesystem dataset.
+// If target exists, the stream must be incremental from its most recent snapshot. Otherwise, target is
+// created from a full stream, under an existing parent.
+// If origin is not empty, target is created as a clone of this snapshot from a stream incremental from origin.
+func (t *Transaction) Receive(target, origin string, r io.Reader) (errReceive error) {
+	t.checkValid()
+
+	log.Debugf(t.ctx, i18n.G("ZFS: trying to receive into %q"), target)
+
+	d, exists := t.Zfs.allDatasets[target]
+	if exists && d.IsSnapshot {
+		return fmt.Errorf(i18n.G("can't receive into %q: it's a snapshot"), target)
+	}
+	parent, err := t.Zfs.findDatasetByName(filepath.Dir(target))
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot find parent for %q: %v"), target, err)
+	}
+	if origin != "" {
+		if exists {
+			return fmt.Errorf(i18n.G("can't receive %q as a clone: it already exists"), target)
+		}
+		o, err := t.Zfs.findDatasetByName(origin)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot find origin %q: %v"), origin, err)
+		}
+		if !o.IsSnapshot {
+			return fmt.Errorf(i18n.G("%q isn't a snapshot"), origin)
+		}
+	}
+
+	dZFS, err := t.Zfs.libzfs.DatasetReceive(target, origin, r)
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't receive into %q: ")+config.ErrorFormat, target, err)
+	}
+	// Received streams can bring any number of snapshots and properties.
+	t.Zfs.changes.Everything = true
+
+	nestedT := t.newNestedTransaction()
+	defer nestedT.Done(&errReceive)
+
+	// Full stream: the whole dataset and its snapshot are new.
+	if !exists {
+		newD, err := newDatasetTree(t.ctx, dZFS, &t.Zfs.allDatasets)
+		if err != nil {
+			return fmt.Errorf(i18n.G("couldn't scan received dataset %q: %v"), target, err)
+		}
+		nestedT.registerRevert(func() error {
+			nt := t.Zfs.NewNoTransaction(t.ctx)
+			if err := nt.destroyRecursive(newD, ""); err != nil {
+				return fmt.Errorf(i18n.G("couldn't destroy %q for cleanup: %v"), newD.Name, err)
+			}
+			return nil
+		})
+		parent.children = append(parent.children, newD)
+		return nil
+	}
+
+	// Incremental stream: only attach the newly received snapshot.
+	for _, c := range dZFS.Children() {
+		if !c.IsSnapshot() {
+			continue
+		}
+		name := (*c.Properties())[libzfs.DatasetPropName].Value
+		if _, ok := t.Zfs.allDatasets[name]; ok {
+			continue
+		}
+		snap, err := newDatasetTree(t.ctx, c, &t.Zfs.allDatasets)
+		if err != nil {
+			return fmt.Errorf(i18n.G("couldn't scan received snapshot %q: %v"), name, err)
+		}
+		nestedT.registerRevert(func() error {
+			nt := t.Zfs.NewNoTransaction(t.ctx)
+			if err := nt.destroyOne(snap); err != nil {
+				return fmt.Errorf(i18n.G("couldn't destroy %q for cleanup: %v"), snap.Name, err)
+			}
+			return nil
+		})
+		d.children = append(d.children, snap)
+	}
+	*dZFS.DZFSChildren() = nil
+
+	// Received properties may have changed on the filesystem dataset.
+	if err := d.dZFS.ReloadProperties(); err != nil {
+		return fmt.Errorf(i18n.G("couldn't refresh properties for %q: ")+config.ErrorFormat, d.Name, err)
+	}
+	if err := d.refreshProperties(t.ctx); err != nil {
+		log.Warningf(t.ctx, i18n.G("couldn't fetch property of received dataset: %v"), err)
+	}
+
+	return nil
+}
+
 // Destroy recursively all children, including dataset named "name".
 // If the dataset is a filesystem dataset, only remove it and children if there is no snapshots in the descendants.
 // If the dataset is a snapshot, navigate through the hierarchy to delete all dataset with the same snapshot name.
@@ -743,6 +1101,37 @@ func (nt *NoTransaction) Destroy(name string) error {
 	return nil
 }
 
+// LoadKey loads the encryption key of the encryption root name from its passphrase, for all datasets sharing it.
+// It's a no-op if the key is already loaded. Loading a key isn't reverted.
+func (nt *NoTransaction) LoadKey(name string, passphrase []byte) error {
+	log.Debugf(nt.ctx, i18n.G("ZFS: trying to load key of %q"), name)
+	d, err := nt.Zfs.findDatasetByName(name)
+	if err != nil {
+		return fmt.Errorf(i18n.G("can't get dataset to load key of %q: ")+config.ErrorFormat, name, err)
+	}
+	if d.EncryptionRoot != d.Name {
+		return fmt.Errorf(i18n.G("%q isn't an encryption root"), name)
+	}
+	if d.KeyStatus != keyUnavailable {
+		return nil
+	}
+
+	if err := d.dZFS.LoadKey(bytes.NewReader(passphrase)); err != nil {
+		return fmt.Errorf(i18n.G("couldn't load key of %q: ")+config.ErrorFormat, name, err)
+	}
+
+	for _, ds := range nt.Zfs.allDatasets {
+		if ds.EncryptionRoot != name {
+			continue
+		}
+		if err := ds.refreshProperties(nt.ctx); err != nil {
+			return fmt.Errorf(i18n.G("couldn't refresh properties of %q: ")+config.ErrorFormat, ds.Name, err)
+		}
+		nt.Zfs.recordModified(ds, KeyStatusProp)
+	}
+	return nil
+}
+
 // destroyRecursive destroys and unreference dataset objects, starting with children.
 func (nt *NoTransaction) destroyRecursive(d *Dataset, snapName string) error {
 	// Only try to find snapshot to destroy on filesystem datasets (snapshots can’t have snapshot as child)
@@ -802,6 +1191,7 @@ func (nt *NoTransaction) destroyOne(d *Dataset) error {
 
 	// Delete from main list of dataset
 	delete(nt.Zfs.allDatasets, d.Name)
+	nt.Zfs.recordDestroyed(d.Name)
 
 	return nil
 }
@@ -826,9 +1216,16 @@ func (t *Transaction) SetProperty(name, value, datasetName string, force bool) e
 	if err = d.setProperty(name, value, "local"); err != nil {
 		return fmt.Errorf(i18n.G("can't set dataset property %q=%q for %q: ")+config.ErrorFormat, name, value, datasetName, err)
 	}
+	t.Zfs.recordModified(d, name)
 	// Note: the revert will not exactly ensure we are back to the same state for propertie
 	// as we can't run "inherit" on dataset when origS != local
-	t.registerRevert(func() error { return d.setProperty(name, origV, origS) })
+	t.registerRevert(func() error {
+		if err := d.setProperty(name, origV, origS); err != nil {
+			return err
+		}
+		t.Zfs.recordModified(d, name)
+		return nil
+	})
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package zfs_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -163,6 +164,7 @@ func TestCreate(t *testing.T) {
 		path       string
 		mountpoint string
 		canmount   string
+		passphrase []byte
 
 		wantErr bool
 	}{
@@ -170,11 +172,14 @@ func TestCreate(t *testing.T) {
 		"Without mountpoint":                 {def: "one_pool_one_dataset.yaml", path: "rpool/dataset", canmount: "on"},
 		"With canmount false":                {def: "one_pool_one_dataset.yaml", path: "rpool/dataset", mountpoint: "/home/foo", canmount: "off"},
 		"With mountpoint and canmount false": {def: "one_pool_one_dataset.yaml", path: "rpool/dataset", canmount: "off"},
-
-		"Failing on dataset already exists":        {def: "one_pool_n_datasets.yaml", path: "rpool/ROOT/ubuntu", wantErr: true},
-		"Failing on pool directly":                 {def: "one_pool_one_dataset.yaml", path: "rpool", wantErr: true},
-		"Failing on unexisting pool":               {def: "one_pool_one_dataset.yaml", path: "rpool2", wantErr: true},
-		"Failing on missing intermediate datasets": {def: "one_pool_one_dataset.yaml", path: "rpool/intermediate/doesnt/exist", wantErr: true},
+		"Encrypted":                          {def: "one_pool_one_dataset.yaml", path: "rpool/dataset", mountpoint: "/home/foo", canmount: "on", passphrase: []byte("my secret passphrase")},
+
+		"Failing on dataset already exists":           {def: "one_pool_n_datasets.yaml", path: "rpool/ROOT/ubuntu", wantErr: true},
+		"Failing on pool directly":                    {def: "one_pool_one_dataset.yaml", path: "rpool", wantErr: true},
+		"Failing on unexisting pool":                  {def: "one_pool_one_dataset.yaml", path: "rpool2", wantErr: true},
+		"Failing on missing intermediate datasets":    {def: "one_pool_one_dataset.yaml", path: "rpool/intermediate/doesnt/exist", wantErr: true},
+		"Failing on too short passphrase":             {def: "one_pool_one_dataset.yaml", path: "rpool/dataset", canmount: "on", passphrase: []byte("secret"), wantErr: true},
+		"Failing on encrypted dataset already exists": {def: "one_pool_n_datasets.yaml", path: "rpool/ROOT/ubuntu", passphrase: []byte("my secret passphrase"), wantErr: true},
 	}
 
 	for name, tc := range tests {
@@ -194,7 +199,11 @@ func TestCreate(t *testing.T) {
 			trans, _ := z.NewTransaction(context.Background())
 			defer trans.Done()
 
-			err = trans.Create(tc.path, tc.mountpoint, tc.canmount)
+			if tc.passphrase != nil {
+				err = trans.CreateEncrypted(tc.path, tc.mountpoint, tc.canmount, tc.passphrase)
+			} else {
+				err = trans.Create(tc.path, tc.mountpoint, tc.canmount)
+			}
 
 			if err != nil && !tc.wantErr {
 				t.Fatalf("expected no error but got: %v", err)
@@ -336,6 +345,11 @@ func TestClone(t *testing.T) {
 		"No suffix provided":              {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", wantErr: true, isNoOp: true},
 		"Suffixed dataset already exists": {def: "layout1_with_bootfs_already_cloned.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", suffix: "5678", wantErr: true, isNoOp: true},
 		"Clone on root fails":             {def: "one_pool_one_dataset_one_snapshot.yaml", dataset: "rpool@snap1", suffix: "5678", wantErr: true, isNoOp: true},
+
+		"Recursive clone keeps encryption root":            {def: "layout1_encrypted.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", suffix: "5678", recursive: true},
+		"Clone with children key not loaded":               {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", suffix: "5678"},
+		"Recursive clone fails on children key not loaded": {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", suffix: "5678", recursive: true, wantErr: true, isNoOp: true},
+		"Clone fails on key not loaded":                    {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib@snap_r1", suffix: "5678", wantErr: true, isNoOp: true},
 	}
 
 	for name, tc := range tests {
@@ -377,6 +391,73 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestCloneTo(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+
+	tests := map[string]struct {
+		def       string
+		dataset   string
+		target    string
+		recursive bool
+
+		wantErr bool
+		isNoOp  bool
+	}{
+		"Simple clone to a sibling":             {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "rpool/ROOT/ubuntu_1234/var_5678"},
+		"Recursive clone to a sibling":          {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "rpool/ROOT/ubuntu_1234/var_5678", recursive: true},
+		"Recursive clone of a leaf to a parent": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib/apt@snap_r1", target: "rpool/ROOT/ubuntu_1234/apt", recursive: true},
+
+		"Recursive missing intermediate snapshots": {def: "layout1_missing_intermediate_snapshot.yaml", dataset: "rpool/ROOT/ubuntu_1234@snap_r1", target: "rpool/ROOT/ubuntu_5678", recursive: true, wantErr: true, isNoOp: true},
+
+		"Snapshot doesn't exists":      {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@doesntexists", target: "rpool/ROOT/ubuntu_1234/var_5678", wantErr: true, isNoOp: true},
+		"Not a snapshot":               {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var", target: "rpool/ROOT/ubuntu_1234/var_5678", wantErr: true, isNoOp: true},
+		"Target already exists":        {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "rpool/ROOT/ubuntu_1234/opt", wantErr: true, isNoOp: true},
+		"Target parent doesn't exists": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "rpool/ROOT/ubuntu_5678/var", wantErr: true, isNoOp: true},
+
+		"Encrypted clone to an unencrypted parent":   {def: "layout1_encrypted.yaml", dataset: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "rpool/var_5678", recursive: true},
+		"Unencrypted clone to an encrypted parent":   {def: "layout1_encrypted.yaml", dataset: "rpool/srv@snap_r1", target: "rpool/ROOT/ubuntu_1234/srv", wantErr: true, isNoOp: true},
+		"Clone to a sibling fails on key not loaded": {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib@snap_r1", target: "rpool/ROOT/ubuntu_1234/var/lib_5678", wantErr: true, isNoOp: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			ta := timeAsserter(time.Now())
+			adapter := testutils.GetLibZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			initState := copyState(z)
+			trans, _ := z.NewTransaction(context.Background())
+			defer trans.Done()
+
+			err = trans.CloneTo(tc.dataset, tc.target, tc.recursive)
+
+			if err != nil && !tc.wantErr {
+				t.Fatalf("expected no error but got: %v", err)
+			} else if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertDatasetsEquals(t, ta, initState, z.Datasets())
+				return
+			}
+			if err == nil && !tc.isNoOp {
+				assertDatasetsToGolden(t, ta, z.Datasets())
+			}
+
+			zfs.AssertNoZFSChildren(t, z)
+			assertIdempotentWithNew(t, ta, z.Datasets(), adapter)
+		})
+	}
+}
+
 func TestPromote(t *testing.T) {
 	failOnZFSPermissionDenied(t)
 
@@ -396,6 +477,8 @@ func TestPromote(t *testing.T) {
 		"Promote missing some leaf snapshots":           {def: "layout1_missing_leaf_snapshot.yaml", dataset: "rpool/ROOT/ubuntu_5678", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1"},
 		"Promote with snapshots and ancestor snapshots": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_5678", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r2"},
 
+		"Promote moves encryption root": {def: "layout1_encrypted.yaml", dataset: "rpool/ROOT/ubuntu_5678", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1"},
+
 		"Promote already promoted hierarchy":  {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_1234", isNoOp: true},
 		"Root of hierarchy already promoted":  {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_5678", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1", alreadyPromoted: "rpool/ROOT/ubuntu_5678"},
 		"Child of hierarchy already promoted": {def: "layout1__one_pool_n_datasets_n_snapshots.yaml", dataset: "rpool/ROOT/ubuntu_5678", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1", alreadyPromoted: "rpool/ROOT/ubuntu_5678/var"},
@@ -534,6 +617,119 @@ func TestPromoteCloneTree(t *testing.T) {
 	assertIdempotentWithNew(t, ta, z.Datasets(), adapter)
 }
 
+func TestSendReceive(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+
+	tests := map[string]struct {
+		def           string
+		snapshot      string
+		from          string
+		target        string
+		origin        string
+		preReceive    string
+		cloneFrom     string
+		corruptStream bool
+
+		wantErr bool
+	}{
+		"Full send and receive":                                     {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_1234"},
+		"Full send and receive with a new name":                     {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_5678"},
+		"Full send and receive of a child":                          {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234/var/lib@snap_r2", target: "bpool/BACKUP/lib"},
+		"Full send and receive on the same pool":                    {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "rpool/ROOT/ubuntu_5678"},
+		"Incremental send and receive":                              {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_1234", preReceive: "rpool/ROOT/ubuntu_1234@snap_r1"},
+		"Incremental send from clone origin and receive as a clone": {def: "layout1_with_backup_pool.yaml", cloneFrom: "rpool/ROOT/ubuntu_1234@snap_r1", snapshot: "rpool/ROOT/ubuntu_5678@snap_c1", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "rpool/ROOT/ubuntu_9999", origin: "rpool/ROOT/ubuntu_1234@snap_r1"},
+		"Incremental send and receive as a clone":                   {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "rpool/ROOT/ubuntu_5678", origin: "rpool/ROOT/ubuntu_1234@snap_r1"},
+
+		"Send a filesystem dataset fails":                                          {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234", target: "bpool/BACKUP/ubuntu_1234", wantErr: true},
+		"Send unexisting snapshot fails":                                           {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@doesntexist", target: "bpool/BACKUP/ubuntu_1234", wantErr: true},
+		"Send from unexisting snapshot fails":                                      {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@doesntexist", target: "bpool/BACKUP/ubuntu_1234", wantErr: true},
+		"Send from a snapshot of another dataset fails":                            {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234/var@snap_r1", target: "bpool/BACKUP/ubuntu_1234", wantErr: true},
+		"Receive full stream on existing dataset fails":                            {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP", wantErr: true},
+		"Receive incremental stream on new dataset fails":                          {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_1234", wantErr: true},
+		"Receive incremental stream without base fails":                            {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP", wantErr: true},
+		"Receive already received snapshot fails":                                  {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_1234", preReceive: "rpool/ROOT/ubuntu_1234@snap_r2", wantErr: true},
+		"Receive on a snapshot fails":                                              {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "rpool/ROOT/ubuntu_1234@snap_r1", wantErr: true},
+		"Receive with missing intermediate datasets fails":                         {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/doesnt/exist", wantErr: true},
+		"Receive on unexisting pool fails":                                         {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "tpool/ubuntu_1234", wantErr: true},
+		"Receive invalid stream fails":                                             {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r1", target: "bpool/BACKUP/ubuntu_1234", corruptStream: true, wantErr: true},
+		"Receive as a clone of a filesystem dataset fails":                         {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "rpool/ROOT/ubuntu_5678", origin: "rpool/ROOT/ubuntu_1234", wantErr: true},
+		"Receive as a clone of unexisting origin fails":                            {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "rpool/ROOT/ubuntu_5678", origin: "rpool/ROOT/ubuntu_1234@doesntexist", wantErr: true},
+		"Receive as a clone on existing dataset fails":                             {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "bpool/BACKUP", origin: "rpool/ROOT/ubuntu_1234@snap_r1", wantErr: true},
+		"Receive as a clone on another pool fails":                                 {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "bpool/BACKUP/ubuntu_1234", origin: "rpool/ROOT/ubuntu_1234@snap_r1", wantErr: true},
+		"Receive full stream as a clone fails":                                     {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", target: "rpool/ROOT/ubuntu_5678", origin: "rpool/ROOT/ubuntu_1234@snap_r1", wantErr: true},
+		"Receive as a clone of another snapshot than the incremental source fails": {def: "layout1_with_backup_pool.yaml", snapshot: "rpool/ROOT/ubuntu_1234@snap_r2", from: "rpool/ROOT/ubuntu_1234@snap_r1", target: "rpool/ROOT/ubuntu_5678", origin: "rpool/ROOT/ubuntu_1234@snap_r2", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			ta := timeAsserter(time.Now())
+			adapter := testutils.GetLibZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if tc.cloneFrom != "" {
+				base, snapshot := zfs.SplitSnapshotName(tc.snapshot)
+				trans, _ := z.NewTransaction(context.Background())
+				if err := trans.CloneTo(tc.cloneFrom, base, false); err != nil {
+					t.Fatalf("couldn't clone %q to prepare source: %v", tc.cloneFrom, err)
+				}
+				if err := trans.Snapshot(snapshot, base, false); err != nil {
+					t.Fatalf("couldn't snapshot %q to prepare source: %v", base, err)
+				}
+				trans.Done()
+			}
+
+			if tc.preReceive != "" {
+				var stream bytes.Buffer
+				if err := z.Send(context.Background(), &stream, tc.preReceive, ""); err != nil {
+					t.Fatalf("couldn't send %q to prepare target: %v", tc.preReceive, err)
+				}
+				trans, _ := z.NewTransaction(context.Background())
+				if err := trans.Receive(tc.target, "", &stream); err != nil {
+					t.Fatalf("couldn't receive %q to prepare target: %v", tc.preReceive, err)
+				}
+				trans.Done()
+			}
+
+			initState := copyState(z)
+			trans, _ := z.NewTransaction(context.Background())
+			defer trans.Done()
+
+			var stream bytes.Buffer
+			err = z.Send(context.Background(), &stream, tc.snapshot, tc.from)
+			if err == nil {
+				if tc.corruptStream {
+					stream.Reset()
+					stream.WriteString("this isn't a send stream")
+				}
+				err = trans.Receive(tc.target, tc.origin, &stream)
+			}
+
+			if err != nil && !tc.wantErr {
+				t.Fatalf("expected no error but got: %v", err)
+			} else if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.wantErr {
+				assertDatasetsEquals(t, ta, initState, z.Datasets())
+			} else {
+				assertDatasetsToGolden(t, ta, z.Datasets())
+			}
+
+			zfs.AssertNoZFSChildren(t, z)
+			assertIdempotentWithNew(t, ta, z.Datasets(), adapter)
+		})
+	}
+}
+
 func TestDestroy(t *testing.T) {
 	failOnZFSPermissionDenied(t)
 
@@ -615,6 +811,67 @@ func TestDestroy(t *testing.T) {
 	}
 }
 
+func TestLoadKey(t *testing.T) {
+	failOnZFSPermissionDenied(t)
+
+	tests := map[string]struct {
+		def        string
+		dataset    string
+		passphrase string
+
+		wantErr bool
+		isNoOp  bool
+	}{
+		"Load key of all datasets sharing the encryption root": {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib"},
+		"Key already loaded": {def: "layout1_encrypted.yaml", dataset: "rpool/ROOT/ubuntu_1234", isNoOp: true},
+
+		"Wrong passphrase":       {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib", passphrase: "not my passphrase", wantErr: true, isNoOp: true},
+		"Not an encryption root": {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var/lib/apt", wantErr: true, isNoOp: true},
+		"Unencrypted dataset":    {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_1234/var", wantErr: true, isNoOp: true},
+		"Dataset doesn't exists": {def: "layout1_encrypted_child_locked.yaml", dataset: "rpool/ROOT/ubuntu_doesntexist", wantErr: true, isNoOp: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			ta := timeAsserter(time.Now())
+			adapter := testutils.GetLibZFS(t)
+			fPools := testutils.NewFakePools(t, filepath.Join("testdata", tc.def), testutils.WithLibZFS(adapter))
+			defer fPools.Create(dir)()
+			z, err := zfs.New(context.Background(), zfs.WithLibZFS(adapter))
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			z.TakeChanges()
+			initState := copyState(z)
+
+			if tc.passphrase == "" {
+				tc.passphrase = "my secret passphrase"
+			}
+			err = z.NewNoTransaction(context.Background()).LoadKey(tc.dataset, []byte(tc.passphrase))
+
+			if err != nil && !tc.wantErr {
+				t.Fatalf("expected no error but got: %v", err)
+			} else if err == nil && tc.wantErr {
+				t.Fatal("expected an error but got none")
+			}
+
+			if tc.isNoOp {
+				assertDatasetsEquals(t, ta, initState, z.Datasets())
+				assert.True(t, z.TakeChanges().IsEmpty(), "No change should be recorded")
+				return
+			}
+			assertDatasetsToGolden(t, ta, z.Datasets())
+			assert.True(t, z.TakeChanges().Modified[tc.dataset][zfs.KeyStatusProp], "Key loading should be recorded")
+
+			zfs.AssertNoZFSChildren(t, z)
+			assertIdempotentWithNew(t, ta, z.Datasets(), adapter)
+		})
+	}
+}
+
 func TestSetProperty(t *testing.T) {
 	failOnZFSPermissionDenied(t)
 
@@ -910,6 +1167,7 @@ func TestTransactionsWithZFS(t *testing.T) {
 		doClone       bool
 		doPromote     bool
 		doSetProperty bool
+		doReceive     bool
 		shouldErr     bool
 		cancel        bool
 	}{
@@ -941,12 +1199,17 @@ func TestTransactionsWithZFS(t *testing.T) {
 		"SetProperty only, fail, Cancel":    {def: "layout1_for_transactions_tests.yaml", doSetProperty: true, shouldErr: true, cancel: true},
 		"SetProperty only, fail, No cancel": {def: "layout1_for_transactions_tests.yaml", doSetProperty: true, shouldErr: true},
 
+		"Receive only, success, Done":   {def: "layout1_for_transactions_tests.yaml", doReceive: true},
+		"Receive only, success, Cancel": {def: "layout1_for_transactions_tests.yaml", doReceive: true, cancel: true},
+		"Receive only, fail, Cancel":    {def: "layout1_for_transactions_tests.yaml", doReceive: true, shouldErr: true, cancel: true},
+		"Receive only, fail, No cancel": {def: "layout1_for_transactions_tests.yaml", doReceive: true, shouldErr: true},
+
 		// Destroy can't be in transactions
 
-		"Multiple steps transaction, success, Done":   {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true},
-		"Multiple steps transaction, success, Cancel": {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, cancel: true},
-		"Multiple steps transaction, fail, Cancel":    {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, shouldErr: true, cancel: true},
-		"Multiple steps transaction, fail, No cancel": {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, shouldErr: true},
+		"Multiple steps transaction, success, Done":   {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, doReceive: true},
+		"Multiple steps transaction, success, Cancel": {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, doReceive: true, cancel: true},
+		"Multiple steps transaction, fail, Cancel":    {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, doReceive: true, shouldErr: true, cancel: true},
+		"Multiple steps transaction, fail, No cancel": {def: "layout1_for_transactions_tests.yaml", doCreate: true, doSnapshot: true, doClone: true, doPromote: true, doSetProperty: true, doReceive: true, shouldErr: true},
 	}
 
 	for name, tc := range tests {
@@ -1017,6 +1280,31 @@ func TestTransactionsWithZFS(t *testing.T) {
 				state = copyState(z)
 			}
 
+			if tc.doReceive {
+				target := "rpool/ROOT/ubuntu_4343"
+				if tc.shouldErr {
+					// receiving a full stream on an existing dataset will make it fail
+					target = "rpool/ROOT/ubuntu_9999"
+				}
+				var stream bytes.Buffer
+				if err := z.Send(context.Background(), &stream, "rpool/ROOT/ubuntu_1234@snap_r1", ""); err != nil {
+					t.Fatalf("sending shouldn't have failed but it did: %v", err)
+				}
+				err := trans.Receive(target, "", &stream)
+				if !tc.shouldErr && err != nil {
+					t.Fatalf("receiving shouldn't have failed but it did: %v", err)
+				} else if tc.shouldErr && err == nil {
+					t.Fatal("receiving should have returned an error but it didn't")
+				}
+				if err != nil {
+					assertDatasetsEquals(t, ta, state, z.Datasets())
+				} else {
+					assertDatasetsNotEquals(t, ta, state, z.Datasets())
+					haveChanges = true
+				}
+				state = copyState(z)
+			}
+
 			if tc.doClone {
 				name := "rpool/ROOT/ubuntu_1234@snap_r2"
 				suffix := "5678"
@@ -191,8 +191,10 @@ type CreateUserDataRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	User     string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	Homepath string `protobuf:"bytes,2,opt,name=homepath,proto3" json:"homepath,omitempty"`
+	User       string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Homepath   string `protobuf:"bytes,2,opt,name=homepath,proto3" json:"homepath,omitempty"`
+	Encrypt    bool   `protobuf:"varint,3,opt,name=encrypt,proto3" json:"encrypt,omitempty"`
+	Passphrase []byte `protobuf:"bytes,4,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
 }
 
 func (x *CreateUserDataRequest) Reset() {
@@ -241,6 +243,130 @@ func (x *CreateUserDataRequest) GetHomepath() string {
 	return ""
 }
 
+func (x *CreateUserDataRequest) GetEncrypt() bool {
+	if x != nil {
+		return x.Encrypt
+	}
+	return false
+}
+
+func (x *CreateUserDataRequest) GetPassphrase() []byte {
+	if x != nil {
+		return x.Passphrase
+	}
+	return nil
+}
+
+type MountUserDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User     string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Homepath string `protobuf:"bytes,2,opt,name=homepath,proto3" json:"homepath,omitempty"`
+}
+
+func (x *MountUserDataRequest) Reset() {
+	*x = MountUserDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MountUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MountUserDataRequest) ProtoMessage() {}
+
+func (x *MountUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MountUserDataRequest.ProtoReflect.Descriptor instead.
+func (*MountUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MountUserDataRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *MountUserDataRequest) GetHomepath() string {
+	if x != nil {
+		return x.Homepath
+	}
+	return ""
+}
+
+type LoadUserKeysRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User       string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Passphrase []byte `protobuf:"bytes,2,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+}
+
+func (x *LoadUserKeysRequest) Reset() {
+	*x = LoadUserKeysRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoadUserKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadUserKeysRequest) ProtoMessage() {}
+
+func (x *LoadUserKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadUserKeysRequest.ProtoReflect.Descriptor instead.
+func (*LoadUserKeysRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LoadUserKeysRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *LoadUserKeysRequest) GetPassphrase() []byte {
+	if x != nil {
+		return x.Passphrase
+	}
+	return nil
+}
+
 type ChangeHomeOnUserDataRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -253,7 +379,7 @@ type ChangeHomeOnUserDataRequest struct {
 func (x *ChangeHomeOnUserDataRequest) Reset() {
 	*x = ChangeHomeOnUserDataRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[4]
+		mi := &file_zsys_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -266,7 +392,7 @@ func (x *ChangeHomeOnUserDataRequest) String() string {
 func (*ChangeHomeOnUserDataRequest) ProtoMessage() {}
 
 func (x *ChangeHomeOnUserDataRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[4]
+	mi := &file_zsys_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -279,7 +405,7 @@ func (x *ChangeHomeOnUserDataRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChangeHomeOnUserDataRequest.ProtoReflect.Descriptor instead.
 func (*ChangeHomeOnUserDataRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{4}
+	return file_zsys_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ChangeHomeOnUserDataRequest) GetHome() string {
@@ -308,7 +434,7 @@ type DissociateUserRequest struct {
 func (x *DissociateUserRequest) Reset() {
 	*x = DissociateUserRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[5]
+		mi := &file_zsys_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -321,7 +447,7 @@ func (x *DissociateUserRequest) String() string {
 func (*DissociateUserRequest) ProtoMessage() {}
 
 func (x *DissociateUserRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[5]
+	mi := &file_zsys_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -334,7 +460,7 @@ func (x *DissociateUserRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DissociateUserRequest.ProtoReflect.Descriptor instead.
 func (*DissociateUserRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{5}
+	return file_zsys_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DissociateUserRequest) GetUser() string {
@@ -366,7 +492,7 @@ type PrepareBootResponse struct {
 func (x *PrepareBootResponse) Reset() {
 	*x = PrepareBootResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[6]
+		mi := &file_zsys_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -379,7 +505,7 @@ func (x *PrepareBootResponse) String() string {
 func (*PrepareBootResponse) ProtoMessage() {}
 
 func (x *PrepareBootResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[6]
+	mi := &file_zsys_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -392,7 +518,7 @@ func (x *PrepareBootResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PrepareBootResponse.ProtoReflect.Descriptor instead.
 func (*PrepareBootResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{6}
+	return file_zsys_proto_rawDescGZIP(), []int{8}
 }
 
 func (m *PrepareBootResponse) GetReply() isPrepareBootResponse_Reply {
@@ -447,7 +573,7 @@ type CommitBootResponse struct {
 func (x *CommitBootResponse) Reset() {
 	*x = CommitBootResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[7]
+		mi := &file_zsys_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -460,7 +586,7 @@ func (x *CommitBootResponse) String() string {
 func (*CommitBootResponse) ProtoMessage() {}
 
 func (x *CommitBootResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[7]
+	mi := &file_zsys_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -473,7 +599,7 @@ func (x *CommitBootResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommitBootResponse.ProtoReflect.Descriptor instead.
 func (*CommitBootResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{7}
+	return file_zsys_proto_rawDescGZIP(), []int{9}
 }
 
 func (m *CommitBootResponse) GetReply() isCommitBootResponse_Reply {
@@ -524,7 +650,7 @@ type UpdateBootMenuRequest struct {
 func (x *UpdateBootMenuRequest) Reset() {
 	*x = UpdateBootMenuRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[8]
+		mi := &file_zsys_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -537,7 +663,7 @@ func (x *UpdateBootMenuRequest) String() string {
 func (*UpdateBootMenuRequest) ProtoMessage() {}
 
 func (x *UpdateBootMenuRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[8]
+	mi := &file_zsys_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -550,7 +676,7 @@ func (x *UpdateBootMenuRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateBootMenuRequest.ProtoReflect.Descriptor instead.
 func (*UpdateBootMenuRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{8}
+	return file_zsys_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *UpdateBootMenuRequest) GetAuto() bool {
@@ -560,20 +686,78 @@ func (x *UpdateBootMenuRequest) GetAuto() bool {
 	return false
 }
 
-type SaveSystemStateRequest struct {
+type BootNextRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	StateName      string `protobuf:"bytes,1,opt,name=stateName,proto3" json:"stateName,omitempty"`
-	UpdateBootMenu bool   `protobuf:"varint,2,opt,name=updateBootMenu,proto3" json:"updateBootMenu,omitempty"`
-	Autosave       bool   `protobuf:"varint,3,opt,name=autosave,proto3" json:"autosave,omitempty"`
+	RevertUserData bool   `protobuf:"varint,2,opt,name=revertUserData,proto3" json:"revertUserData,omitempty"`
+}
+
+func (x *BootNextRequest) Reset() {
+	*x = BootNextRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BootNextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BootNextRequest) ProtoMessage() {}
+
+func (x *BootNextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BootNextRequest.ProtoReflect.Descriptor instead.
+func (*BootNextRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BootNextRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *BootNextRequest) GetRevertUserData() bool {
+	if x != nil {
+		return x.RevertUserData
+	}
+	return false
+}
+
+type SaveSystemStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StateName      string            `protobuf:"bytes,1,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	UpdateBootMenu bool              `protobuf:"varint,2,opt,name=updateBootMenu,proto3" json:"updateBootMenu,omitempty"`
+	Autosave       bool              `protobuf:"varint,3,opt,name=autosave,proto3" json:"autosave,omitempty"`
+	PackageChanges *PackageChanges   `protobuf:"bytes,4,opt,name=packageChanges,proto3" json:"packageChanges,omitempty"`
+	Description    string            `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Tags           map[string]string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (x *SaveSystemStateRequest) Reset() {
 	*x = SaveSystemStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[9]
+		mi := &file_zsys_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -586,7 +770,7 @@ func (x *SaveSystemStateRequest) String() string {
 func (*SaveSystemStateRequest) ProtoMessage() {}
 
 func (x *SaveSystemStateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[9]
+	mi := &file_zsys_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -599,7 +783,7 @@ func (x *SaveSystemStateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SaveSystemStateRequest.ProtoReflect.Descriptor instead.
 func (*SaveSystemStateRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{9}
+	return file_zsys_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *SaveSystemStateRequest) GetStateName() string {
@@ -623,32 +807,54 @@ func (x *SaveSystemStateRequest) GetAutosave() bool {
 	return false
 }
 
-type SaveUserStateRequest struct {
+func (x *SaveSystemStateRequest) GetPackageChanges() *PackageChanges {
+	if x != nil {
+		return x.PackageChanges
+	}
+	return nil
+}
+
+func (x *SaveSystemStateRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SaveSystemStateRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type PackageChanges struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	UserName  string `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
-	StateName string `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Upgraded  []string `protobuf:"bytes,1,rep,name=upgraded,proto3" json:"upgraded,omitempty"`
+	Installed []string `protobuf:"bytes,2,rep,name=installed,proto3" json:"installed,omitempty"`
+	Removed   []string `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
 }
 
-func (x *SaveUserStateRequest) Reset() {
-	*x = SaveUserStateRequest{}
+func (x *PackageChanges) Reset() {
+	*x = PackageChanges{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[10]
+		mi := &file_zsys_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SaveUserStateRequest) String() string {
+func (x *PackageChanges) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SaveUserStateRequest) ProtoMessage() {}
+func (*PackageChanges) ProtoMessage() {}
 
-func (x *SaveUserStateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[10]
+func (x *PackageChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,54 +865,60 @@ func (x *SaveUserStateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SaveUserStateRequest.ProtoReflect.Descriptor instead.
-func (*SaveUserStateRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use PackageChanges.ProtoReflect.Descriptor instead.
+func (*PackageChanges) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *SaveUserStateRequest) GetUserName() string {
+func (x *PackageChanges) GetUpgraded() []string {
 	if x != nil {
-		return x.UserName
+		return x.Upgraded
 	}
-	return ""
+	return nil
 }
 
-func (x *SaveUserStateRequest) GetStateName() string {
+func (x *PackageChanges) GetInstalled() []string {
 	if x != nil {
-		return x.StateName
+		return x.Installed
 	}
-	return ""
+	return nil
 }
 
-type CreateSaveStateResponse struct {
+func (x *PackageChanges) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+type SaveUserStateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Reply:
-	//
-	//	*CreateSaveStateResponse_Log
-	//	*CreateSaveStateResponse_StateName
-	Reply isCreateSaveStateResponse_Reply `protobuf_oneof:"reply"`
+	UserName    string            `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName   string            `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Description string            `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Tags        map[string]string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *CreateSaveStateResponse) Reset() {
-	*x = CreateSaveStateResponse{}
+func (x *SaveUserStateRequest) Reset() {
+	*x = SaveUserStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[11]
+		mi := &file_zsys_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CreateSaveStateResponse) String() string {
+func (x *SaveUserStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateSaveStateResponse) ProtoMessage() {}
+func (*SaveUserStateRequest) ProtoMessage() {}
 
-func (x *CreateSaveStateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[11]
+func (x *SaveUserStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -717,15 +929,87 @@ func (x *CreateSaveStateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateSaveStateResponse.ProtoReflect.Descriptor instead.
-func (*CreateSaveStateResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use SaveUserStateRequest.ProtoReflect.Descriptor instead.
+func (*SaveUserStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{14}
 }
 
-func (m *CreateSaveStateResponse) GetReply() isCreateSaveStateResponse_Reply {
-	if m != nil {
-		return m.Reply
-	}
+func (x *SaveUserStateRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *SaveUserStateRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *SaveUserStateRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SaveUserStateRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type CreateSaveStateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*CreateSaveStateResponse_Log
+	//	*CreateSaveStateResponse_StateName
+	Reply isCreateSaveStateResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *CreateSaveStateResponse) Reset() {
+	*x = CreateSaveStateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateSaveStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSaveStateResponse) ProtoMessage() {}
+
+func (x *CreateSaveStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSaveStateResponse.ProtoReflect.Descriptor instead.
+func (*CreateSaveStateResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{15}
+}
+
+func (m *CreateSaveStateResponse) GetReply() isCreateSaveStateResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
 	return nil
 }
 
@@ -772,7 +1056,7 @@ type RemoveSystemStateRequest struct {
 func (x *RemoveSystemStateRequest) Reset() {
 	*x = RemoveSystemStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[12]
+		mi := &file_zsys_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -785,7 +1069,7 @@ func (x *RemoveSystemStateRequest) String() string {
 func (*RemoveSystemStateRequest) ProtoMessage() {}
 
 func (x *RemoveSystemStateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[12]
+	mi := &file_zsys_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -798,7 +1082,7 @@ func (x *RemoveSystemStateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveSystemStateRequest.ProtoReflect.Descriptor instead.
 func (*RemoveSystemStateRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{12}
+	return file_zsys_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *RemoveSystemStateRequest) GetStateName() string {
@@ -836,7 +1120,7 @@ type RemoveUserStateRequest struct {
 func (x *RemoveUserStateRequest) Reset() {
 	*x = RemoveUserStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[13]
+		mi := &file_zsys_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -849,7 +1133,7 @@ func (x *RemoveUserStateRequest) String() string {
 func (*RemoveUserStateRequest) ProtoMessage() {}
 
 func (x *RemoveUserStateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[13]
+	mi := &file_zsys_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -862,7 +1146,7 @@ func (x *RemoveUserStateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveUserStateRequest.ProtoReflect.Descriptor instead.
 func (*RemoveUserStateRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{13}
+	return file_zsys_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *RemoveUserStateRequest) GetUserName() string {
@@ -893,35 +1177,90 @@ func (x *RemoveUserStateRequest) GetDryrun() bool {
 	return false
 }
 
-type DumpStatesResponse struct {
+type ExportStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StateName string `protobuf:"bytes,1,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	From      string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (x *ExportStateRequest) Reset() {
+	*x = ExportStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportStateRequest) ProtoMessage() {}
+
+func (x *ExportStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportStateRequest.ProtoReflect.Descriptor instead.
+func (*ExportStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ExportStateRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *ExportStateRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+type ExportStateResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Reply:
 	//
-	//	*DumpStatesResponse_Log
-	//	*DumpStatesResponse_States
-	Reply isDumpStatesResponse_Reply `protobuf_oneof:"reply"`
+	//	*ExportStateResponse_Log
+	//	*ExportStateResponse_Archive
+	Reply isExportStateResponse_Reply `protobuf_oneof:"reply"`
 }
 
-func (x *DumpStatesResponse) Reset() {
-	*x = DumpStatesResponse{}
+func (x *ExportStateResponse) Reset() {
+	*x = ExportStateResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[14]
+		mi := &file_zsys_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DumpStatesResponse) String() string {
+func (x *ExportStateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DumpStatesResponse) ProtoMessage() {}
+func (*ExportStateResponse) ProtoMessage() {}
 
-func (x *DumpStatesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[14]
+func (x *ExportStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -932,73 +1271,74 @@ func (x *DumpStatesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DumpStatesResponse.ProtoReflect.Descriptor instead.
-func (*DumpStatesResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use ExportStateResponse.ProtoReflect.Descriptor instead.
+func (*ExportStateResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{19}
 }
 
-func (m *DumpStatesResponse) GetReply() isDumpStatesResponse_Reply {
+func (m *ExportStateResponse) GetReply() isExportStateResponse_Reply {
 	if m != nil {
 		return m.Reply
 	}
 	return nil
 }
 
-func (x *DumpStatesResponse) GetLog() string {
-	if x, ok := x.GetReply().(*DumpStatesResponse_Log); ok {
+func (x *ExportStateResponse) GetLog() string {
+	if x, ok := x.GetReply().(*ExportStateResponse_Log); ok {
 		return x.Log
 	}
 	return ""
 }
 
-func (x *DumpStatesResponse) GetStates() string {
-	if x, ok := x.GetReply().(*DumpStatesResponse_States); ok {
-		return x.States
+func (x *ExportStateResponse) GetArchive() []byte {
+	if x, ok := x.GetReply().(*ExportStateResponse_Archive); ok {
+		return x.Archive
 	}
-	return ""
+	return nil
 }
 
-type isDumpStatesResponse_Reply interface {
-	isDumpStatesResponse_Reply()
+type isExportStateResponse_Reply interface {
+	isExportStateResponse_Reply()
 }
 
-type DumpStatesResponse_Log struct {
+type ExportStateResponse_Log struct {
 	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
 }
 
-type DumpStatesResponse_States struct {
-	States string `protobuf:"bytes,2,opt,name=states,proto3,oneof"`
+type ExportStateResponse_Archive struct {
+	Archive []byte `protobuf:"bytes,2,opt,name=archive,proto3,oneof"`
 }
 
-func (*DumpStatesResponse_Log) isDumpStatesResponse_Reply() {}
+func (*ExportStateResponse_Log) isExportStateResponse_Reply() {}
 
-func (*DumpStatesResponse_States) isDumpStatesResponse_Reply() {}
+func (*ExportStateResponse_Archive) isExportStateResponse_Reply() {}
 
-type LoggingLevelRequest struct {
+type ImportStateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Logginglevel int32 `protobuf:"varint,1,opt,name=logginglevel,proto3" json:"logginglevel,omitempty"`
+	AsNewMachine bool   `protobuf:"varint,1,opt,name=asNewMachine,proto3" json:"asNewMachine,omitempty"`
+	Archive      []byte `protobuf:"bytes,2,opt,name=archive,proto3" json:"archive,omitempty"`
 }
 
-func (x *LoggingLevelRequest) Reset() {
-	*x = LoggingLevelRequest{}
+func (x *ImportStateRequest) Reset() {
+	*x = ImportStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[15]
+		mi := &file_zsys_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LoggingLevelRequest) String() string {
+func (x *ImportStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LoggingLevelRequest) ProtoMessage() {}
+func (*ImportStateRequest) ProtoMessage() {}
 
-func (x *LoggingLevelRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[15]
+func (x *ImportStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1009,44 +1349,51 @@ func (x *LoggingLevelRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LoggingLevelRequest.ProtoReflect.Descriptor instead.
-func (*LoggingLevelRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ImportStateRequest.ProtoReflect.Descriptor instead.
+func (*ImportStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *LoggingLevelRequest) GetLogginglevel() int32 {
+func (x *ImportStateRequest) GetAsNewMachine() bool {
 	if x != nil {
-		return x.Logginglevel
+		return x.AsNewMachine
 	}
-	return 0
+	return false
 }
 
-type TraceRequest struct {
+func (x *ImportStateRequest) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+type RestoreUserStateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type     string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
-	Duration int32  `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	UserName  string `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
 }
 
-func (x *TraceRequest) Reset() {
-	*x = TraceRequest{}
+func (x *RestoreUserStateRequest) Reset() {
+	*x = RestoreUserStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[16]
+		mi := &file_zsys_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TraceRequest) String() string {
+func (x *RestoreUserStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TraceRequest) ProtoMessage() {}
+func (*RestoreUserStateRequest) ProtoMessage() {}
 
-func (x *TraceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[16]
+func (x *RestoreUserStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1057,54 +1404,51 @@ func (x *TraceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TraceRequest.ProtoReflect.Descriptor instead.
-func (*TraceRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use RestoreUserStateRequest.ProtoReflect.Descriptor instead.
+func (*RestoreUserStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *TraceRequest) GetType() string {
+func (x *RestoreUserStateRequest) GetUserName() string {
 	if x != nil {
-		return x.Type
+		return x.UserName
 	}
 	return ""
 }
 
-func (x *TraceRequest) GetDuration() int32 {
+func (x *RestoreUserStateRequest) GetStateName() string {
 	if x != nil {
-		return x.Duration
+		return x.StateName
 	}
-	return 0
+	return ""
 }
 
-type TraceResponse struct {
+type RevertDatasetsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Reply:
-	//
-	//	*TraceResponse_Log
-	//	*TraceResponse_Trace
-	Reply isTraceResponse_Reply `protobuf_oneof:"reply"`
+	StateName string   `protobuf:"bytes,1,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Datasets  []string `protobuf:"bytes,2,rep,name=datasets,proto3" json:"datasets,omitempty"`
 }
 
-func (x *TraceResponse) Reset() {
-	*x = TraceResponse{}
+func (x *RevertDatasetsRequest) Reset() {
+	*x = RevertDatasetsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[17]
+		mi := &file_zsys_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TraceResponse) String() string {
+func (x *RevertDatasetsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TraceResponse) ProtoMessage() {}
+func (*RevertDatasetsRequest) ProtoMessage() {}
 
-func (x *TraceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[17]
+func (x *RevertDatasetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1115,73 +1459,117 @@ func (x *TraceResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TraceResponse.ProtoReflect.Descriptor instead.
-func (*TraceResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{17}
-}
-
-func (m *TraceResponse) GetReply() isTraceResponse_Reply {
-	if m != nil {
-		return m.Reply
-	}
-	return nil
+// Deprecated: Use RevertDatasetsRequest.ProtoReflect.Descriptor instead.
+func (*RevertDatasetsRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *TraceResponse) GetLog() string {
-	if x, ok := x.GetReply().(*TraceResponse_Log); ok {
-		return x.Log
+func (x *RevertDatasetsRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
 	}
 	return ""
 }
 
-func (x *TraceResponse) GetTrace() []byte {
-	if x, ok := x.GetReply().(*TraceResponse_Trace); ok {
-		return x.Trace
+func (x *RevertDatasetsRequest) GetDatasets() []string {
+	if x != nil {
+		return x.Datasets
 	}
 	return nil
 }
 
-type isTraceResponse_Reply interface {
-	isTraceResponse_Reply()
+type StatePathRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserName  string `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Path      string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
 }
 
-type TraceResponse_Log struct {
-	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+func (x *StatePathRequest) Reset() {
+	*x = StatePathRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-type TraceResponse_Trace struct {
-	Trace []byte `protobuf:"bytes,2,opt,name=trace,proto3,oneof"`
+func (x *StatePathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TraceResponse_Log) isTraceResponse_Reply() {}
+func (*StatePathRequest) ProtoMessage() {}
 
-func (*TraceResponse_Trace) isTraceResponse_Reply() {}
+func (x *StatePathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-type GCRequest struct {
+// Deprecated: Use StatePathRequest.ProtoReflect.Descriptor instead.
+func (*StatePathRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StatePathRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *StatePathRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *StatePathRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type StatePathResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	All bool `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	// Types that are assignable to Reply:
+	//
+	//	*StatePathResponse_Log
+	//	*StatePathResponse_Path
+	Reply isStatePathResponse_Reply `protobuf_oneof:"reply"`
 }
 
-func (x *GCRequest) Reset() {
-	*x = GCRequest{}
+func (x *StatePathResponse) Reset() {
+	*x = StatePathResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[18]
+		mi := &file_zsys_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GCRequest) String() string {
+func (x *StatePathResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GCRequest) ProtoMessage() {}
+func (*StatePathResponse) ProtoMessage() {}
 
-func (x *GCRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[18]
+func (x *StatePathResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1192,44 +1580,75 @@ func (x *GCRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GCRequest.ProtoReflect.Descriptor instead.
-func (*GCRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use StatePathResponse.ProtoReflect.Descriptor instead.
+func (*StatePathResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *GCRequest) GetAll() bool {
-	if x != nil {
-		return x.All
+func (m *StatePathResponse) GetReply() isStatePathResponse_Reply {
+	if m != nil {
+		return m.Reply
 	}
-	return false
+	return nil
 }
 
-type MachineShowRequest struct {
+func (x *StatePathResponse) GetLog() string {
+	if x, ok := x.GetReply().(*StatePathResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *StatePathResponse) GetPath() string {
+	if x, ok := x.GetReply().(*StatePathResponse_Path); ok {
+		return x.Path
+	}
+	return ""
+}
+
+type isStatePathResponse_Reply interface {
+	isStatePathResponse_Reply()
+}
+
+type StatePathResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type StatePathResponse_Path struct {
+	Path string `protobuf:"bytes,2,opt,name=path,proto3,oneof"`
+}
+
+func (*StatePathResponse_Log) isStatePathResponse_Reply() {}
+
+func (*StatePathResponse_Path) isStatePathResponse_Reply() {}
+
+type StateDiffRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MachineId string `protobuf:"bytes,1,opt,name=machineId,proto3" json:"machineId,omitempty"`
-	Full      bool   `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`
+	StateA   string `protobuf:"bytes,1,opt,name=stateA,proto3" json:"stateA,omitempty"`
+	StateB   string `protobuf:"bytes,2,opt,name=stateB,proto3" json:"stateB,omitempty"`
+	UserName string `protobuf:"bytes,3,opt,name=userName,proto3" json:"userName,omitempty"`
 }
 
-func (x *MachineShowRequest) Reset() {
-	*x = MachineShowRequest{}
+func (x *StateDiffRequest) Reset() {
+	*x = StateDiffRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[19]
+		mi := &file_zsys_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MachineShowRequest) String() string {
+func (x *StateDiffRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MachineShowRequest) ProtoMessage() {}
+func (*StateDiffRequest) ProtoMessage() {}
 
-func (x *MachineShowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[19]
+func (x *StateDiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1240,54 +1659,61 @@ func (x *MachineShowRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MachineShowRequest.ProtoReflect.Descriptor instead.
-func (*MachineShowRequest) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use StateDiffRequest.ProtoReflect.Descriptor instead.
+func (*StateDiffRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *MachineShowRequest) GetMachineId() string {
+func (x *StateDiffRequest) GetStateA() string {
 	if x != nil {
-		return x.MachineId
+		return x.StateA
 	}
 	return ""
 }
 
-func (x *MachineShowRequest) GetFull() bool {
+func (x *StateDiffRequest) GetStateB() string {
 	if x != nil {
-		return x.Full
+		return x.StateB
 	}
-	return false
+	return ""
 }
 
-type MachineShowResponse struct {
+func (x *StateDiffRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+type StateDiffResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Reply:
 	//
-	//	*MachineShowResponse_Log
-	//	*MachineShowResponse_MachineInfo
-	Reply isMachineShowResponse_Reply `protobuf_oneof:"reply"`
+	//	*StateDiffResponse_Log
+	//	*StateDiffResponse_Diff
+	Reply isStateDiffResponse_Reply `protobuf_oneof:"reply"`
 }
 
-func (x *MachineShowResponse) Reset() {
-	*x = MachineShowResponse{}
+func (x *StateDiffResponse) Reset() {
+	*x = StateDiffResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[20]
+		mi := &file_zsys_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MachineShowResponse) String() string {
+func (x *StateDiffResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MachineShowResponse) ProtoMessage() {}
+func (*StateDiffResponse) ProtoMessage() {}
 
-func (x *MachineShowResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[20]
+func (x *StateDiffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1298,420 +1724,2300 @@ func (x *MachineShowResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MachineShowResponse.ProtoReflect.Descriptor instead.
-func (*MachineShowResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use StateDiffResponse.ProtoReflect.Descriptor instead.
+func (*StateDiffResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{26}
 }
 
-func (m *MachineShowResponse) GetReply() isMachineShowResponse_Reply {
+func (m *StateDiffResponse) GetReply() isStateDiffResponse_Reply {
 	if m != nil {
 		return m.Reply
 	}
 	return nil
 }
 
-func (x *MachineShowResponse) GetLog() string {
-	if x, ok := x.GetReply().(*MachineShowResponse_Log); ok {
+func (x *StateDiffResponse) GetLog() string {
+	if x, ok := x.GetReply().(*StateDiffResponse_Log); ok {
 		return x.Log
 	}
 	return ""
 }
 
-func (x *MachineShowResponse) GetMachineInfo() string {
-	if x, ok := x.GetReply().(*MachineShowResponse_MachineInfo); ok {
-		return x.MachineInfo
+func (x *StateDiffResponse) GetDiff() string {
+	if x, ok := x.GetReply().(*StateDiffResponse_Diff); ok {
+		return x.Diff
 	}
 	return ""
 }
 
-type isMachineShowResponse_Reply interface {
-	isMachineShowResponse_Reply()
+type isStateDiffResponse_Reply interface {
+	isStateDiffResponse_Reply()
 }
 
-type MachineShowResponse_Log struct {
+type StateDiffResponse_Log struct {
 	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
 }
 
-type MachineShowResponse_MachineInfo struct {
-	MachineInfo string `protobuf:"bytes,2,opt,name=machineInfo,proto3,oneof"`
+type StateDiffResponse_Diff struct {
+	Diff string `protobuf:"bytes,2,opt,name=diff,proto3,oneof"`
 }
 
-func (*MachineShowResponse_Log) isMachineShowResponse_Reply() {}
+func (*StateDiffResponse_Log) isStateDiffResponse_Reply() {}
 
-func (*MachineShowResponse_MachineInfo) isMachineShowResponse_Reply() {}
+func (*StateDiffResponse_Diff) isStateDiffResponse_Reply() {}
 
-type MachineListResponse struct {
+type TagStateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Reply:
-	//
-	//	*MachineListResponse_Log
-	//	*MachineListResponse_MachineList
-	Reply isMachineListResponse_Reply `protobuf_oneof:"reply"`
+	UserName  string            `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string            `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Tags      map[string]string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *MachineListResponse) Reset() {
-	*x = MachineListResponse{}
+func (x *TagStateRequest) Reset() {
+	*x = TagStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_zsys_proto_msgTypes[21]
+		mi := &file_zsys_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MachineListResponse) String() string {
+func (x *TagStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MachineListResponse) ProtoMessage() {}
+func (*TagStateRequest) ProtoMessage() {}
 
-func (x *MachineListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_zsys_proto_msgTypes[21]
+func (x *TagStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagStateRequest.ProtoReflect.Descriptor instead.
+func (*TagStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TagStateRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *TagStateRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *TagStateRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type UntagStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserName  string   `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string   `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Tags      []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *UntagStateRequest) Reset() {
+	*x = UntagStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UntagStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UntagStateRequest) ProtoMessage() {}
+
+func (x *UntagStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UntagStateRequest.ProtoReflect.Descriptor instead.
+func (*UntagStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UntagStateRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *UntagStateRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *UntagStateRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type PinStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserName  string `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+	Pin       bool   `protobuf:"varint,3,opt,name=pin,proto3" json:"pin,omitempty"`
+}
+
+func (x *PinStateRequest) Reset() {
+	*x = PinStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PinStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinStateRequest) ProtoMessage() {}
+
+func (x *PinStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use MachineListResponse.ProtoReflect.Descriptor instead.
-func (*MachineListResponse) Descriptor() ([]byte, []int) {
-	return file_zsys_proto_rawDescGZIP(), []int{21}
-}
-
-func (m *MachineListResponse) GetReply() isMachineListResponse_Reply {
-	if m != nil {
-		return m.Reply
-	}
-	return nil
-}
-
-func (x *MachineListResponse) GetLog() string {
-	if x, ok := x.GetReply().(*MachineListResponse_Log); ok {
-		return x.Log
-	}
-	return ""
-}
-
-func (x *MachineListResponse) GetMachineList() string {
-	if x, ok := x.GetReply().(*MachineListResponse_MachineList); ok {
-		return x.MachineList
-	}
-	return ""
-}
-
-type isMachineListResponse_Reply interface {
-	isMachineListResponse_Reply()
-}
-
-type MachineListResponse_Log struct {
-	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
-}
-
-type MachineListResponse_MachineList struct {
-	MachineList string `protobuf:"bytes,2,opt,name=machineList,proto3,oneof"`
-}
-
-func (*MachineListResponse_Log) isMachineListResponse_Reply() {}
-
-func (*MachineListResponse_MachineList) isMachineListResponse_Reply() {}
-
-var File_zsys_proto protoreflect.FileDescriptor
-
-var file_zsys_proto_rawDesc = []byte{
-	0x0a, 0x0a, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x7a, 0x73,
-	0x79, 0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x1f, 0x0a, 0x0b, 0x4c,
-	0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f,
-	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x22, 0x4a, 0x0a, 0x0f,
-	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03,
-	0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42,
-	0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x47, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x6d, 0x65, 0x70, 0x61, 0x74,
-	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x6d, 0x65, 0x70, 0x61, 0x74,
-	0x68, 0x22, 0x4b, 0x0a, 0x1b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f,
-	0x6e, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x68, 0x6f, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x6d, 0x65, 0x22, 0x4b,
-	0x0a, 0x15, 0x44, 0x69, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x72,
-	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x22, 0x4e, 0x0a, 0x13, 0x50,
-	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48,
-	0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67,
-	0x65, 0x64, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x4d, 0x0a, 0x12, 0x43,
-	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
-	0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
-	0x64, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x2b, 0x0a, 0x15, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x6f, 0x22, 0x7a, 0x0a, 0x16, 0x53, 0x61, 0x76, 0x65, 0x53,
-	0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x26, 0x0a, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e,
-	0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42,
-	0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x75, 0x74, 0x6f, 0x73,
-	0x61, 0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x75, 0x74, 0x6f, 0x73,
-	0x61, 0x76, 0x65, 0x22, 0x50, 0x0a, 0x14, 0x53, 0x61, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75,
-	0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75,
-	0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74,
-	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x56, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53,
-	0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
-	0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1e, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x4e, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x66, 0x0a,
-	0x18, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61,
-	0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74,
-	0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x64, 0x72, 0x79, 0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64,
-	0x72, 0x79, 0x72, 0x75, 0x6e, 0x22, 0x80, 0x01, 0x0a, 0x16, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
-	0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f,
-	0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65,
-	0x12, 0x16, 0x0a, 0x06, 0x64, 0x72, 0x79, 0x72, 0x75, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x06, 0x64, 0x72, 0x79, 0x72, 0x75, 0x6e, 0x22, 0x4b, 0x0a, 0x12, 0x44, 0x75, 0x6d, 0x70,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12,
-	0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c,
-	0x6f, 0x67, 0x12, 0x18, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05,
-	0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x39, 0x0a, 0x13, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67,
-	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0c,
-	0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x76, 0x65, 0x6c,
-	0x22, 0x3e, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x22, 0x44, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
-	0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x16, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x42, 0x07, 0x0a,
-	0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x1d, 0x0a, 0x09, 0x47, 0x43, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x03, 0x61, 0x6c, 0x6c, 0x22, 0x46, 0x0a, 0x12, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
-	0x53, 0x68, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6d,
-	0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x75, 0x6c,
-	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x66, 0x75, 0x6c, 0x6c, 0x22, 0x56, 0x0a,
-	0x13, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x22, 0x0a, 0x0b, 0x6d, 0x61, 0x63, 0x68,
-	0x69, 0x6e, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
-	0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x42, 0x07, 0x0a, 0x05,
-	0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x56, 0x0a, 0x13, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
-	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03,
-	0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67,
-	0x12, 0x22, 0x0a, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
-	0x4c, 0x69, 0x73, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x32, 0xb2, 0x0a,
-	0x0a, 0x04, 0x5a, 0x73, 0x79, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
-	0x6e, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15,
-	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1b, 0x2e, 0x7a, 0x73, 0x79, 0x73,
-	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f,
-	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4e, 0x0a, 0x14, 0x43,
-	0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x44,
-	0x61, 0x74, 0x61, 0x12, 0x21, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67,
-	0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f,
-	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0e, 0x44,
-	0x69, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x1b, 0x2e,
-	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x44, 0x69, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x55,
-	0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79,
-	0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
-	0x37, 0x0a, 0x0b, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x0b,
-	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x19, 0x2e, 0x7a, 0x73,
-	0x79, 0x73, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x35, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x6d,
-	0x69, 0x74, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
-	0x74, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
-	0x42, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e,
-	0x75, 0x12, 0x1b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42,
-	0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
-	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x61, 0x73,
-	0x74, 0x55, 0x73, 0x65, 0x64, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x0f, 0x53, 0x61, 0x76, 0x65, 0x53,
-	0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x7a, 0x73, 0x79,
-	0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4c, 0x0a, 0x0d, 0x53, 0x61, 0x76,
-	0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x2e, 0x7a, 0x73, 0x79,
-	0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x48, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76,
-	0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1e, 0x2e, 0x7a,
-	0x73, 0x79, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a,
-	0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
-	0x01, 0x12, 0x44, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f,
-	0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x35, 0x0a, 0x0a, 0x44, 0x75, 0x6d, 0x70, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x53, 0x74,
-	0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2e,
-	0x0a, 0x0a, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x0b, 0x2e, 0x7a,
-	0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73,
-	0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x3e,
-	0x0a, 0x0c, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x19,
-	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76,
-	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73,
-	0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2b,
-	0x0a, 0x07, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f,
-	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x05, 0x54,
-	0x72, 0x61, 0x63, 0x65, 0x12, 0x12, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
-	0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
-	0x2a, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f,
-	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2a, 0x0a, 0x06, 0x52,
-	0x65, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2a, 0x0a, 0x02, 0x47, 0x43, 0x12, 0x0f, 0x2e,
-	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
-	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0b, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68,
-	0x6f, 0x77, 0x12, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e,
-	0x65, 0x53, 0x68, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x7a,
-	0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x37, 0x0a, 0x0b, 0x4d, 0x61, 0x63,
-	0x68, 0x69, 0x6e, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63,
-	0x68, 0x69, 0x6e, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x30, 0x01, 0x42, 0x18, 0x5a, 0x16, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x75, 0x62, 0x75, 0x6e, 0x74, 0x75, 0x2f, 0x7a, 0x73, 0x79, 0x73, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_zsys_proto_rawDescOnce sync.Once
-	file_zsys_proto_rawDescData = file_zsys_proto_rawDesc
-)
-
-func file_zsys_proto_rawDescGZIP() []byte {
-	file_zsys_proto_rawDescOnce.Do(func() {
-		file_zsys_proto_rawDescData = protoimpl.X.CompressGZIP(file_zsys_proto_rawDescData)
-	})
-	return file_zsys_proto_rawDescData
-}
-
-var file_zsys_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
-var file_zsys_proto_goTypes = []interface{}{
-	(*Empty)(nil),                       // 0: zsys.Empty
-	(*LogResponse)(nil),                 // 1: zsys.LogResponse
-	(*VersionResponse)(nil),             // 2: zsys.VersionResponse
-	(*CreateUserDataRequest)(nil),       // 3: zsys.CreateUserDataRequest
-	(*ChangeHomeOnUserDataRequest)(nil), // 4: zsys.ChangeHomeOnUserDataRequest
-	(*DissociateUserRequest)(nil),       // 5: zsys.DissociateUserRequest
-	(*PrepareBootResponse)(nil),         // 6: zsys.PrepareBootResponse
-	(*CommitBootResponse)(nil),          // 7: zsys.CommitBootResponse
-	(*UpdateBootMenuRequest)(nil),       // 8: zsys.UpdateBootMenuRequest
-	(*SaveSystemStateRequest)(nil),      // 9: zsys.SaveSystemStateRequest
-	(*SaveUserStateRequest)(nil),        // 10: zsys.SaveUserStateRequest
-	(*CreateSaveStateResponse)(nil),     // 11: zsys.CreateSaveStateResponse
-	(*RemoveSystemStateRequest)(nil),    // 12: zsys.RemoveSystemStateRequest
-	(*RemoveUserStateRequest)(nil),      // 13: zsys.RemoveUserStateRequest
-	(*DumpStatesResponse)(nil),          // 14: zsys.DumpStatesResponse
-	(*LoggingLevelRequest)(nil),         // 15: zsys.LoggingLevelRequest
-	(*TraceRequest)(nil),                // 16: zsys.TraceRequest
-	(*TraceResponse)(nil),               // 17: zsys.TraceResponse
-	(*GCRequest)(nil),                   // 18: zsys.GCRequest
-	(*MachineShowRequest)(nil),          // 19: zsys.MachineShowRequest
-	(*MachineShowResponse)(nil),         // 20: zsys.MachineShowResponse
-	(*MachineListResponse)(nil),         // 21: zsys.MachineListResponse
-}
-var file_zsys_proto_depIdxs = []int32{
-	0,  // 0: zsys.Zsys.Version:input_type -> zsys.Empty
-	3,  // 1: zsys.Zsys.CreateUserData:input_type -> zsys.CreateUserDataRequest
-	4,  // 2: zsys.Zsys.ChangeHomeOnUserData:input_type -> zsys.ChangeHomeOnUserDataRequest
-	5,  // 3: zsys.Zsys.DissociateUser:input_type -> zsys.DissociateUserRequest
-	0,  // 4: zsys.Zsys.PrepareBoot:input_type -> zsys.Empty
-	0,  // 5: zsys.Zsys.CommitBoot:input_type -> zsys.Empty
-	8,  // 6: zsys.Zsys.UpdateBootMenu:input_type -> zsys.UpdateBootMenuRequest
-	0,  // 7: zsys.Zsys.UpdateLastUsed:input_type -> zsys.Empty
-	9,  // 8: zsys.Zsys.SaveSystemState:input_type -> zsys.SaveSystemStateRequest
-	10, // 9: zsys.Zsys.SaveUserState:input_type -> zsys.SaveUserStateRequest
-	12, // 10: zsys.Zsys.RemoveSystemState:input_type -> zsys.RemoveSystemStateRequest
-	13, // 11: zsys.Zsys.RemoveUserState:input_type -> zsys.RemoveUserStateRequest
-	0,  // 12: zsys.Zsys.DumpStates:input_type -> zsys.Empty
-	0,  // 13: zsys.Zsys.DaemonStop:input_type -> zsys.Empty
-	15, // 14: zsys.Zsys.LoggingLevel:input_type -> zsys.LoggingLevelRequest
-	0,  // 15: zsys.Zsys.Refresh:input_type -> zsys.Empty
-	16, // 16: zsys.Zsys.Trace:input_type -> zsys.TraceRequest
-	0,  // 17: zsys.Zsys.Status:input_type -> zsys.Empty
-	0,  // 18: zsys.Zsys.Reload:input_type -> zsys.Empty
-	18, // 19: zsys.Zsys.GC:input_type -> zsys.GCRequest
-	19, // 20: zsys.Zsys.MachineShow:input_type -> zsys.MachineShowRequest
-	0,  // 21: zsys.Zsys.MachineList:input_type -> zsys.Empty
-	2,  // 22: zsys.Zsys.Version:output_type -> zsys.VersionResponse
-	1,  // 23: zsys.Zsys.CreateUserData:output_type -> zsys.LogResponse
-	1,  // 24: zsys.Zsys.ChangeHomeOnUserData:output_type -> zsys.LogResponse
-	1,  // 25: zsys.Zsys.DissociateUser:output_type -> zsys.LogResponse
-	6,  // 26: zsys.Zsys.PrepareBoot:output_type -> zsys.PrepareBootResponse
-	7,  // 27: zsys.Zsys.CommitBoot:output_type -> zsys.CommitBootResponse
-	1,  // 28: zsys.Zsys.UpdateBootMenu:output_type -> zsys.LogResponse
-	1,  // 29: zsys.Zsys.UpdateLastUsed:output_type -> zsys.LogResponse
-	11, // 30: zsys.Zsys.SaveSystemState:output_type -> zsys.CreateSaveStateResponse
-	11, // 31: zsys.Zsys.SaveUserState:output_type -> zsys.CreateSaveStateResponse
-	1,  // 32: zsys.Zsys.RemoveSystemState:output_type -> zsys.LogResponse
-	1,  // 33: zsys.Zsys.RemoveUserState:output_type -> zsys.LogResponse
-	14, // 34: zsys.Zsys.DumpStates:output_type -> zsys.DumpStatesResponse
-	1,  // 35: zsys.Zsys.DaemonStop:output_type -> zsys.LogResponse
-	1,  // 36: zsys.Zsys.LoggingLevel:output_type -> zsys.LogResponse
-	1,  // 37: zsys.Zsys.Refresh:output_type -> zsys.LogResponse
-	17, // 38: zsys.Zsys.Trace:output_type -> zsys.TraceResponse
-	1,  // 39: zsys.Zsys.Status:output_type -> zsys.LogResponse
-	1,  // 40: zsys.Zsys.Reload:output_type -> zsys.LogResponse
-	1,  // 41: zsys.Zsys.GC:output_type -> zsys.LogResponse
-	20, // 42: zsys.Zsys.MachineShow:output_type -> zsys.MachineShowResponse
-	21, // 43: zsys.Zsys.MachineList:output_type -> zsys.MachineListResponse
-	22, // [22:44] is the sub-list for method output_type
-	0,  // [0:22] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
-}
-
-func init() { file_zsys_proto_init() }
-func file_zsys_proto_init() {
-	if File_zsys_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_zsys_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Empty); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinStateRequest.ProtoReflect.Descriptor instead.
+func (*PinStateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PinStateRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *PinStateRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+func (x *PinStateRequest) GetPin() bool {
+	if x != nil {
+		return x.Pin
+	}
+	return false
+}
+
+type StateUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserName  string `protobuf:"bytes,1,opt,name=userName,proto3" json:"userName,omitempty"`
+	StateName string `protobuf:"bytes,2,opt,name=stateName,proto3" json:"stateName,omitempty"`
+}
+
+func (x *StateUsageRequest) Reset() {
+	*x = StateUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StateUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateUsageRequest) ProtoMessage() {}
+
+func (x *StateUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateUsageRequest.ProtoReflect.Descriptor instead.
+func (*StateUsageRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StateUsageRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *StateUsageRequest) GetStateName() string {
+	if x != nil {
+		return x.StateName
+	}
+	return ""
+}
+
+type StateUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Unique uint64 `protobuf:"varint,1,opt,name=unique,proto3" json:"unique,omitempty"`
+	Shared uint64 `protobuf:"varint,2,opt,name=shared,proto3" json:"shared,omitempty"`
+	Freed  uint64 `protobuf:"varint,3,opt,name=freed,proto3" json:"freed,omitempty"`
+}
+
+func (x *StateUsage) Reset() {
+	*x = StateUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StateUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateUsage) ProtoMessage() {}
+
+func (x *StateUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateUsage.ProtoReflect.Descriptor instead.
+func (*StateUsage) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *StateUsage) GetUnique() uint64 {
+	if x != nil {
+		return x.Unique
+	}
+	return 0
+}
+
+func (x *StateUsage) GetShared() uint64 {
+	if x != nil {
+		return x.Shared
+	}
+	return 0
+}
+
+func (x *StateUsage) GetFreed() uint64 {
+	if x != nil {
+		return x.Freed
+	}
+	return 0
+}
+
+type StateUsageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*StateUsageResponse_Log
+	//	*StateUsageResponse_Usage
+	Reply isStateUsageResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *StateUsageResponse) Reset() {
+	*x = StateUsageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StateUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateUsageResponse) ProtoMessage() {}
+
+func (x *StateUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateUsageResponse.ProtoReflect.Descriptor instead.
+func (*StateUsageResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{32}
+}
+
+func (m *StateUsageResponse) GetReply() isStateUsageResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *StateUsageResponse) GetLog() string {
+	if x, ok := x.GetReply().(*StateUsageResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *StateUsageResponse) GetUsage() *StateUsage {
+	if x, ok := x.GetReply().(*StateUsageResponse_Usage); ok {
+		return x.Usage
+	}
+	return nil
+}
+
+type isStateUsageResponse_Reply interface {
+	isStateUsageResponse_Reply()
+}
+
+type StateUsageResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type StateUsageResponse_Usage struct {
+	Usage *StateUsage `protobuf:"bytes,2,opt,name=usage,proto3,oneof"`
+}
+
+func (*StateUsageResponse_Log) isStateUsageResponse_Reply() {}
+
+func (*StateUsageResponse_Usage) isStateUsageResponse_Reply() {}
+
+type DumpStatesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*DumpStatesResponse_Log
+	//	*DumpStatesResponse_States
+	Reply isDumpStatesResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *DumpStatesResponse) Reset() {
+	*x = DumpStatesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DumpStatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpStatesResponse) ProtoMessage() {}
+
+func (x *DumpStatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpStatesResponse.ProtoReflect.Descriptor instead.
+func (*DumpStatesResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{33}
+}
+
+func (m *DumpStatesResponse) GetReply() isDumpStatesResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *DumpStatesResponse) GetLog() string {
+	if x, ok := x.GetReply().(*DumpStatesResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *DumpStatesResponse) GetStates() string {
+	if x, ok := x.GetReply().(*DumpStatesResponse_States); ok {
+		return x.States
+	}
+	return ""
+}
+
+type isDumpStatesResponse_Reply interface {
+	isDumpStatesResponse_Reply()
+}
+
+type DumpStatesResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type DumpStatesResponse_States struct {
+	States string `protobuf:"bytes,2,opt,name=states,proto3,oneof"`
+}
+
+func (*DumpStatesResponse_Log) isDumpStatesResponse_Reply() {}
+
+func (*DumpStatesResponse_States) isDumpStatesResponse_Reply() {}
+
+type LoggingLevelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Logginglevel int32 `protobuf:"varint,1,opt,name=logginglevel,proto3" json:"logginglevel,omitempty"`
+}
+
+func (x *LoggingLevelRequest) Reset() {
+	*x = LoggingLevelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoggingLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoggingLevelRequest) ProtoMessage() {}
+
+func (x *LoggingLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoggingLevelRequest.ProtoReflect.Descriptor instead.
+func (*LoggingLevelRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *LoggingLevelRequest) GetLogginglevel() int32 {
+	if x != nil {
+		return x.Logginglevel
+	}
+	return 0
+}
+
+type TraceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type     string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Duration int32  `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+}
+
+func (x *TraceRequest) Reset() {
+	*x = TraceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TraceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceRequest) ProtoMessage() {}
+
+func (x *TraceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceRequest.ProtoReflect.Descriptor instead.
+func (*TraceRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *TraceRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TraceRequest) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type TraceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*TraceResponse_Log
+	//	*TraceResponse_Trace
+	Reply isTraceResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *TraceResponse) Reset() {
+	*x = TraceResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TraceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceResponse) ProtoMessage() {}
+
+func (x *TraceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceResponse.ProtoReflect.Descriptor instead.
+func (*TraceResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{36}
+}
+
+func (m *TraceResponse) GetReply() isTraceResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *TraceResponse) GetLog() string {
+	if x, ok := x.GetReply().(*TraceResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *TraceResponse) GetTrace() []byte {
+	if x, ok := x.GetReply().(*TraceResponse_Trace); ok {
+		return x.Trace
+	}
+	return nil
+}
+
+type isTraceResponse_Reply interface {
+	isTraceResponse_Reply()
+}
+
+type TraceResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type TraceResponse_Trace struct {
+	Trace []byte `protobuf:"bytes,2,opt,name=trace,proto3,oneof"`
+}
+
+func (*TraceResponse_Log) isTraceResponse_Reply() {}
+
+func (*TraceResponse_Trace) isTraceResponse_Reply() {}
+
+type GCRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	All   bool `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	Space bool `protobuf:"varint,2,opt,name=space,proto3" json:"space,omitempty"`
+}
+
+func (x *GCRequest) Reset() {
+	*x = GCRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCRequest) ProtoMessage() {}
+
+func (x *GCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCRequest.ProtoReflect.Descriptor instead.
+func (*GCRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GCRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *GCRequest) GetSpace() bool {
+	if x != nil {
+		return x.Space
+	}
+	return false
+}
+
+type GCDecision struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Machine  string `protobuf:"bytes,2,opt,name=machine,proto3" json:"machine,omitempty"`
+	User     string `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	LastUsed int64  `protobuf:"varint,4,opt,name=lastUsed,proto3" json:"lastUsed,omitempty"`
+	Bucket   string `protobuf:"bytes,5,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Remove   bool   `protobuf:"varint,6,opt,name=remove,proto3" json:"remove,omitempty"`
+	Reason   string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *GCDecision) Reset() {
+	*x = GCDecision{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCDecision) ProtoMessage() {}
+
+func (x *GCDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCDecision.ProtoReflect.Descriptor instead.
+func (*GCDecision) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GCDecision) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GCDecision) GetMachine() string {
+	if x != nil {
+		return x.Machine
+	}
+	return ""
+}
+
+func (x *GCDecision) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GCDecision) GetLastUsed() int64 {
+	if x != nil {
+		return x.LastUsed
+	}
+	return 0
+}
+
+func (x *GCDecision) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *GCDecision) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+func (x *GCDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GCPlan struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*GCDecision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+}
+
+func (x *GCPlan) Reset() {
+	*x = GCPlan{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCPlan) ProtoMessage() {}
+
+func (x *GCPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCPlan.ProtoReflect.Descriptor instead.
+func (*GCPlan) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GCPlan) GetDecisions() []*GCDecision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+type GCPlanResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*GCPlanResponse_Log
+	//	*GCPlanResponse_Plan
+	Reply isGCPlanResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *GCPlanResponse) Reset() {
+	*x = GCPlanResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCPlanResponse) ProtoMessage() {}
+
+func (x *GCPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCPlanResponse.ProtoReflect.Descriptor instead.
+func (*GCPlanResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{40}
+}
+
+func (m *GCPlanResponse) GetReply() isGCPlanResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *GCPlanResponse) GetLog() string {
+	if x, ok := x.GetReply().(*GCPlanResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *GCPlanResponse) GetPlan() *GCPlan {
+	if x, ok := x.GetReply().(*GCPlanResponse_Plan); ok {
+		return x.Plan
+	}
+	return nil
+}
+
+type isGCPlanResponse_Reply interface {
+	isGCPlanResponse_Reply()
+}
+
+type GCPlanResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type GCPlanResponse_Plan struct {
+	Plan *GCPlan `protobuf:"bytes,2,opt,name=plan,proto3,oneof"`
+}
+
+func (*GCPlanResponse_Log) isGCPlanResponse_Reply() {}
+
+func (*GCPlanResponse_Plan) isGCPlanResponse_Reply() {}
+
+type GCSimulateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config        []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	Days          int32  `protobuf:"varint,2,opt,name=days,proto3" json:"days,omitempty"`
+	SnapshotEvery int64  `protobuf:"varint,3,opt,name=snapshotEvery,proto3" json:"snapshotEvery,omitempty"`
+	All           bool   `protobuf:"varint,4,opt,name=all,proto3" json:"all,omitempty"`
+}
+
+func (x *GCSimulateRequest) Reset() {
+	*x = GCSimulateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCSimulateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCSimulateRequest) ProtoMessage() {}
+
+func (x *GCSimulateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCSimulateRequest.ProtoReflect.Descriptor instead.
+func (*GCSimulateRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GCSimulateRequest) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *GCSimulateRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *GCSimulateRequest) GetSnapshotEvery() int64 {
+	if x != nil {
+		return x.SnapshotEvery
+	}
+	return 0
+}
+
+func (x *GCSimulateRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type GCSimulationDay struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date       int64    `protobuf:"varint,1,opt,name=date,proto3" json:"date,omitempty"`
+	Created    int32    `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Removed    int32    `protobuf:"varint,3,opt,name=removed,proto3" json:"removed,omitempty"`
+	States     []string `protobuf:"bytes,4,rep,name=states,proto3" json:"states,omitempty"`
+	UserStates int32    `protobuf:"varint,5,opt,name=userStates,proto3" json:"userStates,omitempty"`
+}
+
+func (x *GCSimulationDay) Reset() {
+	*x = GCSimulationDay{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCSimulationDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCSimulationDay) ProtoMessage() {}
+
+func (x *GCSimulationDay) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCSimulationDay.ProtoReflect.Descriptor instead.
+func (*GCSimulationDay) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GCSimulationDay) GetDate() int64 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+func (x *GCSimulationDay) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *GCSimulationDay) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *GCSimulationDay) GetStates() []string {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *GCSimulationDay) GetUserStates() int32 {
+	if x != nil {
+		return x.UserStates
+	}
+	return 0
+}
+
+type GCSimulation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Days []*GCSimulationDay `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+}
+
+func (x *GCSimulation) Reset() {
+	*x = GCSimulation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCSimulation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCSimulation) ProtoMessage() {}
+
+func (x *GCSimulation) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCSimulation.ProtoReflect.Descriptor instead.
+func (*GCSimulation) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GCSimulation) GetDays() []*GCSimulationDay {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+type GCSimulateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*GCSimulateResponse_Log
+	//	*GCSimulateResponse_Simulation
+	Reply isGCSimulateResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *GCSimulateResponse) Reset() {
+	*x = GCSimulateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCSimulateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCSimulateResponse) ProtoMessage() {}
+
+func (x *GCSimulateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCSimulateResponse.ProtoReflect.Descriptor instead.
+func (*GCSimulateResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{44}
+}
+
+func (m *GCSimulateResponse) GetReply() isGCSimulateResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *GCSimulateResponse) GetLog() string {
+	if x, ok := x.GetReply().(*GCSimulateResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *GCSimulateResponse) GetSimulation() *GCSimulation {
+	if x, ok := x.GetReply().(*GCSimulateResponse_Simulation); ok {
+		return x.Simulation
+	}
+	return nil
+}
+
+type isGCSimulateResponse_Reply interface {
+	isGCSimulateResponse_Reply()
+}
+
+type GCSimulateResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type GCSimulateResponse_Simulation struct {
+	Simulation *GCSimulation `protobuf:"bytes,2,opt,name=simulation,proto3,oneof"`
+}
+
+func (*GCSimulateResponse_Log) isGCSimulateResponse_Reply() {}
+
+func (*GCSimulateResponse_Simulation) isGCSimulateResponse_Reply() {}
+
+type MachineShowRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MachineId string            `protobuf:"bytes,1,opt,name=machineId,proto3" json:"machineId,omitempty"`
+	Full      bool              `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`
+	Tags      map[string]string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *MachineShowRequest) Reset() {
+	*x = MachineShowRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MachineShowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineShowRequest) ProtoMessage() {}
+
+func (x *MachineShowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineShowRequest.ProtoReflect.Descriptor instead.
+func (*MachineShowRequest) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *MachineShowRequest) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineShowRequest) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *MachineShowRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type MachineShowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*MachineShowResponse_Log
+	//	*MachineShowResponse_MachineInfo
+	Reply isMachineShowResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *MachineShowResponse) Reset() {
+	*x = MachineShowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MachineShowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineShowResponse) ProtoMessage() {}
+
+func (x *MachineShowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineShowResponse.ProtoReflect.Descriptor instead.
+func (*MachineShowResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{46}
+}
+
+func (m *MachineShowResponse) GetReply() isMachineShowResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *MachineShowResponse) GetLog() string {
+	if x, ok := x.GetReply().(*MachineShowResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *MachineShowResponse) GetMachineInfo() string {
+	if x, ok := x.GetReply().(*MachineShowResponse_MachineInfo); ok {
+		return x.MachineInfo
+	}
+	return ""
+}
+
+type isMachineShowResponse_Reply interface {
+	isMachineShowResponse_Reply()
+}
+
+type MachineShowResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type MachineShowResponse_MachineInfo struct {
+	MachineInfo string `protobuf:"bytes,2,opt,name=machineInfo,proto3,oneof"`
+}
+
+func (*MachineShowResponse_Log) isMachineShowResponse_Reply() {}
+
+func (*MachineShowResponse_MachineInfo) isMachineShowResponse_Reply() {}
+
+type MachineListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Reply:
+	//
+	//	*MachineListResponse_Log
+	//	*MachineListResponse_MachineList
+	Reply isMachineListResponse_Reply `protobuf_oneof:"reply"`
+}
+
+func (x *MachineListResponse) Reset() {
+	*x = MachineListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zsys_proto_msgTypes[47]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MachineListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineListResponse) ProtoMessage() {}
+
+func (x *MachineListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zsys_proto_msgTypes[47]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineListResponse.ProtoReflect.Descriptor instead.
+func (*MachineListResponse) Descriptor() ([]byte, []int) {
+	return file_zsys_proto_rawDescGZIP(), []int{47}
+}
+
+func (m *MachineListResponse) GetReply() isMachineListResponse_Reply {
+	if m != nil {
+		return m.Reply
+	}
+	return nil
+}
+
+func (x *MachineListResponse) GetLog() string {
+	if x, ok := x.GetReply().(*MachineListResponse_Log); ok {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *MachineListResponse) GetMachineList() string {
+	if x, ok := x.GetReply().(*MachineListResponse_MachineList); ok {
+		return x.MachineList
+	}
+	return ""
+}
+
+type isMachineListResponse_Reply interface {
+	isMachineListResponse_Reply()
+}
+
+type MachineListResponse_Log struct {
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type MachineListResponse_MachineList struct {
+	MachineList string `protobuf:"bytes,2,opt,name=machineList,proto3,oneof"`
+}
+
+func (*MachineListResponse_Log) isMachineListResponse_Reply() {}
+
+func (*MachineListResponse_MachineList) isMachineListResponse_Reply() {}
+
+var File_zsys_proto protoreflect.FileDescriptor
+
+var file_zsys_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x7a, 0x73,
+	0x79, 0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x1f, 0x0a, 0x0b, 0x4c,
+	0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x22, 0x4a, 0x0a, 0x0f,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03,
+	0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42,
+	0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x81, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x6d, 0x65, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x6d, 0x65, 0x70, 0x61,
+	0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x12, 0x1e, 0x0a, 0x0a,
+	0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x22, 0x46, 0x0a, 0x14,
+	0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x6d, 0x65,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x6d, 0x65,
+	0x70, 0x61, 0x74, 0x68, 0x22, 0x49, 0x0a, 0x13, 0x4c, 0x6f, 0x61, 0x64, 0x55, 0x73, 0x65, 0x72,
+	0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x22,
+	0x4b, 0x0a, 0x1b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f, 0x6e, 0x55,
+	0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x6f, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f,
+	0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x48, 0x6f, 0x6d, 0x65, 0x22, 0x4b, 0x0a, 0x15,
+	0x44, 0x69, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x22, 0x4e, 0x0a, 0x13, 0x50, 0x72, 0x65,
+	0x70, 0x61, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64,
+	0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x4d, 0x0a, 0x12, 0x43, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03,
+	0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x42,
+	0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x2b, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x04, 0x61, 0x75, 0x74, 0x6f, 0x22, 0x57, 0x0a, 0x0f, 0x42, 0x6f, 0x6f, 0x74, 0x4e, 0x65, 0x78,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x72, 0x65, 0x76, 0x65, 0x72, 0x74,
+	0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e,
+	0x72, 0x65, 0x76, 0x65, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x22, 0xcf,
+	0x02, 0x0a, 0x16, 0x53, 0x61, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x12,
+	0x1a, 0x0a, 0x08, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x61, 0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x61, 0x76, 0x65, 0x12, 0x3c, 0x0a, 0x0e, 0x70,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x0e, 0x70, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x1a, 0x37, 0x0a, 0x09, 0x54, 0x61, 0x67, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x64, 0x0a, 0x0e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x70, 0x67, 0x72, 0x61, 0x64, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x75, 0x70, 0x67, 0x72, 0x61, 0x64, 0x65, 0x64, 0x12, 0x1c,
+	0x0a, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6c, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x22, 0xe5, 0x01, 0x0a, 0x14, 0x53, 0x61, 0x76, 0x65, 0x55,
+	0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x53, 0x61, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x04, 0x74, 0x61, 0x67, 0x73, 0x1a, 0x37, 0x0a, 0x09, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x56,
+	0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1e, 0x0a,
+	0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a,
+	0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x66, 0x0a, 0x18, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x72, 0x79, 0x72, 0x75, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x72, 0x75, 0x6e, 0x22, 0x80,
+	0x01, 0x0a, 0x16, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x72, 0x79,
+	0x72, 0x75, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x72, 0x75,
+	0x6e, 0x22, 0x46, 0x0a, 0x12, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x22, 0x4e, 0x0a, 0x13, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x03, 0x6c, 0x6f, 0x67, 0x12, 0x1a, 0x0a, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65,
+	0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x52, 0x0a, 0x12, 0x49, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x22, 0x0a, 0x0c, 0x61, 0x73, 0x4e, 0x65, 0x77, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x73, 0x4e, 0x65, 0x77, 0x4d, 0x61, 0x63, 0x68,
+	0x69, 0x6e, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x22, 0x53, 0x0a,
+	0x17, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x22, 0x51, 0x0a, 0x15, 0x52, 0x65, 0x76, 0x65, 0x72, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x73, 0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x61, 0x74,
+	0x61, 0x73, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x64, 0x61, 0x74,
+	0x61, 0x73, 0x65, 0x74, 0x73, 0x22, 0x60, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61,
+	0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x46, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03,
+	0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67,
+	0x12, 0x14, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x5e, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x41, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x41, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x42, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22,
+	0x46, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x14, 0x0a, 0x04, 0x64, 0x69, 0x66, 0x66,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x64, 0x69, 0x66, 0x66, 0x42, 0x07,
+	0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0xb9, 0x01, 0x0a, 0x0f, 0x54, 0x61, 0x67, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75,
+	0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75,
+	0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x54, 0x61, 0x67, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x1a, 0x37, 0x0a, 0x09, 0x54, 0x61,
+	0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x61, 0x0a, 0x11, 0x55, 0x6e, 0x74, 0x61, 0x67, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x5d, 0x0a, 0x0f, 0x50, 0x69, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x03, 0x70, 0x69, 0x6e, 0x22, 0x4d, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x65, 0x55, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x22, 0x52, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x74, 0x65, 0x55, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x72, 0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x66, 0x72, 0x65, 0x65, 0x64, 0x22, 0x5b, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12,
+	0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c,
+	0x6f, 0x67, 0x12, 0x28, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x55, 0x73,
+	0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x42, 0x07, 0x0a, 0x05,
+	0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x4b, 0x0a, 0x12, 0x44, 0x75, 0x6d, 0x70, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c,
+	0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12,
+	0x18, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x39, 0x0a, 0x13, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x6f, 0x67,
+	0x67, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0x3e, 0x0a,
+	0x0c, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x44, 0x0a,
+	0x0d, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12,
+	0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c,
+	0x6f, 0x67, 0x12, 0x16, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x33, 0x0a, 0x09, 0x47, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x61, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x61,
+	0x6c, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0xae, 0x01, 0x0a, 0x0a, 0x47, 0x43, 0x44,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69,
+	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x55, 0x73, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x55, 0x73, 0x65,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x38, 0x0a, 0x06, 0x47, 0x43, 0x50,
+	0x6c, 0x61, 0x6e, 0x12, 0x2e, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x22, 0x51, 0x0a, 0x0e, 0x47, 0x43, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x22, 0x0a, 0x04, 0x70, 0x6c, 0x61,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47,
+	0x43, 0x50, 0x6c, 0x61, 0x6e, 0x48, 0x00, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x42, 0x07, 0x0a,
+	0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x77, 0x0a, 0x11, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75,
+	0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x45, 0x76, 0x65, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x45, 0x76, 0x65, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x61, 0x6c, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x61, 0x6c, 0x6c, 0x22,
+	0x91, 0x01, 0x0a, 0x0f, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x44, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x73, 0x22, 0x39, 0x0a, 0x0c, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x22, 0x67,
+	0x0a, 0x12, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x34, 0x0a, 0x0a, 0x73, 0x69, 0x6d, 0x75,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x48, 0x00, 0x52, 0x0a, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x07,
+	0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0xb7, 0x01, 0x0a, 0x12, 0x4d, 0x61, 0x63, 0x68,
+	0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x75, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x66, 0x75, 0x6c, 0x6c,
+	0x12, 0x36, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f,
+	0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x1a, 0x37, 0x0a, 0x09, 0x54, 0x61, 0x67, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x56, 0x0a, 0x13, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x22, 0x0a, 0x0b,
+	0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x56, 0x0a, 0x13, 0x4d, 0x61, 0x63,
+	0x68, 0x69, 0x6e, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x03, 0x6c, 0x6f, 0x67, 0x12, 0x22, 0x0a, 0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x4c,
+	0x69, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x61, 0x63,
+	0x68, 0x69, 0x6e, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c,
+	0x79, 0x32, 0xaa, 0x12, 0x0a, 0x04, 0x5a, 0x73, 0x79, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x1a, 0x15, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1b, 0x2e,
+	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x40, 0x0a, 0x0d, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61,
+	0x12, 0x1a, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x55, 0x73, 0x65,
+	0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
+	0x01, 0x12, 0x3e, 0x0a, 0x0c, 0x4c, 0x6f, 0x61, 0x64, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79,
+	0x73, 0x12, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x55, 0x73, 0x65,
+	0x72, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
+	0x01, 0x12, 0x4e, 0x0a, 0x14, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f,
+	0x6e, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x21, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x6f, 0x6d, 0x65, 0x4f, 0x6e, 0x55, 0x73, 0x65,
+	0x72, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
+	0x01, 0x12, 0x42, 0x0a, 0x0e, 0x44, 0x69, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x55,
+	0x73, 0x65, 0x72, 0x12, 0x1b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x44, 0x69, 0x73, 0x73, 0x6f,
+	0x63, 0x69, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x37, 0x0a, 0x0b, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65,
+	0x42, 0x6f, 0x6f, 0x74, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x1a, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65,
+	0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x35,
+	0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x0b, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42,
+	0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x12, 0x1b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x4d, 0x65, 0x6e, 0x75, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x0e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x4c, 0x61, 0x73, 0x74, 0x55, 0x73, 0x65, 0x64, 0x12, 0x0b, 0x2e, 0x7a, 0x73,
+	0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
+	0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x36, 0x0a,
+	0x08, 0x42, 0x6f, 0x6f, 0x74, 0x4e, 0x65, 0x78, 0x74, 0x12, 0x15, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x42, 0x6f, 0x6f, 0x74, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x0f, 0x53, 0x61, 0x76, 0x65, 0x53, 0x79, 0x73,
+	0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
+	0x53, 0x61, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4c, 0x0a, 0x0d, 0x53, 0x61, 0x76, 0x65, 0x55,
+	0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
+	0x53, 0x61, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x48, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1e, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x44, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x1c, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
+	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4a, 0x0a, 0x0b, 0x49,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x52, 0x0a, 0x10, 0x52, 0x65, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x2e, 0x7a, 0x73,
+	0x79, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0e, 0x52,
+	0x65, 0x76, 0x65, 0x72, 0x74, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x12, 0x1b, 0x2e,
+	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x52, 0x65, 0x76, 0x65, 0x72, 0x74, 0x44, 0x61, 0x74, 0x61, 0x73,
+	0x65, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x3e, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x16, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x3e, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x69, 0x66, 0x66, 0x12, 0x16, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x36, 0x0a, 0x08, 0x54, 0x61, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x15, 0x2e, 0x7a, 0x73,
+	0x79, 0x73, 0x2e, 0x54, 0x61, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x3a, 0x0a, 0x0a, 0x55, 0x6e, 0x74, 0x61, 0x67,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x55, 0x6e, 0x74,
+	0x61, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
+	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x08, 0x50, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x15, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x50, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f,
+	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x41, 0x0a, 0x0a, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x17, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x55,
+	0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x35,
+	0x0a, 0x0a, 0x44, 0x75, 0x6d, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x0b, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x44, 0x75, 0x6d, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x0a, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x53,
+	0x74, 0x6f, 0x70, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x3e, 0x0a, 0x0c, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67,
+	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67,
+	0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2b, 0x0a, 0x07, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68,
+	0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e,
+	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x30, 0x01, 0x12, 0x32, 0x0a, 0x05, 0x54, 0x72, 0x61, 0x63, 0x65, 0x12, 0x12, 0x2e, 0x7a, 0x73,
+	0x79, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2a, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e,
+	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x30, 0x01, 0x12, 0x2a, 0x0a, 0x06, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x0b, 0x2e, 0x7a,
+	0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73,
+	0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x2a,
+	0x0a, 0x02, 0x47, 0x43, 0x12, 0x0f, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4c, 0x6f, 0x67,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x31, 0x0a, 0x06, 0x47, 0x43,
+	0x50, 0x6c, 0x61, 0x6e, 0x12, 0x0f, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x50,
+	0x6c, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x41, 0x0a,
+	0x0a, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x7a, 0x73,
+	0x79, 0x73, 0x2e, 0x47, 0x43, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x47, 0x43, 0x53, 0x69,
+	0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01,
+	0x12, 0x2d, 0x0a, 0x09, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x2e,
+	0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x7a, 0x73, 0x79,
+	0x73, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x44, 0x0a, 0x0b, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77, 0x12, 0x18,
+	0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f,
+	0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e,
+	0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x68, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x37, 0x0a, 0x0b, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x0b, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x1a, 0x19, 0x2e, 0x7a, 0x73, 0x79, 0x73, 0x2e, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x18,
+	0x5a, 0x16, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x75, 0x62, 0x75,
+	0x6e, 0x74, 0x75, 0x2f, 0x7a, 0x73, 0x79, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_zsys_proto_rawDescOnce sync.Once
+	file_zsys_proto_rawDescData = file_zsys_proto_rawDesc
+)
+
+func file_zsys_proto_rawDescGZIP() []byte {
+	file_zsys_proto_rawDescOnce.Do(func() {
+		file_zsys_proto_rawDescData = protoimpl.X.CompressGZIP(file_zsys_proto_rawDescData)
+	})
+	return file_zsys_proto_rawDescData
+}
+
+var file_zsys_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
+var file_zsys_proto_goTypes = []interface{}{
+	(*Empty)(nil),                       // 0: zsys.Empty
+	(*LogResponse)(nil),                 // 1: zsys.LogResponse
+	(*VersionResponse)(nil),             // 2: zsys.VersionResponse
+	(*CreateUserDataRequest)(nil),       // 3: zsys.CreateUserDataRequest
+	(*MountUserDataRequest)(nil),        // 4: zsys.MountUserDataRequest
+	(*LoadUserKeysRequest)(nil),         // 5: zsys.LoadUserKeysRequest
+	(*ChangeHomeOnUserDataRequest)(nil), // 6: zsys.ChangeHomeOnUserDataRequest
+	(*DissociateUserRequest)(nil),       // 7: zsys.DissociateUserRequest
+	(*PrepareBootResponse)(nil),         // 8: zsys.PrepareBootResponse
+	(*CommitBootResponse)(nil),          // 9: zsys.CommitBootResponse
+	(*UpdateBootMenuRequest)(nil),       // 10: zsys.UpdateBootMenuRequest
+	(*BootNextRequest)(nil),             // 11: zsys.BootNextRequest
+	(*SaveSystemStateRequest)(nil),      // 12: zsys.SaveSystemStateRequest
+	(*PackageChanges)(nil),              // 13: zsys.PackageChanges
+	(*SaveUserStateRequest)(nil),        // 14: zsys.SaveUserStateRequest
+	(*CreateSaveStateResponse)(nil),     // 15: zsys.CreateSaveStateResponse
+	(*RemoveSystemStateRequest)(nil),    // 16: zsys.RemoveSystemStateRequest
+	(*RemoveUserStateRequest)(nil),      // 17: zsys.RemoveUserStateRequest
+	(*ExportStateRequest)(nil),          // 18: zsys.ExportStateRequest
+	(*ExportStateResponse)(nil),         // 19: zsys.ExportStateResponse
+	(*ImportStateRequest)(nil),          // 20: zsys.ImportStateRequest
+	(*RestoreUserStateRequest)(nil),     // 21: zsys.RestoreUserStateRequest
+	(*RevertDatasetsRequest)(nil),       // 22: zsys.RevertDatasetsRequest
+	(*StatePathRequest)(nil),            // 23: zsys.StatePathRequest
+	(*StatePathResponse)(nil),           // 24: zsys.StatePathResponse
+	(*StateDiffRequest)(nil),            // 25: zsys.StateDiffRequest
+	(*StateDiffResponse)(nil),           // 26: zsys.StateDiffResponse
+	(*TagStateRequest)(nil),             // 27: zsys.TagStateRequest
+	(*UntagStateRequest)(nil),           // 28: zsys.UntagStateRequest
+	(*PinStateRequest)(nil),             // 29: zsys.PinStateRequest
+	(*StateUsageRequest)(nil),           // 30: zsys.StateUsageRequest
+	(*StateUsage)(nil),                  // 31: zsys.StateUsage
+	(*StateUsageResponse)(nil),          // 32: zsys.StateUsageResponse
+	(*DumpStatesResponse)(nil),          // 33: zsys.DumpStatesResponse
+	(*LoggingLevelRequest)(nil),         // 34: zsys.LoggingLevelRequest
+	(*TraceRequest)(nil),                // 35: zsys.TraceRequest
+	(*TraceResponse)(nil),               // 36: zsys.TraceResponse
+	(*GCRequest)(nil),                   // 37: zsys.GCRequest
+	(*GCDecision)(nil),                  // 38: zsys.GCDecision
+	(*GCPlan)(nil),                      // 39: zsys.GCPlan
+	(*GCPlanResponse)(nil),              // 40: zsys.GCPlanResponse
+	(*GCSimulateRequest)(nil),           // 41: zsys.GCSimulateRequest
+	(*GCSimulationDay)(nil),             // 42: zsys.GCSimulationDay
+	(*GCSimulation)(nil),                // 43: zsys.GCSimulation
+	(*GCSimulateResponse)(nil),          // 44: zsys.GCSimulateResponse
+	(*MachineShowRequest)(nil),          // 45: zsys.MachineShowRequest
+	(*MachineShowResponse)(nil),         // 46: zsys.MachineShowResponse
+	(*MachineListResponse)(nil),         // 47: zsys.MachineListResponse
+	nil,                                 // 48: zsys.SaveSystemStateRequest.TagsEntry
+	nil,                                 // 49: zsys.SaveUserStateRequest.TagsEntry
+	nil,                                 // 50: zsys.TagStateRequest.TagsEntry
+	nil,                                 // 51: zsys.MachineShowRequest.TagsEntry
+}
+var file_zsys_proto_depIdxs = []int32{
+	13, // 0: zsys.SaveSystemStateRequest.packageChanges:type_name -> zsys.PackageChanges
+	48, // 1: zsys.SaveSystemStateRequest.tags:type_name -> zsys.SaveSystemStateRequest.TagsEntry
+	49, // 2: zsys.SaveUserStateRequest.tags:type_name -> zsys.SaveUserStateRequest.TagsEntry
+	50, // 3: zsys.TagStateRequest.tags:type_name -> zsys.TagStateRequest.TagsEntry
+	31, // 4: zsys.StateUsageResponse.usage:type_name -> zsys.StateUsage
+	38, // 5: zsys.GCPlan.decisions:type_name -> zsys.GCDecision
+	39, // 6: zsys.GCPlanResponse.plan:type_name -> zsys.GCPlan
+	42, // 7: zsys.GCSimulation.days:type_name -> zsys.GCSimulationDay
+	43, // 8: zsys.GCSimulateResponse.simulation:type_name -> zsys.GCSimulation
+	51, // 9: zsys.MachineShowRequest.tags:type_name -> zsys.MachineShowRequest.TagsEntry
+	0,  // 10: zsys.Zsys.Version:input_type -> zsys.Empty
+	3,  // 11: zsys.Zsys.CreateUserData:input_type -> zsys.CreateUserDataRequest
+	4,  // 12: zsys.Zsys.MountUserData:input_type -> zsys.MountUserDataRequest
+	5,  // 13: zsys.Zsys.LoadUserKeys:input_type -> zsys.LoadUserKeysRequest
+	6,  // 14: zsys.Zsys.ChangeHomeOnUserData:input_type -> zsys.ChangeHomeOnUserDataRequest
+	7,  // 15: zsys.Zsys.DissociateUser:input_type -> zsys.DissociateUserRequest
+	0,  // 16: zsys.Zsys.PrepareBoot:input_type -> zsys.Empty
+	0,  // 17: zsys.Zsys.CommitBoot:input_type -> zsys.Empty
+	10, // 18: zsys.Zsys.UpdateBootMenu:input_type -> zsys.UpdateBootMenuRequest
+	0,  // 19: zsys.Zsys.UpdateLastUsed:input_type -> zsys.Empty
+	11, // 20: zsys.Zsys.BootNext:input_type -> zsys.BootNextRequest
+	12, // 21: zsys.Zsys.SaveSystemState:input_type -> zsys.SaveSystemStateRequest
+	14, // 22: zsys.Zsys.SaveUserState:input_type -> zsys.SaveUserStateRequest
+	16, // 23: zsys.Zsys.RemoveSystemState:input_type -> zsys.RemoveSystemStateRequest
+	17, // 24: zsys.Zsys.RemoveUserState:input_type -> zsys.RemoveUserStateRequest
+	18, // 25: zsys.Zsys.ExportState:input_type -> zsys.ExportStateRequest
+	20, // 26: zsys.Zsys.ImportState:input_type -> zsys.ImportStateRequest
+	21, // 27: zsys.Zsys.RestoreUserState:input_type -> zsys.RestoreUserStateRequest
+	22, // 28: zsys.Zsys.RevertDatasets:input_type -> zsys.RevertDatasetsRequest
+	23, // 29: zsys.Zsys.StatePath:input_type -> zsys.StatePathRequest
+	25, // 30: zsys.Zsys.StateDiff:input_type -> zsys.StateDiffRequest
+	27, // 31: zsys.Zsys.TagState:input_type -> zsys.TagStateRequest
+	28, // 32: zsys.Zsys.UntagState:input_type -> zsys.UntagStateRequest
+	29, // 33: zsys.Zsys.PinState:input_type -> zsys.PinStateRequest
+	30, // 34: zsys.Zsys.StateUsage:input_type -> zsys.StateUsageRequest
+	0,  // 35: zsys.Zsys.DumpStates:input_type -> zsys.Empty
+	0,  // 36: zsys.Zsys.DaemonStop:input_type -> zsys.Empty
+	34, // 37: zsys.Zsys.LoggingLevel:input_type -> zsys.LoggingLevelRequest
+	0,  // 38: zsys.Zsys.Refresh:input_type -> zsys.Empty
+	35, // 39: zsys.Zsys.Trace:input_type -> zsys.TraceRequest
+	0,  // 40: zsys.Zsys.Status:input_type -> zsys.Empty
+	0,  // 41: zsys.Zsys.Reload:input_type -> zsys.Empty
+	37, // 42: zsys.Zsys.GC:input_type -> zsys.GCRequest
+	37, // 43: zsys.Zsys.GCPlan:input_type -> zsys.GCRequest
+	41, // 44: zsys.Zsys.GCSimulate:input_type -> zsys.GCSimulateRequest
+	0,  // 45: zsys.Zsys.Replicate:input_type -> zsys.Empty
+	45, // 46: zsys.Zsys.MachineShow:input_type -> zsys.MachineShowRequest
+	0,  // 47: zsys.Zsys.MachineList:input_type -> zsys.Empty
+	2,  // 48: zsys.Zsys.Version:output_type -> zsys.VersionResponse
+	1,  // 49: zsys.Zsys.CreateUserData:output_type -> zsys.LogResponse
+	1,  // 50: zsys.Zsys.MountUserData:output_type -> zsys.LogResponse
+	1,  // 51: zsys.Zsys.LoadUserKeys:output_type -> zsys.LogResponse
+	1,  // 52: zsys.Zsys.ChangeHomeOnUserData:output_type -> zsys.LogResponse
+	1,  // 53: zsys.Zsys.DissociateUser:output_type -> zsys.LogResponse
+	8,  // 54: zsys.Zsys.PrepareBoot:output_type -> zsys.PrepareBootResponse
+	9,  // 55: zsys.Zsys.CommitBoot:output_type -> zsys.CommitBootResponse
+	1,  // 56: zsys.Zsys.UpdateBootMenu:output_type -> zsys.LogResponse
+	1,  // 57: zsys.Zsys.UpdateLastUsed:output_type -> zsys.LogResponse
+	1,  // 58: zsys.Zsys.BootNext:output_type -> zsys.LogResponse
+	15, // 59: zsys.Zsys.SaveSystemState:output_type -> zsys.CreateSaveStateResponse
+	15, // 60: zsys.Zsys.SaveUserState:output_type -> zsys.CreateSaveStateResponse
+	1,  // 61: zsys.Zsys.RemoveSystemState:output_type -> zsys.LogResponse
+	1,  // 62: zsys.Zsys.RemoveUserState:output_type -> zsys.LogResponse
+	19, // 63: zsys.Zsys.ExportState:output_type -> zsys.ExportStateResponse
+	15, // 64: zsys.Zsys.ImportState:output_type -> zsys.CreateSaveStateResponse
+	15, // 65: zsys.Zsys.RestoreUserState:output_type -> zsys.CreateSaveStateResponse
+	1,  // 66: zsys.Zsys.RevertDatasets:output_type -> zsys.LogResponse
+	24, // 67: zsys.Zsys.StatePath:output_type -> zsys.StatePathResponse
+	26, // 68: zsys.Zsys.StateDiff:output_type -> zsys.StateDiffResponse
+	1,  // 69: zsys.Zsys.TagState:output_type -> zsys.LogResponse
+	1,  // 70: zsys.Zsys.UntagState:output_type -> zsys.LogResponse
+	1,  // 71: zsys.Zsys.PinState:output_type -> zsys.LogResponse
+	32, // 72: zsys.Zsys.StateUsage:output_type -> zsys.StateUsageResponse
+	33, // 73: zsys.Zsys.DumpStates:output_type -> zsys.DumpStatesResponse
+	1,  // 74: zsys.Zsys.DaemonStop:output_type -> zsys.LogResponse
+	1,  // 75: zsys.Zsys.LoggingLevel:output_type -> zsys.LogResponse
+	1,  // 76: zsys.Zsys.Refresh:output_type -> zsys.LogResponse
+	36, // 77: zsys.Zsys.Trace:output_type -> zsys.TraceResponse
+	1,  // 78: zsys.Zsys.Status:output_type -> zsys.LogResponse
+	1,  // 79: zsys.Zsys.Reload:output_type -> zsys.LogResponse
+	1,  // 80: zsys.Zsys.GC:output_type -> zsys.LogResponse
+	40, // 81: zsys.Zsys.GCPlan:output_type -> zsys.GCPlanResponse
+	44, // 82: zsys.Zsys.GCSimulate:output_type -> zsys.GCSimulateResponse
+	1,  // 83: zsys.Zsys.Replicate:output_type -> zsys.LogResponse
+	46, // 84: zsys.Zsys.MachineShow:output_type -> zsys.MachineShowResponse
+	47, // 85: zsys.Zsys.MachineList:output_type -> zsys.MachineListResponse
+	48, // [48:86] is the sub-list for method output_type
+	10, // [10:48] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_zsys_proto_init() }
+func file_zsys_proto_init() {
+	if File_zsys_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_zsys_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Empty); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VersionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateUserDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MountUserDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoadUserKeysRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeHomeOnUserDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DissociateUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PrepareBootResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommitBootResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateBootMenuRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BootNextRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SaveSystemStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PackageChanges); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SaveUserStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateSaveStateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveSystemStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveUserStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1722,8 +4028,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogResponse); i {
+		file_zsys_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1734,8 +4040,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VersionResponse); i {
+		file_zsys_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportStateResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1746,8 +4052,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateUserDataRequest); i {
+		file_zsys_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1758,8 +4064,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ChangeHomeOnUserDataRequest); i {
+		file_zsys_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreUserStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1770,8 +4076,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DissociateUserRequest); i {
+		file_zsys_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RevertDatasetsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1782,8 +4088,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PrepareBootResponse); i {
+		file_zsys_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatePathRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1794,8 +4100,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CommitBootResponse); i {
+		file_zsys_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatePathResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1806,8 +4112,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateBootMenuRequest); i {
+		file_zsys_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateDiffRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1818,8 +4124,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SaveSystemStateRequest); i {
+		file_zsys_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateDiffResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1830,8 +4136,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SaveUserStateRequest); i {
+		file_zsys_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TagStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1842,8 +4148,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateSaveStateResponse); i {
+		file_zsys_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UntagStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1854,8 +4160,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RemoveSystemStateRequest); i {
+		file_zsys_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PinStateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1866,8 +4172,8 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RemoveUserStateRequest); i {
+		file_zsys_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateUsageRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1878,7 +4184,31 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateUsage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateUsageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DumpStatesResponse); i {
 			case 0:
 				return &v.state
@@ -1890,7 +4220,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*LoggingLevelRequest); i {
 			case 0:
 				return &v.state
@@ -1902,7 +4232,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TraceRequest); i {
 			case 0:
 				return &v.state
@@ -1914,7 +4244,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TraceResponse); i {
 			case 0:
 				return &v.state
@@ -1926,7 +4256,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GCRequest); i {
 			case 0:
 				return &v.state
@@ -1938,7 +4268,91 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCDecision); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCPlan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCPlanResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCSimulateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCSimulationDay); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCSimulation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCSimulateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zsys_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MachineShowRequest); i {
 			case 0:
 				return &v.state
@@ -1950,7 +4364,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MachineShowResponse); i {
 			case 0:
 				return &v.state
@@ -1962,7 +4376,7 @@ func file_zsys_proto_init() {
 				return nil
 			}
 		}
-		file_zsys_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+		file_zsys_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MachineListResponse); i {
 			case 0:
 				return &v.state
@@ -1979,31 +4393,55 @@ func file_zsys_proto_init() {
 		(*VersionResponse_Log)(nil),
 		(*VersionResponse_Version)(nil),
 	}
-	file_zsys_proto_msgTypes[6].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*PrepareBootResponse_Log)(nil),
 		(*PrepareBootResponse_Changed)(nil),
 	}
-	file_zsys_proto_msgTypes[7].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[9].OneofWrappers = []interface{}{
 		(*CommitBootResponse_Log)(nil),
 		(*CommitBootResponse_Changed)(nil),
 	}
-	file_zsys_proto_msgTypes[11].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[15].OneofWrappers = []interface{}{
 		(*CreateSaveStateResponse_Log)(nil),
 		(*CreateSaveStateResponse_StateName)(nil),
 	}
-	file_zsys_proto_msgTypes[14].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[19].OneofWrappers = []interface{}{
+		(*ExportStateResponse_Log)(nil),
+		(*ExportStateResponse_Archive)(nil),
+	}
+	file_zsys_proto_msgTypes[24].OneofWrappers = []interface{}{
+		(*StatePathResponse_Log)(nil),
+		(*StatePathResponse_Path)(nil),
+	}
+	file_zsys_proto_msgTypes[26].OneofWrappers = []interface{}{
+		(*StateDiffResponse_Log)(nil),
+		(*StateDiffResponse_Diff)(nil),
+	}
+	file_zsys_proto_msgTypes[32].OneofWrappers = []interface{}{
+		(*StateUsageResponse_Log)(nil),
+		(*StateUsageResponse_Usage)(nil),
+	}
+	file_zsys_proto_msgTypes[33].OneofWrappers = []interface{}{
 		(*DumpStatesResponse_Log)(nil),
 		(*DumpStatesResponse_States)(nil),
 	}
-	file_zsys_proto_msgTypes[17].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[36].OneofWrappers = []interface{}{
 		(*TraceResponse_Log)(nil),
 		(*TraceResponse_Trace)(nil),
 	}
-	file_zsys_proto_msgTypes[20].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[40].OneofWrappers = []interface{}{
+		(*GCPlanResponse_Log)(nil),
+		(*GCPlanResponse_Plan)(nil),
+	}
+	file_zsys_proto_msgTypes[44].OneofWrappers = []interface{}{
+		(*GCSimulateResponse_Log)(nil),
+		(*GCSimulateResponse_Simulation)(nil),
+	}
+	file_zsys_proto_msgTypes[46].OneofWrappers = []interface{}{
 		(*MachineShowResponse_Log)(nil),
 		(*MachineShowResponse_MachineInfo)(nil),
 	}
-	file_zsys_proto_msgTypes[21].OneofWrappers = []interface{}{
+	file_zsys_proto_msgTypes[47].OneofWrappers = []interface{}{
 		(*MachineListResponse_Log)(nil),
 		(*MachineListResponse_MachineList)(nil),
 	}
@@ -2013,7 +4451,7 @@ func file_zsys_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_zsys_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   22,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -119,6 +119,66 @@ func (z *ZsysLogServer) CreateUserData(req *CreateUserDataRequest, stream Zsys_C
 	})
 }
 
+/*
+ * Zsys.MountUserData()
+ */
+
+// zsysMountUserDataLogStream is a Zsys_MountUserDataServer augmented by its own Context containing the log streamer
+type zsysMountUserDataLogStream struct {
+	Zsys_MountUserDataServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysMountUserDataLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// MountUserData overrides ZsysServer MountUserData, installing a logger first
+func (z *ZsysLogServer) MountUserData(req *MountUserDataRequest, stream Zsys_MountUserDataServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "MountUserData")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.MountUserData(req, &zsysMountUserDataLogStream{
+		Zsys_MountUserDataServer: stream,
+		ctx:                      ctx,
+	})
+}
+
+/*
+ * Zsys.LoadUserKeys()
+ */
+
+// zsysLoadUserKeysLogStream is a Zsys_LoadUserKeysServer augmented by its own Context containing the log streamer
+type zsysLoadUserKeysLogStream struct {
+	Zsys_LoadUserKeysServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysLoadUserKeysLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// LoadUserKeys overrides ZsysServer LoadUserKeys, installing a logger first
+func (z *ZsysLogServer) LoadUserKeys(req *LoadUserKeysRequest, stream Zsys_LoadUserKeysServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "LoadUserKeys")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.LoadUserKeys(req, &zsysLoadUserKeysLogStream{
+		Zsys_LoadUserKeysServer: stream,
+		ctx:                     ctx,
+	})
+}
+
 /*
  * Zsys.ChangeHomeOnUserData()
  */
@@ -299,6 +359,36 @@ func (z *ZsysLogServer) UpdateLastUsed(req *Empty, stream Zsys_UpdateLastUsedSer
 	})
 }
 
+/*
+ * Zsys.BootNext()
+ */
+
+// zsysBootNextLogStream is a Zsys_BootNextServer augmented by its own Context containing the log streamer
+type zsysBootNextLogStream struct {
+	Zsys_BootNextServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysBootNextLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// BootNext overrides ZsysServer BootNext, installing a logger first
+func (z *ZsysLogServer) BootNext(req *BootNextRequest, stream Zsys_BootNextServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "BootNext")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.BootNext(req, &zsysBootNextLogStream{
+		Zsys_BootNextServer: stream,
+		ctx:                 ctx,
+	})
+}
+
 /*
  * Zsys.SaveSystemState()
  */
@@ -419,6 +509,306 @@ func (z *ZsysLogServer) RemoveUserState(req *RemoveUserStateRequest, stream Zsys
 	})
 }
 
+/*
+ * Zsys.ExportState()
+ */
+
+// zsysExportStateLogStream is a Zsys_ExportStateServer augmented by its own Context containing the log streamer
+type zsysExportStateLogStream struct {
+	Zsys_ExportStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysExportStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// ExportState overrides ZsysServer ExportState, installing a logger first
+func (z *ZsysLogServer) ExportState(req *ExportStateRequest, stream Zsys_ExportStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "ExportState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.ExportState(req, &zsysExportStateLogStream{
+		Zsys_ExportStateServer: stream,
+		ctx:                    ctx,
+	})
+}
+
+/*
+ * Zsys.ImportState()
+ */
+
+// zsysImportStateLogStream is a Zsys_ImportStateServer augmented by its own Context containing the log streamer
+type zsysImportStateLogStream struct {
+	Zsys_ImportStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysImportStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// ImportState overrides ZsysServer ImportState, installing a logger first
+func (z *ZsysLogServer) ImportState(stream Zsys_ImportStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "ImportState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.ImportState(&zsysImportStateLogStream{
+		Zsys_ImportStateServer: stream,
+		ctx:                    ctx,
+	})
+}
+
+/*
+ * Zsys.RestoreUserState()
+ */
+
+// zsysRestoreUserStateLogStream is a Zsys_RestoreUserStateServer augmented by its own Context containing the log streamer
+type zsysRestoreUserStateLogStream struct {
+	Zsys_RestoreUserStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysRestoreUserStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// RestoreUserState overrides ZsysServer RestoreUserState, installing a logger first
+func (z *ZsysLogServer) RestoreUserState(req *RestoreUserStateRequest, stream Zsys_RestoreUserStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "RestoreUserState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.RestoreUserState(req, &zsysRestoreUserStateLogStream{
+		Zsys_RestoreUserStateServer: stream,
+		ctx:                         ctx,
+	})
+}
+
+/*
+ * Zsys.RevertDatasets()
+ */
+
+// zsysRevertDatasetsLogStream is a Zsys_RevertDatasetsServer augmented by its own Context containing the log streamer
+type zsysRevertDatasetsLogStream struct {
+	Zsys_RevertDatasetsServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysRevertDatasetsLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// RevertDatasets overrides ZsysServer RevertDatasets, installing a logger first
+func (z *ZsysLogServer) RevertDatasets(req *RevertDatasetsRequest, stream Zsys_RevertDatasetsServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "RevertDatasets")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.RevertDatasets(req, &zsysRevertDatasetsLogStream{
+		Zsys_RevertDatasetsServer: stream,
+		ctx:                       ctx,
+	})
+}
+
+/*
+ * Zsys.StatePath()
+ */
+
+// zsysStatePathLogStream is a Zsys_StatePathServer augmented by its own Context containing the log streamer
+type zsysStatePathLogStream struct {
+	Zsys_StatePathServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysStatePathLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// StatePath overrides ZsysServer StatePath, installing a logger first
+func (z *ZsysLogServer) StatePath(req *StatePathRequest, stream Zsys_StatePathServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "StatePath")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.StatePath(req, &zsysStatePathLogStream{
+		Zsys_StatePathServer: stream,
+		ctx:                  ctx,
+	})
+}
+
+/*
+ * Zsys.StateDiff()
+ */
+
+// zsysStateDiffLogStream is a Zsys_StateDiffServer augmented by its own Context containing the log streamer
+type zsysStateDiffLogStream struct {
+	Zsys_StateDiffServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysStateDiffLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// StateDiff overrides ZsysServer StateDiff, installing a logger first
+func (z *ZsysLogServer) StateDiff(req *StateDiffRequest, stream Zsys_StateDiffServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "StateDiff")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.StateDiff(req, &zsysStateDiffLogStream{
+		Zsys_StateDiffServer: stream,
+		ctx:                  ctx,
+	})
+}
+
+/*
+ * Zsys.TagState()
+ */
+
+// zsysTagStateLogStream is a Zsys_TagStateServer augmented by its own Context containing the log streamer
+type zsysTagStateLogStream struct {
+	Zsys_TagStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysTagStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// TagState overrides ZsysServer TagState, installing a logger first
+func (z *ZsysLogServer) TagState(req *TagStateRequest, stream Zsys_TagStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "TagState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.TagState(req, &zsysTagStateLogStream{
+		Zsys_TagStateServer: stream,
+		ctx:                 ctx,
+	})
+}
+
+/*
+ * Zsys.UntagState()
+ */
+
+// zsysUntagStateLogStream is a Zsys_UntagStateServer augmented by its own Context containing the log streamer
+type zsysUntagStateLogStream struct {
+	Zsys_UntagStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysUntagStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// UntagState overrides ZsysServer UntagState, installing a logger first
+func (z *ZsysLogServer) UntagState(req *UntagStateRequest, stream Zsys_UntagStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "UntagState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.UntagState(req, &zsysUntagStateLogStream{
+		Zsys_UntagStateServer: stream,
+		ctx:                   ctx,
+	})
+}
+
+/*
+ * Zsys.PinState()
+ */
+
+// zsysPinStateLogStream is a Zsys_PinStateServer augmented by its own Context containing the log streamer
+type zsysPinStateLogStream struct {
+	Zsys_PinStateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysPinStateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// PinState overrides ZsysServer PinState, installing a logger first
+func (z *ZsysLogServer) PinState(req *PinStateRequest, stream Zsys_PinStateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "PinState")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.PinState(req, &zsysPinStateLogStream{
+		Zsys_PinStateServer: stream,
+		ctx:                 ctx,
+	})
+}
+
+/*
+ * Zsys.StateUsage()
+ */
+
+// zsysStateUsageLogStream is a Zsys_StateUsageServer augmented by its own Context containing the log streamer
+type zsysStateUsageLogStream struct {
+	Zsys_StateUsageServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysStateUsageLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// StateUsage overrides ZsysServer StateUsage, installing a logger first
+func (z *ZsysLogServer) StateUsage(req *StateUsageRequest, stream Zsys_StateUsageServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "StateUsage")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.StateUsage(req, &zsysStateUsageLogStream{
+		Zsys_StateUsageServer: stream,
+		ctx:                   ctx,
+	})
+}
+
 /*
  * Zsys.DumpStates()
  */
@@ -659,6 +1049,96 @@ func (z *ZsysLogServer) GC(req *GCRequest, stream Zsys_GCServer) error {
 	})
 }
 
+/*
+ * Zsys.GCPlan()
+ */
+
+// zsysGCPlanLogStream is a Zsys_GCPlanServer augmented by its own Context containing the log streamer
+type zsysGCPlanLogStream struct {
+	Zsys_GCPlanServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysGCPlanLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// GCPlan overrides ZsysServer GCPlan, installing a logger first
+func (z *ZsysLogServer) GCPlan(req *GCRequest, stream Zsys_GCPlanServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "GCPlan")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.GCPlan(req, &zsysGCPlanLogStream{
+		Zsys_GCPlanServer: stream,
+		ctx:               ctx,
+	})
+}
+
+/*
+ * Zsys.GCSimulate()
+ */
+
+// zsysGCSimulateLogStream is a Zsys_GCSimulateServer augmented by its own Context containing the log streamer
+type zsysGCSimulateLogStream struct {
+	Zsys_GCSimulateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysGCSimulateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// GCSimulate overrides ZsysServer GCSimulate, installing a logger first
+func (z *ZsysLogServer) GCSimulate(req *GCSimulateRequest, stream Zsys_GCSimulateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "GCSimulate")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.GCSimulate(req, &zsysGCSimulateLogStream{
+		Zsys_GCSimulateServer: stream,
+		ctx:                   ctx,
+	})
+}
+
+/*
+ * Zsys.Replicate()
+ */
+
+// zsysReplicateLogStream is a Zsys_ReplicateServer augmented by its own Context containing the log streamer
+type zsysReplicateLogStream struct {
+	Zsys_ReplicateServer
+	ctx context.Context
+}
+
+// Context access the log streamer context
+func (s *zsysReplicateLogStream) Context() context.Context {
+	return s.ctx
+}
+
+// Replicate overrides ZsysServer Replicate, installing a logger first
+func (z *ZsysLogServer) Replicate(req *Empty, stream Zsys_ReplicateServer) error {
+	// it's ok to panic in the assertion as we expect to have generated above the Write() function.
+	ctx, err := streamlogger.AddLogger(stream.(streamlogger.StreamLogger), "Replicate")
+	if err != nil {
+		return fmt.Errorf(i18n.G("couldn't attach a logger to request: %w"), err)
+	}
+
+	// wrap the context to access the context with logger
+	return z.ZsysServerIdleTimeout.Replicate(req, &zsysReplicateLogStream{
+		Zsys_ReplicateServer: stream,
+		ctx:                  ctx,
+	})
+}
+
 /*
  * Zsys.MachineShow()
  */
@@ -749,6 +1229,32 @@ func (s *zsysCreateUserDataServer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Write promote zsysMountUserDataServer to an io.Writer
+func (s *zsysMountUserDataServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysLoadUserKeysServer to an io.Writer
+func (s *zsysLoadUserKeysServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 // Write promote zsysChangeHomeOnUserDataServer to an io.Writer
 func (s *zsysChangeHomeOnUserDataServer) Write(p []byte) (n int, err error) {
 	err = s.Send(
@@ -827,6 +1333,19 @@ func (s *zsysUpdateLastUsedServer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Write promote zsysBootNextServer to an io.Writer
+func (s *zsysBootNextServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 // Write promote zsysSaveSystemStateServer to an io.Writer
 func (s *zsysSaveSystemStateServer) Write(p []byte) (n int, err error) {
 	err = s.Send(
@@ -879,6 +1398,136 @@ func (s *zsysRemoveUserStateServer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Write promote zsysExportStateServer to an io.Writer
+func (s *zsysExportStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&ExportStateResponse{
+			Reply: &ExportStateResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysImportStateServer to an io.Writer
+func (s *zsysImportStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&CreateSaveStateResponse{
+			Reply: &CreateSaveStateResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysRestoreUserStateServer to an io.Writer
+func (s *zsysRestoreUserStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&CreateSaveStateResponse{
+			Reply: &CreateSaveStateResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysRevertDatasetsServer to an io.Writer
+func (s *zsysRevertDatasetsServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysStatePathServer to an io.Writer
+func (s *zsysStatePathServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&StatePathResponse{
+			Reply: &StatePathResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysStateDiffServer to an io.Writer
+func (s *zsysStateDiffServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&StateDiffResponse{
+			Reply: &StateDiffResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysTagStateServer to an io.Writer
+func (s *zsysTagStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysUntagStateServer to an io.Writer
+func (s *zsysUntagStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysPinStateServer to an io.Writer
+func (s *zsysPinStateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysStateUsageServer to an io.Writer
+func (s *zsysStateUsageServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&StateUsageResponse{
+			Reply: &StateUsageResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 // Write promote zsysDumpStatesServer to an io.Writer
 func (s *zsysDumpStatesServer) Write(p []byte) (n int, err error) {
 	err = s.Send(
@@ -983,6 +1632,45 @@ func (s *zsysGCServer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Write promote zsysGCPlanServer to an io.Writer
+func (s *zsysGCPlanServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&GCPlanResponse{
+			Reply: &GCPlanResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysGCSimulateServer to an io.Writer
+func (s *zsysGCSimulateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&GCSimulateResponse{
+			Reply: &GCSimulateResponse_Log{Log: string(p)},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Write promote zsysReplicateServer to an io.Writer
+func (s *zsysReplicateServer) Write(p []byte) (n int, err error) {
+	err = s.Send(
+		&LogResponse{
+			Log: string(p),
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 // Write promote zsysMachineShowServer to an io.Writer
 func (s *zsysMachineShowServer) Write(p []byte) (n int, err error) {
 	err = s.Send(
@@ -21,16 +21,29 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	Zsys_Version_FullMethodName              = "/zsys.Zsys/Version"
 	Zsys_CreateUserData_FullMethodName       = "/zsys.Zsys/CreateUserData"
+	Zsys_MountUserData_FullMethodName        = "/zsys.Zsys/MountUserData"
+	Zsys_LoadUserKeys_FullMethodName         = "/zsys.Zsys/LoadUserKeys"
 	Zsys_ChangeHomeOnUserData_FullMethodName = "/zsys.Zsys/ChangeHomeOnUserData"
 	Zsys_DissociateUser_FullMethodName       = "/zsys.Zsys/DissociateUser"
 	Zsys_PrepareBoot_FullMethodName          = "/zsys.Zsys/PrepareBoot"
 	Zsys_CommitBoot_FullMethodName           = "/zsys.Zsys/CommitBoot"
 	Zsys_UpdateBootMenu_FullMethodName       = "/zsys.Zsys/UpdateBootMenu"
 	Zsys_UpdateLastUsed_FullMethodName       = "/zsys.Zsys/UpdateLastUsed"
+	Zsys_BootNext_FullMethodName             = "/zsys.Zsys/BootNext"
 	Zsys_SaveSystemState_FullMethodName      = "/zsys.Zsys/SaveSystemState"
 	Zsys_SaveUserState_FullMethodName        = "/zsys.Zsys/SaveUserState"
 	Zsys_RemoveSystemState_FullMethodName    = "/zsys.Zsys/RemoveSystemState"
 	Zsys_RemoveUserState_FullMethodName      = "/zsys.Zsys/RemoveUserState"
+	Zsys_ExportState_FullMethodName          = "/zsys.Zsys/ExportState"
+	Zsys_ImportState_FullMethodName          = "/zsys.Zsys/ImportState"
+	Zsys_RestoreUserState_FullMethodName     = "/zsys.Zsys/RestoreUserState"
+	Zsys_RevertDatasets_FullMethodName       = "/zsys.Zsys/RevertDatasets"
+	Zsys_StatePath_FullMethodName            = "/zsys.Zsys/StatePath"
+	Zsys_StateDiff_FullMethodName            = "/zsys.Zsys/StateDiff"
+	Zsys_TagState_FullMethodName             = "/zsys.Zsys/TagState"
+	Zsys_UntagState_FullMethodName           = "/zsys.Zsys/UntagState"
+	Zsys_PinState_FullMethodName             = "/zsys.Zsys/PinState"
+	Zsys_StateUsage_FullMethodName           = "/zsys.Zsys/StateUsage"
 	Zsys_DumpStates_FullMethodName           = "/zsys.Zsys/DumpStates"
 	Zsys_DaemonStop_FullMethodName           = "/zsys.Zsys/DaemonStop"
 	Zsys_LoggingLevel_FullMethodName         = "/zsys.Zsys/LoggingLevel"
@@ -39,6 +52,9 @@ const (
 	Zsys_Status_FullMethodName               = "/zsys.Zsys/Status"
 	Zsys_Reload_FullMethodName               = "/zsys.Zsys/Reload"
 	Zsys_GC_FullMethodName                   = "/zsys.Zsys/GC"
+	Zsys_GCPlan_FullMethodName               = "/zsys.Zsys/GCPlan"
+	Zsys_GCSimulate_FullMethodName           = "/zsys.Zsys/GCSimulate"
+	Zsys_Replicate_FullMethodName            = "/zsys.Zsys/Replicate"
 	Zsys_MachineShow_FullMethodName          = "/zsys.Zsys/MachineShow"
 	Zsys_MachineList_FullMethodName          = "/zsys.Zsys/MachineList"
 )
@@ -49,16 +65,29 @@ const (
 type ZsysClient interface {
 	Version(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_VersionClient, error)
 	CreateUserData(ctx context.Context, in *CreateUserDataRequest, opts ...grpc.CallOption) (Zsys_CreateUserDataClient, error)
+	MountUserData(ctx context.Context, in *MountUserDataRequest, opts ...grpc.CallOption) (Zsys_MountUserDataClient, error)
+	LoadUserKeys(ctx context.Context, in *LoadUserKeysRequest, opts ...grpc.CallOption) (Zsys_LoadUserKeysClient, error)
 	ChangeHomeOnUserData(ctx context.Context, in *ChangeHomeOnUserDataRequest, opts ...grpc.CallOption) (Zsys_ChangeHomeOnUserDataClient, error)
 	DissociateUser(ctx context.Context, in *DissociateUserRequest, opts ...grpc.CallOption) (Zsys_DissociateUserClient, error)
 	PrepareBoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_PrepareBootClient, error)
 	CommitBoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_CommitBootClient, error)
 	UpdateBootMenu(ctx context.Context, in *UpdateBootMenuRequest, opts ...grpc.CallOption) (Zsys_UpdateBootMenuClient, error)
 	UpdateLastUsed(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_UpdateLastUsedClient, error)
+	BootNext(ctx context.Context, in *BootNextRequest, opts ...grpc.CallOption) (Zsys_BootNextClient, error)
 	SaveSystemState(ctx context.Context, in *SaveSystemStateRequest, opts ...grpc.CallOption) (Zsys_SaveSystemStateClient, error)
 	SaveUserState(ctx context.Context, in *SaveUserStateRequest, opts ...grpc.CallOption) (Zsys_SaveUserStateClient, error)
 	RemoveSystemState(ctx context.Context, in *RemoveSystemStateRequest, opts ...grpc.CallOption) (Zsys_RemoveSystemStateClient, error)
 	RemoveUserState(ctx context.Context, in *RemoveUserStateRequest, opts ...grpc.CallOption) (Zsys_RemoveUserStateClient, error)
+	ExportState(ctx context.Context, in *ExportStateRequest, opts ...grpc.CallOption) (Zsys_ExportStateClient, error)
+	ImportState(ctx context.Context, opts ...grpc.CallOption) (Zsys_ImportStateClient, error)
+	RestoreUserState(ctx context.Context, in *RestoreUserStateRequest, opts ...grpc.CallOption) (Zsys_RestoreUserStateClient, error)
+	RevertDatasets(ctx context.Context, in *RevertDatasetsRequest, opts ...grpc.CallOption) (Zsys_RevertDatasetsClient, error)
+	StatePath(ctx context.Context, in *StatePathRequest, opts ...grpc.CallOption) (Zsys_StatePathClient, error)
+	StateDiff(ctx context.Context, in *StateDiffRequest, opts ...grpc.CallOption) (Zsys_StateDiffClient, error)
+	TagState(ctx context.Context, in *TagStateRequest, opts ...grpc.CallOption) (Zsys_TagStateClient, error)
+	UntagState(ctx context.Context, in *UntagStateRequest, opts ...grpc.CallOption) (Zsys_UntagStateClient, error)
+	PinState(ctx context.Context, in *PinStateRequest, opts ...grpc.CallOption) (Zsys_PinStateClient, error)
+	StateUsage(ctx context.Context, in *StateUsageRequest, opts ...grpc.CallOption) (Zsys_StateUsageClient, error)
 	DumpStates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DumpStatesClient, error)
 	DaemonStop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DaemonStopClient, error)
 	LoggingLevel(ctx context.Context, in *LoggingLevelRequest, opts ...grpc.CallOption) (Zsys_LoggingLevelClient, error)
@@ -67,6 +96,9 @@ type ZsysClient interface {
 	Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_StatusClient, error)
 	Reload(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_ReloadClient, error)
 	GC(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (Zsys_GCClient, error)
+	GCPlan(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (Zsys_GCPlanClient, error)
+	GCSimulate(ctx context.Context, in *GCSimulateRequest, opts ...grpc.CallOption) (Zsys_GCSimulateClient, error)
+	Replicate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_ReplicateClient, error)
 	MachineShow(ctx context.Context, in *MachineShowRequest, opts ...grpc.CallOption) (Zsys_MachineShowClient, error)
 	MachineList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_MachineListClient, error)
 }
@@ -143,8 +175,72 @@ func (x *zsysCreateUserDataClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
+func (c *zsysClient) MountUserData(ctx context.Context, in *MountUserDataRequest, opts ...grpc.CallOption) (Zsys_MountUserDataClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[2], Zsys_MountUserData_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysMountUserDataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_MountUserDataClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysMountUserDataClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysMountUserDataClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) LoadUserKeys(ctx context.Context, in *LoadUserKeysRequest, opts ...grpc.CallOption) (Zsys_LoadUserKeysClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[3], Zsys_LoadUserKeys_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysLoadUserKeysClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_LoadUserKeysClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysLoadUserKeysClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysLoadUserKeysClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *zsysClient) ChangeHomeOnUserData(ctx context.Context, in *ChangeHomeOnUserDataRequest, opts ...grpc.CallOption) (Zsys_ChangeHomeOnUserDataClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[2], Zsys_ChangeHomeOnUserData_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[4], Zsys_ChangeHomeOnUserData_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +272,7 @@ func (x *zsysChangeHomeOnUserDataClient) Recv() (*LogResponse, error) {
 }
 
 func (c *zsysClient) DissociateUser(ctx context.Context, in *DissociateUserRequest, opts ...grpc.CallOption) (Zsys_DissociateUserClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[3], Zsys_DissociateUser_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[5], Zsys_DissociateUser_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +304,7 @@ func (x *zsysDissociateUserClient) Recv() (*LogResponse, error) {
 }
 
 func (c *zsysClient) PrepareBoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_PrepareBootClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[4], Zsys_PrepareBoot_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[6], Zsys_PrepareBoot_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +336,7 @@ func (x *zsysPrepareBootClient) Recv() (*PrepareBootResponse, error) {
 }
 
 func (c *zsysClient) CommitBoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_CommitBootClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[5], Zsys_CommitBoot_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[7], Zsys_CommitBoot_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +368,7 @@ func (x *zsysCommitBootClient) Recv() (*CommitBootResponse, error) {
 }
 
 func (c *zsysClient) UpdateBootMenu(ctx context.Context, in *UpdateBootMenuRequest, opts ...grpc.CallOption) (Zsys_UpdateBootMenuClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[6], Zsys_UpdateBootMenu_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[8], Zsys_UpdateBootMenu_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +400,7 @@ func (x *zsysUpdateBootMenuClient) Recv() (*LogResponse, error) {
 }
 
 func (c *zsysClient) UpdateLastUsed(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_UpdateLastUsedClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[7], Zsys_UpdateLastUsed_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[9], Zsys_UpdateLastUsed_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -335,8 +431,40 @@ func (x *zsysUpdateLastUsedClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
+func (c *zsysClient) BootNext(ctx context.Context, in *BootNextRequest, opts ...grpc.CallOption) (Zsys_BootNextClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[10], Zsys_BootNext_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysBootNextClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_BootNextClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysBootNextClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysBootNextClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *zsysClient) SaveSystemState(ctx context.Context, in *SaveSystemStateRequest, opts ...grpc.CallOption) (Zsys_SaveSystemStateClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[8], Zsys_SaveSystemState_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[11], Zsys_SaveSystemState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -368,7 +496,7 @@ func (x *zsysSaveSystemStateClient) Recv() (*CreateSaveStateResponse, error) {
 }
 
 func (c *zsysClient) SaveUserState(ctx context.Context, in *SaveUserStateRequest, opts ...grpc.CallOption) (Zsys_SaveUserStateClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[9], Zsys_SaveUserState_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[12], Zsys_SaveUserState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -400,7 +528,7 @@ func (x *zsysSaveUserStateClient) Recv() (*CreateSaveStateResponse, error) {
 }
 
 func (c *zsysClient) RemoveSystemState(ctx context.Context, in *RemoveSystemStateRequest, opts ...grpc.CallOption) (Zsys_RemoveSystemStateClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[10], Zsys_RemoveSystemState_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[13], Zsys_RemoveSystemState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -432,7 +560,7 @@ func (x *zsysRemoveSystemStateClient) Recv() (*LogResponse, error) {
 }
 
 func (c *zsysClient) RemoveUserState(ctx context.Context, in *RemoveUserStateRequest, opts ...grpc.CallOption) (Zsys_RemoveUserStateClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[11], Zsys_RemoveUserState_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[14], Zsys_RemoveUserState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -463,12 +591,12 @@ func (x *zsysRemoveUserStateClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
-func (c *zsysClient) DumpStates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DumpStatesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[12], Zsys_DumpStates_FullMethodName, opts...)
+func (c *zsysClient) ExportState(ctx context.Context, in *ExportStateRequest, opts ...grpc.CallOption) (Zsys_ExportStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[15], Zsys_ExportState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysDumpStatesClient{stream}
+	x := &zsysExportStateClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -478,29 +606,60 @@ func (c *zsysClient) DumpStates(ctx context.Context, in *Empty, opts ...grpc.Cal
 	return x, nil
 }
 
-type Zsys_DumpStatesClient interface {
-	Recv() (*DumpStatesResponse, error)
+type Zsys_ExportStateClient interface {
+	Recv() (*ExportStateResponse, error)
 	grpc.ClientStream
 }
 
-type zsysDumpStatesClient struct {
+type zsysExportStateClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysDumpStatesClient) Recv() (*DumpStatesResponse, error) {
-	m := new(DumpStatesResponse)
+func (x *zsysExportStateClient) Recv() (*ExportStateResponse, error) {
+	m := new(ExportStateResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *zsysClient) DaemonStop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DaemonStopClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[13], Zsys_DaemonStop_FullMethodName, opts...)
+func (c *zsysClient) ImportState(ctx context.Context, opts ...grpc.CallOption) (Zsys_ImportStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[16], Zsys_ImportState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysDaemonStopClient{stream}
+	x := &zsysImportStateClient{stream}
+	return x, nil
+}
+
+type Zsys_ImportStateClient interface {
+	Send(*ImportStateRequest) error
+	Recv() (*CreateSaveStateResponse, error)
+	grpc.ClientStream
+}
+
+type zsysImportStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysImportStateClient) Send(m *ImportStateRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *zsysImportStateClient) Recv() (*CreateSaveStateResponse, error) {
+	m := new(CreateSaveStateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) RestoreUserState(ctx context.Context, in *RestoreUserStateRequest, opts ...grpc.CallOption) (Zsys_RestoreUserStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[17], Zsys_RestoreUserState_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysRestoreUserStateClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -510,29 +669,29 @@ func (c *zsysClient) DaemonStop(ctx context.Context, in *Empty, opts ...grpc.Cal
 	return x, nil
 }
 
-type Zsys_DaemonStopClient interface {
-	Recv() (*LogResponse, error)
+type Zsys_RestoreUserStateClient interface {
+	Recv() (*CreateSaveStateResponse, error)
 	grpc.ClientStream
 }
 
-type zsysDaemonStopClient struct {
+type zsysRestoreUserStateClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysDaemonStopClient) Recv() (*LogResponse, error) {
-	m := new(LogResponse)
+func (x *zsysRestoreUserStateClient) Recv() (*CreateSaveStateResponse, error) {
+	m := new(CreateSaveStateResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *zsysClient) LoggingLevel(ctx context.Context, in *LoggingLevelRequest, opts ...grpc.CallOption) (Zsys_LoggingLevelClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[14], Zsys_LoggingLevel_FullMethodName, opts...)
+func (c *zsysClient) RevertDatasets(ctx context.Context, in *RevertDatasetsRequest, opts ...grpc.CallOption) (Zsys_RevertDatasetsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[18], Zsys_RevertDatasets_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysLoggingLevelClient{stream}
+	x := &zsysRevertDatasetsClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -542,16 +701,16 @@ func (c *zsysClient) LoggingLevel(ctx context.Context, in *LoggingLevelRequest,
 	return x, nil
 }
 
-type Zsys_LoggingLevelClient interface {
+type Zsys_RevertDatasetsClient interface {
 	Recv() (*LogResponse, error)
 	grpc.ClientStream
 }
 
-type zsysLoggingLevelClient struct {
+type zsysRevertDatasetsClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysLoggingLevelClient) Recv() (*LogResponse, error) {
+func (x *zsysRevertDatasetsClient) Recv() (*LogResponse, error) {
 	m := new(LogResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
@@ -559,12 +718,12 @@ func (x *zsysLoggingLevelClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
-func (c *zsysClient) Refresh(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_RefreshClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[15], Zsys_Refresh_FullMethodName, opts...)
+func (c *zsysClient) StatePath(ctx context.Context, in *StatePathRequest, opts ...grpc.CallOption) (Zsys_StatePathClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[19], Zsys_StatePath_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysRefreshClient{stream}
+	x := &zsysStatePathClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -574,29 +733,29 @@ func (c *zsysClient) Refresh(ctx context.Context, in *Empty, opts ...grpc.CallOp
 	return x, nil
 }
 
-type Zsys_RefreshClient interface {
-	Recv() (*LogResponse, error)
+type Zsys_StatePathClient interface {
+	Recv() (*StatePathResponse, error)
 	grpc.ClientStream
 }
 
-type zsysRefreshClient struct {
+type zsysStatePathClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysRefreshClient) Recv() (*LogResponse, error) {
-	m := new(LogResponse)
+func (x *zsysStatePathClient) Recv() (*StatePathResponse, error) {
+	m := new(StatePathResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *zsysClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (Zsys_TraceClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[16], Zsys_Trace_FullMethodName, opts...)
+func (c *zsysClient) StateDiff(ctx context.Context, in *StateDiffRequest, opts ...grpc.CallOption) (Zsys_StateDiffClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[20], Zsys_StateDiff_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysTraceClient{stream}
+	x := &zsysStateDiffClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -606,29 +765,29 @@ func (c *zsysClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.C
 	return x, nil
 }
 
-type Zsys_TraceClient interface {
-	Recv() (*TraceResponse, error)
+type Zsys_StateDiffClient interface {
+	Recv() (*StateDiffResponse, error)
 	grpc.ClientStream
 }
 
-type zsysTraceClient struct {
+type zsysStateDiffClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysTraceClient) Recv() (*TraceResponse, error) {
-	m := new(TraceResponse)
+func (x *zsysStateDiffClient) Recv() (*StateDiffResponse, error) {
+	m := new(StateDiffResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *zsysClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_StatusClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[17], Zsys_Status_FullMethodName, opts...)
+func (c *zsysClient) TagState(ctx context.Context, in *TagStateRequest, opts ...grpc.CallOption) (Zsys_TagStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[21], Zsys_TagState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysStatusClient{stream}
+	x := &zsysTagStateClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -638,16 +797,16 @@ func (c *zsysClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOpt
 	return x, nil
 }
 
-type Zsys_StatusClient interface {
+type Zsys_TagStateClient interface {
 	Recv() (*LogResponse, error)
 	grpc.ClientStream
 }
 
-type zsysStatusClient struct {
+type zsysTagStateClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysStatusClient) Recv() (*LogResponse, error) {
+func (x *zsysTagStateClient) Recv() (*LogResponse, error) {
 	m := new(LogResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
@@ -655,12 +814,12 @@ func (x *zsysStatusClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
-func (c *zsysClient) Reload(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_ReloadClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[18], Zsys_Reload_FullMethodName, opts...)
+func (c *zsysClient) UntagState(ctx context.Context, in *UntagStateRequest, opts ...grpc.CallOption) (Zsys_UntagStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[22], Zsys_UntagState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysReloadClient{stream}
+	x := &zsysUntagStateClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -670,16 +829,16 @@ func (c *zsysClient) Reload(ctx context.Context, in *Empty, opts ...grpc.CallOpt
 	return x, nil
 }
 
-type Zsys_ReloadClient interface {
+type Zsys_UntagStateClient interface {
 	Recv() (*LogResponse, error)
 	grpc.ClientStream
 }
 
-type zsysReloadClient struct {
+type zsysUntagStateClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysReloadClient) Recv() (*LogResponse, error) {
+func (x *zsysUntagStateClient) Recv() (*LogResponse, error) {
 	m := new(LogResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
@@ -687,12 +846,12 @@ func (x *zsysReloadClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
-func (c *zsysClient) GC(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (Zsys_GCClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[19], Zsys_GC_FullMethodName, opts...)
+func (c *zsysClient) PinState(ctx context.Context, in *PinStateRequest, opts ...grpc.CallOption) (Zsys_PinStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[23], Zsys_PinState_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysGCClient{stream}
+	x := &zsysPinStateClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -702,16 +861,16 @@ func (c *zsysClient) GC(ctx context.Context, in *GCRequest, opts ...grpc.CallOpt
 	return x, nil
 }
 
-type Zsys_GCClient interface {
+type Zsys_PinStateClient interface {
 	Recv() (*LogResponse, error)
 	grpc.ClientStream
 }
 
-type zsysGCClient struct {
+type zsysPinStateClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysGCClient) Recv() (*LogResponse, error) {
+func (x *zsysPinStateClient) Recv() (*LogResponse, error) {
 	m := new(LogResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
@@ -719,12 +878,12 @@ func (x *zsysGCClient) Recv() (*LogResponse, error) {
 	return m, nil
 }
 
-func (c *zsysClient) MachineShow(ctx context.Context, in *MachineShowRequest, opts ...grpc.CallOption) (Zsys_MachineShowClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[20], Zsys_MachineShow_FullMethodName, opts...)
+func (c *zsysClient) StateUsage(ctx context.Context, in *StateUsageRequest, opts ...grpc.CallOption) (Zsys_StateUsageClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[24], Zsys_StateUsage_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysMachineShowClient{stream}
+	x := &zsysStateUsageClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -734,29 +893,29 @@ func (c *zsysClient) MachineShow(ctx context.Context, in *MachineShowRequest, op
 	return x, nil
 }
 
-type Zsys_MachineShowClient interface {
-	Recv() (*MachineShowResponse, error)
+type Zsys_StateUsageClient interface {
+	Recv() (*StateUsageResponse, error)
 	grpc.ClientStream
 }
 
-type zsysMachineShowClient struct {
+type zsysStateUsageClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysMachineShowClient) Recv() (*MachineShowResponse, error) {
-	m := new(MachineShowResponse)
+func (x *zsysStateUsageClient) Recv() (*StateUsageResponse, error) {
+	m := new(StateUsageResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *zsysClient) MachineList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_MachineListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[21], Zsys_MachineList_FullMethodName, opts...)
+func (c *zsysClient) DumpStates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DumpStatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[25], Zsys_DumpStates_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &zsysMachineListClient{stream}
+	x := &zsysDumpStatesClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -766,72 +925,478 @@ func (c *zsysClient) MachineList(ctx context.Context, in *Empty, opts ...grpc.Ca
 	return x, nil
 }
 
-type Zsys_MachineListClient interface {
-	Recv() (*MachineListResponse, error)
+type Zsys_DumpStatesClient interface {
+	Recv() (*DumpStatesResponse, error)
 	grpc.ClientStream
 }
 
-type zsysMachineListClient struct {
+type zsysDumpStatesClient struct {
 	grpc.ClientStream
 }
 
-func (x *zsysMachineListClient) Recv() (*MachineListResponse, error) {
-	m := new(MachineListResponse)
+func (x *zsysDumpStatesClient) Recv() (*DumpStatesResponse, error) {
+	m := new(DumpStatesResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// ZsysServer is the server API for Zsys service.
-// All implementations should embed UnimplementedZsysServer
-// for forward compatibility
-type ZsysServer interface {
-	Version(*Empty, Zsys_VersionServer) error
-	CreateUserData(*CreateUserDataRequest, Zsys_CreateUserDataServer) error
-	ChangeHomeOnUserData(*ChangeHomeOnUserDataRequest, Zsys_ChangeHomeOnUserDataServer) error
-	DissociateUser(*DissociateUserRequest, Zsys_DissociateUserServer) error
-	PrepareBoot(*Empty, Zsys_PrepareBootServer) error
-	CommitBoot(*Empty, Zsys_CommitBootServer) error
-	UpdateBootMenu(*UpdateBootMenuRequest, Zsys_UpdateBootMenuServer) error
-	UpdateLastUsed(*Empty, Zsys_UpdateLastUsedServer) error
-	SaveSystemState(*SaveSystemStateRequest, Zsys_SaveSystemStateServer) error
-	SaveUserState(*SaveUserStateRequest, Zsys_SaveUserStateServer) error
-	RemoveSystemState(*RemoveSystemStateRequest, Zsys_RemoveSystemStateServer) error
-	RemoveUserState(*RemoveUserStateRequest, Zsys_RemoveUserStateServer) error
-	DumpStates(*Empty, Zsys_DumpStatesServer) error
-	DaemonStop(*Empty, Zsys_DaemonStopServer) error
-	LoggingLevel(*LoggingLevelRequest, Zsys_LoggingLevelServer) error
-	Refresh(*Empty, Zsys_RefreshServer) error
-	Trace(*TraceRequest, Zsys_TraceServer) error
-	Status(*Empty, Zsys_StatusServer) error
-	Reload(*Empty, Zsys_ReloadServer) error
-	GC(*GCRequest, Zsys_GCServer) error
-	MachineShow(*MachineShowRequest, Zsys_MachineShowServer) error
-	MachineList(*Empty, Zsys_MachineListServer) error
+func (c *zsysClient) DaemonStop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_DaemonStopClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[26], Zsys_DaemonStop_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysDaemonStopClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-// UnimplementedZsysServer should be embedded to have forward compatible implementations.
-type UnimplementedZsysServer struct {
+type Zsys_DaemonStopClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
 }
 
-func (UnimplementedZsysServer) Version(*Empty, Zsys_VersionServer) error {
-	return status.Errorf(codes.Unimplemented, "method Version not implemented")
-}
-func (UnimplementedZsysServer) CreateUserData(*CreateUserDataRequest, Zsys_CreateUserDataServer) error {
-	return status.Errorf(codes.Unimplemented, "method CreateUserData not implemented")
+type zsysDaemonStopClient struct {
+	grpc.ClientStream
 }
-func (UnimplementedZsysServer) ChangeHomeOnUserData(*ChangeHomeOnUserDataRequest, Zsys_ChangeHomeOnUserDataServer) error {
-	return status.Errorf(codes.Unimplemented, "method ChangeHomeOnUserData not implemented")
+
+func (x *zsysDaemonStopClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (UnimplementedZsysServer) DissociateUser(*DissociateUserRequest, Zsys_DissociateUserServer) error {
-	return status.Errorf(codes.Unimplemented, "method DissociateUser not implemented")
+
+func (c *zsysClient) LoggingLevel(ctx context.Context, in *LoggingLevelRequest, opts ...grpc.CallOption) (Zsys_LoggingLevelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[27], Zsys_LoggingLevel_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysLoggingLevelClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
-func (UnimplementedZsysServer) PrepareBoot(*Empty, Zsys_PrepareBootServer) error {
-	return status.Errorf(codes.Unimplemented, "method PrepareBoot not implemented")
+
+type Zsys_LoggingLevelClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
 }
-func (UnimplementedZsysServer) CommitBoot(*Empty, Zsys_CommitBootServer) error {
-	return status.Errorf(codes.Unimplemented, "method CommitBoot not implemented")
+
+type zsysLoggingLevelClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysLoggingLevelClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) Refresh(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_RefreshClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[28], Zsys_Refresh_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysRefreshClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_RefreshClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysRefreshClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysRefreshClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (Zsys_TraceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[29], Zsys_Trace_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysTraceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_TraceClient interface {
+	Recv() (*TraceResponse, error)
+	grpc.ClientStream
+}
+
+type zsysTraceClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysTraceClient) Recv() (*TraceResponse, error) {
+	m := new(TraceResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_StatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[30], Zsys_Status_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_StatusClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysStatusClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) Reload(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_ReloadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[31], Zsys_Reload_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysReloadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_ReloadClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysReloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysReloadClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) GC(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (Zsys_GCClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[32], Zsys_GC_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysGCClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_GCClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysGCClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysGCClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) GCPlan(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (Zsys_GCPlanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[33], Zsys_GCPlan_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysGCPlanClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_GCPlanClient interface {
+	Recv() (*GCPlanResponse, error)
+	grpc.ClientStream
+}
+
+type zsysGCPlanClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysGCPlanClient) Recv() (*GCPlanResponse, error) {
+	m := new(GCPlanResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) GCSimulate(ctx context.Context, in *GCSimulateRequest, opts ...grpc.CallOption) (Zsys_GCSimulateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[34], Zsys_GCSimulate_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysGCSimulateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_GCSimulateClient interface {
+	Recv() (*GCSimulateResponse, error)
+	grpc.ClientStream
+}
+
+type zsysGCSimulateClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysGCSimulateClient) Recv() (*GCSimulateResponse, error) {
+	m := new(GCSimulateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) Replicate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_ReplicateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[35], Zsys_Replicate_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysReplicateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_ReplicateClient interface {
+	Recv() (*LogResponse, error)
+	grpc.ClientStream
+}
+
+type zsysReplicateClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysReplicateClient) Recv() (*LogResponse, error) {
+	m := new(LogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) MachineShow(ctx context.Context, in *MachineShowRequest, opts ...grpc.CallOption) (Zsys_MachineShowClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[36], Zsys_MachineShow_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysMachineShowClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_MachineShowClient interface {
+	Recv() (*MachineShowResponse, error)
+	grpc.ClientStream
+}
+
+type zsysMachineShowClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysMachineShowClient) Recv() (*MachineShowResponse, error) {
+	m := new(MachineShowResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zsysClient) MachineList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Zsys_MachineListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zsys_ServiceDesc.Streams[37], Zsys_MachineList_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zsysMachineListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zsys_MachineListClient interface {
+	Recv() (*MachineListResponse, error)
+	grpc.ClientStream
+}
+
+type zsysMachineListClient struct {
+	grpc.ClientStream
+}
+
+func (x *zsysMachineListClient) Recv() (*MachineListResponse, error) {
+	m := new(MachineListResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ZsysServer is the server API for Zsys service.
+// All implementations should embed UnimplementedZsysServer
+// for forward compatibility
+type ZsysServer interface {
+	Version(*Empty, Zsys_VersionServer) error
+	CreateUserData(*CreateUserDataRequest, Zsys_CreateUserDataServer) error
+	MountUserData(*MountUserDataRequest, Zsys_MountUserDataServer) error
+	LoadUserKeys(*LoadUserKeysRequest, Zsys_LoadUserKeysServer) error
+	ChangeHomeOnUserData(*ChangeHomeOnUserDataRequest, Zsys_ChangeHomeOnUserDataServer) error
+	DissociateUser(*DissociateUserRequest, Zsys_DissociateUserServer) error
+	PrepareBoot(*Empty, Zsys_PrepareBootServer) error
+	CommitBoot(*Empty, Zsys_CommitBootServer) error
+	UpdateBootMenu(*UpdateBootMenuRequest, Zsys_UpdateBootMenuServer) error
+	UpdateLastUsed(*Empty, Zsys_UpdateLastUsedServer) error
+	BootNext(*BootNextRequest, Zsys_BootNextServer) error
+	SaveSystemState(*SaveSystemStateRequest, Zsys_SaveSystemStateServer) error
+	SaveUserState(*SaveUserStateRequest, Zsys_SaveUserStateServer) error
+	RemoveSystemState(*RemoveSystemStateRequest, Zsys_RemoveSystemStateServer) error
+	RemoveUserState(*RemoveUserStateRequest, Zsys_RemoveUserStateServer) error
+	ExportState(*ExportStateRequest, Zsys_ExportStateServer) error
+	ImportState(Zsys_ImportStateServer) error
+	RestoreUserState(*RestoreUserStateRequest, Zsys_RestoreUserStateServer) error
+	RevertDatasets(*RevertDatasetsRequest, Zsys_RevertDatasetsServer) error
+	StatePath(*StatePathRequest, Zsys_StatePathServer) error
+	StateDiff(*StateDiffRequest, Zsys_StateDiffServer) error
+	TagState(*TagStateRequest, Zsys_TagStateServer) error
+	UntagState(*UntagStateRequest, Zsys_UntagStateServer) error
+	PinState(*PinStateRequest, Zsys_PinStateServer) error
+	StateUsage(*StateUsageRequest, Zsys_StateUsageServer) error
+	DumpStates(*Empty, Zsys_DumpStatesServer) error
+	DaemonStop(*Empty, Zsys_DaemonStopServer) error
+	LoggingLevel(*LoggingLevelRequest, Zsys_LoggingLevelServer) error
+	Refresh(*Empty, Zsys_RefreshServer) error
+	Trace(*TraceRequest, Zsys_TraceServer) error
+	Status(*Empty, Zsys_StatusServer) error
+	Reload(*Empty, Zsys_ReloadServer) error
+	GC(*GCRequest, Zsys_GCServer) error
+	GCPlan(*GCRequest, Zsys_GCPlanServer) error
+	GCSimulate(*GCSimulateRequest, Zsys_GCSimulateServer) error
+	Replicate(*Empty, Zsys_ReplicateServer) error
+	MachineShow(*MachineShowRequest, Zsys_MachineShowServer) error
+	MachineList(*Empty, Zsys_MachineListServer) error
+}
+
+// UnimplementedZsysServer should be embedded to have forward compatible implementations.
+type UnimplementedZsysServer struct {
+}
+
+func (UnimplementedZsysServer) Version(*Empty, Zsys_VersionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedZsysServer) CreateUserData(*CreateUserDataRequest, Zsys_CreateUserDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateUserData not implemented")
+}
+func (UnimplementedZsysServer) MountUserData(*MountUserDataRequest, Zsys_MountUserDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method MountUserData not implemented")
+}
+func (UnimplementedZsysServer) LoadUserKeys(*LoadUserKeysRequest, Zsys_LoadUserKeysServer) error {
+	return status.Errorf(codes.Unimplemented, "method LoadUserKeys not implemented")
+}
+func (UnimplementedZsysServer) ChangeHomeOnUserData(*ChangeHomeOnUserDataRequest, Zsys_ChangeHomeOnUserDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method ChangeHomeOnUserData not implemented")
+}
+func (UnimplementedZsysServer) DissociateUser(*DissociateUserRequest, Zsys_DissociateUserServer) error {
+	return status.Errorf(codes.Unimplemented, "method DissociateUser not implemented")
+}
+func (UnimplementedZsysServer) PrepareBoot(*Empty, Zsys_PrepareBootServer) error {
+	return status.Errorf(codes.Unimplemented, "method PrepareBoot not implemented")
+}
+func (UnimplementedZsysServer) CommitBoot(*Empty, Zsys_CommitBootServer) error {
+	return status.Errorf(codes.Unimplemented, "method CommitBoot not implemented")
 }
 func (UnimplementedZsysServer) UpdateBootMenu(*UpdateBootMenuRequest, Zsys_UpdateBootMenuServer) error {
 	return status.Errorf(codes.Unimplemented, "method UpdateBootMenu not implemented")
@@ -839,6 +1404,9 @@ func (UnimplementedZsysServer) UpdateBootMenu(*UpdateBootMenuRequest, Zsys_Updat
 func (UnimplementedZsysServer) UpdateLastUsed(*Empty, Zsys_UpdateLastUsedServer) error {
 	return status.Errorf(codes.Unimplemented, "method UpdateLastUsed not implemented")
 }
+func (UnimplementedZsysServer) BootNext(*BootNextRequest, Zsys_BootNextServer) error {
+	return status.Errorf(codes.Unimplemented, "method BootNext not implemented")
+}
 func (UnimplementedZsysServer) SaveSystemState(*SaveSystemStateRequest, Zsys_SaveSystemStateServer) error {
 	return status.Errorf(codes.Unimplemented, "method SaveSystemState not implemented")
 }
@@ -851,6 +1419,36 @@ func (UnimplementedZsysServer) RemoveSystemState(*RemoveSystemStateRequest, Zsys
 func (UnimplementedZsysServer) RemoveUserState(*RemoveUserStateRequest, Zsys_RemoveUserStateServer) error {
 	return status.Errorf(codes.Unimplemented, "method RemoveUserState not implemented")
 }
+func (UnimplementedZsysServer) ExportState(*ExportStateRequest, Zsys_ExportStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportState not implemented")
+}
+func (UnimplementedZsysServer) ImportState(Zsys_ImportStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportState not implemented")
+}
+func (UnimplementedZsysServer) RestoreUserState(*RestoreUserStateRequest, Zsys_RestoreUserStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method RestoreUserState not implemented")
+}
+func (UnimplementedZsysServer) RevertDatasets(*RevertDatasetsRequest, Zsys_RevertDatasetsServer) error {
+	return status.Errorf(codes.Unimplemented, "method RevertDatasets not implemented")
+}
+func (UnimplementedZsysServer) StatePath(*StatePathRequest, Zsys_StatePathServer) error {
+	return status.Errorf(codes.Unimplemented, "method StatePath not implemented")
+}
+func (UnimplementedZsysServer) StateDiff(*StateDiffRequest, Zsys_StateDiffServer) error {
+	return status.Errorf(codes.Unimplemented, "method StateDiff not implemented")
+}
+func (UnimplementedZsysServer) TagState(*TagStateRequest, Zsys_TagStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method TagState not implemented")
+}
+func (UnimplementedZsysServer) UntagState(*UntagStateRequest, Zsys_UntagStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method UntagState not implemented")
+}
+func (UnimplementedZsysServer) PinState(*PinStateRequest, Zsys_PinStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method PinState not implemented")
+}
+func (UnimplementedZsysServer) StateUsage(*StateUsageRequest, Zsys_StateUsageServer) error {
+	return status.Errorf(codes.Unimplemented, "method StateUsage not implemented")
+}
 func (UnimplementedZsysServer) DumpStates(*Empty, Zsys_DumpStatesServer) error {
 	return status.Errorf(codes.Unimplemented, "method DumpStates not implemented")
 }
@@ -875,6 +1473,15 @@ func (UnimplementedZsysServer) Reload(*Empty, Zsys_ReloadServer) error {
 func (UnimplementedZsysServer) GC(*GCRequest, Zsys_GCServer) error {
 	return status.Errorf(codes.Unimplemented, "method GC not implemented")
 }
+func (UnimplementedZsysServer) GCPlan(*GCRequest, Zsys_GCPlanServer) error {
+	return status.Errorf(codes.Unimplemented, "method GCPlan not implemented")
+}
+func (UnimplementedZsysServer) GCSimulate(*GCSimulateRequest, Zsys_GCSimulateServer) error {
+	return status.Errorf(codes.Unimplemented, "method GCSimulate not implemented")
+}
+func (UnimplementedZsysServer) Replicate(*Empty, Zsys_ReplicateServer) error {
+	return status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
 func (UnimplementedZsysServer) MachineShow(*MachineShowRequest, Zsys_MachineShowServer) error {
 	return status.Errorf(codes.Unimplemented, "method MachineShow not implemented")
 }
@@ -935,6 +1542,48 @@ func (x *zsysCreateUserDataServer) Send(m *LogResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Zsys_MountUserData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MountUserDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).MountUserData(m, &zsysMountUserDataServer{stream})
+}
+
+type Zsys_MountUserDataServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysMountUserDataServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysMountUserDataServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_LoadUserKeys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LoadUserKeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).LoadUserKeys(m, &zsysLoadUserKeysServer{stream})
+}
+
+type Zsys_LoadUserKeysServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysLoadUserKeysServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysLoadUserKeysServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Zsys_ChangeHomeOnUserData_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ChangeHomeOnUserDataRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1061,6 +1710,27 @@ func (x *zsysUpdateLastUsedServer) Send(m *LogResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Zsys_BootNext_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BootNextRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).BootNext(m, &zsysBootNextServer{stream})
+}
+
+type Zsys_BootNextServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysBootNextServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysBootNextServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Zsys_SaveSystemState_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(SaveSystemStateRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1145,6 +1815,221 @@ func (x *zsysRemoveUserStateServer) Send(m *LogResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Zsys_ExportState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).ExportState(m, &zsysExportStateServer{stream})
+}
+
+type Zsys_ExportStateServer interface {
+	Send(*ExportStateResponse) error
+	grpc.ServerStream
+}
+
+type zsysExportStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysExportStateServer) Send(m *ExportStateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_ImportState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ZsysServer).ImportState(&zsysImportStateServer{stream})
+}
+
+type Zsys_ImportStateServer interface {
+	Send(*CreateSaveStateResponse) error
+	Recv() (*ImportStateRequest, error)
+	grpc.ServerStream
+}
+
+type zsysImportStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysImportStateServer) Send(m *CreateSaveStateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *zsysImportStateServer) Recv() (*ImportStateRequest, error) {
+	m := new(ImportStateRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Zsys_RestoreUserState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RestoreUserStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).RestoreUserState(m, &zsysRestoreUserStateServer{stream})
+}
+
+type Zsys_RestoreUserStateServer interface {
+	Send(*CreateSaveStateResponse) error
+	grpc.ServerStream
+}
+
+type zsysRestoreUserStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysRestoreUserStateServer) Send(m *CreateSaveStateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_RevertDatasets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RevertDatasetsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).RevertDatasets(m, &zsysRevertDatasetsServer{stream})
+}
+
+type Zsys_RevertDatasetsServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysRevertDatasetsServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysRevertDatasetsServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_StatePath_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StatePathRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).StatePath(m, &zsysStatePathServer{stream})
+}
+
+type Zsys_StatePathServer interface {
+	Send(*StatePathResponse) error
+	grpc.ServerStream
+}
+
+type zsysStatePathServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysStatePathServer) Send(m *StatePathResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_StateDiff_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StateDiffRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).StateDiff(m, &zsysStateDiffServer{stream})
+}
+
+type Zsys_StateDiffServer interface {
+	Send(*StateDiffResponse) error
+	grpc.ServerStream
+}
+
+type zsysStateDiffServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysStateDiffServer) Send(m *StateDiffResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_TagState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TagStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).TagState(m, &zsysTagStateServer{stream})
+}
+
+type Zsys_TagStateServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysTagStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysTagStateServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_UntagState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UntagStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).UntagState(m, &zsysUntagStateServer{stream})
+}
+
+type Zsys_UntagStateServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysUntagStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysUntagStateServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_PinState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PinStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).PinState(m, &zsysPinStateServer{stream})
+}
+
+type Zsys_PinStateServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysPinStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysPinStateServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_StateUsage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StateUsageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).StateUsage(m, &zsysStateUsageServer{stream})
+}
+
+type Zsys_StateUsageServer interface {
+	Send(*StateUsageResponse) error
+	grpc.ServerStream
+}
+
+type zsysStateUsageServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysStateUsageServer) Send(m *StateUsageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Zsys_DumpStates_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(Empty)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1313,6 +2198,69 @@ func (x *zsysGCServer) Send(m *LogResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Zsys_GCPlan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GCRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).GCPlan(m, &zsysGCPlanServer{stream})
+}
+
+type Zsys_GCPlanServer interface {
+	Send(*GCPlanResponse) error
+	grpc.ServerStream
+}
+
+type zsysGCPlanServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysGCPlanServer) Send(m *GCPlanResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_GCSimulate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GCSimulateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).GCSimulate(m, &zsysGCSimulateServer{stream})
+}
+
+type Zsys_GCSimulateServer interface {
+	Send(*GCSimulateResponse) error
+	grpc.ServerStream
+}
+
+type zsysGCSimulateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysGCSimulateServer) Send(m *GCSimulateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zsys_Replicate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZsysServer).Replicate(m, &zsysReplicateServer{stream})
+}
+
+type Zsys_ReplicateServer interface {
+	Send(*LogResponse) error
+	grpc.ServerStream
+}
+
+type zsysReplicateServer struct {
+	grpc.ServerStream
+}
+
+func (x *zsysReplicateServer) Send(m *LogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Zsys_MachineShow_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(MachineShowRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1373,6 +2321,16 @@ var Zsys_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Zsys_CreateUserData_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "MountUserData",
+			Handler:       _Zsys_MountUserData_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LoadUserKeys",
+			Handler:       _Zsys_LoadUserKeys_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "ChangeHomeOnUserData",
 			Handler:       _Zsys_ChangeHomeOnUserData_Handler,
@@ -1403,6 +2361,11 @@ var Zsys_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Zsys_UpdateLastUsed_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "BootNext",
+			Handler:       _Zsys_BootNext_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "SaveSystemState",
 			Handler:       _Zsys_SaveSystemState_Handler,
@@ -1423,6 +2386,57 @@ var Zsys_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Zsys_RemoveUserState_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExportState",
+			Handler:       _Zsys_ExportState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportState",
+			Handler:       _Zsys_ImportState_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RestoreUserState",
+			Handler:       _Zsys_RestoreUserState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RevertDatasets",
+			Handler:       _Zsys_RevertDatasets_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StatePath",
+			Handler:       _Zsys_StatePath_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StateDiff",
+			Handler:       _Zsys_StateDiff_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TagState",
+			Handler:       _Zsys_TagState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UntagState",
+			Handler:       _Zsys_UntagState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PinState",
+			Handler:       _Zsys_PinState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StateUsage",
+			Handler:       _Zsys_StateUsage_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "DumpStates",
 			Handler:       _Zsys_DumpStates_Handler,
@@ -1463,6 +2477,21 @@ var Zsys_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Zsys_GC_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GCPlan",
+			Handler:       _Zsys_GCPlan_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GCSimulate",
+			Handler:       _Zsys_GCSimulate_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Replicate",
+			Handler:       _Zsys_Replicate_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "MachineShow",
 			Handler:       _Zsys_MachineShow_Handler,